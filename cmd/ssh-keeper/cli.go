@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"ssh-keeper/internal/ssh"
+)
+
+// runSubcommand выполняет подкоманду командной строки и завершает процесс с ее кодом
+func runSubcommand(run func(args []string) error, args []string) {
+	err := run(args)
+	// Пароли, которые ssh так и не запросил, не остаются на диске
+	ssh.RemoveAskPassFiles()
+	if err == nil {
+		return
+	}
+
+	// Справка уже выведена пакетом flag
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+
+	// Внешняя команда (ssh, scp, rsync) уже вывела ошибку - передаем ее код выхода
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/ssh"
+)
+
+// copyOperand аргумент команды cp: локальный путь или путь на сервере
+type copyOperand struct {
+	connection *models.Connection
+	path       string
+}
+
+// runCopy реализует команду `ssh-keeper cp [-r] [-rsync|-scp] <источник>... <назначение>`.
+// Путь на сервере задается как <имя подключения>:<путь>
+func runCopy(args []string) error {
+	flags := flag.NewFlagSet("cp", flag.ContinueOnError)
+	recursive := flags.Bool("r", false, "копировать каталоги рекурсивно")
+	useRsync := flags.Bool("rsync", false, "использовать rsync")
+	useSCP := flags.Bool("scp", false, "использовать scp")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ssh-keeper cp [-r] [-rsync|-scp] <source>... <destination>\n")
+		fmt.Fprintf(flags.Output(), "\nRemote paths are written as <connection name>:<path>, e.g.\n")
+		fmt.Fprintf(flags.Output(), "  ssh-keeper cp ./app.conf prod-web:/tmp/\n")
+		fmt.Fprintf(flags.Output(), "  ssh-keeper cp prod-db:/var/log/x.log .\n")
+		fmt.Fprintf(flags.Output(), "\nA prefix that is not a connection name keeps the path local (notes:v2.txt);\n")
+		fmt.Fprintf(flags.Output(), "write ./notes:v2.txt to keep it local even if a connection is named notes.\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return fmt.Errorf("нужно указать источник и назначение")
+	}
+	if *useRsync && *useSCP {
+		return fmt.Errorf("флаги -rsync и -scp взаимоисключающие")
+	}
+
+	backend := ssh.CopyBackendAuto
+	if *useRsync {
+		backend = ssh.CopyBackendRsync
+	}
+	if *useSCP {
+		backend = ssh.CopyBackendSCP
+	}
+
+	if err := initializeServices(true); err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+
+	operands := make([]copyOperand, 0, flags.NArg())
+	for _, arg := range flags.Args() {
+		operand, err := parseCopyOperand(arg)
+		if err != nil {
+			return err
+		}
+		operands = append(operands, operand)
+	}
+
+	// Все пути на сервере должны относиться к одному подключению,
+	// и хотя бы одна сторона должна быть локальной
+	var connection *models.Connection
+	for _, operand := range operands {
+		if operand.connection == nil {
+			continue
+		}
+		if connection != nil && connection.ID != operand.connection.ID {
+			return fmt.Errorf("копирование между разными серверами не поддерживается")
+		}
+		connection = operand.connection
+	}
+	if connection == nil {
+		return fmt.Errorf("ни один из путей не относится к сохраненному подключению (формат <имя>:<путь>)")
+	}
+
+	destination := operands[len(operands)-1]
+	sources := operands[:len(operands)-1]
+	for _, source := range sources {
+		if (source.connection == nil) == (destination.connection == nil) {
+			return fmt.Errorf("одна сторона копирования должна быть локальной, другая - на сервере")
+		}
+	}
+
+	sourcePaths := make([]string, 0, len(sources))
+	for _, source := range sources {
+		sourcePaths = append(sourcePaths, source.argument())
+	}
+
+	cmd, err := ssh.CopyCommand(connection, sourcePaths, destination.argument(), ssh.CopyOptions{
+		Recursive: *recursive,
+		Backend:   backend,
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.Run()
+}
+
+// parseCopyOperand определяет, является ли аргумент путем на сервере.
+// Префикс до двоеточия считается именем подключения; пути со слешем до двоеточия,
+// буквы дисков Windows (C:\file) и префиксы, не совпадающие ни с одним
+// подключением (notes:v2.txt), остаются локальными
+func parseCopyOperand(arg string) (copyOperand, error) {
+	index := strings.Index(arg, ":")
+	if index <= 0 {
+		return copyOperand{path: arg}, nil
+	}
+
+	name := arg[:index]
+	// Явный путь вида ./a:b или /tmp/a:b всегда локальный
+	if strings.ContainsAny(name, `/\`) {
+		return copyOperand{path: arg}, nil
+	}
+
+	if !connectionService.HasConnectionNamed(name) {
+		return copyOperand{path: arg}, nil
+	}
+	connection, err := connectionService.FindConnectionByName(name)
+	if err != nil {
+		return copyOperand{}, err
+	}
+	if connection.Template {
+		return copyOperand{}, fmt.Errorf("'%s' - шаблон, а не сервер", connection.Name)
+	}
+
+	path := arg[index+1:]
+	if path == "" {
+		// Пустой путь - домашний каталог пользователя на сервере
+		path = "."
+	}
+
+	return copyOperand{connection: connection, path: path}, nil
+}
+
+// argument возвращает аргумент для scp/rsync. Локальный путь с двоеточием до первого
+// слеша scp и rsync приняли бы за путь на сервере, поэтому он получает префикс ./
+func (co copyOperand) argument() string {
+	if co.connection != nil {
+		return ssh.RemotePath(co.connection, co.path)
+	}
+	if prefix, _, found := strings.Cut(co.path, ":"); found && len(prefix) > 1 && !strings.ContainsAny(prefix, `/\`) {
+		return "./" + co.path
+	}
+	return co.path
+}
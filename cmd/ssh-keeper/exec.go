@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/ssh"
+)
+
+// runExec реализует команду `ssh-keeper exec [-t тег] [-c имя,...] [-p N] <команда>`.
+// Команда выполняется параллельно на всех выбранных подключениях
+func runExec(args []string) error {
+	flags := flag.NewFlagSet("exec", flag.ContinueOnError)
+	tag := flags.String("t", "", "выполнить на подключениях с тегом или группой")
+	names := flags.String("c", "", "имена подключений через запятую")
+	limit := flags.Int("p", ssh.DefaultExecConcurrency, "сколько серверов обрабатывать одновременно")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ssh-keeper exec [-t tag] [-c name[,name...]] [-p N] <command>\n")
+		fmt.Fprintf(flags.Output(), "\nRuns the command on every selected connection in parallel, e.g.\n")
+		fmt.Fprintf(flags.Output(), "  ssh-keeper exec -t prod 'uptime'\n")
+		fmt.Fprintf(flags.Output(), "  ssh-keeper exec -c web-1,web-2 -p 2 'sudo systemctl restart nginx'\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("не указана команда")
+	}
+	if *tag == "" && *names == "" {
+		flags.Usage()
+		return fmt.Errorf("укажите подключения через -t или -c")
+	}
+	command := strings.Join(flags.Args(), " ")
+
+	if err := initializeServices(true); err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+
+	connections, err := selectExecTargets(*tag, *names)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Выравниваем префиксы строк по самому длинному имени
+	width := 0
+	for _, conn := range connections {
+		if len(conn.Name) > width {
+			width = len(conn.Name)
+		}
+	}
+
+	events := make(chan ssh.ExecEvent)
+	go ssh.RunParallel(ctx, connections, command, *limit, events)
+
+	results := make([]ssh.ExecResult, len(connections))
+	for event := range events {
+		name := connections[event.Index].Name
+		switch {
+		case event.Done:
+			results[event.Index] = event.Result
+		case event.Stderr:
+			fmt.Fprintf(os.Stderr, "%-*s | %s\n", width, name, event.Line)
+		default:
+			fmt.Fprintf(os.Stdout, "%-*s | %s\n", width, name, event.Line)
+		}
+	}
+
+	// Итог по каждому серверу
+	fmt.Println()
+	var failed []string
+	for i, result := range results {
+		status := "ok"
+		if result.Failed() {
+			status = execStatus(result)
+			failed = append(failed, fmt.Sprintf("%s (%s)", connections[i].Name, status))
+		}
+		fmt.Printf("%-*s  %-12s %s\n", width, connections[i].Name, status, result.Duration.Round(100*time.Millisecond))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("команда завершилась с ошибкой на %d из %d серверов: %s",
+			len(failed), len(connections), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// selectExecTargets выбирает подключения по тегу/группе и по списку имен
+func selectExecTargets(tag, names string) ([]models.Connection, error) {
+	var connections []models.Connection
+	seen := make(map[string]bool)
+
+	if tag != "" {
+		for _, conn := range connectionService.GetAllConnections() {
+			if conn.HasTag(tag) && !conn.Template {
+				connections = append(connections, conn)
+				seen[conn.ID] = true
+			}
+		}
+		if len(connections) == 0 {
+			return nil, fmt.Errorf("нет подключений с тегом или группой '%s'", tag)
+		}
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		conn, err := connectionService.FindConnectionByName(name)
+		if err != nil {
+			return nil, err
+		}
+		if conn.Template {
+			return nil, fmt.Errorf("'%s' - шаблон, а не сервер", conn.Name)
+		}
+		if !seen[conn.ID] {
+			connections = append(connections, *conn)
+			seen[conn.ID] = true
+		}
+	}
+
+	return connections, nil
+}
+
+// execStatus возвращает краткое описание неуспешного результата
+func execStatus(result ssh.ExecResult) string {
+	if result.Err != nil {
+		if result.Err == context.Canceled {
+			return "canceled"
+		}
+		return "error: " + result.Err.Error()
+	}
+	return fmt.Sprintf("exit %d", result.ExitCode)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/services"
+)
+
+// useTestConnections подменяет сервис подключений на сервис во временном каталоге
+func useTestConnections(t *testing.T, conns ...*models.Connection) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	service := services.NewConnectionService(filepath.Join(t.TempDir(), "config"))
+	for _, conn := range conns {
+		if err := service.AddConnection(conn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previous := connectionService
+	connectionService = service
+	t.Cleanup(func() { connectionService = previous })
+}
+
+func TestSelectExecTargets(t *testing.T) {
+	connection := func(name, group string, tags ...string) *models.Connection {
+		conn := models.NewConnection(name, name+".example.com", "deploy")
+		conn.UseSSHKey = true
+		conn.Group = group
+		conn.Tags = tags
+		return conn
+	}
+	template := connection("prod-defaults", "prod")
+	template.Template = true
+	useTestConnections(t,
+		connection("web-1", "prod", "web"),
+		connection("web-2", "stage", "web"),
+		connection("db-1", "prod", "db"),
+		template,
+	)
+
+	tests := []struct {
+		name    string
+		tag     string
+		names   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "tag", tag: "web", want: []string{"web-1", "web-2"}},
+		// Группа тоже считается тегом, шаблоны пропускаются
+		{name: "group", tag: "PROD", want: []string{"web-1", "db-1"}},
+		{name: "names", names: "db-1, web-2", want: []string{"db-1", "web-2"}},
+		{name: "name in another case", names: "WEB-1", want: []string{"web-1"}},
+		{name: "tag and names without duplicates", tag: "web", names: "web-1,db-1", want: []string{"web-1", "web-2", "db-1"}},
+		{name: "unknown tag", tag: "missing", wantErr: true},
+		{name: "unknown name", names: "web-1,missing", wantErr: true},
+		{name: "template by name", names: "prod-defaults", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conns, err := selectExecTargets(tt.tag, tt.names)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %d connections", len(conns))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, conn := range conns {
+				names = append(names, conn.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("targets = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"ssh-keeper/internal/services"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// bundlePassphraseEnv переменная окружения с паролем зашифрованного экспорта для скриптов
+const bundlePassphraseEnv = "SSH_KEEPER_BUNDLE_PASSPHRASE"
+
+// runExport реализует команду `ssh-keeper export [--format F] [-g группа] [-t тег] [-o файл]`.
+// Без -o результат выводится в stdout, чтобы его можно было передать другой программе
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatName := flags.String("format", string(services.ExportFormatOpenSSH), "формат: "+exportFormatNames())
+	group := flags.String("g", "", "только подключения группы")
+	tag := flags.String("t", "", "только подключения с тегом")
+	secrets := flags.String("secrets", string(services.SecretsOmit), "пароли: omit, encrypt (мастер-паролем) или plain")
+	output := flags.String("o", "", "записать в файл вместо stdout")
+	knownHosts := flags.String("known-hosts", "", "файлы known_hosts через запятую (по умолчанию ~/.ssh/known_hosts)")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ssh-keeper export [--format format] [-g group] [-t tag] [--secrets mode] [-o file]\n")
+		fmt.Fprintf(flags.Output(), "\nWrites the stored connections in the chosen format, e.g.\n")
+		fmt.Fprintf(flags.Output(), "  ssh-keeper export --format ansible-ini -g prod > inventory.ini\n")
+		fmt.Fprintf(flags.Output(), "  ssh-keeper export --format ansible-yaml -o inventory.yml\n")
+		fmt.Fprintf(flags.Output(), "  ssh-keeper export --format known-hosts >> ~/.ssh/known_hosts_managed\n")
+		fmt.Fprintf(flags.Output(), "\nThe bundle format reads its passphrase from %s or prompts for it.\n\n", bundlePassphraseEnv)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("лишние аргументы: %s", strings.Join(flags.Args(), " "))
+	}
+
+	format, err := services.ParseExportFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	opts := services.ExportOptions{
+		Format:  format,
+		Group:   *group,
+		Tag:     *tag,
+		Secrets: services.SecretsMode(*secrets),
+	}
+	switch opts.Secrets {
+	case services.SecretsOmit, services.SecretsEncrypt, services.SecretsPlain:
+	default:
+		return fmt.Errorf("неизвестный режим паролей %q (omit, encrypt, plain)", *secrets)
+	}
+	for _, path := range strings.Split(*knownHosts, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			opts.KnownHostsFiles = append(opts.KnownHostsFiles, path)
+		}
+	}
+
+	// Собственный формат и зашифрованный файл пишутся только в файл
+	if (format == services.ExportFormatKeeper || format == services.ExportFormatBundle) && *output == "" {
+		return fmt.Errorf("формат %s записывается только в файл: укажите -o", format)
+	}
+	if format == services.ExportFormatBundle {
+		if opts.Passphrase, err = readBundlePassphrase(); err != nil {
+			return err
+		}
+	}
+
+	if err := initializeServices(true); err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+
+	if *output != "" {
+		count, err := connectionService.Export(*output, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d connections to %s (%s)\n", count, *output, format)
+		return nil
+	}
+
+	connections, err := connectionService.ExportConnections(opts)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(os.Stdout)
+	if err := services.WriteExport(writer, connections, opts); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+// exportFormatNames возвращает список форматов экспорта для справки
+func exportFormatNames() string {
+	names := make([]string, 0, len(services.ExportFormats))
+	for _, format := range services.ExportFormats {
+		names = append(names, string(format))
+	}
+	return strings.Join(names, ", ")
+}
+
+// readBundlePassphrase берет пароль зашифрованного экспорта из окружения
+// или запрашивает его дважды в терминале
+func readBundlePassphrase() (string, error) {
+	if passphrase := os.Getenv(bundlePassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return "", fmt.Errorf("задайте пароль файла в %s", bundlePassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, "Bundle passphrase: ")
+	passphrase, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+	confirm, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	if string(passphrase) != string(confirm) {
+		return "", fmt.Errorf("пароли не совпадают")
+	}
+	if len(passphrase) < services.MinBundlePassphraseLength {
+		return "", fmt.Errorf("пароль файла должен содержать минимум %d символов", services.MinBundlePassphraseLength)
+	}
+	return string(passphrase), nil
+}
@@ -11,6 +11,7 @@ import (
 
 	"ssh-keeper/internal/config"
 	"ssh-keeper/internal/services"
+	"ssh-keeper/internal/ssh"
 	"ssh-keeper/internal/ui/screens"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -23,6 +24,12 @@ var (
 	connectionService *services.ConnectionService
 )
 
+// Результат синхронизации при запуске, если она настроена
+var (
+	startupSync    *services.SyncResult
+	startupSyncErr error
+)
+
 // Build-time variables
 var (
 	version      string
@@ -67,6 +74,12 @@ func isTerminal(file *os.File) bool {
 }
 
 func main() {
+	// ssh вызывает нас как SSH_ASKPASS программу для передачи сохраненного пароля
+	if ssh.IsAskPassInvocation() {
+		ssh.RunAskPass()
+		return
+	}
+
 	// Проверяем флаги командной строки
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
@@ -77,9 +90,35 @@ func main() {
 			fmt.Printf("SSH Keeper - Secure SSH Connection Manager\n")
 			fmt.Printf("Version: %s\n", version)
 			fmt.Printf("\nUsage: ssh-keeper [options]\n")
+			fmt.Printf("       ssh-keeper <command> [arguments]\n")
 			fmt.Printf("\nOptions:\n")
 			fmt.Printf("  --version, -v    Show version information\n")
 			fmt.Printf("  --help, -h       Show this help message\n")
+			fmt.Printf("\nCommands:\n")
+			fmt.Printf("  cp               Copy files to/from a saved connection (scp/rsync)\n")
+			fmt.Printf("  exec             Run a command on several connections in parallel\n")
+			fmt.Printf("  export           Export connections (OpenSSH, Ansible inventory, known_hosts, ...)\n")
+			fmt.Printf("  mux              Open several connections in tmux/screen windows or panes\n")
+			fmt.Printf("  sync             Sync connections with the git repository (SYNC_GIT_REMOTE)\n")
+			fmt.Printf("  team             Share connections in a team vault (per-member keys)\n")
+			return
+		case "cp":
+			runSubcommand(runCopy, os.Args[2:])
+			return
+		case "exec":
+			runSubcommand(runExec, os.Args[2:])
+			return
+		case "mux":
+			runSubcommand(runMux, os.Args[2:])
+			return
+		case "export":
+			runSubcommand(runExport, os.Args[2:])
+			return
+		case "sync":
+			runSubcommand(runSync, os.Args[2:])
+			return
+		case "team":
+			runSubcommand(runTeam, os.Args[2:])
 			return
 		}
 	}
@@ -92,6 +131,7 @@ func main() {
 
 	// Убеждаемся, что терминал восстановится при выходе
 	defer restoreTerminal()
+	defer ssh.RemoveAskPassFiles()
 
 	// Set up signal handler to restore terminal on exit
 	c := make(chan os.Signal, 1)
@@ -99,11 +139,12 @@ func main() {
 	go func() {
 		<-c
 		restoreTerminal()
+		ssh.RemoveAskPassFiles()
 		os.Exit(0)
 	}()
 
 	// Initialize services
-	if err := initializeServices(); err != nil {
+	if err := initializeServices(false); err != nil {
 		fmt.Printf("Error initializing services: %v\n", err)
 		os.Exit(1)
 	}
@@ -118,6 +159,7 @@ func main() {
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running application: %v\n", err)
 		restoreTerminal()
+		ssh.RemoveAskPassFiles()
 		os.Exit(1)
 	}
 
@@ -127,8 +169,110 @@ func main() {
 	// Terminal will be restored by signal handler if needed
 }
 
-// initializeServices initializes all application services
-func initializeServices() error {
+// initializeServices initializes all application services. readOnly is set by
+// subcommands that only read connections (cp, exec, export, mux): they skip the git
+// sync and do not rewrite the Include file, so they start without network access
+func initializeServices(readOnly bool) error {
+	cfg, configPath, encryptionService, err := initializeEncryption()
+	if err != nil {
+		return err
+	}
+	configDir := filepath.Dir(configPath)
+
+	// Initialize connection service
+	connectionService = services.NewConnectionService(configPath)
+
+	// Командное хранилище открывается личным ключом; без него пароли не прочитать,
+	// а сохранение перезаписало бы общий файл
+	if err := connectionService.OpenTeamVault(encryptionService); err != nil {
+		return fmt.Errorf("failed to open team vault: %w", err)
+	}
+
+	// Синхронизация через git: изменения с других машин получаем до первого показа подключений
+	if remote := cfg.GetSyncGitRemote(); remote != "" && !readOnly {
+		syncDir := filepath.Join(configDir, services.SyncDirName)
+		connectionService.SetGitSync(services.NewGitSync(syncDir, config.ExpandPath(remote), cfg.GetSyncGitBranch()))
+		startupSync, startupSyncErr = connectionService.Sync()
+		if startupSyncErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: git sync failed: %v\n", startupSyncErr)
+		}
+	}
+
+	// Initialize with sample data if no config exists. With a sync remote the samples
+	// would be pushed into the shared repository (an offline or empty remote also
+	// leaves the file missing), so they are only created for a standalone store
+	if cfg.GetSyncGitRemote() == "" {
+		if err := connectionService.InitializeWithSampleData(); err != nil {
+			return fmt.Errorf("failed to initialize with sample data: %w", err)
+		}
+	}
+
+	// Set global service
+	services.SetGlobalConnectionService(connectionService)
+
+	// Подключения по ключу доступны обычному ssh через Include в ~/.ssh/config
+	sshConfigPath := config.ExpandPath(cfg.GetSSHConfigPath())
+	include := services.NewSSHConfigInclude(sshConfigPath)
+	if cfg.IsSSHConfigIncludeEnabled() && !readOnly {
+		if err := connectionService.SetSSHConfigInclude(include); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write %s: %v\n", include.Path(), err)
+		}
+	}
+
+	// Хосты из ~/.ssh/config показываются рядом с подключениями только для чтения;
+	// файл для Include не читается: в нем те же подключения SSH Keeper
+	if cfg.IsSSHConfigMirrorEnabled() {
+		mirror := services.NewSSHConfigMirror(sshConfigPath, include.Path())
+		if _, err := mirror.Refresh(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read SSH config: %v\n", err)
+		}
+		services.SetGlobalSSHConfigMirror(mirror)
+	}
+
+	// Формат экспорта по умолчанию для экрана экспорта
+	if format, err := services.ParseExportFormat(cfg.GetExportFormat()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: EXPORT_FORMAT: %v\n", err)
+	} else {
+		services.SetGlobalExportFormat(format)
+	}
+
+	// Initialize SSH key service
+	services.SetGlobalSSHKeyService(services.NewSSHKeyService())
+
+	// Initialize session history service
+	services.SetGlobalHistoryService(services.NewHistoryService(filepath.Join(configDir, services.HistoryFileName)))
+
+	// Initialize session recording service
+	services.SetGlobalRecordingService(services.NewRecordingService(configDir, encryptionService))
+
+	// Каталог сокетов ControlMaster для подключений с общим каналом
+	if err := ssh.SetControlDir(filepath.Join(configDir, ssh.ControlDirName)); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	// // Инициализируем сервис автоматических обновлений
+	// autoUpdateService := services.NewAutoUpdateService(cfg)
+	// services.SetGlobalAutoUpdateService(autoUpdateService)
+
+	// // Выполняем автоматическую проверку обновлений если нужно
+	// if autoUpdateService.CheckIfUpdateNeeded() {
+	// 	go func() {
+	// 		updateInfo, err := autoUpdateService.PerformAutoCheck()
+	// 		if err != nil {
+	// 			fmt.Printf("Auto-update check failed: %v\n", err)
+	// 		} else if updateInfo != nil && updateInfo.IsAvailable {
+	// 			// Уведомление об обновлениях временно отключено
+	// 			// fmt.Printf("🔄 New version %s is available! Check Settings > Updates to install.\n", updateInfo.Version)
+	// 		}
+	// 	}()
+	// }
+
+	return nil
+}
+
+// initializeEncryption загружает конфигурацию, создает каталог конфигурации и
+// шифрование мастер-паролем. Возвращает путь к файлу подключений
+func initializeEncryption() (*config.Config, string, *services.EncryptionService, error) {
 	// Устанавливаем встроенную подпись если она есть (из CI build)
 	// Это нужно сделать ДО загрузки конфигурации
 	if appSignature != "" {
@@ -138,7 +282,7 @@ func initializeServices() error {
 	// Инициализируем конфигурацию (это загрузит .env файл если он есть)
 	cfg, err := config.Init()
 	if err != nil {
-		return fmt.Errorf("failed to initialize config: %w", err)
+		return nil, "", nil, fmt.Errorf("failed to initialize config: %w", err)
 	}
 
 	// Получаем путь к конфигурации из настроек
@@ -148,7 +292,7 @@ func initializeServices() error {
 	if strings.HasPrefix(configPath, "~/") {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
-			return fmt.Errorf("failed to get user home directory: %w", err)
+			return nil, "", nil, fmt.Errorf("failed to get user home directory: %w", err)
 		}
 		configPath = filepath.Join(homeDir, configPath[2:])
 	}
@@ -156,7 +300,7 @@ func initializeServices() error {
 	// Создаем директорию конфигурации если она не существует
 	configDir := filepath.Dir(configPath)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+		return nil, "", nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
 	// Initialize master password service
@@ -174,35 +318,7 @@ func initializeServices() error {
 		}
 	}
 
-	// Initialize connection service
-	connectionService = services.NewConnectionService(configPath)
-
-	// Initialize with sample data if no config exists
-	if err := connectionService.InitializeWithSampleData(); err != nil {
-		return fmt.Errorf("failed to initialize with sample data: %w", err)
-	}
-
-	// Set global service
-	services.SetGlobalConnectionService(connectionService)
-
-	// // Инициализируем сервис автоматических обновлений
-	// autoUpdateService := services.NewAutoUpdateService(cfg)
-	// services.SetGlobalAutoUpdateService(autoUpdateService)
-
-	// // Выполняем автоматическую проверку обновлений если нужно
-	// if autoUpdateService.CheckIfUpdateNeeded() {
-	// 	go func() {
-	// 		updateInfo, err := autoUpdateService.PerformAutoCheck()
-	// 		if err != nil {
-	// 			fmt.Printf("Auto-update check failed: %v\n", err)
-	// 		} else if updateInfo != nil && updateInfo.IsAvailable {
-	// 			// Уведомление об обновлениях временно отключено
-	// 			// fmt.Printf("🔄 New version %s is available! Check Settings > Updates to install.\n", updateInfo.Version)
-	// 		}
-	// 	}()
-	// }
-
-	return nil
+	return cfg, configPath, encryptionService, nil
 }
 
 // GetConnectionService returns the global connection service
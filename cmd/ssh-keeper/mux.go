@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"ssh-keeper/internal/ssh"
+)
+
+// runMux реализует команду `ssh-keeper mux [-t тег] [-c имя,...] [-s сессия] [-panes] [-sync] [-screen]`.
+// Подключения открываются окнами или панелями сессии tmux (или GNU screen)
+func runMux(args []string) error {
+	flags := flag.NewFlagSet("mux", flag.ContinueOnError)
+	tag := flags.String("t", "", "открыть подключения с тегом или группой")
+	names := flags.String("c", "", "имена подключений через запятую")
+	session := flags.String("s", ssh.DefaultMultiplexSession, "имя сессии tmux/screen")
+	panes := flags.Bool("panes", false, "открыть подключения панелями одного окна (tmux)")
+	synchronize := flags.Bool("sync", false, "вводить во все панели одновременно (tmux, включает -panes)")
+	useScreen := flags.Bool("screen", false, "использовать GNU screen вместо tmux")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ssh-keeper mux [-t tag] [-c name[,name...]] [-s session] [-panes] [-sync] [-screen]\n")
+		fmt.Fprintf(flags.Output(), "\nOpens the selected connections as tmux windows or panes (GNU screen windows if tmux is missing), e.g.\n")
+		fmt.Fprintf(flags.Output(), "  ssh-keeper mux -t prod\n")
+		fmt.Fprintf(flags.Output(), "  ssh-keeper mux -c web-1,web-2 -sync\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *tag == "" && *names == "" {
+		flags.Usage()
+		return fmt.Errorf("укажите подключения через -t или -c")
+	}
+
+	multiplexer := ssh.MultiplexerScreen
+	if !*useScreen {
+		detected, err := ssh.DetectMultiplexer()
+		if err != nil {
+			return err
+		}
+		multiplexer = detected
+	}
+
+	if err := initializeServices(true); err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+
+	connections, err := selectExecTargets(*tag, *names)
+	if err != nil {
+		return err
+	}
+
+	attach, err := ssh.OpenMultiplexed(multiplexer, connections, ssh.MultiplexOptions{
+		Session:     *session,
+		Panes:       *panes,
+		Synchronize: *synchronize,
+	})
+	if err != nil {
+		return err
+	}
+	if attach == nil {
+		return nil
+	}
+
+	attach.Stdin = os.Stdin
+	attach.Stdout = os.Stdout
+	attach.Stderr = os.Stderr
+	return attach.Run()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// runSync реализует команду `ssh-keeper sync`: синхронизирует подключения с
+// git-репозиторием из SYNC_GIT_REMOTE и выводит результат
+func runSync(args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ssh-keeper sync\n")
+		fmt.Fprintf(flags.Output(), "\nMerges the stored connections with the git repository set in SYNC_GIT_REMOTE\n")
+		fmt.Fprintf(flags.Output(), "(branch SYNC_GIT_BRANCH, default main) and pushes the result, e.g.\n")
+		fmt.Fprintf(flags.Output(), "  git init --bare /srv/git/ssh-keeper.git\n")
+		fmt.Fprintf(flags.Output(), "  SYNC_GIT_REMOTE=/srv/git/ssh-keeper.git ssh-keeper sync\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("лишние аргументы: %s", strings.Join(flags.Args(), " "))
+	}
+
+	// Синхронизация выполняется при инициализации сервисов
+	if err := initializeServices(false); err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	gitSync := connectionService.GitSync()
+	if gitSync == nil {
+		return fmt.Errorf("синхронизация не настроена: задайте SYNC_GIT_REMOTE")
+	}
+	if startupSyncErr != nil {
+		return startupSyncErr
+	}
+
+	fmt.Printf("Synced %d connections with %s (%s)\n", len(connectionService.GetAllConnections()), gitSync.Remote(), gitSync.Branch())
+	switch {
+	case startupSync.Pulled && startupSync.Pushed:
+		fmt.Println("Pulled remote changes and pushed local changes")
+	case startupSync.Pulled:
+		fmt.Println("Pulled remote changes")
+	case startupSync.Pushed:
+		fmt.Println("Pushed local changes")
+	default:
+		fmt.Println("Already up to date")
+	}
+	for _, conflict := range startupSync.Conflicts {
+		kept := "remote"
+		if conflict.LocalWins {
+			kept = "local"
+		}
+		if conflict.Deleted {
+			fmt.Printf("  conflict: %s was deleted on one side and changed on the other, kept the %s change\n", conflict.Name, kept)
+		} else {
+			fmt.Printf("  conflict: %s was changed on both sides, kept the newer %s version\n", conflict.Name, kept)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"ssh-keeper/internal/services"
+)
+
+// runTeam реализует команду `ssh-keeper team <key|init|add|remove|list>`:
+// управление командным хранилищем, в котором ключ данных зашифрован для
+// открытого ключа каждого участника
+func runTeam(args []string) error {
+	flags := flag.NewFlagSet("team", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: ssh-keeper team <command> [arguments]\n")
+		fmt.Fprintf(flags.Output(), "\nCommands:\n")
+		fmt.Fprintf(flags.Output(), "  key                  Print your public key (created on first use)\n")
+		fmt.Fprintf(flags.Output(), "  init [name]          Move the connections into a new team vault with you as the only member\n")
+		fmt.Fprintf(flags.Output(), "  add <name> <key>     Give a member access with their public key\n")
+		fmt.Fprintf(flags.Output(), "  remove <name|key>    Revoke a member and re-encrypt the passwords with a new data key\n")
+		fmt.Fprintf(flags.Output(), "  list                 List the members\n")
+		fmt.Fprintf(flags.Output(), "\nYour private key is stored in %s, encrypted with your master password.\n", services.TeamIdentityFileName)
+		fmt.Fprintf(flags.Output(), "Share the vault with SYNC_GIT_REMOTE: %s is synced along with the connections.\n", services.TeamVaultFileName)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("не указана команда")
+	}
+
+	command, rest := flags.Arg(0), flags.Args()[1:]
+	expectArgs := func(min, max int) error {
+		if len(rest) < min || len(rest) > max {
+			flags.Usage()
+			return fmt.Errorf("неверное число аргументов для %s", command)
+		}
+		return nil
+	}
+
+	// Личный ключ нужен до открытия хранилища: новый участник создает его раньше, чем получает доступ
+	if command == "key" {
+		if err := expectArgs(0, 0); err != nil {
+			return err
+		}
+		_, configPath, encryptionService, err := initializeEncryption()
+		if err != nil {
+			return err
+		}
+		identityPath := filepath.Join(filepath.Dir(configPath), services.TeamIdentityFileName)
+		identity, created, err := services.LoadOrCreateTeamIdentity(identityPath, encryptionService)
+		if err != nil {
+			return err
+		}
+		if created {
+			fmt.Fprintf(os.Stderr, "Created a new key in %s\n", identityPath)
+		}
+		fmt.Println(identity.PublicKey())
+		return nil
+	}
+
+	if err := initializeServices(false); err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+
+	switch command {
+	case "init":
+		if err := expectArgs(0, 1); err != nil {
+			return err
+		}
+		name := defaultTeamMemberName()
+		if len(rest) == 1 {
+			name = rest[0]
+		}
+		if err := connectionService.CreateTeamVault(name); err != nil {
+			return err
+		}
+		fmt.Printf("Created a team vault with %d connections, member %s\n", len(connectionService.GetAllConnections()), name)
+
+	case "add":
+		if err := expectArgs(2, 2); err != nil {
+			return err
+		}
+		if err := connectionService.AddTeamMember(rest[0], rest[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Added %s to the team vault\n", rest[0])
+
+	case "remove":
+		if err := expectArgs(1, 1); err != nil {
+			return err
+		}
+		removed, err := connectionService.RemoveTeamMember(rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s; passwords are re-encrypted with a new data key\n", removed.Name)
+		fmt.Println("Passwords the member could read before are still known to them: rotate them on the servers")
+
+	case "list":
+		if err := expectArgs(0, 0); err != nil {
+			return err
+		}
+		vault := connectionService.TeamVault()
+		if vault == nil {
+			return fmt.Errorf("командное хранилище не создано: ssh-keeper team init")
+		}
+		self := connectionService.TeamIdentity().PublicKey()
+		for _, member := range vault.Members {
+			marker := " "
+			if member.PublicKey == self {
+				marker = "*"
+			}
+			fmt.Printf("%s %-20s %s\n", marker, member.Name, member.PublicKey)
+		}
+		return nil
+
+	default:
+		flags.Usage()
+		return fmt.Errorf("неизвестная команда team: %s", command)
+	}
+
+	if err := connectionService.SyncError(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: saved locally, git sync failed: %v\n", err)
+	}
+	return nil
+}
+
+// defaultTeamMemberName возвращает имя участника по умолчанию: user@hostname
+func defaultTeamMemberName() string {
+	name := "member"
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		name = current.Username
+		// В Windows имя содержит домен: DOMAIN\user
+		if _, short, ok := strings.Cut(name, `\`); ok {
+			name = short
+		}
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		name += "@" + hostname
+	}
+	return name
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
@@ -26,6 +28,8 @@ type Config struct {
 	// Настройки SSH
 	SSH struct {
 		ConfigPath string `envconfig:"SSH_CONFIG_PATH" default:"~/.ssh/config"`
+		Mirror     bool   `envconfig:"SSH_CONFIG_MIRROR" default:"false"`  // Показывать хосты из SSH конфигурации только для чтения
+		Include    bool   `envconfig:"SSH_CONFIG_INCLUDE" default:"false"` // Записывать подключения по ключу в файл для Include
 	} `envconfig:"SSH"`
 
 	// Настройки приложения
@@ -41,6 +45,17 @@ type Config struct {
 		Format string `envconfig:"LOG_FORMAT" default:"text"`
 	} `envconfig:"LOGGING"`
 
+	// Настройки экспорта
+	Export struct {
+		Format string `envconfig:"EXPORT_FORMAT" default:"openssh"` // Формат по умолчанию на экране экспорта
+	} `envconfig:"EXPORT"`
+
+	// Синхронизация подключений через git-репозиторий (выключена, если адрес не задан)
+	Sync struct {
+		GitRemote string `envconfig:"SYNC_GIT_REMOTE"`
+		GitBranch string `envconfig:"SYNC_GIT_BRANCH" default:"main"`
+	} `envconfig:"SYNC"`
+
 	// Настройки обновлений
 	Updates struct {
 		AutoCheck     bool   `envconfig:"AUTO_CHECK_UPDATES" default:"true"`
@@ -109,6 +124,45 @@ func (c *Config) GetSSHConfigPath() string {
 	return c.SSH.ConfigPath
 }
 
+// IsSSHConfigMirrorEnabled проверяет, показываются ли хосты из SSH конфигурации
+func (c *Config) IsSSHConfigMirrorEnabled() bool {
+	return c.SSH.Mirror
+}
+
+// IsSSHConfigIncludeEnabled проверяет, записываются ли подключения в файл для Include в SSH конфигурации
+func (c *Config) IsSSHConfigIncludeEnabled() bool {
+	return c.SSH.Include
+}
+
+// GetExportFormat возвращает формат экспорта по умолчанию
+func (c *Config) GetExportFormat() string {
+	return c.Export.Format
+}
+
+// GetSyncGitRemote возвращает адрес git-репозитория синхронизации
+func (c *Config) GetSyncGitRemote() string {
+	return c.Sync.GitRemote
+}
+
+// GetSyncGitBranch возвращает ветку git-репозитория синхронизации
+func (c *Config) GetSyncGitBranch() string {
+	return c.Sync.GitBranch
+}
+
+// ExpandPath разворачивает ~ в начале пути в домашнюю директорию пользователя
+func ExpandPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(homeDir, strings.TrimPrefix(path[1:], "/"))
+}
+
 // ValidateAppSignature проверяет подпись приложения
 func (c *Config) ValidateAppSignature() error {
 	// В development режиме пропускаем проверку подписи
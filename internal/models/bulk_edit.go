@@ -0,0 +1,151 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// BulkClear значение поля групповой правки, которое очищает поле
+const BulkClear = "-"
+
+// BulkEdit изменения для нескольких подключений. Пустое поле оставляет значение
+// без изменений, BulkClear очищает его
+type BulkEdit struct {
+	User     string
+	Port     int    // 0 - без изменений
+	KeyPath  string // Переводит подключение на аутентификацию по ключу; BulkClear - ключи по умолчанию
+	Group    string
+	Tags     string // "a, b" заменяет теги; "+a -b" добавляет и удаляет отдельные теги
+	JumpHost string
+}
+
+// FieldChange изменение одного поля подключения
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// IsEmpty проверяет, что правка ничего не меняет
+func (e BulkEdit) IsEmpty() bool {
+	return e == BulkEdit{}
+}
+
+// Apply применяет правку к подключению и возвращает список изменений.
+// Измененные поля перестают наследоваться от шаблона
+func (e BulkEdit) Apply(conn *Connection) []FieldChange {
+	var changes []FieldChange
+	change := func(field, inherit, old, new string) {
+		if old == new {
+			return
+		}
+		changes = append(changes, FieldChange{Field: field, Old: old, New: new})
+		conn.Override(inherit)
+	}
+
+	if e.User != "" && e.User != BulkClear {
+		old := conn.User
+		conn.User = e.User
+		change("пользователь", InheritUser, old, conn.User)
+	}
+
+	if e.Port != 0 {
+		old := conn.Port
+		conn.Port = e.Port
+		change("порт", InheritPort, fmt.Sprint(old), fmt.Sprint(conn.Port))
+	}
+
+	if e.KeyPath != "" {
+		old := authLabel(conn)
+		conn.UseSSHKey = true
+		conn.HasPassword = false
+		conn.Password = ""
+		conn.KeyPath = ""
+		if e.KeyPath != BulkClear {
+			conn.KeyPath = e.KeyPath
+		}
+		change("ключ", InheritAuth, old, authLabel(conn))
+	}
+
+	if e.Group != "" {
+		old := conn.Group
+		conn.Group = bulkValue(e.Group)
+		change("группа", InheritGroup, old, conn.Group)
+	}
+
+	if e.Tags != "" {
+		old := strings.Join(conn.Tags, ", ")
+		conn.Tags = applyTags(conn.Tags, e.Tags)
+		change("теги", InheritTags, old, strings.Join(conn.Tags, ", "))
+	}
+
+	if e.JumpHost != "" {
+		old := conn.JumpHost
+		conn.JumpHost = bulkValue(e.JumpHost)
+		change("jump хост", InheritJumpHost, old, conn.JumpHost)
+	}
+
+	return changes
+}
+
+// bulkValue возвращает новое значение поля: BulkClear очищает его
+func bulkValue(value string) string {
+	if value == BulkClear {
+		return ""
+	}
+	return value
+}
+
+// applyTags применяет правку тегов: список заменяет теги, +тег и -тег
+// добавляют и удаляют отдельные теги
+func applyTags(tags []string, spec string) []string {
+	if strings.TrimSpace(spec) == BulkClear {
+		return nil
+	}
+
+	fields := ParseTags(spec)
+	incremental := false
+	for _, field := range fields {
+		if strings.HasPrefix(field, "+") || strings.HasPrefix(field, "-") {
+			incremental = true
+			break
+		}
+	}
+	if !incremental {
+		return fields
+	}
+
+	result := slices.Clone(tags)
+	for _, field := range fields {
+		switch {
+		case strings.HasPrefix(field, "-"):
+			name := field[1:]
+			result = slices.DeleteFunc(result, func(tag string) bool {
+				return strings.EqualFold(tag, name)
+			})
+		default:
+			name := strings.TrimPrefix(field, "+")
+			if name != "" && !slices.ContainsFunc(result, func(tag string) bool {
+				return strings.EqualFold(tag, name)
+			}) {
+				result = append(result, name)
+			}
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// authLabel возвращает краткое описание аутентификации подключения
+func authLabel(conn *Connection) string {
+	switch {
+	case conn.UseSSHKey && conn.KeyPath != "":
+		return conn.KeyPath
+	case conn.UseSSHKey:
+		return "ключи по умолчанию"
+	}
+	return "пароль"
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestApplyTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		spec string
+		want []string
+	}{
+		{"replace", []string{"web", "eu"}, "db, us", []string{"db", "us"}},
+		{"clear", []string{"web"}, " - ", nil},
+		{"add", []string{"web"}, "+eu", []string{"web", "eu"}},
+		{"add existing in another case", []string{"web"}, "+WEB", []string{"web"}},
+		{"remove in another case", []string{"web", "eu"}, "-EU", []string{"web"}},
+		{"remove last", []string{"web"}, "-web", nil},
+		{"remove missing", []string{"web"}, "-db", []string{"web"}},
+		{"add and remove", []string{"web", "eu"}, "+db -eu", []string{"web", "db"}},
+		// Хотя бы один +/- делает правку построчной: тег без знака добавляется
+		{"mixed", []string{"web"}, "db, -web", []string{"db"}},
+		{"add to empty", nil, "+web", []string{"web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := slices.Clone(tt.tags)
+			if got := applyTags(tt.tags, tt.spec); !slices.Equal(got, tt.want) {
+				t.Errorf("applyTags(%q, %q) = %q, want %q", tt.tags, tt.spec, got, tt.want)
+			}
+			if !slices.Equal(tt.tags, original) {
+				t.Errorf("applyTags modified the input: %q", tt.tags)
+			}
+		})
+	}
+}
+
+func TestBulkEditApply(t *testing.T) {
+	base := Connection{
+		Name: "web", Host: "10.0.0.5", Port: 22, User: "deploy",
+		HasPassword: true, Password: "secret", Group: "prod", Tags: []string{"web"},
+		JumpHost: "bastion", Parent: "tpl", Inherit: []string{InheritUser, InheritGroup, InheritJumpHost},
+	}
+
+	tests := []struct {
+		name    string
+		edit    BulkEdit
+		check   func(Connection) bool
+		changes []string
+		inherit []string
+	}{
+		{
+			name:    "empty edit leaves everything unchanged",
+			edit:    BulkEdit{},
+			check:   func(c Connection) bool { return c.User == "deploy" && c.Group == "prod" && c.HasPassword },
+			inherit: []string{InheritUser, InheritGroup, InheritJumpHost},
+		},
+		{
+			name:    "same value is not a change",
+			edit:    BulkEdit{User: "deploy", Port: 22, Group: "prod"},
+			check:   func(c Connection) bool { return c.User == "deploy" && c.Port == 22 },
+			inherit: []string{InheritUser, InheritGroup, InheritJumpHost},
+		},
+		{
+			name:    "user and port",
+			edit:    BulkEdit{User: "admin", Port: 2222},
+			check:   func(c Connection) bool { return c.User == "admin" && c.Port == 2222 },
+			changes: []string{"пользователь", "порт"},
+			inherit: []string{InheritGroup, InheritJumpHost},
+		},
+		{
+			name:    "user cannot be cleared",
+			edit:    BulkEdit{User: BulkClear},
+			check:   func(c Connection) bool { return c.User == "deploy" },
+			inherit: []string{InheritUser, InheritGroup, InheritJumpHost},
+		},
+		{
+			name: "key switches to key auth and drops the password",
+			edit: BulkEdit{KeyPath: "~/.ssh/prod"},
+			check: func(c Connection) bool {
+				return c.UseSSHKey && c.KeyPath == "~/.ssh/prod" && !c.HasPassword && c.Password == ""
+			},
+			changes: []string{"ключ"},
+			inherit: []string{InheritUser, InheritGroup, InheritJumpHost},
+		},
+		{
+			name:    "cleared key uses default keys",
+			edit:    BulkEdit{KeyPath: BulkClear},
+			check:   func(c Connection) bool { return c.UseSSHKey && c.KeyPath == "" && !c.HasPassword },
+			changes: []string{"ключ"},
+			inherit: []string{InheritUser, InheritGroup, InheritJumpHost},
+		},
+		{
+			name:    "clear group and jump host",
+			edit:    BulkEdit{Group: BulkClear, JumpHost: BulkClear},
+			check:   func(c Connection) bool { return c.Group == "" && c.JumpHost == "" },
+			changes: []string{"группа", "jump хост"},
+			inherit: []string{InheritUser},
+		},
+		{
+			name:    "add tag",
+			edit:    BulkEdit{Tags: "+eu"},
+			check:   func(c Connection) bool { return slices.Equal(c.Tags, []string{"web", "eu"}) },
+			changes: []string{"теги"},
+			inherit: []string{InheritUser, InheritGroup, InheritJumpHost},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := base
+			conn.Tags = slices.Clone(base.Tags)
+			conn.Inherit = slices.Clone(base.Inherit)
+
+			var fields []string
+			for _, change := range tt.edit.Apply(&conn) {
+				fields = append(fields, change.Field)
+			}
+			if !slices.Equal(fields, tt.changes) {
+				t.Errorf("changes = %q, want %q", fields, tt.changes)
+			}
+			if !tt.check(conn) {
+				t.Errorf("unexpected result: %+v", conn)
+			}
+			// Измененные поля перестают наследоваться от шаблона
+			if !slices.Equal(conn.Inherit, tt.inherit) {
+				t.Errorf("Inherit = %q, want %q", conn.Inherit, tt.inherit)
+			}
+		})
+	}
+}
+
+func TestBulkEditApplyReportsOldAndNewValues(t *testing.T) {
+	conn := Connection{User: "deploy", Port: 22, HasPassword: true, Tags: []string{"web"}}
+	changes := BulkEdit{Port: 2222, KeyPath: "~/.ssh/prod", Tags: "+eu"}.Apply(&conn)
+
+	want := []FieldChange{
+		{Field: "порт", Old: "22", New: "2222"},
+		{Field: "ключ", Old: "пароль", New: "~/.ssh/prod"},
+		{Field: "теги", Old: "web", New: "web, eu"},
+	}
+	if !slices.Equal(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+	if !(BulkEdit{}).IsEmpty() || (BulkEdit{Group: BulkClear}).IsEmpty() {
+		t.Error("IsEmpty: want true only for an edit without fields")
+	}
+}
@@ -1,22 +1,36 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
 // Connection represents an SSH connection configuration
 type Connection struct {
-	ID          string    `yaml:"id"`
-	Name        string    `yaml:"name"`
-	Host        string    `yaml:"host"`
-	Port        int       `yaml:"port,omitempty"`
-	User        string    `yaml:"user"`
-	KeyPath     string    `yaml:"key_path,omitempty"`
-	UseSSHKey   bool      `yaml:"use_ssh_key"` // Whether to use SSH key authentication
-	HasPassword bool      `yaml:"has_password"`
-	Password    string    `yaml:"password,omitempty"`
-	CreatedAt   time.Time `yaml:"created_at"`
-	UpdatedAt   time.Time `yaml:"updated_at"`
+	ID            string    `yaml:"id"`
+	Name          string    `yaml:"name"`
+	Host          string    `yaml:"host"`
+	Port          int       `yaml:"port,omitempty"`
+	User          string    `yaml:"user"`
+	JumpHost      string    `yaml:"jump_host,omitempty"` // ProxyJump: [user@]host[:port][,...]
+	Group         string    `yaml:"group,omitempty"`
+	Tags          []string  `yaml:"tags,omitempty"`
+	KeyPath       string    `yaml:"key_path,omitempty"`
+	UseSSHKey     bool      `yaml:"use_ssh_key"` // Whether to use SSH key authentication
+	HasPassword   bool      `yaml:"has_password"`
+	Password      string    `yaml:"password,omitempty"`
+	RemoteCommand string    `yaml:"remote_command,omitempty"` // Команда при входе (запускается с -t)
+	WorkDir       string    `yaml:"work_dir,omitempty"`       // Рабочий каталог на сервере
+	Env           []string  `yaml:"env,omitempty"`            // KEY=VALUE для SetEnv, KEY или шаблон для SendEnv
+	Record        bool      `yaml:"record,omitempty"`         // Записывать сессии (asciicast)
+	ControlMaster bool      `yaml:"control_master,omitempty"` // Общий канал OpenSSH (ControlMaster)
+	Template      bool      `yaml:"template,omitempty"`       // Шаблон: только источник значений, к нему не подключаются
+	Parent        string    `yaml:"parent,omitempty"`         // ID шаблона или родительского подключения
+	Inherit       []string  `yaml:"inherit,omitempty"`        // Поля, которые берутся из шаблона (Inherit*)
+	Source        string    `yaml:"source,omitempty"`         // Внешний источник для синхронизации (ansible:путь к inventory)
+	SourceHost    string    `yaml:"source_host,omitempty"`    // Имя хоста в источнике
+	CreatedAt     time.Time `yaml:"created_at"`
+	UpdatedAt     time.Time `yaml:"updated_at"`
 }
 
 // NewConnection creates a new connection with default values
@@ -31,3 +45,112 @@ func NewConnection(name, host, user string) *Connection {
 		UpdatedAt: time.Now(),
 	}
 }
+
+// HasTag проверяет, относится ли подключение к тегу или группе (без учета регистра)
+func (c *Connection) HasTag(tag string) bool {
+	if tag == "" {
+		return false
+	}
+	if strings.EqualFold(c.Group, tag) {
+		return true
+	}
+	for _, t := range c.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTags разбирает список тегов, разделенных запятыми или пробелами.
+// Пустые значения и повторы отбрасываются
+func ParseTags(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+
+	tags := make([]string, 0, len(fields))
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		key := strings.ToLower(field)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, field)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// ParseEnv разбирает список переменных окружения, разделенных запятыми:
+// KEY=VALUE задает значение (SetEnv), KEY или шаблон LC_* передает локальную переменную (SendEnv).
+// Значение может содержать запятые: список делится только перед следующим NAME=, поэтому
+// переменные без значения пишутся до KEY=VALUE (так их выводит FormatEnv).
+// Записи с некорректным именем отбрасываются
+func ParseEnv(value string) []string {
+	var env []string
+	seen := make(map[string]bool)
+	for _, part := range splitEnv(value) {
+		entries := []string{part}
+		if !strings.Contains(part, "=") {
+			// Список имен без значений: в именах запятых не бывает
+			entries = strings.Split(part, ",")
+		}
+		for _, entry := range entries {
+			entry = strings.TrimSpace(entry)
+			name, _, _ := strings.Cut(entry, "=")
+			if name == "" || strings.ContainsAny(name, " \t\"'") || seen[name] {
+				continue
+			}
+			seen[name] = true
+			env = append(env, entry)
+		}
+	}
+	return env
+}
+
+// FormatEnv объединяет переменные окружения в строку, которую ParseEnv разберет обратно:
+// сначала имена без значений, затем KEY=VALUE
+func FormatEnv(env []string) string {
+	var names, values []string
+	for _, entry := range env {
+		if strings.Contains(entry, "=") {
+			values = append(values, entry)
+		} else {
+			names = append(names, entry)
+		}
+	}
+	return strings.Join(append(names, values...), ", ")
+}
+
+// splitEnv делит строку по запятым, за которыми следует NAME=
+func splitEnv(value string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] == ',' && startsWithAssignment(value[i+1:]) {
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// startsWithAssignment проверяет, начинается ли строка (без ведущих пробелов) с NAME=
+func startsWithAssignment(value string) bool {
+	value = strings.TrimLeft(value, " \t")
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == '=':
+			return i > 0
+		case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || i > 0 && c >= '0' && c <= '9':
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		// Имя без значения после KEY=VALUE считается частью значения
+		{"APP_ENV=prod, LC_*", []string{"APP_ENV=prod, LC_*"}},
+		{"LANG, LC_*, APP_ENV=prod", []string{"LANG", "LC_*", "APP_ENV=prod"}},
+		{"PATH_LIST=a,b", []string{"PATH_LIST=a,b"}},
+		{"LANG,PATH_LIST=a,b, c,DEBUG=1", []string{"LANG", "PATH_LIST=a,b, c", "DEBUG=1"}},
+		{"A=1,A=2,B C=3,=4", []string{"A=1"}},
+		{"URL=http://x/?a=1,b=2", []string{"URL=http://x/?a=1", "b=2"}},
+	}
+	for _, tt := range tests {
+		if got := ParseEnv(tt.value); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseEnv(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFormatEnvRoundTrip(t *testing.T) {
+	env := []string{"PATH_LIST=a,b", "LANG", "DEBUG=1", "LC_*"}
+	got := ParseEnv(FormatEnv(env))
+	want := []string{"LANG", "LC_*", "PATH_LIST=a,b", "DEBUG=1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseEnv(FormatEnv(%q)) = %q, want %q", env, got, want)
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// SessionRecord represents a single SSH session in the history log
+type SessionRecord struct {
+	ConnectionID string    `json:"connection_id"`
+	Name         string    `json:"name"` // Connection name at the time of the session
+	StartedAt    time.Time `json:"started_at"`
+	EndedAt      time.Time `json:"ended_at"`
+	ExitCode     int       `json:"exit_code"`
+}
+
+// Duration returns the session duration
+func (sr SessionRecord) Duration() time.Duration {
+	return sr.EndedAt.Sub(sr.StartedAt)
+}
+
+// ConnectionStats aggregates session history for a single connection
+type ConnectionStats struct {
+	ConnectionID  string
+	Name          string
+	Sessions      int
+	Failures      int // Sessions finished with a non-zero exit code
+	LastUsed      time.Time
+	LastExitCode  int
+	TotalDuration time.Duration
+}
+
+// AverageDuration returns the average session duration
+func (cs *ConnectionStats) AverageDuration() time.Duration {
+	if cs.Sessions == 0 {
+		return 0
+	}
+	return cs.TotalDuration / time.Duration(cs.Sessions)
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SourceRemovedTag тег подключений, хост которых пропал из источника синхронизации
+const SourceRemovedTag = "removed-from-source"
+
+// Поля названия и адреса подключения при сравнении импортируемых подключений.
+// Они не наследуются, но при синхронизации с источником могут измениться
+const (
+	importFieldName = "name"
+	importFieldHost = "host"
+)
+
+// importFields поля, которые сравниваются и переносятся при импорте
+var importFields = append([]string{importFieldName, importFieldHost}, InheritableFields...)
+
+// importFieldLabels названия полей для списка изменений
+var importFieldLabels = map[string]string{
+	importFieldName:      "название",
+	importFieldHost:      "адрес",
+	InheritPort:          "порт",
+	InheritUser:          "пользователь",
+	InheritJumpHost:      "jump хост",
+	InheritGroup:         "группа",
+	InheritTags:          "теги",
+	InheritAuth:          "аутентификация",
+	InheritRemoteCommand: "команда",
+	InheritWorkDir:       "рабочий каталог",
+	InheritEnv:           "окружение",
+	InheritRecord:        "запись",
+	InheritControlMaster: "общий канал",
+}
+
+// ImportDiff возвращает различия импортируемого подключения с существующим
+func ImportDiff(existing, incoming *Connection) []FieldChange {
+	var changes []FieldChange
+	for _, field := range importFields {
+		if existing.sameImportField(incoming, field) {
+			continue
+		}
+		changes = append(changes, FieldChange{
+			Field: importFieldLabels[field],
+			Old:   existing.importFieldValue(field),
+			New:   incoming.importFieldValue(field),
+		})
+	}
+	return changes
+}
+
+// MergeImported применяет импортируемое подключение к существующему. overwrite заменяет
+// все поля; иначе заполняются только пустые поля, а теги и окружение объединяются.
+// Измененные поля перестают наследоваться от шаблона
+func MergeImported(existing, incoming *Connection, overwrite bool) Connection {
+	result := *existing
+	result.Tags = slices.Clone(existing.Tags)
+	result.Env = slices.Clone(existing.Env)
+
+	for _, field := range importFields {
+		if result.sameImportField(incoming, field) {
+			continue
+		}
+
+		switch {
+		case overwrite:
+			result.copyImportField(incoming, field)
+		case field == InheritTags:
+			result.Tags = mergeValues(result.Tags, incoming.Tags, strings.EqualFold)
+		case field == InheritEnv:
+			result.Env = mergeValues(result.Env, incoming.Env, func(a, b string) bool { return a == b })
+		case result.importFieldEmpty(field):
+			result.copyImportField(incoming, field)
+		default:
+			continue
+		}
+		result.Override(field)
+	}
+	return result
+}
+
+// mergeValues добавляет к values отсутствующие в них значения other
+func mergeValues(values, other []string, equal func(a, b string) bool) []string {
+	for _, value := range other {
+		if !slices.ContainsFunc(values, func(v string) bool { return equal(v, value) }) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// sameImportField сравнивает поле импорта, включая название и адрес
+func (c *Connection) sameImportField(other *Connection, field string) bool {
+	switch field {
+	case importFieldName:
+		return c.Name == other.Name
+	case importFieldHost:
+		// Имена хостов не зависят от регистра
+		return strings.EqualFold(c.Host, other.Host)
+	}
+	return c.sameField(other, field)
+}
+
+// copyImportField копирует поле импорта, включая название и адрес
+func (c *Connection) copyImportField(src *Connection, field string) {
+	switch field {
+	case importFieldName:
+		c.Name = src.Name
+	case importFieldHost:
+		c.Host = src.Host
+	default:
+		c.copyField(src, field)
+	}
+}
+
+// importFieldEmpty проверяет, что поле не заполнено и его можно дополнить при слиянии
+func (c *Connection) importFieldEmpty(field string) bool {
+	switch field {
+	case importFieldName:
+		return c.Name == ""
+	case importFieldHost:
+		return c.Host == ""
+	case InheritPort:
+		return c.Port == 0
+	case InheritUser:
+		return c.User == ""
+	case InheritJumpHost:
+		return c.JumpHost == ""
+	case InheritGroup:
+		return c.Group == ""
+	case InheritAuth:
+		return c.UseSSHKey && c.KeyPath == ""
+	case InheritRemoteCommand:
+		return c.RemoteCommand == ""
+	case InheritWorkDir:
+		return c.WorkDir == ""
+	}
+	// Флаги записи и общего канала при слиянии не меняются
+	return false
+}
+
+// importFieldValue возвращает значение поля для списка изменений; пароль не показывается
+func (c *Connection) importFieldValue(field string) string {
+	switch field {
+	case importFieldName:
+		return c.Name
+	case importFieldHost:
+		return c.Host
+	case InheritPort:
+		return fmt.Sprint(c.Port)
+	case InheritUser:
+		return c.User
+	case InheritJumpHost:
+		return c.JumpHost
+	case InheritGroup:
+		return c.Group
+	case InheritTags:
+		return strings.Join(c.Tags, ", ")
+	case InheritAuth:
+		if !c.UseSSHKey && c.HasPassword {
+			return "сохраненный пароль"
+		}
+		return authLabel(c)
+	case InheritRemoteCommand:
+		return c.RemoteCommand
+	case InheritWorkDir:
+		return c.WorkDir
+	case InheritEnv:
+		return strings.Join(c.Env, " ")
+	case InheritRecord:
+		return onOff(c.Record)
+	case InheritControlMaster:
+		return onOff(c.ControlMaster)
+	}
+	return ""
+}
+
+// onOff возвращает описание флага
+func onOff(value bool) string {
+	if value {
+		return "вкл"
+	}
+	return "выкл"
+}
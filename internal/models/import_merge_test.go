@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestImportHost(t *testing.T) {
+	existing := *NewConnection("web1", "10.0.0.5", "deploy")
+	existing.ID = "web1"
+	incoming := *NewConnection("web1", "10.0.0.50", "deploy")
+
+	changes := ImportDiff(&existing, &incoming)
+	if len(changes) != 1 || changes[0].Field != "адрес" || changes[0].Old != "10.0.0.5" || changes[0].New != "10.0.0.50" {
+		t.Fatalf("ImportDiff = %+v, want only the address change", changes)
+	}
+
+	if merged := MergeImported(&existing, &incoming, true); merged.Host != "10.0.0.50" || merged.ID != "web1" {
+		t.Errorf("overwrite: Host = %q, ID = %q", merged.Host, merged.ID)
+	}
+	if merged := MergeImported(&existing, &incoming, false); merged.Host != "10.0.0.5" {
+		t.Errorf("merge: Host = %q, want the existing address", merged.Host)
+	}
+
+	// Регистр имени хоста не важен
+	incoming.Host = "WEB1.example.com"
+	existing.Host = "web1.example.com"
+	if changes := ImportDiff(&existing, &incoming); len(changes) != 0 {
+		t.Errorf("ImportDiff = %+v, want no changes for a case-only difference", changes)
+	}
+}
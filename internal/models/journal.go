@@ -0,0 +1,91 @@
+package models
+
+import (
+	"reflect"
+	"slices"
+	"time"
+)
+
+// JournalItem is a connection together with its position in the connection list
+type JournalItem struct {
+	Index      int        `json:"index"`
+	Connection Connection `json:"connection"`
+}
+
+// JournalEntry describes one operation on connections: the affected connections
+// before and after it. A connection missing from Before was added, a connection
+// missing from After was deleted
+type JournalEntry struct {
+	Description string        `json:"description"`
+	Time        time.Time     `json:"time"`
+	Before      []JournalItem `json:"before,omitempty"`
+	After       []JournalItem `json:"after,omitempty"`
+}
+
+// Journal holds the undo and redo stacks, most recent operation last
+type Journal struct {
+	Undo []JournalEntry `json:"undo"`
+	Redo []JournalEntry `json:"redo"`
+}
+
+// NewJournalEntry сравнивает списки подключений до и после операции и
+// возвращает запись журнала. Если ничего не изменилось, возвращает nil
+func NewJournalEntry(description string, before, after []Connection) *JournalEntry {
+	entry := &JournalEntry{Description: description, Time: time.Now()}
+	entry.Before = changedItems(before, after)
+	entry.After = changedItems(after, before)
+
+	if len(entry.Before) == 0 && len(entry.After) == 0 {
+		return nil
+	}
+	return entry
+}
+
+// changedItems возвращает подключения из conns, которых нет в other или которые в нем отличаются
+func changedItems(conns, other []Connection) []JournalItem {
+	byID := make(map[string]*Connection, len(other))
+	for i := range other {
+		byID[other[i].ID] = &other[i]
+	}
+
+	var items []JournalItem
+	for i, conn := range conns {
+		if match, ok := byID[conn.ID]; ok && reflect.DeepEqual(*match, conn) {
+			continue
+		}
+		items = append(items, JournalItem{Index: i, Connection: conn})
+	}
+	return items
+}
+
+// Revert возвращает список подключений в состояние до операции
+func (e *JournalEntry) Revert(conns []Connection) []Connection {
+	return replaceItems(conns, e.After, e.Before)
+}
+
+// Replay повторяет операцию над списком подключений
+func (e *JournalEntry) Replay(conns []Connection) []Connection {
+	return replaceItems(conns, e.Before, e.After)
+}
+
+// replaceItems убирает из списка подключения remove и вставляет insert на их прежние позиции
+func replaceItems(conns []Connection, remove, insert []JournalItem) []Connection {
+	ids := make(map[string]bool, len(remove)+len(insert))
+	for _, item := range remove {
+		ids[item.Connection.ID] = true
+	}
+	for _, item := range insert {
+		ids[item.Connection.ID] = true
+	}
+
+	result := slices.DeleteFunc(slices.Clone(conns), func(conn Connection) bool {
+		return ids[conn.ID]
+	})
+
+	// Позиции возрастают, поэтому каждая вставка попадает на свое прежнее место
+	for _, item := range insert {
+		index := min(max(item.Index, 0), len(result))
+		result = slices.Insert(result, index, item.Connection)
+	}
+	return result
+}
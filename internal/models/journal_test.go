@@ -0,0 +1,70 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func journalConnection(name, host string) Connection {
+	conn := *NewConnection(name, host, "deploy")
+	conn.ID = name
+	return conn
+}
+
+func journalNames(conns []Connection) []string {
+	var names []string
+	for _, conn := range conns {
+		names = append(names, conn.Name+"@"+conn.Host)
+	}
+	return names
+}
+
+func TestJournalEntryOwnDelta(t *testing.T) {
+	web := journalConnection("web", "10.0.0.5")
+	db := journalConnection("db", "10.0.0.6")
+	before := []Connection{web, db}
+
+	// Операция: изменен хост web
+	edited := web
+	edited.Host = "10.0.0.50"
+	entry := NewJournalEntry("edit web", before, []Connection{edited, db})
+	if entry == nil || len(entry.Before) != 1 || len(entry.After) != 1 {
+		t.Fatalf("entry = %+v, want one changed connection", entry)
+	}
+
+	// После операции синхронизация принесла чужие изменения: новый cache и правку db
+	remoteDB := db
+	remoteDB.Host = "10.0.0.60"
+	cache := journalConnection("cache", "10.0.0.7")
+	current := []Connection{edited, remoteDB, cache}
+
+	reverted := entry.Revert(current)
+	want := []string{"web@10.0.0.5", "db@10.0.0.60", "cache@10.0.0.7"}
+	if got := journalNames(reverted); !slices.Equal(got, want) {
+		t.Errorf("Revert = %v, want %v", got, want)
+	}
+
+	replayed := entry.Replay(reverted)
+	want[0] = "web@10.0.0.50"
+	if got := journalNames(replayed); !slices.Equal(got, want) {
+		t.Errorf("Replay = %v, want %v", got, want)
+	}
+}
+
+func TestJournalEntryAddDelete(t *testing.T) {
+	web := journalConnection("web", "10.0.0.5")
+	db := journalConnection("db", "10.0.0.6")
+
+	added := NewJournalEntry("add db", []Connection{web}, []Connection{web, db})
+	if got := journalNames(added.Revert([]Connection{web, db})); !slices.Equal(got, []string{"web@10.0.0.5"}) {
+		t.Errorf("undo add = %v", got)
+	}
+
+	deleted := NewJournalEntry("delete web", []Connection{web, db}, []Connection{db})
+	if got := journalNames(deleted.Revert([]Connection{db})); !slices.Equal(got, []string{"web@10.0.0.5", "db@10.0.0.6"}) {
+		t.Errorf("undo delete = %v", got)
+	}
+	if NewJournalEntry("noop", []Connection{web}, []Connection{web}) != nil {
+		t.Error("NewJournalEntry without changes should return nil")
+	}
+}
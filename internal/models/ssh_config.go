@@ -17,11 +17,38 @@ type SSHConfigHost struct {
 	Port     int    `yaml:"port,omitempty"`
 	User     string `yaml:"user"`
 
+	// Grouping
+	Group string   `yaml:"group,omitempty"`
+	Tags  []string `yaml:"tags,omitempty"`
+
+	// Jump host (ProxyJump)
+	ProxyJump string `yaml:"proxyjump,omitempty"`
+
 	// Authentication
 	IdentityFile string `yaml:"identityfile,omitempty"`
 	UseSSHKey    bool   `yaml:"usesshkey,omitempty"` // Whether to use SSH key authentication
 	Password     string `yaml:"password,omitempty"`  // Will be encrypted
 
+	// Session startup
+	RemoteCommand string   `yaml:"remotecommand,omitempty"`
+	WorkDir       string   `yaml:"workdir,omitempty"`
+	Env           []string `yaml:"env,omitempty"`
+
+	// Session recording
+	Record bool `yaml:"record,omitempty"`
+
+	// Connection sharing (written as "ControlMaster auto")
+	ControlMaster bool `yaml:"controlmaster,omitempty"`
+
+	// Templates and inheritance
+	Template bool     `yaml:"template,omitempty"`
+	Parent   string   `yaml:"parent,omitempty"`
+	Inherit  []string `yaml:"inherit,omitempty"`
+
+	// External source the connection is synced from
+	Source     string `yaml:"source,omitempty"`
+	SourceHost string `yaml:"sourcehost,omitempty"`
+
 	// Additional SSH options
 	StrictHostKeyChecking string `yaml:"strictHostKeyChecking,omitempty"`
 	UserKnownHostsFile    string `yaml:"userKnownHostsFile,omitempty"`
@@ -108,17 +135,30 @@ func (sh *SSHConfigHost) ConvertToConnection() *Connection {
 	id := sh.generateUniqueID()
 
 	conn := &Connection{
-		ID:          id,
-		Name:        sh.Name,
-		Host:        sh.HostName,
-		Port:        sh.Port,
-		User:        sh.User,
-		KeyPath:     sh.IdentityFile,
-		UseSSHKey:   sh.UseSSHKey,
-		Password:    sh.Password,
-		HasPassword: !sh.UseSSHKey && sh.Password != "",
-		CreatedAt:   sh.CreatedAt,
-		UpdatedAt:   sh.UpdatedAt,
+		ID:            id,
+		Name:          sh.Name,
+		Host:          sh.HostName,
+		Port:          sh.Port,
+		User:          sh.User,
+		JumpHost:      sh.ProxyJump,
+		Group:         sh.Group,
+		Tags:          sh.Tags,
+		KeyPath:       sh.IdentityFile,
+		UseSSHKey:     sh.UseSSHKey,
+		Password:      sh.Password,
+		HasPassword:   !sh.UseSSHKey && sh.Password != "",
+		RemoteCommand: sh.RemoteCommand,
+		WorkDir:       sh.WorkDir,
+		Env:           sh.Env,
+		Record:        sh.Record,
+		ControlMaster: sh.ControlMaster,
+		Template:      sh.Template,
+		Parent:        sh.Parent,
+		Inherit:       sh.Inherit,
+		Source:        sh.Source,
+		SourceHost:    sh.SourceHost,
+		CreatedAt:     sh.CreatedAt,
+		UpdatedAt:     sh.UpdatedAt,
 	}
 
 	// Set default port if not specified
@@ -157,9 +197,22 @@ func (sh *SSHConfigHost) ConvertFromConnection(conn *Connection) {
 	sh.HostName = conn.Host
 	sh.Port = conn.Port
 	sh.User = conn.User
+	sh.ProxyJump = conn.JumpHost
+	sh.Group = conn.Group
+	sh.Tags = conn.Tags
 	sh.IdentityFile = conn.KeyPath
 	sh.UseSSHKey = conn.UseSSHKey
 	sh.Password = conn.Password
+	sh.RemoteCommand = conn.RemoteCommand
+	sh.WorkDir = conn.WorkDir
+	sh.Env = conn.Env
+	sh.Record = conn.Record
+	sh.ControlMaster = conn.ControlMaster
+	sh.Template = conn.Template
+	sh.Parent = conn.Parent
+	sh.Inherit = conn.Inherit
+	sh.Source = conn.Source
+	sh.SourceHost = conn.SourceHost
 	sh.CreatedAt = conn.CreatedAt
 	sh.UpdatedAt = conn.UpdatedAt
 
@@ -174,8 +227,12 @@ func (sh *SSHConfigHost) ConvertFromConnection(conn *Connection) {
 		sh.ServerAliveCountMax = 3
 	}
 
-	// Set host pattern based on hostname
+	// Set host pattern based on hostname (templates may have no hostname)
 	if len(sh.Host) == 0 {
-		sh.Host = []string{sh.HostName}
+		if sh.HostName != "" {
+			sh.Host = []string{sh.HostName}
+		} else {
+			sh.Host = []string{strings.ReplaceAll(sh.Name, " ", "_")}
+		}
 	}
 }
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// SyncConflict подключение, измененное на обеих сторонах синхронизации
+type SyncConflict struct {
+	ID        string
+	Name      string
+	LocalWins bool // Оставлена локальная версия
+	Deleted   bool // Одна из сторон удалила подключение, другая изменила его
+}
+
+// MergeConnections выполняет трехстороннее слияние списков подключений по ID:
+// base - последнее синхронизированное состояние, local и remote - изменения сторон.
+// Изменение одной стороны применяется как есть. Если подключение изменили обе стороны,
+// объединяются изменения разных полей, а для поля, измененного обеими сторонами,
+// остается значение версии с более поздним UpdatedAt (при равенстве - локальной);
+// изменение побеждает удаление. Порядок берется из remote, если он изменился, иначе из local;
+// подключения, добавленные другой стороной, идут в конце
+func MergeConnections(base, local, remote []Connection) ([]Connection, []SyncConflict) {
+	baseByID := connectionsByID(base)
+	localByID := connectionsByID(local)
+	remoteByID := connectionsByID(remote)
+
+	var conflicts []SyncConflict
+	// resolve возвращает версию подключения после слияния; false - подключение удалено
+	resolve := func(id string) (Connection, bool) {
+		b, inBase := baseByID[id]
+		l, inLocal := localByID[id]
+		r, inRemote := remoteByID[id]
+
+		switch {
+		case !inLocal && !inBase:
+			// Добавлено на другой стороне
+			return r, true
+		case !inRemote && !inBase:
+			// Добавлено локально
+			return l, true
+		case !inLocal:
+			// Удалено локально: сохраняется, только если изменено на другой стороне
+			if SameConnection(b, r) {
+				return Connection{}, false
+			}
+			conflicts = append(conflicts, SyncConflict{ID: id, Name: r.Name, Deleted: true})
+			return r, true
+		case !inRemote:
+			if SameConnection(b, l) {
+				return Connection{}, false
+			}
+			conflicts = append(conflicts, SyncConflict{ID: id, Name: l.Name, LocalWins: true, Deleted: true})
+			return l, true
+		case inBase && SameConnection(b, r), SameConnection(l, r):
+			return l, true
+		case inBase && SameConnection(b, l):
+			return r, true
+		}
+
+		localWins := !r.UpdatedAt.After(l.UpdatedAt)
+		if !inBase {
+			// Без основы поля не сравнить: остается более новая версия целиком
+			conflicts = append(conflicts, SyncConflict{ID: id, Name: l.Name, LocalWins: localWins})
+			if localWins {
+				return l, true
+			}
+			return r, true
+		}
+
+		// Обе стороны изменили подключение: поля, измененные одной стороной,
+		// объединяются, а по полям, измененным обеими, побеждает более новая версия
+		merged, changedBoth := mergeFields(b, l, r)
+		if len(changedBoth) > 0 {
+			conflicts = append(conflicts, SyncConflict{ID: id, Name: l.Name, LocalWins: localWins})
+			if localWins {
+				for _, field := range changedBoth {
+					merged.copyEditField(&l, field)
+				}
+			}
+		}
+		if l.UpdatedAt.After(merged.UpdatedAt) {
+			merged.UpdatedAt = l.UpdatedAt
+		}
+		return merged, true
+	}
+
+	first, second := local, remote
+	if !sameOrder(base, remote) {
+		first, second = remote, local
+	}
+
+	merged := make([]Connection, 0, len(first)+len(second))
+	seen := make(map[string]bool, len(first)+len(second))
+	for _, list := range [][]Connection{first, second} {
+		for _, conn := range list {
+			if seen[conn.ID] {
+				continue
+			}
+			seen[conn.ID] = true
+			if result, ok := resolve(conn.ID); ok {
+				merged = append(merged, result)
+			}
+		}
+	}
+	return merged, conflicts
+}
+
+// sameOrder проверяет, что списки содержат подключения с теми же ID в том же порядке
+func sameOrder(a, b []Connection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+// SameConnections проверяет, что списки содержат одинаковые подключения в том же порядке
+func SameConnections(a, b []Connection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !SameConnection(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SameConnection сравнивает все поля подключений. Время сравнивается как момент,
+// без учета часового пояса, в котором оно было записано
+func SameConnection(a, b Connection) bool {
+	a.CreatedAt, b.CreatedAt = normalizeSyncTime(a.CreatedAt), normalizeSyncTime(b.CreatedAt)
+	a.UpdatedAt, b.UpdatedAt = normalizeSyncTime(a.UpdatedAt), normalizeSyncTime(b.UpdatedAt)
+	return reflect.DeepEqual(a, b)
+}
+
+// normalizeSyncTime приводит время к UTC с точностью до секунды, как в файле конфигурации
+func normalizeSyncTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// connectionsByID индексирует подключения по ID
+func connectionsByID(connections []Connection) map[string]Connection {
+	byID := make(map[string]Connection, len(connections))
+	for _, conn := range connections {
+		byID[conn.ID] = conn
+	}
+	return byID
+}
+
+// Поля шаблона и источника при переносе правки формы и слиянии синхронизации
+const (
+	editFieldTemplate = "template"
+	editFieldParent   = "parent"
+	editFieldSource   = "source"
+)
+
+// editFields поля, которые переносят ApplyEdit и слияние синхронизации
+var editFields = append(slices.Clone(importFields), editFieldTemplate, editFieldParent, editFieldSource)
+
+// editFieldLabels названия полей шаблона и источника для сообщения о конфликте
+var editFieldLabels = map[string]string{
+	editFieldTemplate: "шаблон",
+	editFieldParent:   "родительский шаблон",
+	editFieldSource:   "источник",
+}
+
+// ApplyEdit переносит правку на актуальную версию подключения current: поля, которые
+// изменены в edited относительно opened (копии, с которой начато редактирование),
+// берутся из edited, остальные остаются как в current. Так изменения, полученные
+// синхронизацией во время редактирования, не теряются. Если то же поле изменилось
+// и в current, возвращается ошибка
+func ApplyEdit(current, opened, edited Connection) (Connection, error) {
+	result, changedBoth := mergeFields(opened, edited, current)
+	if len(changedBoth) > 0 {
+		label := importFieldLabels[changedBoth[0]]
+		if label == "" {
+			label = editFieldLabels[changedBoth[0]]
+		}
+		return Connection{}, fmt.Errorf("поле «%s» подключения '%s' изменено на другой машине во время редактирования; откройте подключение заново", label, current.Name)
+	}
+	return result, nil
+}
+
+// mergeFields переносит на theirs поля, которые ours изменил относительно base.
+// Поля, которые по-разному изменили обе стороны, остаются как в theirs и
+// возвращаются в changedBoth
+func mergeFields(base, ours, theirs Connection) (result Connection, changedBoth []string) {
+	result = theirs
+	result.Tags = slices.Clone(theirs.Tags)
+	result.Env = slices.Clone(theirs.Env)
+	result.Inherit = slices.Clone(theirs.Inherit)
+
+	for _, field := range editFields {
+		if ours.sameEditField(&base, field) {
+			continue
+		}
+		if !theirs.sameEditField(&base, field) && !theirs.sameEditField(&ours, field) {
+			changedBoth = append(changedBoth, field)
+			continue
+		}
+		result.copyEditField(&ours, field)
+	}
+	return result, changedBoth
+}
+
+// sameEditField сравнивает поле вместе с признаком наследования от шаблона
+func (c *Connection) sameEditField(other *Connection, field string) bool {
+	switch field {
+	case editFieldTemplate:
+		return c.Template == other.Template
+	case editFieldParent:
+		return c.Parent == other.Parent
+	case editFieldSource:
+		return c.Source == other.Source && c.SourceHost == other.SourceHost
+	case importFieldHost:
+		// В отличие от импорта, правка регистра в форме тоже изменение
+		return c.Host == other.Host
+	}
+	return c.sameImportField(other, field) && c.Inherits(field) == other.Inherits(field)
+}
+
+// copyEditField копирует поле вместе с признаком наследования от шаблона
+func (c *Connection) copyEditField(src *Connection, field string) {
+	switch field {
+	case editFieldTemplate:
+		c.Template = src.Template
+		return
+	case editFieldParent:
+		c.Parent = src.Parent
+		return
+	case editFieldSource:
+		c.Source = src.Source
+		c.SourceHost = src.SourceHost
+		return
+	}
+
+	c.copyImportField(src, field)
+	if !slices.Contains(InheritableFields, field) {
+		return
+	}
+	inherited := src.Inherits(field)
+	c.Inherit = slices.DeleteFunc(c.Inherit, func(f string) bool { return f == field })
+	if inherited {
+		// Порядок полей как в InheritableFields, чтобы запись в файле не менялась
+		c.Inherit = append(c.Inherit, field)
+		slices.SortFunc(c.Inherit, func(a, b string) int {
+			return slices.Index(InheritableFields, a) - slices.Index(InheritableFields, b)
+		})
+	}
+}
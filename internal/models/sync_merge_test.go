@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// syncConnection создает подключение с ID, равным названию, и временем правки
+func syncConnection(name string, updatedAt time.Time) Connection {
+	conn := *NewConnection(name, name+".example.com", "deploy")
+	conn.ID = name
+	conn.UpdatedAt = updatedAt
+	return conn
+}
+
+func TestMergeConnectionsDifferentFields(t *testing.T) {
+	now := time.Now()
+	base := syncConnection("web", now)
+
+	local := base
+	local.Port = 2222
+	local.UpdatedAt = now.Add(2 * time.Minute)
+	remote := base
+	remote.User = "admin"
+	remote.UpdatedAt = now.Add(time.Minute)
+
+	merged, conflicts := MergeConnections([]Connection{base}, []Connection{local}, []Connection{remote})
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none for different fields", conflicts)
+	}
+	if len(merged) != 1 || merged[0].Port != 2222 || merged[0].User != "admin" {
+		t.Fatalf("merged = %+v, want both changes", merged)
+	}
+	if !merged[0].UpdatedAt.Equal(local.UpdatedAt) {
+		t.Errorf("UpdatedAt = %v, want the later %v", merged[0].UpdatedAt, local.UpdatedAt)
+	}
+}
+
+func TestMergeConnectionsSameField(t *testing.T) {
+	now := time.Now()
+	base := syncConnection("web", now)
+
+	local := base
+	local.Host = "local.example.com"
+	local.Port = 2222
+	local.UpdatedAt = now.Add(time.Minute)
+	remote := base
+	remote.Host = "remote.example.com"
+	remote.User = "admin"
+	remote.UpdatedAt = now.Add(2 * time.Minute)
+
+	merged, conflicts := MergeConnections([]Connection{base}, []Connection{local}, []Connection{remote})
+	if len(conflicts) != 1 || conflicts[0].LocalWins {
+		t.Errorf("conflicts = %+v, want one won by remote", conflicts)
+	}
+	// Поле, измененное обеими сторонами, берется из более новой версии, остальные сохраняются
+	web := merged[0]
+	if web.Host != "remote.example.com" || web.Port != 2222 || web.User != "admin" {
+		t.Errorf("merged = %s@%s:%d, want admin@remote.example.com:2222", web.User, web.Host, web.Port)
+	}
+
+	// При более новой локальной версии спорное поле остается локальным
+	local.UpdatedAt = now.Add(3 * time.Minute)
+	merged, conflicts = MergeConnections([]Connection{base}, []Connection{local}, []Connection{remote})
+	if len(conflicts) != 1 || !conflicts[0].LocalWins || merged[0].Host != "local.example.com" || merged[0].User != "admin" {
+		t.Errorf("merged = %+v, conflicts = %+v, want local host and remote user", merged[0], conflicts)
+	}
+}
+
+func TestApplyEdit(t *testing.T) {
+	opened := syncConnection("web", time.Now())
+	current := opened
+	current.User = "admin"
+
+	edited := opened
+	edited.Port = 2222
+	result, err := ApplyEdit(current, opened, edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.User != "admin" || result.Port != 2222 {
+		t.Errorf("ApplyEdit = %s:%d, want admin:2222", result.User, result.Port)
+	}
+
+	edited = opened
+	edited.User = "root"
+	if _, err := ApplyEdit(current, opened, edited); err == nil {
+		t.Error("ApplyEdit overwrote a field changed meanwhile")
+	}
+}
@@ -0,0 +1,261 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Поля подключения, которые можно наследовать от шаблона.
+// Название и хост всегда задаются в самом подключении
+const (
+	InheritPort          = "port"
+	InheritUser          = "user"
+	InheritJumpHost      = "jump_host"
+	InheritGroup         = "group"
+	InheritTags          = "tags"
+	InheritAuth          = "auth" // Тип аутентификации, ключ и пароль вместе
+	InheritRemoteCommand = "remote_command"
+	InheritWorkDir       = "work_dir"
+	InheritEnv           = "env"
+	InheritRecord        = "record"
+	InheritControlMaster = "control_master"
+)
+
+// InheritableFields все наследуемые поля в порядке формы
+var InheritableFields = []string{
+	InheritPort,
+	InheritUser,
+	InheritJumpHost,
+	InheritGroup,
+	InheritTags,
+	InheritRemoteCommand,
+	InheritWorkDir,
+	InheritEnv,
+	InheritRecord,
+	InheritControlMaster,
+	InheritAuth,
+}
+
+// maxInheritanceDepth ограничивает длину цепочки шаблонов
+const maxInheritanceDepth = 16
+
+// Inherits проверяет, берется ли поле из шаблона
+func (c *Connection) Inherits(field string) bool {
+	return c.Parent != "" && slices.Contains(c.Inherit, field)
+}
+
+// InheritFrom связывает подключение с шаблоном по данным формы. Наследование
+// меняется только по действиям пользователя: поля, унаследованные в opened (копия,
+// с которой открыта форма; nil для нового подключения) и не измененные в форме,
+// наследуются и дальше, очищенные поля cleared начинают наследоваться. Явное
+// значение, совпадающее с шаблоном, остается переопределенным. Наследуемые
+// поля заполняются значениями parent
+func (c *Connection) InheritFrom(parent, opened *Connection, cleared []string) {
+	c.Inherit = nil
+	if parent == nil {
+		c.Parent = ""
+		return
+	}
+
+	c.Parent = parent.ID
+	for _, field := range InheritableFields {
+		kept := opened != nil && opened.Inherits(field) && c.sameField(opened, field)
+		if kept || slices.Contains(cleared, field) {
+			c.Inherit = append(c.Inherit, field)
+		}
+	}
+	c.FillFrom(parent, c.Inherit)
+}
+
+// WithoutInherited возвращает копию подключения без унаследованных значений.
+// В файле конфигурации хранятся только переопределенные поля
+func (c Connection) WithoutInherited() Connection {
+	if c.Parent == "" {
+		return c
+	}
+
+	var empty Connection
+	for _, field := range c.Inherit {
+		c.copyField(&empty, field)
+	}
+	return c
+}
+
+// Flatten возвращает копию подключения с действующими значениями без ссылки на шаблон
+func (c Connection) Flatten() Connection {
+	c.Parent = ""
+	c.Inherit = nil
+	return c
+}
+
+// ResolveInheritance заполняет наследуемые поля подключений действующими значениями
+// шаблонов с учетом всей цепочки. Подключения с отсутствующим шаблоном или
+// циклом в цепочке сохраняют свои значения; по ним возвращаются ошибки
+func ResolveInheritance(conns []Connection) []error {
+	index := make(map[string]int, len(conns))
+	for i := range conns {
+		index[conns[i].ID] = i
+	}
+
+	const (
+		pending = iota
+		visiting
+		done
+	)
+	state := make([]int, len(conns))
+	var errs []error
+
+	var resolve func(i, depth int) bool
+	resolve = func(i, depth int) bool {
+		switch state[i] {
+		case done:
+			return true
+		case visiting:
+			return false
+		}
+
+		conn := &conns[i]
+		if conn.Parent == "" {
+			state[i] = done
+			return true
+		}
+
+		parent, ok := index[conn.Parent]
+		if !ok {
+			state[i] = done
+			errs = append(errs, fmt.Errorf("шаблон подключения '%s' не найден", conn.Name))
+			return true
+		}
+		if depth > maxInheritanceDepth {
+			state[i] = done
+			errs = append(errs, fmt.Errorf("слишком длинная цепочка шаблонов у подключения '%s'", conn.Name))
+			return true
+		}
+
+		state[i] = visiting
+		if !resolve(parent, depth+1) {
+			state[i] = done
+			errs = append(errs, fmt.Errorf("цикл в шаблонах подключения '%s'", conn.Name))
+			return false
+		}
+
+		for _, field := range conn.Inherit {
+			conn.copyField(&conns[parent], field)
+		}
+		state[i] = done
+		return true
+	}
+
+	for i := range conns {
+		resolve(i, 0)
+	}
+	return errs
+}
+
+// CheckParent проверяет, что подключение id может наследовать от parentID:
+// шаблон существует и не наследует (в том числе через цепочку) от самого подключения
+func CheckParent(conns []Connection, id, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+
+	byID := make(map[string]*Connection, len(conns))
+	for i := range conns {
+		byID[conns[i].ID] = &conns[i]
+	}
+
+	current := parentID
+	for depth := 0; current != ""; depth++ {
+		if current == id {
+			return fmt.Errorf("подключение не может наследовать само от себя")
+		}
+		parent, ok := byID[current]
+		if !ok {
+			return fmt.Errorf("шаблон не найден")
+		}
+		if depth > maxInheritanceDepth {
+			return fmt.Errorf("слишком длинная цепочка шаблонов")
+		}
+		current = parent.Parent
+	}
+	return nil
+}
+
+// copyField копирует значение поля из src
+func (c *Connection) copyField(src *Connection, field string) {
+	switch field {
+	case InheritPort:
+		c.Port = src.Port
+	case InheritUser:
+		c.User = src.User
+	case InheritJumpHost:
+		c.JumpHost = src.JumpHost
+	case InheritGroup:
+		c.Group = src.Group
+	case InheritTags:
+		c.Tags = slices.Clone(src.Tags)
+	case InheritAuth:
+		c.KeyPath = src.KeyPath
+		c.UseSSHKey = src.UseSSHKey
+		c.HasPassword = src.HasPassword
+		c.Password = src.Password
+	case InheritRemoteCommand:
+		c.RemoteCommand = src.RemoteCommand
+	case InheritWorkDir:
+		c.WorkDir = src.WorkDir
+	case InheritEnv:
+		c.Env = slices.Clone(src.Env)
+	case InheritRecord:
+		c.Record = src.Record
+	case InheritControlMaster:
+		c.ControlMaster = src.ControlMaster
+	}
+}
+
+// sameField сравнивает значение поля со значением other
+func (c *Connection) sameField(other *Connection, field string) bool {
+	switch field {
+	case InheritPort:
+		return c.Port == other.Port
+	case InheritUser:
+		return c.User == other.User
+	case InheritJumpHost:
+		return c.JumpHost == other.JumpHost
+	case InheritGroup:
+		return c.Group == other.Group
+	case InheritTags:
+		return slices.EqualFunc(c.Tags, other.Tags, strings.EqualFold)
+	case InheritAuth:
+		return c.UseSSHKey == other.UseSSHKey &&
+			c.KeyPath == other.KeyPath &&
+			c.HasPassword == other.HasPassword &&
+			c.Password == other.Password
+	case InheritRemoteCommand:
+		return c.RemoteCommand == other.RemoteCommand
+	case InheritWorkDir:
+		return c.WorkDir == other.WorkDir
+	case InheritEnv:
+		// Порядок переменных не важен: форма выводит имена без значений первыми
+		return slices.Equal(slices.Sorted(slices.Values(c.Env)), slices.Sorted(slices.Values(other.Env)))
+	case InheritRecord:
+		return c.Record == other.Record
+	case InheritControlMaster:
+		return c.ControlMaster == other.ControlMaster
+	}
+	return false
+}
+
+// FillFrom копирует значения полей из шаблона
+func (c *Connection) FillFrom(parent *Connection, fields []string) {
+	for _, field := range fields {
+		c.copyField(parent, field)
+	}
+}
+
+// Override отмечает поле как переопределенное: оно больше не берется из шаблона
+func (c *Connection) Override(field string) {
+	c.Inherit = slices.DeleteFunc(slices.Clone(c.Inherit), func(inherited string) bool {
+		return inherited == field
+	})
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInheritFrom(t *testing.T) {
+	parent := NewConnection("base", "", "deploy")
+	parent.ID = "parent"
+	parent.Port = 2222
+	parent.Env = []string{"APP_ENV=prod", "LANG"}
+
+	// Форма открыта с подключением, где порт и Env унаследованы, а пользователь
+	// явно задан и совпадает с шаблоном
+	opened := *NewConnection("web", "10.0.0.5", "deploy")
+	opened.Parent = parent.ID
+	opened.Port = parent.Port
+	opened.Env = slices.Clone(parent.Env)
+	opened.Inherit = []string{InheritPort, InheritEnv}
+
+	tests := []struct {
+		name    string
+		edit    func(c *Connection)
+		cleared []string
+		want    []string
+	}{
+		{"unchanged", func(c *Connection) {}, nil, []string{InheritPort, InheritEnv}},
+		{"env reordered by the form", func(c *Connection) { c.Env = []string{"LANG", "APP_ENV=prod"} }, nil, []string{InheritPort, InheritEnv}},
+		{"inherited field edited", func(c *Connection) { c.Port = 22 }, nil, []string{InheritEnv}},
+		{"explicit field cleared", func(c *Connection) { c.User = "" }, []string{InheritUser}, []string{InheritPort, InheritUser, InheritEnv}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := opened
+			conn.Inherit = nil
+			tt.edit(&conn)
+			conn.InheritFrom(parent, &opened, tt.cleared)
+			if !slices.Equal(conn.Inherit, tt.want) {
+				t.Errorf("Inherit = %v, want %v", conn.Inherit, tt.want)
+			}
+			if conn.Parent != parent.ID || conn.User != "deploy" {
+				t.Errorf("Parent = %q, User = %q; want values from the template", conn.Parent, conn.User)
+			}
+		})
+	}
+
+	// Новое подключение: значение, равное шаблону, остается переопределенным
+	conn := NewConnection("db", "10.0.0.6", "deploy")
+	conn.Port = 2222
+	conn.InheritFrom(parent, nil, []string{InheritEnv})
+	if !slices.Equal(conn.Inherit, []string{InheritEnv}) || !slices.Equal(conn.Env, parent.Env) {
+		t.Errorf("new connection: Inherit = %v, Env = %v", conn.Inherit, conn.Env)
+	}
+
+	conn.InheritFrom(nil, nil, nil)
+	if conn.Parent != "" || conn.Inherit != nil {
+		t.Errorf("without template: Parent = %q, Inherit = %v", conn.Parent, conn.Inherit)
+	}
+}
@@ -0,0 +1,271 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Header заголовок записи в формате asciicast v2
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event событие записи: время от начала в секундах, тип ("o" - вывод) и данные
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+// LineCodec преобразует строки файла записи (например, шифрует).
+// nil означает запись открытым текстом
+type LineCodec interface {
+	EncodeLine(line string) (string, error)
+	DecodeLine(line string) (string, error)
+}
+
+// Writer записывает вывод терминала в формате asciicast v2.
+// Реализует io.Writer и безопасен для использования из нескольких горутин
+type Writer struct {
+	mu      sync.Mutex
+	file    io.WriteCloser
+	buf     *bufio.Writer
+	codec   LineCodec
+	start   time.Time
+	pending []byte // Неполный UTF-8 символ с конца предыдущего блока
+	closed  bool
+}
+
+// NewWriter создает файл записи и пишет в него заголовок
+func NewWriter(path string, header Header, codec LineCodec) (*Writer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	header.Version = 2
+	start := time.Now()
+	if header.Timestamp == 0 {
+		header.Timestamp = start.Unix()
+	}
+
+	w := &Writer{
+		file:  file,
+		buf:   bufio.NewWriter(file),
+		codec: codec,
+		start: start,
+	}
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to encode recording header: %w", err)
+	}
+	if err := w.writeLine(string(data)); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write записывает блок вывода как событие "o"
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	data := append(w.pending, p...)
+
+	// JSON строка должна быть корректным UTF-8: неполный символ в конце блока
+	// откладываем до следующей записи
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), data[cut:]...)
+
+	if cut > 0 {
+		if err := w.writeEvent(string(data[:cut])); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// writeEvent записывает событие вывода с текущим временем
+func (w *Writer) writeEvent(data string) error {
+	elapsed := time.Since(w.start).Seconds()
+	line, err := json.Marshal([]interface{}{elapsed, "o", data})
+	if err != nil {
+		return fmt.Errorf("failed to encode recording event: %w", err)
+	}
+	return w.writeLine(string(line))
+}
+
+// writeLine записывает строку файла через кодек
+func (w *Writer) writeLine(line string) error {
+	if w.codec != nil {
+		encoded, err := w.codec.EncodeLine(line)
+		if err != nil {
+			return err
+		}
+		line = encoded
+	}
+
+	if _, err := w.buf.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// Close дописывает отложенные данные и закрывает файл
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if len(w.pending) > 0 {
+		w.writeEvent(string(w.pending))
+		w.pending = nil
+	}
+
+	if err := w.buf.Flush(); err != nil {
+		w.file.Close()
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return w.file.Close()
+}
+
+// Cast загруженная запись
+type Cast struct {
+	Header Header
+	Events []Event
+}
+
+// Duration возвращает длительность записи
+func (c *Cast) Duration() time.Duration {
+	if len(c.Events) == 0 {
+		return 0
+	}
+	return time.Duration(c.Events[len(c.Events)-1].Time * float64(time.Second))
+}
+
+// Load читает запись из файла
+func Load(path string, codec LineCodec) (*Cast, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	return Read(file, codec)
+}
+
+// ReadHeader читает только заголовок записи
+func ReadHeader(path string, codec LineCodec) (Header, error) {
+	var header Header
+
+	file, err := os.Open(path)
+	if err != nil {
+		return header, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return header, fmt.Errorf("empty recording")
+	}
+	if line, err = decodeLine(strings.TrimRight(line, "\r\n"), codec); err != nil {
+		return header, err
+	}
+	if err := json.Unmarshal([]byte(line), &header); err != nil {
+		return header, fmt.Errorf("invalid recording header: %w", err)
+	}
+	return header, nil
+}
+
+// Read разбирает запись asciicast v2. Событие, оборванное при сбое, пропускается
+func Read(r io.Reader, codec LineCodec) (*Cast, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("empty recording")
+	}
+	line, err := decodeLine(scanner.Text(), codec)
+	if err != nil {
+		return nil, err
+	}
+
+	cast := &Cast{}
+	if err := json.Unmarshal([]byte(line), &cast.Header); err != nil {
+		return nil, fmt.Errorf("invalid recording header: %w", err)
+	}
+	if cast.Header.Version != 2 {
+		return nil, fmt.Errorf("unsupported asciicast version: %d", cast.Header.Version)
+	}
+
+	for scanner.Scan() {
+		line, err := decodeLine(scanner.Text(), codec)
+		if err != nil {
+			continue
+		}
+
+		var raw []json.RawMessage
+		if err := json.Unmarshal([]byte(line), &raw); err != nil || len(raw) != 3 {
+			continue
+		}
+
+		var event Event
+		if json.Unmarshal(raw[0], &event.Time) != nil ||
+			json.Unmarshal(raw[1], &event.Type) != nil ||
+			json.Unmarshal(raw[2], &event.Data) != nil {
+			continue
+		}
+		cast.Events = append(cast.Events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading recording: %w", err)
+	}
+
+	return cast, nil
+}
+
+// decodeLine декодирует строку файла через кодек
+func decodeLine(line string, codec LineCodec) (string, error) {
+	if codec == nil {
+		return line, nil
+	}
+	decoded, err := codec.DecodeLine(line)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt recording: %w", err)
+	}
+	return decoded, nil
+}
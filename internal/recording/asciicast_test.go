@@ -0,0 +1,101 @@
+package recording
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// reverseCodec обратимо искажает строки, как шифрование
+type reverseCodec struct{}
+
+func (reverseCodec) EncodeLine(line string) (string, error) { return "x" + reverse(line), nil }
+
+func (reverseCodec) DecodeLine(line string) (string, error) {
+	return reverse(strings.TrimPrefix(line, "x")), nil
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec LineCodec
+	}{
+		{"plain", nil},
+		{"codec", reverseCodec{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.cast")
+			w, err := NewWriter(path, Header{Width: 80, Height: 24, Title: "web"}, tt.codec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// "привет" разрезан посередине символа: он должен попасть в запись целиком
+			output := []byte("$ echo привет\r\n")
+			for _, chunk := range [][]byte{output[:10], output[10:]} {
+				if _, err := w.Write(chunk); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			header, err := ReadHeader(path, tt.codec)
+			if err != nil || header.Title != "web" {
+				t.Fatalf("ReadHeader = %+v, %v", header, err)
+			}
+			cast, err := Load(path, tt.codec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cast.Header.Version != 2 || cast.Header.Width != 80 || cast.Header.Height != 24 {
+				t.Errorf("header = %+v", cast.Header)
+			}
+			var got strings.Builder
+			for _, event := range cast.Events {
+				if event.Type != "o" {
+					t.Errorf("event type %q, want o", event.Type)
+				}
+				got.WriteString(event.Data)
+			}
+			if got.String() != string(output) {
+				t.Errorf("output = %q, want %q", got.String(), output)
+			}
+		})
+	}
+}
+
+func TestNewWriterDoesNotOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cast")
+	w, err := NewWriter(path, Header{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if _, err := NewWriter(path, Header{}, nil); err == nil {
+		t.Error("NewWriter overwrote an existing recording")
+	}
+}
+
+func TestReadSkipsBrokenEvents(t *testing.T) {
+	data := `{"version":2,"width":80,"height":24}
+[0.1,"o","ok"]
+[0.2,"o","cut
+`
+	cast, err := Read(strings.NewReader(data), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cast.Events) != 1 || cast.Events[0].Data != "ok" {
+		t.Errorf("events = %+v, want only the complete one", cast.Events)
+	}
+}
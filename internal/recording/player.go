@@ -0,0 +1,165 @@
+package recording
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/muesli/cancelreader"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Ограничения скорости воспроизведения
+const (
+	MinSpeed = 0.25
+	MaxSpeed = 16.0
+)
+
+// DefaultIdleLimit максимальная пауза между событиями при воспроизведении
+const DefaultIdleLimit = 2 * time.Second
+
+// Player воспроизводит запись в терминале. Реализует tea.ExecCommand, поэтому
+// запускается через tea.Exec: интерфейс освобождает терминал на время воспроизведения.
+//
+// Управление: пробел - пауза, +/- - скорость, q или Ctrl+C - выход
+type Player struct {
+	cast      *Cast
+	speed     float64
+	idleLimit time.Duration
+	stdin     io.Reader
+	stdout    io.Writer
+}
+
+// NewPlayer создает проигрыватель записи с начальной скоростью speed
+func NewPlayer(cast *Cast, speed float64) *Player {
+	return &Player{
+		cast:      cast,
+		speed:     clampSpeed(speed),
+		idleLimit: DefaultIdleLimit,
+		stdin:     os.Stdin,
+		stdout:    os.Stdout,
+	}
+}
+
+// SetStdin устанавливает поток ввода для управления воспроизведением
+func (p *Player) SetStdin(r io.Reader) {
+	p.stdin = r
+}
+
+// SetStdout устанавливает поток вывода
+func (p *Player) SetStdout(w io.Writer) {
+	p.stdout = w
+}
+
+// SetStderr не используется: проигрыватель пишет только в stdout
+func (p *Player) SetStderr(io.Writer) {}
+
+// Run воспроизводит запись до конца или до выхода по клавише
+func (p *Player) Run() error {
+	if file, ok := p.stdin.(*os.File); ok && terminal.IsTerminal(int(file.Fd())) {
+		state, err := terminal.MakeRaw(int(file.Fd()))
+		if err == nil {
+			defer terminal.Restore(int(file.Fd()), state)
+		}
+	}
+
+	// Ввод читается через cancelreader, чтобы после выхода не осталось горутины,
+	// перехватывающей нажатия у интерфейса
+	reader, err := cancelreader.NewReader(p.stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	defer reader.Close()
+	defer reader.Cancel()
+
+	keys := make(chan byte)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 16)
+		for {
+			n, err := reader.Read(buf)
+			for _, key := range buf[:n] {
+				select {
+				case keys <- key:
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	fmt.Fprint(p.stdout, "\033[2J\033[H")
+
+	if !p.play(keys) {
+		return nil
+	}
+
+	fmt.Fprint(p.stdout, "\r\n\033[7m Воспроизведение завершено. Нажмите любую клавишу \033[0m")
+	<-keys
+	return nil
+}
+
+// play выводит события с исходными интервалами. Возвращает false при выходе по клавише
+func (p *Player) play(keys <-chan byte) bool {
+	previous := 0.0
+	paused := false
+
+	for i := 0; i < len(p.cast.Events); {
+		event := p.cast.Events[i]
+
+		wait := time.Duration((event.Time - previous) * float64(time.Second))
+		if wait > p.idleLimit {
+			wait = p.idleLimit
+		}
+		wait = time.Duration(float64(wait) / p.speed)
+
+		var timer <-chan time.Time
+		if !paused {
+			timer = time.After(wait)
+		}
+
+		select {
+		case <-timer:
+			if event.Type == "o" {
+				io.WriteString(p.stdout, event.Data)
+			}
+			previous = event.Time
+			i++
+		case key, ok := <-keys:
+			if !ok {
+				return false
+			}
+			switch key {
+			case 'q', 'Q', 3:
+				return false
+			case ' ':
+				paused = !paused
+			case '+', '=':
+				p.speed = clampSpeed(p.speed * 2)
+			case '-', '_':
+				p.speed = clampSpeed(p.speed / 2)
+			}
+		}
+	}
+
+	return true
+}
+
+// clampSpeed ограничивает скорость воспроизведения допустимыми значениями
+func clampSpeed(speed float64) float64 {
+	switch {
+	case speed <= 0:
+		return 1
+	case speed < MinSpeed:
+		return MinSpeed
+	case speed > MaxSpeed:
+		return MaxSpeed
+	}
+	return speed
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"ssh-keeper/internal/models"
+)
+
+// ansibleExportHost хост inventory, построенный из подключения
+type ansibleExportHost struct {
+	name string
+	vars [][2]string // Пары имя-значение в порядке записи
+}
+
+// ansibleExportInventory хосты без группы и группы с хостами в порядке первого появления
+type ansibleExportInventory struct {
+	ungrouped []ansibleExportHost
+	groups    []string
+	members   map[string][]ansibleExportHost
+	primary   map[string]bool // Группы, которые являются группой хотя бы одного подключения
+}
+
+// newAnsibleExportInventory раскладывает подключения по группам: группа подключения
+// получает хост с переменными, каждый тег - хост без переменных (Ansible объединяет их)
+func newAnsibleExportInventory(connections []models.Connection) *ansibleExportInventory {
+	inv := &ansibleExportInventory{
+		members: make(map[string][]ansibleExportHost),
+		primary: make(map[string]bool),
+	}
+	used := make(map[string]bool, len(connections))
+
+	add := func(group string, host ansibleExportHost) {
+		if _, ok := inv.members[group]; !ok {
+			inv.groups = append(inv.groups, group)
+		}
+		inv.members[group] = append(inv.members[group], host)
+	}
+
+	for _, conn := range connections {
+		host := ansibleExportHost{name: ansibleHostName(conn, used), vars: ansibleHostVars(conn)}
+
+		group := ansibleGroupName(conn.Group)
+		if group == "" {
+			inv.ungrouped = append(inv.ungrouped, host)
+		} else {
+			add(group, host)
+			inv.primary[group] = true
+		}
+
+		for _, tag := range conn.Tags {
+			if tagGroup := ansibleGroupName(tag); tagGroup != "" && tagGroup != group {
+				add(tagGroup, ansibleExportHost{name: host.name})
+			}
+		}
+	}
+
+	// Группы подключений идут раньше групп тегов: при импорте первая группа хоста
+	// снова станет его группой
+	ordered := make([]string, 0, len(inv.groups))
+	for _, primary := range []bool{true, false} {
+		for _, group := range inv.groups {
+			if inv.primary[group] == primary {
+				ordered = append(ordered, group)
+			}
+		}
+	}
+	inv.groups = ordered
+	return inv
+}
+
+// ansibleHostName возвращает имя хоста inventory: имя из inventory, если подключение
+// было из него импортировано, иначе псевдоним OpenSSH
+func ansibleHostName(conn models.Connection, used map[string]bool) string {
+	if strings.HasPrefix(conn.Source, AnsibleSourcePrefix) && conn.SourceHost != "" &&
+		!used[strings.ToLower(conn.SourceHost)] {
+		used[strings.ToLower(conn.SourceHost)] = true
+		return conn.SourceHost
+	}
+	return OpenSSHAlias(conn, used)
+}
+
+// ansibleHostVars возвращает переменные подключения Ansible
+func ansibleHostVars(conn models.Connection) [][2]string {
+	var vars [][2]string
+	vars = append(vars, [2]string{"ansible_host", conn.Host})
+	if conn.Port != 0 && conn.Port != 22 {
+		vars = append(vars, [2]string{"ansible_port", strconv.Itoa(conn.Port)})
+	}
+	if conn.User != "" {
+		vars = append(vars, [2]string{"ansible_user", conn.User})
+	}
+	if conn.UseSSHKey && conn.KeyPath != "" {
+		vars = append(vars, [2]string{"ansible_ssh_private_key_file", conn.KeyPath})
+	}
+	if conn.JumpHost != "" {
+		vars = append(vars, [2]string{"ansible_ssh_common_args", "-o ProxyJump=" + conn.JumpHost})
+	}
+	return vars
+}
+
+// ansibleGroupName приводит группу или тег к допустимому имени группы Ansible:
+// буквы, цифры и подчеркивания, не с цифры. all и ungrouped зарезервированы
+func ansibleGroupName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(name))
+	name = strings.Trim(name, "_")
+	if name == "" || name == ansibleGroupAll || name == ansibleGroupUngrouped {
+		return ""
+	}
+	if unicode.IsDigit(rune(name[0])) {
+		name = "_" + name
+	}
+	return name
+}
+
+// writeAnsibleINI записывает подключения как inventory Ansible в формате INI
+func writeAnsibleINI(w io.Writer, connections []models.Connection) error {
+	inv := newAnsibleExportInventory(connections)
+
+	fmt.Fprintf(w, "# Ansible inventory exported by SSH Keeper on %s\n", time.Now().Format(time.RFC3339))
+	hostLine := func(host ansibleExportHost) {
+		parts := []string{host.name}
+		for _, v := range host.vars {
+			value := v[1]
+			switch {
+			case strings.Contains(value, "'"):
+				value = `"` + value + `"`
+			case strings.ContainsAny(value, " \t\"#"):
+				value = "'" + value + "'"
+			}
+			parts = append(parts, v[0]+"="+value)
+		}
+		fmt.Fprintln(w, strings.Join(parts, " "))
+	}
+
+	if len(inv.ungrouped) > 0 {
+		fmt.Fprintln(w)
+		for _, host := range inv.ungrouped {
+			hostLine(host)
+		}
+	}
+	for _, group := range inv.groups {
+		fmt.Fprintf(w, "\n[%s]\n", group)
+		for _, host := range inv.members[group] {
+			hostLine(host)
+		}
+	}
+	return nil
+}
+
+// writeAnsibleYAML записывает подключения как inventory Ansible в формате YAML.
+// Строки записываются в двойных кавычках JSON, которые YAML читает без изменений
+func writeAnsibleYAML(w io.Writer, connections []models.Connection) error {
+	inv := newAnsibleExportInventory(connections)
+	quote := func(value string) string {
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+	writeHosts := func(hosts []ansibleExportHost, indent string) {
+		fmt.Fprintf(w, "%shosts:\n", indent)
+		for _, host := range hosts {
+			fmt.Fprintf(w, "%s  %s:\n", indent, quote(host.name))
+			for _, v := range host.vars {
+				value := quote(v[1])
+				if v[0] == "ansible_port" {
+					value = v[1]
+				}
+				fmt.Fprintf(w, "%s    %s: %s\n", indent, v[0], value)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "# Ansible inventory exported by SSH Keeper on %s\n", time.Now().Format(time.RFC3339))
+	if len(connections) == 0 {
+		_, err := fmt.Fprintln(w, "all: {}")
+		return err
+	}
+
+	fmt.Fprintln(w, "all:")
+	if len(inv.ungrouped) > 0 {
+		writeHosts(inv.ungrouped, "  ")
+	}
+	if len(inv.groups) > 0 {
+		fmt.Fprintln(w, "  children:")
+		for _, group := range inv.groups {
+			fmt.Fprintf(w, "    %s:\n", group)
+			writeHosts(inv.members[group], "      ")
+		}
+	}
+	return nil
+}
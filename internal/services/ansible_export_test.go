@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+// ansibleExportTestConnections подключения с группами, тегами и значениями,
+// которые нужно заключать в кавычки
+func ansibleExportTestConnections() []models.Connection {
+	return []models.Connection{
+		{
+			Name: "web eu", Host: "10.0.0.5", Port: 2222, User: "deploy",
+			UseSSHKey: true, KeyPath: "~/.ssh/my key", Group: "prod eu", Tags: []string{"web"},
+		},
+		{
+			Name: "db", Host: "db.internal", Port: 22, User: "o'brien",
+			UseSSHKey: true, Group: "prod eu", Tags: []string{"9db", "web"},
+		},
+		{
+			Name: "solo", Host: "solo.example.com", User: "root", JumpHost: "admin@bastion:2200",
+		},
+	}
+}
+
+func TestWriteAnsibleInventoryParsesBack(t *testing.T) {
+	want := []string{
+		"db o'brien@db.internal:22 group=prod_eu tags=_9db,web",
+		"solo root@solo.example.com:22 group= tags= jump=admin@bastion:2200",
+		"web-eu deploy@10.0.0.5:2222 group=prod_eu tags=web key=~/.ssh/my key",
+	}
+
+	tests := []struct {
+		format ExportFormat
+		path   string
+	}{
+		{ExportFormatAnsibleINI, "inventory.ini"},
+		{ExportFormatAnsibleYAML, "inventory.yml"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteExport(&buf, ansibleExportTestConnections(), ExportOptions{Format: tt.format}); err != nil {
+				t.Fatal(err)
+			}
+			connections, err := ParseAnsibleInventory(tt.path, buf.Bytes())
+			if err != nil {
+				t.Fatalf("ParseAnsibleInventory: %v\n%s", err, buf.String())
+			}
+			// Порядок хостов в YAML определяется словарем
+			got := inventorySummary(connections)
+			slices.Sort(got)
+			if !slices.Equal(got, want) {
+				t.Errorf("parsed back:\n%s\nwant:\n%s\ninventory:\n%s",
+					strings.Join(got, "\n"), strings.Join(want, "\n"), buf.String())
+			}
+		})
+	}
+}
+
+func TestWriteAnsibleINI(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeAnsibleINI(&buf, ansibleExportTestConnections()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Первая строка - комментарий с временем экспорта
+	_, body, _ := strings.Cut(buf.String(), "\n")
+	want := strings.Join([]string{
+		"",
+		"solo ansible_host=solo.example.com ansible_user=root ansible_ssh_common_args='-o ProxyJump=admin@bastion:2200'",
+		"",
+		"[prod_eu]",
+		"web-eu ansible_host=10.0.0.5 ansible_port=2222 ansible_user=deploy ansible_ssh_private_key_file='~/.ssh/my key'",
+		`db ansible_host=db.internal ansible_user="o'brien"`,
+		"",
+		"[web]",
+		"web-eu",
+		"db",
+		"",
+		"[_9db]",
+		"db",
+		"",
+	}, "\n")
+	if body != want {
+		t.Errorf("inventory:\n%s\nwant:\n%s", body, want)
+	}
+}
+
+func TestWriteAnsibleYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeAnsibleYAML(&buf, ansibleExportTestConnections()); err != nil {
+		t.Fatal(err)
+	}
+
+	_, body, _ := strings.Cut(buf.String(), "\n")
+	want := strings.Join([]string{
+		"all:",
+		"  hosts:",
+		`    "solo":`,
+		`      ansible_host: "solo.example.com"`,
+		`      ansible_user: "root"`,
+		`      ansible_ssh_common_args: "-o ProxyJump=admin@bastion:2200"`,
+		"  children:",
+		"    prod_eu:",
+		"      hosts:",
+		`        "web-eu":`,
+		`          ansible_host: "10.0.0.5"`,
+		"          ansible_port: 2222",
+		`          ansible_user: "deploy"`,
+		`          ansible_ssh_private_key_file: "~/.ssh/my key"`,
+		`        "db":`,
+		`          ansible_host: "db.internal"`,
+		`          ansible_user: "o'brien"`,
+		"    web:",
+		"      hosts:",
+		`        "web-eu":`,
+		`        "db":`,
+		"    _9db:",
+		"      hosts:",
+		`        "db":`,
+		"",
+	}, "\n")
+	if body != want {
+		t.Errorf("inventory:\n%s\nwant:\n%s", body, want)
+	}
+
+	buf.Reset()
+	if err := writeAnsibleYAML(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "\nall: {}\n") {
+		t.Errorf("empty inventory = %q", buf.String())
+	}
+}
+
+func TestAnsibleGroupName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"prod", "prod"},
+		{"prod eu", "prod_eu"},
+		{"team/web-1", "team_web_1"},
+		{"9db", "_9db"},
+		{" -- ", ""},
+		{"all", ""},
+		{"ungrouped", ""},
+	}
+	for _, tt := range tests {
+		if got := ansibleGroupName(tt.name); got != tt.want {
+			t.Errorf("ansibleGroupName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
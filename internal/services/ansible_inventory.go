@@ -0,0 +1,570 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"ssh-keeper/internal/models"
+)
+
+// AnsibleSourcePrefix префикс источника подключений, импортированных из Ansible inventory
+const AnsibleSourcePrefix = "ansible:"
+
+// Группы, которые есть в любом inventory и не переносятся в группы и теги
+const (
+	ansibleGroupAll       = "all"
+	ansibleGroupUngrouped = "ungrouped"
+)
+
+// ansibleInventory хосты и группы inventory в порядке объявления
+type ansibleInventory struct {
+	groups    map[string]*ansibleGroup
+	hosts     map[string]*ansibleHost
+	hostOrder []string
+}
+
+// ansibleGroup группа inventory
+type ansibleGroup struct {
+	name    string
+	parents []string
+	vars    map[string]string
+}
+
+// ansibleHost хост inventory с собственными переменными и группами, в которые он входит напрямую
+type ansibleHost struct {
+	name      string
+	groups    []string
+	varsGroup string // Группа, в которой заданы переменные хоста
+	vars      map[string]string
+}
+
+// newAnsibleInventory создает пустой inventory с группой all
+func newAnsibleInventory() *ansibleInventory {
+	inv := &ansibleInventory{
+		groups: make(map[string]*ansibleGroup),
+		hosts:  make(map[string]*ansibleHost),
+	}
+	inv.group(ansibleGroupAll)
+	return inv
+}
+
+// group возвращает группу, создавая ее при первом упоминании
+func (inv *ansibleInventory) group(name string) *ansibleGroup {
+	if g, ok := inv.groups[name]; ok {
+		return g
+	}
+	g := &ansibleGroup{name: name, vars: make(map[string]string)}
+	inv.groups[name] = g
+	return g
+}
+
+// addChild делает группу child дочерней для parent
+func (inv *ansibleInventory) addChild(parent, child string) {
+	inv.group(parent)
+	g := inv.group(child)
+	if child != parent && !slices.Contains(g.parents, parent) {
+		g.parents = append(g.parents, parent)
+	}
+}
+
+// addHost добавляет хост в группу; повторное объявление дополняет переменные.
+// Шаблон с диапазонами (web[01:03]) раскрывается в несколько хостов
+func (inv *ansibleInventory) addHost(group, pattern string, vars map[string]string) {
+	inv.group(group)
+	for _, name := range expandHostPattern(pattern) {
+		hostVars := vars
+		// host:port, кроме адресов IPv6
+		if host, port, found := strings.Cut(name, ":"); found && !strings.Contains(port, ":") {
+			if _, err := strconv.Atoi(port); err == nil {
+				name = host
+				hostVars = make(map[string]string, len(vars)+1)
+				hostVars["ansible_port"] = port
+				for key, value := range vars {
+					hostVars[key] = value
+				}
+			}
+		}
+
+		h, ok := inv.hosts[name]
+		if !ok {
+			h = &ansibleHost{name: name, vars: make(map[string]string)}
+			inv.hosts[name] = h
+			inv.hostOrder = append(inv.hostOrder, name)
+		}
+		if !slices.Contains(h.groups, group) {
+			h.groups = append(h.groups, group)
+		}
+		if h.varsGroup == "" && len(hostVars) > 0 {
+			h.varsGroup = group
+		}
+		for key, value := range hostVars {
+			h.vars[key] = value
+		}
+	}
+}
+
+// depth возвращает глубину группы в дереве от all; seen защищает от циклов
+func (inv *ansibleInventory) depth(name string, seen map[string]bool) int {
+	g, ok := inv.groups[name]
+	if !ok || name == ansibleGroupAll || seen[name] {
+		return 0
+	}
+	seen[name] = true
+	defer delete(seen, name)
+
+	depth := 1
+	for _, parent := range g.parents {
+		depth = max(depth, inv.depth(parent, seen)+1)
+	}
+	return depth
+}
+
+// hostGroups возвращает группы хоста вместе с родительскими, от общих к частным
+func (inv *ansibleInventory) hostGroups(h *ansibleHost) []string {
+	found := map[string]bool{ansibleGroupAll: true}
+	queue := slices.Clone(h.groups)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if found[name] {
+			continue
+		}
+		found[name] = true
+		if g, ok := inv.groups[name]; ok {
+			queue = append(queue, g.parents...)
+		}
+	}
+
+	groups := make([]string, 0, len(found))
+	depths := make(map[string]int, len(found))
+	for name := range found {
+		groups = append(groups, name)
+		depths[name] = inv.depth(name, make(map[string]bool))
+	}
+	// Ansible применяет переменные групп по глубине, при равной глубине - по имени
+	sort.Slice(groups, func(i, j int) bool {
+		if depths[groups[i]] != depths[groups[j]] {
+			return depths[groups[i]] < depths[groups[j]]
+		}
+		return groups[i] < groups[j]
+	})
+	return groups
+}
+
+// connections преобразует хосты inventory в подключения. Переменные групп
+// применяются от all к частным группам, переменные хоста - последними.
+// Группа с переменными хоста (или первая) становится группой подключения, остальные - тегами
+func (inv *ansibleInventory) connections(source string) []models.Connection {
+	var connections []models.Connection
+	for _, name := range inv.hostOrder {
+		h := inv.hosts[name]
+		groups := inv.hostGroups(h)
+
+		vars := make(map[string]string)
+		for _, group := range groups {
+			for key, value := range inv.groups[group].vars {
+				vars[key] = value
+			}
+		}
+		for key, value := range h.vars {
+			vars[key] = value
+		}
+
+		// Хосты с подключением не по SSH (local, winrm, docker) пропускаются
+		switch ansibleVar(vars, "ansible_connection") {
+		case "", "ssh", "smart", "paramiko":
+		default:
+			continue
+		}
+
+		address := ansibleVar(vars, "ansible_host", "ansible_ssh_host")
+		if address == "" {
+			address = name
+		}
+		port, _ := strconv.Atoi(ansibleVar(vars, "ansible_port", "ansible_ssh_port"))
+
+		conn := newImportedConnection(name, address, ansibleVar(vars, "ansible_user", "ansible_ssh_user"), port)
+		conn.Source = source
+		conn.SourceHost = name
+		conn.KeyPath = ansibleVar(vars, "ansible_ssh_private_key_file", "ansible_private_key_file")
+		conn.JumpHost = jumpHostFromSSHArgs(ansibleVar(vars, "ansible_ssh_common_args") + " " +
+			ansibleVar(vars, "ansible_ssh_extra_args"))
+
+		if password := ansibleVar(vars, "ansible_password", "ansible_ssh_pass"); password != "" && conn.KeyPath == "" {
+			conn.UseSSHKey = false
+			conn.HasPassword = true
+			conn.Password = password
+		}
+
+		// Группой подключения становится группа, где заданы переменные хоста, иначе первая
+		for _, group := range append([]string{h.varsGroup}, h.groups...) {
+			if group != "" && group != ansibleGroupAll && group != ansibleGroupUngrouped {
+				conn.Group = group
+				break
+			}
+		}
+		for _, group := range groups {
+			if group != ansibleGroupAll && group != ansibleGroupUngrouped && group != conn.Group {
+				conn.Tags = append(conn.Tags, group)
+			}
+		}
+		connections = append(connections, conn)
+	}
+	return connections
+}
+
+// ansibleVar возвращает первое заданное значение переменной. Шаблоны Jinja
+// и значения из Ansible Vault вычислить нельзя, они пропускаются
+func ansibleVar(vars map[string]string, names ...string) string {
+	for _, name := range names {
+		value := strings.TrimSpace(vars[name])
+		if value == "" || strings.Contains(value, "{{") || strings.HasPrefix(value, "!vault") {
+			continue
+		}
+		return value
+	}
+	return ""
+}
+
+// jumpHostFromSSHArgs находит jump хост в аргументах ssh: -J, -o ProxyJump
+// или -o ProxyCommand="ssh -W %h:%p bastion"
+func jumpHostFromSSHArgs(args string) string {
+	tokens := splitInventoryLine(args)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		var option string
+		switch {
+		case token == "-J" && i+1 < len(tokens):
+			i++
+			return tokens[i]
+		case strings.HasPrefix(token, "-J"):
+			return token[2:]
+		case token == "-o" && i+1 < len(tokens):
+			i++
+			option = tokens[i]
+		case strings.HasPrefix(token, "-o"):
+			option = token[2:]
+		default:
+			continue
+		}
+
+		key, value, found := strings.Cut(option, "=")
+		if !found {
+			key, value, _ = strings.Cut(option, " ")
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "proxyjump":
+			return strings.TrimSpace(value)
+		case "proxycommand":
+			if jump := jumpHostFromProxyCommand(value); jump != "" {
+				return jump
+			}
+		}
+	}
+	return ""
+}
+
+// jumpHostFromProxyCommand разбирает ProxyCommand вида "ssh -W %h:%p [-p порт] [user@]bastion"
+func jumpHostFromProxyCommand(command string) string {
+	tokens := splitInventoryLine(command)
+	if len(tokens) == 0 || filepath.Base(tokens[0]) != "ssh" || !slices.Contains(tokens, "-W") {
+		return ""
+	}
+
+	var host, port string
+	for i := 1; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token == "-p" && i+1 < len(tokens):
+			i++
+			port = tokens[i]
+		case token == "-W" || token == "-o" || token == "-i" || token == "-l" || token == "-F":
+			i++
+		case strings.HasPrefix(token, "-"):
+		default:
+			host = token
+		}
+	}
+	if host != "" && port != "" {
+		host += ":" + port
+	}
+	return host
+}
+
+// splitInventoryLine разбивает строку на слова с учетом кавычек; # вне кавычек начинает комментарий
+func splitInventoryLine(line string) []string {
+	var tokens []string
+	var current strings.Builder
+	var quote rune
+	inToken := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		case r == '#' && !inToken:
+			return tokens
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// expandHostPattern раскрывает диапазоны в имени хоста: web[01:03], db-[a:c], node[0:10:5]
+func expandHostPattern(pattern string) []string {
+	start := strings.Index(pattern, "[")
+	end := strings.Index(pattern, "]")
+	if start < 0 || end < start {
+		return []string{pattern}
+	}
+
+	bounds := strings.Split(pattern[start+1:end], ":")
+	if len(bounds) < 2 || len(bounds) > 3 {
+		return []string{pattern}
+	}
+	step := 1
+	if len(bounds) == 3 {
+		if n, err := strconv.Atoi(bounds[2]); err == nil && n > 0 {
+			step = n
+		}
+	}
+
+	var values []string
+	if from, err := strconv.Atoi(bounds[0]); err == nil {
+		to, err := strconv.Atoi(bounds[1])
+		if err != nil {
+			return []string{pattern}
+		}
+		// Ведущие нули задают ширину номера
+		width := 0
+		if len(bounds[0]) > 1 && bounds[0][0] == '0' {
+			width = len(bounds[0])
+		}
+		for i := from; i <= to; i += step {
+			values = append(values, fmt.Sprintf("%0*d", width, i))
+		}
+	} else if len(bounds[0]) == 1 && len(bounds[1]) == 1 {
+		for c := bounds[0][0]; c <= bounds[1][0]; c += byte(step) {
+			values = append(values, string(c))
+		}
+	} else {
+		return []string{pattern}
+	}
+
+	var hosts []string
+	for _, value := range values {
+		hosts = append(hosts, expandHostPattern(pattern[:start]+value+pattern[end+1:])...)
+	}
+	return hosts
+}
+
+// parseAnsibleINI разбирает inventory в формате INI: [группа], [группа:vars], [группа:children]
+func parseAnsibleINI(text string) *ansibleInventory {
+	inv := newAnsibleInventory()
+	group, kind := ansibleGroupUngrouped, ""
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group, kind, _ = strings.Cut(line[1:len(line)-1], ":")
+			inv.group(group)
+			continue
+		}
+
+		switch kind {
+		case "vars":
+			key, value, found := strings.Cut(line, "=")
+			if found {
+				inv.group(group).vars[strings.TrimSpace(key)] = unquoteINIValue(value)
+			}
+		case "children":
+			inv.addChild(group, line)
+		default:
+			tokens := splitInventoryLine(line)
+			if len(tokens) == 0 {
+				continue
+			}
+			vars := make(map[string]string)
+			for _, token := range tokens[1:] {
+				if key, value, found := strings.Cut(token, "="); found {
+					vars[key] = value
+				}
+			}
+			inv.addHost(group, tokens[0], vars)
+		}
+	}
+	return inv
+}
+
+// unquoteINIValue убирает кавычки и комментарий из значения переменной группы
+func unquoteINIValue(value string) string {
+	tokens := splitInventoryLine(value)
+	return strings.Join(tokens, " ")
+}
+
+// parseAnsibleYAML разбирает inventory в формате YAML: группы с ключами hosts, vars и children
+func parseAnsibleYAML(text string) (*ansibleInventory, error) {
+	root, err := parseYAML(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML inventory: %w", err)
+	}
+	groups, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("inventory должен начинаться с групп (all:)")
+	}
+
+	inv := newAnsibleInventory()
+	for _, name := range sortedKeys(groups) {
+		inv.addYAMLGroup(name, groups[name])
+	}
+	return inv, nil
+}
+
+// addYAMLGroup добавляет группу YAML inventory вместе с хостами и дочерними группами
+func (inv *ansibleInventory) addYAMLGroup(name string, value interface{}) {
+	inv.group(name)
+	body, _ := value.(map[string]interface{})
+
+	if vars, ok := body["vars"].(map[string]interface{}); ok {
+		for key, value := range vars {
+			inv.group(name).vars[key] = yamlString(value)
+		}
+	}
+
+	if hosts, ok := body["hosts"].(map[string]interface{}); ok {
+		for _, host := range sortedKeys(hosts) {
+			vars := make(map[string]string)
+			if hostVars, ok := hosts[host].(map[string]interface{}); ok {
+				for key, value := range hostVars {
+					vars[key] = yamlString(value)
+				}
+			}
+			inv.addHost(name, host, vars)
+		}
+	}
+
+	if children, ok := body["children"].(map[string]interface{}); ok {
+		for _, child := range sortedKeys(children) {
+			inv.addChild(name, child)
+			inv.addYAMLGroup(child, children[child])
+		}
+	}
+}
+
+// ParseAnsibleInventory читает хосты из inventory в формате INI или YAML.
+// Подключения помечаются источником, чтобы повторный импорт обновлял их
+func ParseAnsibleInventory(path string, data []byte) ([]models.Connection, error) {
+	source := AnsibleSourcePrefix + path
+	if absolute, err := filepath.Abs(path); err == nil {
+		source = AnsibleSourcePrefix + absolute
+	}
+
+	text := decodeText(data)
+	if isAnsibleYAML(path, text) {
+		inv, err := parseAnsibleYAML(text)
+		if err != nil {
+			return nil, err
+		}
+		return inv.connections(source), nil
+	}
+	return parseAnsibleINI(text).connections(source), nil
+}
+
+// isAnsibleYAML определяет формат inventory по расширению или первой значимой строке
+func isAnsibleYAML(path, text string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		return true
+	case ".ini":
+		return false
+	}
+	line := firstSignificantLine(text)
+	return line == "---" || (strings.HasSuffix(line, ":") && !strings.HasPrefix(line, "["))
+}
+
+// isAnsibleINI проверяет, похож ли текст на INI inventory: есть секции групп или переменные ansible_
+func isAnsibleINI(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if isAnsibleGroupHeader(line) {
+			return true
+		}
+		if strings.Contains(line, "ansible_host=") || strings.Contains(line, "ansible_user=") {
+			return true
+		}
+	}
+	return false
+}
+
+// isAnsibleGroupHeader проверяет, что строка - заголовок группы: [web], [web:vars], [web:children]
+func isAnsibleGroupHeader(line string) bool {
+	if len(line) < 3 || line[0] != '[' || line[len(line)-1] != ']' {
+		return false
+	}
+	return !strings.ContainsFunc(line[1:len(line)-1], func(r rune) bool {
+		return r != '_' && r != '-' && r != '.' && r != ':' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// isAnsibleYAMLText проверяет, похож ли текст на YAML inventory: группы с ключами hosts или children
+func isAnsibleYAMLText(text string) bool {
+	root, err := parseYAML(text)
+	if err != nil {
+		return false
+	}
+	groups, ok := root.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, value := range groups {
+		body, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := body["hosts"]; ok {
+			return true
+		}
+		if _, ok := body["children"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// firstSignificantLine возвращает первую непустую строку, не являющуюся комментарием
+func firstSignificantLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, ";") {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+// inventorySummary кратко описывает импортированные подключения для сравнения
+func inventorySummary(connections []models.Connection) []string {
+	var lines []string
+	for _, conn := range connections {
+		line := fmt.Sprintf("%s %s@%s:%d group=%s tags=%s", conn.Name, conn.User, conn.Host, conn.Port,
+			conn.Group, strings.Join(conn.Tags, ","))
+		if conn.KeyPath != "" {
+			line += " key=" + conn.KeyPath
+		}
+		if conn.JumpHost != "" {
+			line += " jump=" + conn.JumpHost
+		}
+		if conn.HasPassword {
+			line += " password=" + conn.Password
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestParseAnsibleInventoryINI(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "hosts with vars",
+			text: "web1 ansible_host=10.0.0.5 ansible_user=deploy ansible_port=2222\n",
+			want: []string{"web1 deploy@10.0.0.5:2222 group= tags="},
+		},
+		{
+			name: "groups, children and group vars",
+			text: strings.Join([]string{
+				"[web]",
+				"web1 ansible_host=10.0.0.5",
+				"[db]",
+				"db1",
+				"[prod:children]",
+				"web",
+				"db",
+				"[prod:vars]",
+				"ansible_user=admin",
+				"ansible_ssh_private_key_file=~/.ssh/prod",
+			}, "\n"),
+			want: []string{
+				"web1 admin@10.0.0.5:22 group=web tags=prod key=~/.ssh/prod",
+				"db1 admin@db1:22 group=db tags=prod key=~/.ssh/prod",
+			},
+		},
+		{
+			name: "ranges, quotes and comments",
+			text: strings.Join([]string{
+				"# comment",
+				"[app]",
+				"app[01:02].example.com ansible_user='ops user'",
+				"; another comment",
+			}, "\n"),
+			want: []string{
+				"app01.example.com ops user@app01.example.com:22 group=app tags=",
+				"app02.example.com ops user@app02.example.com:22 group=app tags=",
+			},
+		},
+		{
+			name: "password, jump host and non-ssh hosts",
+			text: strings.Join([]string{
+				"[legacy]",
+				"old ansible_host=10.0.0.9 ansible_password=secret ansible_ssh_common_args='-o ProxyJump=bastion'",
+				"localhost ansible_connection=local",
+				"tpl ansible_host={{ lookup('env', 'HOST') }}",
+			}, "\n"),
+			want: []string{
+				"old @10.0.0.9:22 group=legacy tags= jump=bastion password=secret",
+				"tpl @tpl:22 group=legacy tags=",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connections, err := ParseAnsibleInventory("hosts", []byte(tt.text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := inventorySummary(connections); !slices.Equal(got, tt.want) {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseAnsibleInventoryYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "hosts with vars",
+			text: strings.Join([]string{
+				"all:",
+				"  hosts:",
+				"    web1:",
+				"      ansible_host: 10.0.0.5",
+				"      ansible_user: deploy",
+				"      ansible_port: 2222",
+			}, "\n"),
+			want: []string{"web1 deploy@10.0.0.5:2222 group= tags="},
+		},
+		{
+			name: "children and group vars",
+			text: strings.Join([]string{
+				"all:",
+				"  children:",
+				"    prod:",
+				"      vars:",
+				"        ansible_user: admin",
+				"      children:",
+				"        web:",
+				"          hosts:",
+				"            web1: {}",
+				"            web2:",
+				"              ansible_host: \"10.0.0.6\" # comment",
+			}, "\n"),
+			want: []string{
+				"web1 admin@web1:22 group=web tags=prod",
+				"web2 admin@10.0.0.6:22 group=web tags=prod",
+			},
+		},
+		{
+			name: "vault and templates are skipped",
+			text: strings.Join([]string{
+				"all:",
+				"  hosts:",
+				"    db1:",
+				"      ansible_host: '{{ db_address }}'",
+				"      ansible_password: !vault |",
+				"        $ANSIBLE_VAULT;1.1;AES256",
+				"        6162",
+			}, "\n"),
+			want: []string{"db1 @db1:22 group= tags="},
+		},
+		{
+			name: "flow mapping is rejected",
+			text: strings.Join([]string{
+				"all:",
+				"  hosts:",
+				"    web1: {ansible_host: 10.0.0.5, ansible_user: deploy}",
+			}, "\n"),
+			wantErr: "line 3: flow collections",
+		},
+		{
+			name: "flow sequence is rejected",
+			text: strings.Join([]string{
+				"all:",
+				"  vars:",
+				"    tags: [a, b]",
+			}, "\n"),
+			wantErr: "line 3: flow collections",
+		},
+		{
+			name:    "tabs are rejected",
+			text:    "all:\n\thosts:\n",
+			wantErr: "line 2: tabs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connections, err := ParseAnsibleInventory("hosts.yml", []byte(tt.text))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := inventorySummary(connections); !slices.Equal(got, tt.want) {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
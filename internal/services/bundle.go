@@ -0,0 +1,179 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// BundleFormat метка зашифрованного файла экспорта
+const BundleFormat = "ssh-keeper-bundle"
+
+// BundleVersion текущая версия контейнера
+const BundleVersion = 1
+
+// MinBundlePassphraseLength минимальная длина пароля зашифрованного экспорта
+const MinBundlePassphraseLength = 8
+
+// Параметры Argon2id для новых контейнеров
+const (
+	bundleKDFTime    = 3
+	bundleKDFMemory  = 64 * 1024 // KiB
+	bundleKDFThreads = 4
+	bundleKeyLength  = 32
+)
+
+// Ограничения параметров KDF при чтении, чтобы чужой файл не занял всю память
+const (
+	maxBundleKDFTime   = 16
+	maxBundleKDFMemory = 1024 * 1024 // KiB
+)
+
+// ErrBundlePassphrase возвращается, если контейнер не удалось расшифровать
+var ErrBundlePassphrase = errors.New("неверный пароль или файл поврежден")
+
+// bundleKDF параметры получения ключа из пароля
+type bundleKDF struct {
+	Name    string `json:"name"`
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+}
+
+// bundleHeader заголовок контейнера; он же аутентифицируется как дополнительные данные AEAD
+type bundleHeader struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	KDF     bundleKDF `json:"kdf"`
+	Cipher  string    `json:"cipher"`
+}
+
+// bundleFile содержимое файла контейнера
+type bundleFile struct {
+	Header bundleHeader `json:"header"`
+	Nonce  []byte       `json:"nonce"`
+	Data   []byte       `json:"data"`
+}
+
+// SealBundle шифрует payload паролем: ключ выводится Argon2id, данные шифруются AES-256-GCM
+func SealBundle(payload []byte, passphrase string) ([]byte, error) {
+	if len(passphrase) < MinBundlePassphraseLength {
+		return nil, fmt.Errorf("пароль должен содержать минимум %d символов", MinBundlePassphraseLength)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	header := bundleHeader{
+		Format:  BundleFormat,
+		Version: BundleVersion,
+		KDF: bundleKDF{
+			Name:    "argon2id",
+			Salt:    salt,
+			Time:    bundleKDFTime,
+			Memory:  bundleKDFMemory,
+			Threads: bundleKDFThreads,
+		},
+		Cipher: "aes-256-gcm",
+	}
+
+	gcm, err := bundleCipher(header.KDF, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	aad, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return json.MarshalIndent(bundleFile{
+		Header: header,
+		Nonce:  nonce,
+		Data:   gcm.Seal(nil, nonce, payload, aad),
+	}, "", "  ")
+}
+
+// OpenBundle проверяет заголовок контейнера и расшифровывает данные
+func OpenBundle(data []byte, passphrase string) ([]byte, error) {
+	var file bundleFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Header.Format != BundleFormat {
+		return nil, fmt.Errorf("файл не является зашифрованным экспортом SSH Keeper")
+	}
+
+	header := file.Header
+	switch {
+	case header.Version < 1:
+		return nil, fmt.Errorf("недопустимая версия контейнера: %d", header.Version)
+	case header.Version > BundleVersion:
+		return nil, fmt.Errorf("версия контейнера %d не поддерживается, обновите SSH Keeper", header.Version)
+	case header.KDF.Name != "argon2id" || header.Cipher != "aes-256-gcm":
+		return nil, fmt.Errorf("неподдерживаемые параметры шифрования: %s, %s", header.KDF.Name, header.Cipher)
+	case header.KDF.Time == 0 || header.KDF.Time > maxBundleKDFTime ||
+		header.KDF.Memory == 0 || header.KDF.Memory > maxBundleKDFMemory ||
+		header.KDF.Threads == 0 || len(header.KDF.Salt) == 0:
+		return nil, fmt.Errorf("недопустимые параметры KDF в заголовке")
+	}
+
+	gcm, err := bundleCipher(header.KDF, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != gcm.NonceSize() {
+		return nil, ErrBundlePassphrase
+	}
+	aad, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := gcm.Open(nil, file.Nonce, file.Data, aad)
+	if err != nil {
+		return nil, ErrBundlePassphrase
+	}
+	return payload, nil
+}
+
+// bundleCipher выводит ключ из пароля и создает AES-GCM
+func bundleCipher(kdf bundleKDF, passphrase string) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), kdf.Salt, kdf.Time, kdf.Memory, kdf.Threads, bundleKeyLength)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// IsBundleFile проверяет, является ли файл зашифрованным экспортом
+func IsBundleFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	var probe struct {
+		Header struct {
+			Format string `json:"format"`
+		} `json:"header"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Header.Format == BundleFormat
+}
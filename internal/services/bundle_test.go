@@ -0,0 +1,115 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const testBundlePassphrase = "correct horse"
+
+// sealTestBundle шифрует payload и возвращает разобранный контейнер
+func sealTestBundle(t *testing.T, payload string) bundleFile {
+	t.Helper()
+	data, err := SealBundle([]byte(payload), testBundlePassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var file bundleFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// marshalTestBundle собирает файл контейнера
+func marshalTestBundle(t *testing.T, file bundleFile) []byte {
+	t.Helper()
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	data, err := SealBundle([]byte("connections"), testBundlePassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := OpenBundle(data, testBundlePassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != "connections" {
+		t.Errorf("payload = %q, want connections", payload)
+	}
+
+	if _, err := OpenBundle(data, "wrong passphrase"); !errors.Is(err, ErrBundlePassphrase) {
+		t.Errorf("wrong passphrase: err = %v, want ErrBundlePassphrase", err)
+	}
+	if _, err := SealBundle([]byte("x"), "short"); err == nil {
+		t.Error("SealBundle accepted a short passphrase")
+	}
+}
+
+func TestBundleTamperedHeader(t *testing.T) {
+	original := sealTestBundle(t, "connections")
+
+	// Изменение параметров KDF меняет ключ
+	file := original
+	file.Header.KDF.Time++
+	if _, err := OpenBundle(marshalTestBundle(t, file), testBundlePassphrase); !errors.Is(err, ErrBundlePassphrase) {
+		t.Errorf("tampered KDF time: err = %v, want ErrBundlePassphrase", err)
+	}
+
+	// Данные, зашифрованные с другим заголовком, не принимаются даже с верным ключом
+	gcm, err := bundleCipher(original.Header.KDF, testBundlePassphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := original.Header
+	other.Cipher = "other"
+	aad, err := json.Marshal(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file = original
+	file.Data = gcm.Seal(nil, file.Nonce, []byte("forged"), aad)
+	if _, err := OpenBundle(marshalTestBundle(t, file), testBundlePassphrase); !errors.Is(err, ErrBundlePassphrase) {
+		t.Errorf("data sealed with another header: err = %v, want ErrBundlePassphrase", err)
+	}
+}
+
+func TestBundleHeaderBounds(t *testing.T) {
+	original := sealTestBundle(t, "connections")
+
+	tests := []struct {
+		name   string
+		tamper func(*bundleHeader)
+	}{
+		{"version 0", func(h *bundleHeader) { h.Version = 0 }},
+		{"negative version", func(h *bundleHeader) { h.Version = -1 }},
+		{"future version", func(h *bundleHeader) { h.Version = BundleVersion + 1 }},
+		{"other format", func(h *bundleHeader) { h.Format = "other" }},
+		{"other kdf", func(h *bundleHeader) { h.KDF.Name = "scrypt" }},
+		{"other cipher", func(h *bundleHeader) { h.Cipher = "aes-128-cbc" }},
+		{"zero time", func(h *bundleHeader) { h.KDF.Time = 0 }},
+		{"huge time", func(h *bundleHeader) { h.KDF.Time = maxBundleKDFTime + 1 }},
+		{"zero memory", func(h *bundleHeader) { h.KDF.Memory = 0 }},
+		{"huge memory", func(h *bundleHeader) { h.KDF.Memory = maxBundleKDFMemory + 1 }},
+		{"zero threads", func(h *bundleHeader) { h.KDF.Threads = 0 }},
+		{"no salt", func(h *bundleHeader) { h.KDF.Salt = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := original
+			tt.tamper(&file.Header)
+			_, err := OpenBundle(marshalTestBundle(t, file), testBundlePassphrase)
+			// Заголовок отклоняется до вывода ключа, а не как неверный пароль
+			if err == nil || errors.Is(err, ErrBundlePassphrase) {
+				t.Errorf("err = %v, want a header error", err)
+			}
+		})
+	}
+}
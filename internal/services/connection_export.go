@@ -0,0 +1,498 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/ssh"
+)
+
+// ExportFormat формат файла экспорта
+type ExportFormat string
+
+const (
+	// ExportFormatOpenSSH настоящий ~/.ssh/config: только стандартные ключевые слова, без секретов
+	ExportFormatOpenSSH ExportFormat = "openssh"
+	// ExportFormatKeeper собственный формат конфигурации SSH Keeper (с метаданными и паролями)
+	ExportFormatKeeper ExportFormat = "ssh-keeper"
+	ExportFormatJSON   ExportFormat = "json"
+	ExportFormatYAML   ExportFormat = "yaml"
+	ExportFormatCSV    ExportFormat = "csv"
+	// ExportFormatBundle JSON с паролями в контейнере, зашифрованном паролем экспорта
+	ExportFormatBundle ExportFormat = "bundle"
+	// ExportFormatAnsibleINI и ExportFormatAnsibleYAML inventory Ansible: группы и теги
+	// становятся группами, адрес, порт, пользователь, ключ и jump хост - переменными хоста
+	ExportFormatAnsibleINI  ExportFormat = "ansible-ini"
+	ExportFormatAnsibleYAML ExportFormat = "ansible-yaml"
+	// ExportFormatKnownHosts ключи хостов, уже проверенные в known_hosts пользователя
+	ExportFormatKnownHosts ExportFormat = "known-hosts"
+)
+
+// ExportFormats форматы экспорта в порядке отображения
+var ExportFormats = []ExportFormat{
+	ExportFormatOpenSSH,
+	ExportFormatKeeper,
+	ExportFormatJSON,
+	ExportFormatYAML,
+	ExportFormatCSV,
+	ExportFormatBundle,
+	ExportFormatAnsibleINI,
+	ExportFormatAnsibleYAML,
+	ExportFormatKnownHosts,
+}
+
+// SecretsMode определяет, как пароли попадают в файл экспорта
+type SecretsMode string
+
+const (
+	SecretsOmit    SecretsMode = "omit"    // Пароли не экспортируются
+	SecretsEncrypt SecretsMode = "encrypt" // Пароли зашифрованы мастер-паролем
+	SecretsPlain   SecretsMode = "plain"   // Пароли в открытом виде
+)
+
+// ExportOptions параметры экспорта
+type ExportOptions struct {
+	Format  ExportFormat
+	Group   string // Только подключения группы (без учета регистра)
+	Tag     string // Только подключения с тегом (без учета регистра)
+	Secrets SecretsMode
+	// Passphrase пароль контейнера ExportFormatBundle, не связанный с мастер-паролем
+	Passphrase string
+	// KnownHostsFiles файлы known_hosts для ExportFormatKnownHosts; по умолчанию DefaultKnownHostsFiles
+	KnownHostsFiles []string
+}
+
+// ParseExportFormat разбирает название формата экспорта
+func ParseExportFormat(name string) (ExportFormat, error) {
+	format := ExportFormat(strings.ToLower(strings.TrimSpace(name)))
+	switch format {
+	case "":
+		return ExportFormatOpenSSH, nil
+	case "keeper":
+		return ExportFormatKeeper, nil
+	case "yml":
+		return ExportFormatYAML, nil
+	case "ansible", "ansible-inventory":
+		return ExportFormatAnsibleINI, nil
+	case "ansible-yml":
+		return ExportFormatAnsibleYAML, nil
+	case "known_hosts", "knownhosts":
+		return ExportFormatKnownHosts, nil
+	}
+	for _, known := range ExportFormats {
+		if format == known {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown export format %q", name)
+}
+
+// SupportsSecrets сообщает, может ли формат содержать пароли
+func (f ExportFormat) SupportsSecrets() bool {
+	switch f {
+	case ExportFormatOpenSSH, ExportFormatAnsibleINI, ExportFormatAnsibleYAML, ExportFormatKnownHosts:
+		return false
+	}
+	return true
+}
+
+// Export записывает подключения в файл в выбранном формате и возвращает их количество
+func (cs *ConnectionService) Export(exportPath string, opts ExportOptions) (int, error) {
+	// В контейнер пароли попадают открытыми: его целиком защищает пароль экспорта
+	if opts.Format == ExportFormatBundle {
+		opts.Secrets = SecretsPlain
+	}
+
+	connections, err := cs.ExportConnections(opts)
+	if err != nil {
+		return 0, err
+	}
+
+	if opts.Format == ExportFormatBundle {
+		return len(connections), writeBundle(exportPath, connections, opts.Passphrase)
+	}
+
+	if opts.Format == ExportFormatKeeper {
+		exportService := NewSSHConfigService(exportPath)
+		return len(connections), exportService.SaveConfig(cs.sshConfigService.ConvertConnectionsToSSHConfig(connections))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(exportPath), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	// Файл с паролями доступен только владельцу
+	mode := os.FileMode(0644)
+	if opts.Secrets != SecretsOmit && opts.Format.SupportsSecrets() {
+		mode = 0600
+	}
+	file, err := os.OpenFile(exportPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	if err := WriteExport(writer, connections, opts); err != nil {
+		return 0, err
+	}
+	if err := writer.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write export file: %w", err)
+	}
+	return len(connections), nil
+}
+
+// ExportConnections возвращает подключения для экспорта: без шаблонов, с действующими
+// значениями, отфильтрованные по группе и тегу, с паролями в выбранном виде
+func (cs *ConnectionService) ExportConnections(opts ExportOptions) ([]models.Connection, error) {
+	if opts.Secrets == SecretsEncrypt && opts.Format.SupportsSecrets() && !cs.encryptionService.IsInitialized() {
+		return nil, fmt.Errorf("шифрование паролей недоступно: мастер-пароль не задан")
+	}
+
+	var connections []models.Connection
+	for _, conn := range cs.exportConnections() {
+		if opts.Group != "" && !strings.EqualFold(conn.Group, opts.Group) {
+			continue
+		}
+		if opts.Tag != "" && !hasExactTag(conn.Tags, opts.Tag) {
+			continue
+		}
+
+		if conn.Password != "" {
+			password, err := cs.exportPassword(conn.Password, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export password for connection %s: %w", conn.Name, err)
+			}
+			conn.Password = password
+		}
+		connections = append(connections, conn)
+	}
+	return connections, nil
+}
+
+// exportPassword приводит пароль к виду, заданному режимом секретов
+func (cs *ConnectionService) exportPassword(password string, opts ExportOptions) (string, error) {
+	if !opts.Format.SupportsSecrets() || opts.Secrets == SecretsOmit || opts.Secrets == "" {
+		return "", nil
+	}
+
+	// Пароли импортированных подключений могут оставаться зашифрованными в памяти
+	if cs.encryptionService.IsInitialized() && len(password) >= 20 && isBase64Like(password) {
+		if decrypted, err := cs.encryptionService.DecryptPassword(password); err == nil {
+			password = decrypted
+		}
+	}
+
+	if opts.Secrets == SecretsEncrypt {
+		return cs.encryptionService.EncryptPassword(password)
+	}
+	return password, nil
+}
+
+// writeBundle шифрует подключения в формате JSON и записывает контейнер.
+// Открытые данные существуют только в памяти
+func writeBundle(exportPath string, connections []models.Connection, passphrase string) error {
+	var payload bytes.Buffer
+	if err := writeJSONExport(&payload, connections, false); err != nil {
+		return err
+	}
+
+	data, err := SealBundle(payload.Bytes(), passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(exportPath), 0755); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	if err := os.WriteFile(exportPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return nil
+}
+
+// hasExactTag проверяет наличие тега без учета регистра
+func hasExactTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteExport записывает подключения в формате opts.Format. Пароли записываются как есть,
+// opts.Secrets только отмечает зашифрованные
+func WriteExport(w io.Writer, connections []models.Connection, opts ExportOptions) error {
+	encrypted := opts.Secrets == SecretsEncrypt
+	switch opts.Format {
+	case ExportFormatOpenSSH:
+		return writeOpenSSHConfig(w, connections)
+	case ExportFormatJSON:
+		return writeJSONExport(w, connections, encrypted)
+	case ExportFormatYAML:
+		return writeYAMLExport(w, connections, encrypted)
+	case ExportFormatCSV:
+		return writeCSVExport(w, connections, encrypted)
+	case ExportFormatAnsibleINI:
+		return writeAnsibleINI(w, connections)
+	case ExportFormatAnsibleYAML:
+		return writeAnsibleYAML(w, connections)
+	case ExportFormatKnownHosts:
+		files := opts.KnownHostsFiles
+		if len(files) == 0 {
+			files = DefaultKnownHostsFiles()
+		}
+		return writeKnownHosts(w, connections, files)
+	}
+	return fmt.Errorf("unsupported export format %q", opts.Format)
+}
+
+// exportRecord подключение в экспортируемом виде (JSON, YAML, CSV)
+type exportRecord struct {
+	Name          string   `json:"name"`
+	Host          string   `json:"host"`
+	Port          int      `json:"port"`
+	User          string   `json:"user"`
+	Auth          string   `json:"auth"` // key или password
+	KeyPath       string   `json:"key_path,omitempty"`
+	Password      string   `json:"password,omitempty"`
+	Encrypted     bool     `json:"password_encrypted,omitempty"`
+	JumpHost      string   `json:"jump_host,omitempty"`
+	Group         string   `json:"group,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	RemoteCommand string   `json:"remote_command,omitempty"`
+	WorkDir       string   `json:"work_dir,omitempty"`
+	Env           []string `json:"env,omitempty"`
+}
+
+// newExportRecord преобразует подключение в запись экспорта
+func newExportRecord(conn models.Connection, encrypted bool) exportRecord {
+	record := exportRecord{
+		Name:          conn.Name,
+		Host:          conn.Host,
+		Port:          conn.Port,
+		User:          conn.User,
+		Auth:          "key",
+		KeyPath:       conn.KeyPath,
+		JumpHost:      conn.JumpHost,
+		Group:         conn.Group,
+		Tags:          conn.Tags,
+		RemoteCommand: conn.RemoteCommand,
+		WorkDir:       conn.WorkDir,
+		Env:           conn.Env,
+	}
+	if record.Port == 0 {
+		record.Port = 22
+	}
+	if !conn.UseSSHKey {
+		record.Auth = "password"
+		record.KeyPath = ""
+		record.Password = conn.Password
+		record.Encrypted = encrypted && conn.Password != ""
+	}
+	return record
+}
+
+// writeJSONExport записывает подключения массивом JSON
+func writeJSONExport(w io.Writer, connections []models.Connection, encrypted bool) error {
+	records := make([]exportRecord, 0, len(connections))
+	for _, conn := range connections {
+		records = append(records, newExportRecord(conn, encrypted))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// writeYAMLExport записывает подключения списком YAML. Строки записываются в
+// двойных кавычках JSON, которые YAML читает без изменений
+func writeYAMLExport(w io.Writer, connections []models.Connection, encrypted bool) error {
+	quote := func(value string) string {
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+	list := func(values []string) string {
+		quoted := make([]string, 0, len(values))
+		for _, value := range values {
+			quoted = append(quoted, quote(value))
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+
+	fmt.Fprintf(w, "# SSH Keeper export, %s\n", time.Now().Format(time.RFC3339))
+	if len(connections) == 0 {
+		_, err := fmt.Fprintln(w, "connections: []")
+		return err
+	}
+
+	fmt.Fprintln(w, "connections:")
+	for _, conn := range connections {
+		record := newExportRecord(conn, encrypted)
+		fmt.Fprintf(w, "  - name: %s\n", quote(record.Name))
+		fmt.Fprintf(w, "    host: %s\n", quote(record.Host))
+		fmt.Fprintf(w, "    port: %d\n", record.Port)
+		fmt.Fprintf(w, "    user: %s\n", quote(record.User))
+		fmt.Fprintf(w, "    auth: %s\n", record.Auth)
+		optional := []struct{ key, value string }{
+			{"key_path", record.KeyPath},
+			{"password", record.Password},
+			{"jump_host", record.JumpHost},
+			{"group", record.Group},
+			{"remote_command", record.RemoteCommand},
+			{"work_dir", record.WorkDir},
+		}
+		for _, field := range optional {
+			if field.value != "" {
+				fmt.Fprintf(w, "    %s: %s\n", field.key, quote(field.value))
+			}
+		}
+		if record.Encrypted {
+			fmt.Fprintln(w, "    password_encrypted: true")
+		}
+		if len(record.Tags) > 0 {
+			fmt.Fprintf(w, "    tags: %s\n", list(record.Tags))
+		}
+		if len(record.Env) > 0 {
+			fmt.Fprintf(w, "    env: %s\n", list(record.Env))
+		}
+	}
+	return nil
+}
+
+// csvHeader столбцы CSV экспорта
+var csvHeader = []string{
+	"name", "host", "port", "user", "auth", "key_path", "password", "password_encrypted",
+	"jump_host", "group", "tags", "remote_command", "work_dir", "env",
+}
+
+// writeCSVExport записывает подключения в CSV. Теги разделяются запятыми,
+// переменные окружения - пробелами
+func writeCSVExport(w io.Writer, connections []models.Connection, encrypted bool) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, conn := range connections {
+		record := newExportRecord(conn, encrypted)
+		encrypted := ""
+		if record.Encrypted {
+			encrypted = "true"
+		}
+		row := []string{
+			record.Name, record.Host, strconv.Itoa(record.Port), record.User, record.Auth,
+			record.KeyPath, record.Password, encrypted, record.JumpHost, record.Group,
+			strings.Join(record.Tags, ","), record.RemoteCommand, record.WorkDir,
+			strings.Join(record.Env, " "),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeOpenSSHConfig записывает настоящий файл конфигурации OpenSSH: только стандартные
+// ключевые слова, без паролей и собственных полей SSH Keeper (группа и теги - в комментариях)
+func writeOpenSSHConfig(w io.Writer, connections []models.Connection) error {
+	fmt.Fprintf(w, "# Exported by SSH Keeper on %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(w, "# Passwords are not exported; password connections will prompt on connect\n\n")
+	return writeOpenSSHHosts(w, connections, true)
+}
+
+// writeOpenSSHHosts записывает блоки Host для подключений. session - записывать
+// команду при входе (RemoteCommand и RequestTTY)
+func writeOpenSSHHosts(w io.Writer, connections []models.Connection, session bool) error {
+	used := make(map[string]bool, len(connections))
+	for _, conn := range connections {
+		alias := OpenSSHAlias(conn, used)
+
+		if alias != conn.Name {
+			fmt.Fprintf(w, "# %s\n", conn.Name)
+		}
+		if conn.Group != "" {
+			fmt.Fprintf(w, "# group: %s\n", conn.Group)
+		}
+		if len(conn.Tags) > 0 {
+			fmt.Fprintf(w, "# tags: %s\n", strings.Join(conn.Tags, ", "))
+		}
+		fmt.Fprintf(w, "Host %s\n", alias)
+		fmt.Fprintf(w, "    HostName %s\n", conn.Host)
+		if conn.User != "" {
+			fmt.Fprintf(w, "    User %s\n", conn.User)
+		}
+		if conn.Port != 0 && conn.Port != 22 {
+			fmt.Fprintf(w, "    Port %d\n", conn.Port)
+		}
+		if conn.UseSSHKey && conn.KeyPath != "" {
+			fmt.Fprintf(w, "    IdentityFile %s\n", quoteConfigValue(conn.KeyPath))
+			fmt.Fprintf(w, "    IdentitiesOnly yes\n")
+		}
+		if conn.JumpHost != "" {
+			fmt.Fprintf(w, "    ProxyJump %s\n", conn.JumpHost)
+		}
+
+		// Опции окружения приходят в виде "-o", "SetEnv=..."
+		for _, option := range ssh.EnvOptions(&conn) {
+			if option != "-o" {
+				fmt.Fprintf(w, "    %s\n", strings.Replace(option, "=", " ", 1))
+			}
+		}
+		if command := ssh.SessionCommand(&conn); session && command != "" {
+			// ssh раскрывает %-токены в RemoteCommand
+			fmt.Fprintf(w, "    RemoteCommand %s\n", strings.ReplaceAll(command, "%", "%%"))
+			fmt.Fprintf(w, "    RequestTTY yes\n")
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenSSHAlias возвращает уникальный псевдоним Host для подключения: название без
+// пробелов и символов шаблонов. used хранит уже занятые псевдонимы
+func OpenSSHAlias(conn models.Connection, used map[string]bool) string {
+	alias := strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ' || r == '\t':
+			return '-'
+		case strings.ContainsRune(`*?!,"'#`, r):
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(conn.Name))
+	if alias == "" {
+		alias = conn.Host
+	}
+
+	unique := alias
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		unique = fmt.Sprintf("%s-%d", alias, i)
+	}
+	used[strings.ToLower(unique)] = true
+	return unique
+}
+
+// quoteConfigValue заключает значение с пробелами в кавычки
+func quoteConfigValue(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+	return value
+}
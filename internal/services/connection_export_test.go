@@ -0,0 +1,263 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+// exportTestConnections подключения с ключом и паролем, покрывающие все поля экспорта
+func exportTestConnections() []models.Connection {
+	return []models.Connection{
+		{
+			ID: "web", Name: "web eu", Host: "10.0.0.5", Port: 2222, User: "deploy",
+			UseSSHKey: true, KeyPath: "~/.ssh/id ed25519", JumpHost: "admin@bastion:2200",
+			Group: "prod", Tags: []string{"web", "eu"},
+			RemoteCommand: "tmux attach", WorkDir: "/srv/app", Env: []string{"LANG=C.UTF-8", "LC_*"},
+		},
+		{
+			ID: "db", Name: "db", Host: "db.internal", Port: 22, User: "postgres",
+			HasPassword: true, Password: `p@ss, "word"`, Group: "prod", Tags: []string{"db"},
+		},
+		{
+			ID: "stage", Name: "stage", Host: "stage.example.com", Port: 22, User: "root",
+			UseSSHKey: true, Group: "stage",
+		},
+	}
+}
+
+// exportedFields оставляет поля, которые переносит экспорт; пустые списки - nil
+func exportedFields(conn models.Connection) models.Connection {
+	fields := models.Connection{
+		Name: conn.Name, Host: conn.Host, Port: conn.Port, User: conn.User,
+		UseSSHKey: conn.UseSSHKey, KeyPath: conn.KeyPath, JumpHost: conn.JumpHost,
+		HasPassword: conn.HasPassword, Password: conn.Password,
+		Group: conn.Group, Tags: conn.Tags,
+		RemoteCommand: conn.RemoteCommand, WorkDir: conn.WorkDir, Env: conn.Env,
+	}
+	if len(fields.Tags) == 0 {
+		fields.Tags = nil
+	}
+	if len(fields.Env) == 0 {
+		fields.Env = nil
+	}
+	return fields
+}
+
+func TestExportParsesBack(t *testing.T) {
+	connections := exportTestConnections()
+
+	tests := []struct {
+		format ExportFormat
+		parse  func([]byte) ([]models.Connection, error)
+	}{
+		{ExportFormatJSON, parseJSONImport},
+		{ExportFormatCSV, func(data []byte) ([]models.Connection, error) { return parseCSVImport(string(data)) }},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteExport(&buf, connections, ExportOptions{Format: tt.format, Secrets: SecretsPlain}); err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := tt.parse(buf.Bytes())
+			if err != nil {
+				t.Fatalf("parse: %v\n%s", err, buf.String())
+			}
+			if len(parsed) != len(connections) {
+				t.Fatalf("parsed %d connections, want %d", len(parsed), len(connections))
+			}
+			for i := range connections {
+				got, want := exportedFields(parsed[i]), exportedFields(connections[i])
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("connection %d:\n got %+v\nwant %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExportOpenSSHUsesOnlyOpenSSHKeywords(t *testing.T) {
+	allowed := map[string]bool{
+		"Host": true, "HostName": true, "User": true, "Port": true, "IdentityFile": true,
+		"IdentitiesOnly": true, "ProxyJump": true, "SetEnv": true, "SendEnv": true,
+		"RemoteCommand": true, "RequestTTY": true,
+	}
+
+	var buf bytes.Buffer
+	if err := WriteExport(&buf, exportTestConnections(), ExportOptions{Format: ExportFormatOpenSSH, Secrets: SecretsPlain}); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if keyword := strings.Fields(line)[0]; !allowed[keyword] {
+			t.Errorf("non-OpenSSH keyword %q in line %q", keyword, line)
+		}
+	}
+	if strings.Contains(output, "p@ss") {
+		t.Errorf("password written to OpenSSH config:\n%s", output)
+	}
+	for _, want := range []string{"Host web-eu\n", `IdentityFile "~/.ssh/id ed25519"`, "ProxyJump admin@bastion:2200", "SetEnv LANG=C.UTF-8", "SendEnv LC_*"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestWriteOpenSSHHostsSession(t *testing.T) {
+	connections := exportTestConnections()[:1]
+
+	var session, include bytes.Buffer
+	if err := writeOpenSSHHosts(&session, connections, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeOpenSSHHosts(&include, connections, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(session.String(), "RemoteCommand ") || !strings.Contains(session.String(), "RequestTTY yes") {
+		t.Errorf("session hosts lack the startup command:\n%s", session.String())
+	}
+	// Include файл используют и scp, и rsync: команда при входе им мешает
+	if strings.Contains(include.String(), "RemoteCommand") || strings.Contains(include.String(), "RequestTTY") {
+		t.Errorf("include hosts contain the startup command:\n%s", include.String())
+	}
+}
+
+// newExportTestService создает сервис с подключениями и ключом шифрования паролей
+func newExportTestService(t *testing.T) *ConnectionService {
+	t.Helper()
+	return &ConnectionService{
+		connections:       exportTestConnections(),
+		encryptionService: newTestEncryption(t),
+	}
+}
+
+// readJSONExport читает записи JSON экспорта из файла
+func readJSONExport(t *testing.T, path string) []exportRecord {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []exportRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestExportSecretsModes(t *testing.T) {
+	cs := newExportTestService(t)
+	dir := t.TempDir()
+	const password = `p@ss, "word"`
+
+	t.Run("omit", func(t *testing.T) {
+		path := filepath.Join(dir, "omit.json")
+		if _, err := cs.Export(path, ExportOptions{Format: ExportFormatJSON, Secrets: SecretsOmit}); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "p@ss") || strings.Contains(string(data), `"password":`) {
+			t.Errorf("password exported in omit mode:\n%s", data)
+		}
+	})
+
+	t.Run("encrypt", func(t *testing.T) {
+		path := filepath.Join(dir, "encrypt.json")
+		if _, err := cs.Export(path, ExportOptions{Format: ExportFormatJSON, Secrets: SecretsEncrypt}); err != nil {
+			t.Fatal(err)
+		}
+		assertOwnerOnly(t, path)
+
+		record := readJSONExport(t, path)[1]
+		if !record.Encrypted || record.Password == password {
+			t.Fatalf("password not encrypted: %+v", record)
+		}
+		decrypted, err := cs.encryptionService.DecryptPassword(record.Password)
+		if err != nil || decrypted != password {
+			t.Errorf("DecryptPassword = %q, %v; want %q", decrypted, err, password)
+		}
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		path := filepath.Join(dir, "plain.csv")
+		if _, err := cs.Export(path, ExportOptions{Format: ExportFormatCSV, Secrets: SecretsPlain}); err != nil {
+			t.Fatal(err)
+		}
+		assertOwnerOnly(t, path)
+	})
+
+	t.Run("bundle", func(t *testing.T) {
+		path := filepath.Join(dir, "export.sshkeeper")
+		if _, err := cs.Export(path, ExportOptions{Format: ExportFormatBundle, Passphrase: testBundlePassphrase}); err != nil {
+			t.Fatal(err)
+		}
+		assertOwnerOnly(t, path)
+	})
+
+	t.Run("encrypt without master password", func(t *testing.T) {
+		locked := &ConnectionService{connections: exportTestConnections(), encryptionService: &EncryptionService{}}
+		_, err := locked.Export(filepath.Join(dir, "locked.json"), ExportOptions{Format: ExportFormatJSON, Secrets: SecretsEncrypt})
+		if err == nil {
+			t.Error("expected an error without a data key")
+		}
+	})
+}
+
+// assertOwnerOnly проверяет, что файл доступен только владельцу
+func assertOwnerOnly(t *testing.T, path string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("%s mode = %o, want 600", filepath.Base(path), perm)
+	}
+}
+
+func TestExportConnectionsFilters(t *testing.T) {
+	cs := newExportTestService(t)
+
+	tests := []struct {
+		name string
+		opts ExportOptions
+		want []string
+	}{
+		{"all", ExportOptions{}, []string{"web eu", "db", "stage"}},
+		{"group", ExportOptions{Group: "PROD"}, []string{"web eu", "db"}},
+		{"tag", ExportOptions{Tag: "Web"}, []string{"web eu"}},
+		{"group and tag", ExportOptions{Group: "prod", Tag: "db"}, []string{"db"}},
+		{"tag is not a group", ExportOptions{Tag: "stage"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connections, err := cs.ExportConnections(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, conn := range connections {
+				names = append(names, conn.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("names = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+// connection преобразует запись экспорта обратно в подключение
+func (r exportRecord) connection() models.Connection {
+	conn := models.Connection{
+		Name:          r.Name,
+		Host:          r.Host,
+		Port:          r.Port,
+		User:          r.User,
+		JumpHost:      r.JumpHost,
+		Group:         r.Group,
+		Tags:          r.Tags,
+		KeyPath:       r.KeyPath,
+		UseSSHKey:     r.Auth != "password",
+		RemoteCommand: r.RemoteCommand,
+		WorkDir:       r.WorkDir,
+		Env:           r.Env,
+	}
+	if conn.Port == 0 {
+		conn.Port = 22
+	}
+	if !conn.UseSSHKey {
+		conn.HasPassword = r.Password != ""
+		conn.Password = r.Password
+	}
+	return conn
+}
+
+// parseJSONExport разбирает подключения из JSON экспорта
+func parseJSONExport(data []byte) ([]models.Connection, error) {
+	var records []exportRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse connections: %w", err)
+	}
+
+	connections := make([]models.Connection, 0, len(records))
+	for _, record := range records {
+		connections = append(connections, record.connection())
+	}
+	return connections, nil
+}
+
+// ParseBundle расшифровывает контейнер экспорта паролем и возвращает подключения из него
+func ParseBundle(importPath, passphrase string) ([]models.Connection, error) {
+	data, err := os.ReadFile(importPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", importPath, err)
+	}
+
+	payload, err := OpenBundle(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONExport(payload)
+}
+
+// ParseSSHConfigFile читает подключения из файла SSH config для предпросмотра импорта.
+// Зашифрованные мастер-паролем пароли расшифровываются, шаблоны не импортируются,
+// наследники получают действующие значения
+func (cs *ConnectionService) ParseSSHConfigFile(importPath string) ([]models.Connection, error) {
+	importService := NewSSHConfigService(importPath)
+	config, err := importService.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config from %s: %w", importPath, err)
+	}
+
+	parsed := importService.ConvertSSHConfigToConnections(config)
+	models.ResolveInheritance(parsed)
+
+	connections := make([]models.Connection, 0, len(parsed))
+	for _, conn := range parsed {
+		if conn.Template {
+			continue
+		}
+		conn = conn.Flatten()
+		conn.ID = ""
+
+		// Пароль мог быть зашифрован тем же мастер-паролем; иначе он уже открытый
+		if conn.Password != "" && cs.encryptionService.IsInitialized() && len(conn.Password) >= 20 && isBase64Like(conn.Password) {
+			if decrypted, err := cs.encryptionService.DecryptPassword(conn.Password); err == nil {
+				conn.Password = decrypted
+			}
+		}
+		connections = append(connections, conn)
+	}
+
+	if len(connections) == 0 {
+		return nil, fmt.Errorf("в файле не найдено подключений")
+	}
+	return connections, nil
+}
+
+// ImportStatus результат сравнения импортируемого подключения с существующими
+type ImportStatus int
+
+const (
+	ImportStatusNew       ImportStatus = iota // Подключения с таким хостом, портом и пользователем нет
+	ImportStatusIdentical                     // Есть такое же подключение
+	ImportStatusConflict                      // Есть подключение к тому же хосту с другими полями
+	ImportStatusRemoved                       // Подключение из того же источника, хоста в нем больше нет
+)
+
+// ImportAction действие с импортируемым подключением
+type ImportAction string
+
+const (
+	ImportCreate    ImportAction = "create"    // Создать новое подключение
+	ImportSkip      ImportAction = "skip"      // Пропустить
+	ImportOverwrite ImportAction = "overwrite" // Заменить поля существующего
+	ImportKeepBoth  ImportAction = "keep-both" // Создать рядом с существующим
+	ImportMerge     ImportAction = "merge"     // Дополнить пустые поля существующего, объединить теги
+	ImportFlag      ImportAction = "flag"      // Пометить тегом models.SourceRemovedTag
+)
+
+// ImportItem импортируемое подключение с результатом сравнения и выбранным действием
+type ImportItem struct {
+	Incoming models.Connection
+	Existing *models.Connection // Копия совпавшего подключения; nil для новых
+	Status   ImportStatus
+	Action   ImportAction
+	Changes  []models.FieldChange // Различия с существующим подключением
+	Warning  string               // Что не удалось перенести как есть (например, ключ PuTTY)
+}
+
+// Actions возвращает действия, допустимые для подключения
+func (item ImportItem) Actions() []ImportAction {
+	switch item.Status {
+	case ImportStatusNew:
+		return []ImportAction{ImportCreate, ImportSkip}
+	case ImportStatusIdentical:
+		return []ImportAction{ImportSkip, ImportKeepBoth}
+	case ImportStatusRemoved:
+		return []ImportAction{ImportFlag, ImportSkip}
+	}
+	return []ImportAction{ImportSkip, ImportOverwrite, ImportKeepBoth, ImportMerge}
+}
+
+// ImportSummary итог применения импорта
+type ImportSummary struct {
+	Created int
+	Updated int
+	Flagged int // Помечено как удаленные из источника
+	Skipped int
+}
+
+// PlanImport сравнивает импортируемые подключения с существующими. Подключение
+// из источника синхронизации (Source) сопоставляется с ранее импортированным из
+// него же; иначе совпадением считается подключение к тому же хосту, порту и
+// пользователю, при нескольких совпадениях - с тем же названием. Новые подключения
+// по умолчанию создаются, изменившиеся в источнике - обновляются, остальные
+// пропускаются. Подключения источника, которых в нем больше нет, помечаются
+func (cs *ConnectionService) PlanImport(connections []models.Connection) []ImportItem {
+	items := make([]ImportItem, 0, len(connections))
+	synced := make(map[string]bool)
+	for _, conn := range connections {
+		warning := replacePuTTYKey(&conn)
+		item := ImportItem{Incoming: conn, Status: ImportStatusNew, Action: ImportCreate, Warning: warning}
+
+		existing := cs.findSourceMatch(conn)
+		fromSource := existing != nil
+		if existing == nil {
+			existing = cs.findImportMatch(conn)
+		}
+		if existing != nil {
+			match := *existing
+			item.Existing = &match
+			item.Changes = models.ImportDiff(&match, &conn)
+			item.Status = ImportStatusConflict
+			item.Action = ImportSkip
+			if len(item.Changes) == 0 {
+				item.Status = ImportStatusIdentical
+			} else if fromSource {
+				item.Action = ImportOverwrite
+			}
+			synced[match.ID] = true
+		}
+		items = append(items, item)
+	}
+
+	// Хосты, пропавшие из источников импорта
+	sources := make(map[string]bool)
+	for _, conn := range connections {
+		if conn.Source != "" {
+			sources[conn.Source] = true
+		}
+	}
+	for _, existing := range cs.connections {
+		if !sources[existing.Source] || synced[existing.ID] || existing.HasTag(models.SourceRemovedTag) {
+			continue
+		}
+		match := existing
+		items = append(items, ImportItem{
+			Incoming: existing,
+			Existing: &match,
+			Status:   ImportStatusRemoved,
+			Action:   ImportFlag,
+		})
+	}
+	return items
+}
+
+// findSourceMatch ищет подключение, ранее импортированное из того же источника
+func (cs *ConnectionService) findSourceMatch(conn models.Connection) *models.Connection {
+	if conn.Source == "" {
+		return nil
+	}
+	for i := range cs.connections {
+		if cs.connections[i].Source == conn.Source && cs.connections[i].SourceHost == conn.SourceHost {
+			return &cs.connections[i]
+		}
+	}
+	return nil
+}
+
+// findImportMatch ищет существующее подключение к тому же хосту, порту и пользователю
+func (cs *ConnectionService) findImportMatch(conn models.Connection) *models.Connection {
+	var match *models.Connection
+	for i := range cs.connections {
+		existing := &cs.connections[i]
+		if existing.Template || !strings.EqualFold(existing.Host, conn.Host) ||
+			existing.Port != conn.Port || existing.User != conn.User {
+			continue
+		}
+		if existing.Name == conn.Name {
+			return existing
+		}
+		if match == nil {
+			match = existing
+		}
+	}
+	return match
+}
+
+// ApplyImport применяет выбранные действия одной операцией: при ошибке подключения
+// не меняются, при успехе импорт отменяется одним Ctrl+Z
+func (cs *ConnectionService) ApplyImport(items []ImportItem, description string) (ImportSummary, error) {
+	var summary ImportSummary
+	before := slices.Clone(cs.connections)
+	connections := slices.Clone(cs.connections)
+
+	index := make(map[string]int, len(connections))
+	names := make(map[string]bool, len(connections))
+	for i := range connections {
+		index[connections[i].ID] = i
+		names[connections[i].Name] = true
+	}
+
+	now := time.Now()
+	for _, item := range items {
+		conn := item.Incoming
+		switch item.Action {
+		case ImportCreate, ImportKeepBoth:
+			conn.ID = generateID()
+			conn.CreatedAt = now
+			conn.UpdatedAt = now
+			if item.Action == ImportKeepBoth {
+				conn.Name = uniqueName(conn.Name, names)
+			}
+			names[conn.Name] = true
+			connections = append(connections, conn)
+			summary.Created++
+
+		case ImportOverwrite, ImportMerge:
+			if item.Existing == nil {
+				return ImportSummary{}, fmt.Errorf("нет подключения для обновления: %s", conn.Name)
+			}
+			i, ok := index[item.Existing.ID]
+			if !ok {
+				return ImportSummary{}, fmt.Errorf("подключение '%s' было удалено", item.Existing.Name)
+			}
+			connections[i] = models.MergeImported(&connections[i], &conn, item.Action == ImportOverwrite)
+			if conn.Source != "" {
+				// Подключение связывается с источником и снова в нем присутствует
+				connections[i].Source = conn.Source
+				connections[i].SourceHost = conn.SourceHost
+				connections[i].Tags = slices.DeleteFunc(connections[i].Tags, func(tag string) bool {
+					return strings.EqualFold(tag, models.SourceRemovedTag)
+				})
+			}
+			connections[i].UpdatedAt = now
+			summary.Updated++
+
+		case ImportFlag:
+			if item.Existing == nil {
+				return ImportSummary{}, fmt.Errorf("нет подключения для пометки: %s", conn.Name)
+			}
+			i, ok := index[item.Existing.ID]
+			if !ok {
+				return ImportSummary{}, fmt.Errorf("подключение '%s' было удалено", item.Existing.Name)
+			}
+			if !connections[i].HasTag(models.SourceRemovedTag) {
+				connections[i].Tags = append(slices.Clone(connections[i].Tags), models.SourceRemovedTag)
+				connections[i].UpdatedAt = now
+				summary.Flagged++
+			}
+
+		default:
+			summary.Skipped++
+		}
+	}
+
+	if summary.Created == 0 && summary.Updated == 0 && summary.Flagged == 0 {
+		return summary, nil
+	}
+
+	cs.connections = connections
+	models.ResolveInheritance(cs.connections)
+	if err := cs.commit(description, before); err != nil {
+		cs.connections = before
+		return ImportSummary{}, err
+	}
+	return summary, nil
+}
+
+// uniqueName добавляет к названию номер, если оно уже занято
+func uniqueName(name string, names map[string]bool) string {
+	if !names[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)", name, i)
+		if !names[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"ssh-keeper/internal/models"
+)
+
+// JournalFileName имя файла журнала изменений подключений в каталоге конфигурации
+const JournalFileName = "journal.json"
+
+// JournalLimit сколько последних операций можно отменить
+const JournalLimit = 50
+
+// commit сохраняет подключения и записывает операцию в журнал отмены.
+// before - список подключений до операции
+func (cs *ConnectionService) commit(description string, before []models.Connection) error {
+	// Запись строится только из изменений этой операции: изменения, полученные
+	// синхронизацией, отмена не затрагивает
+	entry := models.NewJournalEntry(description, before, cs.connections)
+
+	if err := cs.SaveConnectionsToFile(); err != nil {
+		return err
+	}
+	if entry == nil {
+		return nil
+	}
+
+	cs.journal.Undo = append(cs.journal.Undo, *entry)
+	if len(cs.journal.Undo) > JournalLimit {
+		cs.journal.Undo = slices.Clone(cs.journal.Undo[len(cs.journal.Undo)-JournalLimit:])
+	}
+	cs.journal.Redo = nil
+
+	// Журнал вспомогательный: ошибка его записи не отменяет сохраненную операцию
+	_ = cs.saveJournal()
+	return nil
+}
+
+// CanUndo возвращает описание операции, которую отменит Undo
+func (cs *ConnectionService) CanUndo() (string, bool) {
+	if len(cs.journal.Undo) == 0 {
+		return "", false
+	}
+	return cs.journal.Undo[len(cs.journal.Undo)-1].Description, true
+}
+
+// CanRedo возвращает описание операции, которую повторит Redo
+func (cs *ConnectionService) CanRedo() (string, bool) {
+	if len(cs.journal.Redo) == 0 {
+		return "", false
+	}
+	return cs.journal.Redo[len(cs.journal.Redo)-1].Description, true
+}
+
+// Undo отменяет последнюю операцию и возвращает ее описание
+func (cs *ConnectionService) Undo() (string, error) {
+	if len(cs.journal.Undo) == 0 {
+		return "", fmt.Errorf("нечего отменять")
+	}
+	entry := cs.journal.Undo[len(cs.journal.Undo)-1]
+	if err := cs.applyJournalEntry(entry.Revert(cs.connections)); err != nil {
+		return "", err
+	}
+
+	cs.journal.Undo = cs.journal.Undo[:len(cs.journal.Undo)-1]
+	cs.journal.Redo = append(cs.journal.Redo, entry)
+	_ = cs.saveJournal()
+	return entry.Description, nil
+}
+
+// Redo повторяет последнюю отмененную операцию и возвращает ее описание
+func (cs *ConnectionService) Redo() (string, error) {
+	if len(cs.journal.Redo) == 0 {
+		return "", fmt.Errorf("нечего повторять")
+	}
+	entry := cs.journal.Redo[len(cs.journal.Redo)-1]
+	if err := cs.applyJournalEntry(entry.Replay(cs.connections)); err != nil {
+		return "", err
+	}
+
+	cs.journal.Redo = cs.journal.Redo[:len(cs.journal.Redo)-1]
+	cs.journal.Undo = append(cs.journal.Undo, entry)
+	_ = cs.saveJournal()
+	return entry.Description, nil
+}
+
+// applyJournalEntry устанавливает восстановленный список подключений и сохраняет его
+func (cs *ConnectionService) applyJournalEntry(connections []models.Connection) error {
+	previous := cs.connections
+	cs.connections = connections
+	models.ResolveInheritance(cs.connections)
+
+	if err := cs.SaveConnectionsToFile(); err != nil {
+		cs.connections = previous
+		return err
+	}
+	return nil
+}
+
+// journalPath возвращает путь к файлу журнала рядом с файлом конфигурации
+func (cs *ConnectionService) journalPath() string {
+	return filepath.Join(filepath.Dir(cs.configPath), JournalFileName)
+}
+
+// loadJournal читает журнал изменений. Отсутствующий или поврежденный журнал
+// заменяется пустым: потеря истории отмены не мешает работе с подключениями
+func (cs *ConnectionService) loadJournal() {
+	cs.journal = models.Journal{}
+
+	data, err := os.ReadFile(cs.journalPath())
+	if err != nil {
+		return
+	}
+
+	var journal models.Journal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return
+	}
+
+	// Пароли в журнале хранятся так же, как в конфигурации. Если пароль не удалось
+	// расшифровать, журнал отбрасывается: отмена с неполными записями испортила бы подключения
+	if cs.encryptionService.IsInitialized() {
+		err := cs.transformJournalPasswords(&journal, func(password string) (string, error) {
+			if len(password) < 20 || !isBase64Like(password) {
+				return password, nil
+			}
+			return cs.encryptionService.DecryptPassword(password)
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	cs.journal = journal
+}
+
+// saveJournal записывает журнал изменений
+func (cs *ConnectionService) saveJournal() error {
+	journal := models.Journal{
+		Undo: slices.Clone(cs.journal.Undo),
+		Redo: slices.Clone(cs.journal.Redo),
+	}
+
+	if cs.encryptionService.IsInitialized() {
+		if err := cs.transformJournalPasswords(&journal, cs.encryptionService.EncryptPassword); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(journal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+
+	if err := os.WriteFile(cs.journalPath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// transformJournalPasswords применяет transform к паролям всех подключений журнала.
+// Записи копируются, чтобы не менять журнал в памяти
+func (cs *ConnectionService) transformJournalPasswords(journal *models.Journal, transform func(string) (string, error)) error {
+	transformItems := func(items []models.JournalItem) ([]models.JournalItem, error) {
+		items = slices.Clone(items)
+		for i := range items {
+			if items[i].Connection.Password == "" {
+				continue
+			}
+			password, err := transform(items[i].Connection.Password)
+			if err != nil {
+				return nil, fmt.Errorf("failed to process password for connection %s: %w", items[i].Connection.ID, err)
+			}
+			items[i].Connection.Password = password
+		}
+		return items, nil
+	}
+
+	for _, entries := range [][]models.JournalEntry{journal.Undo, journal.Redo} {
+		for i := range entries {
+			var err error
+			if entries[i].Before, err = transformItems(entries[i].Before); err != nil {
+				return err
+			}
+			if entries[i].After, err = transformItems(entries[i].After); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
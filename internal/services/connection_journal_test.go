@@ -0,0 +1,37 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+func TestLoadJournalDiscardsUndecryptableJournal(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "connections.conf")
+	web := models.Connection{ID: "web", Name: "web", Host: "10.0.0.5", HasPassword: true, Password: "secret"}
+	changed := web
+	changed.Password = "changed"
+
+	writer := &ConnectionService{configPath: configPath, encryptionService: newTestEncryption(t)}
+	writer.journal.Undo = []models.JournalEntry{
+		*models.NewJournalEntry("change password", []models.Connection{web}, []models.Connection{changed}),
+	}
+	if err := writer.saveJournal(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Тот же ключ читает журнал
+	reader := &ConnectionService{configPath: configPath, encryptionService: writer.encryptionService}
+	reader.loadJournal()
+	if _, ok := reader.CanUndo(); !ok {
+		t.Fatal("journal lost with the right key")
+	}
+
+	// Другой ключ не расшифрует пароль: журнал отбрасывается целиком
+	reader = &ConnectionService{configPath: configPath, encryptionService: newTestEncryption(t)}
+	reader.loadJournal()
+	if _, ok := reader.CanUndo(); ok {
+		t.Errorf("journal with an undecryptable password kept: %+v", reader.journal)
+	}
+}
@@ -3,8 +3,12 @@ package services
 import (
 	"fmt"
 	"os"
+	"slices"
 	"ssh-keeper/internal/models"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // ConnectionService предоставляет методы для работы с подключениями
@@ -13,6 +17,14 @@ type ConnectionService struct {
 	sshConfigService  *SSHConfigService
 	encryptionService *EncryptionService
 	configPath        string
+	journal           models.Journal    // Журнал операций для отмены и повтора
+	gitSync           *GitSync          // Синхронизация через git-репозиторий, если настроена
+	syncErr           error             // Ошибка последней автоматической синхронизации
+	syncPending       bool              // Есть сохраненные изменения, которые еще не синхронизированы
+	teamVault         *TeamVault        // Открытое командное хранилище, если подключения общие
+	teamIdentity      *TeamIdentity     // Личный ключ участника командного хранилища
+	sshInclude        *SSHConfigInclude // Файл подключений для Include в ~/.ssh/config, если включен
+	sshIncludeErr     error             // Ошибка последней записи файла для Include
 }
 
 // NewConnectionService создает новый сервис подключений
@@ -62,15 +74,37 @@ func (cs *ConnectionService) LoadConnectionsFromFile() error {
 		}
 	}
 
+	// Наследуемые поля заполняются значениями шаблонов; подключения с потерянным
+	// шаблоном сохраняют собственные значения
+	models.ResolveInheritance(connections)
+
 	cs.connections = connections
+
+	// Журнал читается после инициализации шифрования, чтобы расшифровать пароли в нем
+	cs.loadJournal()
 	return nil
 }
 
-// SaveConnectionsToFile saves connections to SSH config file
+// SaveConnectionsToFile saves connections to SSH config file and marks them for sync
+// to the sync repository when git sync is enabled
 func (cs *ConnectionService) SaveConnectionsToFile() error {
-	// Create a copy of connections for encryption
+	if err := cs.saveLocal(); err != nil {
+		return err
+	}
+
+	// Отправка в репозиторий не блокирует правку: интерфейс синхронизирует
+	// изменения в фоне после операции (см. TakeSyncPending)
+	cs.syncPending = cs.gitSync != nil
+	return nil
+}
+
+// saveLocal saves connections to SSH config file without syncing
+func (cs *ConnectionService) saveLocal() error {
+	// Create a copy of connections for encryption; inherited values are not stored
 	connectionsCopy := make([]models.Connection, len(cs.connections))
-	copy(connectionsCopy, cs.connections)
+	for i := range cs.connections {
+		connectionsCopy[i] = cs.connections[i].WithoutInherited()
+	}
 
 	// Encrypt passwords before saving (only if encryption service is initialized)
 	if cs.encryptionService.IsInitialized() {
@@ -86,7 +120,13 @@ func (cs *ConnectionService) SaveConnectionsToFile() error {
 	}
 
 	config := cs.sshConfigService.ConvertConnectionsToSSHConfig(connectionsCopy)
-	return cs.sshConfigService.SaveConfig(config)
+	if err := cs.sshConfigService.SaveConfig(config); err != nil {
+		return err
+	}
+
+	// Хранилище уже сохранено: ошибка записи файла для Include показывается отдельно
+	_ = cs.writeSSHConfigInclude()
+	return nil
 }
 
 // GetAllConnections возвращает все подключения
@@ -104,39 +144,163 @@ func (cs *ConnectionService) GetConnectionByID(id string) *models.Connection {
 	return nil
 }
 
+// FindConnectionByName ищет подключение по имени: сначала точное совпадение,
+// затем без учета регистра. Возвращает ошибку, если имя неоднозначно
+func (cs *ConnectionService) FindConnectionByName(name string) (*models.Connection, error) {
+	for _, conn := range cs.connections {
+		if conn.Name == name {
+			return &conn, nil
+		}
+	}
+
+	var found []models.Connection
+	for _, conn := range cs.connections {
+		if strings.EqualFold(conn.Name, name) {
+			found = append(found, conn)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("подключение '%s' не найдено", name)
+	case 1:
+		return &found[0], nil
+	}
+	return nil, fmt.Errorf("имя '%s' соответствует нескольким подключениям", name)
+}
+
+// HasConnectionNamed проверяет, есть ли подключение с таким именем (без учета регистра)
+func (cs *ConnectionService) HasConnectionNamed(name string) bool {
+	return slices.ContainsFunc(cs.connections, func(conn models.Connection) bool {
+		return strings.EqualFold(conn.Name, name)
+	})
+}
+
 // AddConnection добавляет новое подключение
 func (cs *ConnectionService) AddConnection(conn *models.Connection) error {
 	conn.ID = generateID()
+	if err := models.CheckParent(cs.connections, conn.ID, conn.Parent); err != nil {
+		return err
+	}
+
+	before := slices.Clone(cs.connections)
+
 	conn.CreatedAt = time.Now()
 	conn.UpdatedAt = time.Now()
 	cs.connections = append(cs.connections, *conn)
+	models.ResolveInheritance(cs.connections)
 
 	// Auto-save to file
-	return cs.SaveConnectionsToFile()
+	return cs.commit(fmt.Sprintf("Добавлено подключение '%s'", conn.Name), before)
 }
 
-// UpdateConnection обновляет существующее подключение
-func (cs *ConnectionService) UpdateConnection(conn *models.Connection) error {
+// UpdateConnection обновляет существующее подключение. opened - копия подключения,
+// с которой начато редактирование: переносятся только поля, измененные относительно
+// нее, поэтому изменения, полученные синхронизацией за время правки, сохраняются
+func (cs *ConnectionService) UpdateConnection(opened, conn *models.Connection) error {
+	if err := models.CheckParent(cs.connections, conn.ID, conn.Parent); err != nil {
+		return err
+	}
+
+	before := slices.Clone(cs.connections)
 	for i, existing := range cs.connections {
 		if existing.ID == conn.ID {
-			conn.UpdatedAt = time.Now()
-			cs.connections[i] = *conn
+			updated, err := models.ApplyEdit(existing, *opened, *conn)
+			if err != nil {
+				return err
+			}
+			updated.UpdatedAt = time.Now()
+			cs.connections[i] = updated
+			*conn = updated
+
+			// Изменения шаблона применяются ко всем подключениям, которые от него наследуют
+			models.ResolveInheritance(cs.connections)
 
 			// Auto-save to file
-			return cs.SaveConnectionsToFile()
+			return cs.commit(fmt.Sprintf("Изменено подключение '%s'", conn.Name), before)
+		}
+	}
+	return fmt.Errorf("подключение '%s' удалено, пока оно редактировалось", conn.Name)
+}
+
+// UpdateConnections обновляет несколько подключений и сохраняет файл один раз.
+// opened[i] - копия conns[i] до правки, как в UpdateConnection
+func (cs *ConnectionService) UpdateConnections(opened, conns []models.Connection) error {
+	index := make(map[string]int, len(cs.connections))
+	for i := range cs.connections {
+		index[cs.connections[i].ID] = i
+	}
+
+	updated := make([]models.Connection, len(conns))
+	for i, conn := range conns {
+		current, ok := index[conn.ID]
+		if !ok {
+			return fmt.Errorf("подключение '%s' удалено, пока оно редактировалось", conn.Name)
+		}
+		var err error
+		if updated[i], err = models.ApplyEdit(cs.connections[current], opened[i], conn); err != nil {
+			return err
 		}
 	}
-	return fmt.Errorf("connection with ID %s not found", conn.ID)
+
+	before := slices.Clone(cs.connections)
+	now := time.Now()
+	for _, conn := range updated {
+		conn.UpdatedAt = now
+		cs.connections[index[conn.ID]] = conn
+	}
+
+	// Изменения шаблонов применяются к их наследникам
+	models.ResolveInheritance(cs.connections)
+
+	return cs.commit(fmt.Sprintf("Групповая правка подключений: %d", len(conns)), before)
+}
+
+// DeleteConnections удаляет несколько подключений и сохраняет файл один раз
+func (cs *ConnectionService) DeleteConnections(ids []string) error {
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	kept := make([]models.Connection, 0, len(cs.connections))
+	for _, conn := range cs.connections {
+		if !remove[conn.ID] {
+			kept = append(kept, conn)
+		}
+	}
+	if len(kept) == len(cs.connections) {
+		return fmt.Errorf("connections not found")
+	}
+
+	// Наследники удаленных шаблонов сохраняют действующие значения
+	for i := range kept {
+		if remove[kept[i].Parent] {
+			kept[i] = kept[i].Flatten()
+		}
+	}
+
+	before := cs.connections
+	cs.connections = kept
+	return cs.commit(fmt.Sprintf("Удалено подключений: %d", len(before)-len(kept)), before)
 }
 
 // DeleteConnection удаляет подключение по ID
 func (cs *ConnectionService) DeleteConnection(id string) error {
 	for i, conn := range cs.connections {
 		if conn.ID == id {
-			cs.connections = append(cs.connections[:i], cs.connections[i+1:]...)
+			before := slices.Clone(cs.connections)
+			cs.connections = slices.Delete(cs.connections, i, i+1)
+
+			// Наследники удаленного шаблона сохраняют действующие значения
+			for j := range cs.connections {
+				if cs.connections[j].Parent == id {
+					cs.connections[j] = cs.connections[j].Flatten()
+				}
+			}
 
 			// Auto-save to file
-			return cs.SaveConnectionsToFile()
+			return cs.commit(fmt.Sprintf("Удалено подключение '%s'", conn.Name), before)
 		}
 	}
 	return fmt.Errorf("connection with ID %s not found", id)
@@ -145,50 +309,16 @@ func (cs *ConnectionService) DeleteConnection(id string) error {
 // ExportConfig exports connections to SSH config file
 func (cs *ConnectionService) ExportConfig(exportPath string) error {
 	exportService := NewSSHConfigService(exportPath)
-	config := cs.sshConfigService.ConvertConnectionsToSSHConfig(cs.connections)
+	config := cs.sshConfigService.ConvertConnectionsToSSHConfig(cs.exportConnections())
 	return exportService.SaveConfig(config)
 }
 
-// ImportConfig imports connections from SSH config file
-func (cs *ConnectionService) ImportConfig(importPath string) error {
-	importService := NewSSHConfigService(importPath)
-	config, err := importService.LoadConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load config from %s: %w", importPath, err)
-	}
-
-	importedConnections := importService.ConvertSSHConfigToConnections(config)
-
-	// Decrypt passwords if they are encrypted
-	for i := range importedConnections {
-		if importedConnections[i].HasPassword && importedConnections[i].Password != "" {
-			// Try to decrypt - if it fails, assume it's already plaintext
-			if decryptedPassword, err := cs.encryptionService.DecryptPassword(importedConnections[i].Password); err == nil {
-				importedConnections[i].Password = decryptedPassword
-			}
-		}
-	}
-
-	// Add imported connections to existing ones
-	for _, conn := range importedConnections {
-		// Generate new ID to avoid conflicts
-		conn.ID = generateID()
-		conn.CreatedAt = time.Now()
-		conn.UpdatedAt = time.Now()
-		cs.connections = append(cs.connections, conn)
-	}
-
-	// Save all connections
-	return cs.SaveConnectionsToFile()
-}
-
 // ExportConfigPlain exports connections to SSH config file without password encryption
 func (cs *ConnectionService) ExportConfigPlain(exportPath string) error {
 	exportService := NewSSHConfigService(exportPath)
 
 	// Create a copy of connections without encryption
-	connectionsCopy := make([]models.Connection, len(cs.connections))
-	copy(connectionsCopy, cs.connections)
+	connectionsCopy := cs.exportConnections()
 
 	// Decrypt passwords before export (only if encryption service is initialized)
 	if cs.encryptionService.IsInitialized() {
@@ -210,87 +340,17 @@ func (cs *ConnectionService) ExportConfigPlain(exportPath string) error {
 	return exportService.SaveConfig(config)
 }
 
-// ImportConfigPlain imports connections from SSH config file and encrypts passwords
-func (cs *ConnectionService) ImportConfigPlain(importPath string) error {
-	importService := NewSSHConfigService(importPath)
-	config, err := importService.LoadConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load config from %s: %w", importPath, err)
-	}
-
-	importedConnections := importService.ConvertSSHConfigToConnections(config)
-
-	// Encrypt passwords if encryption service is initialized
-	if cs.encryptionService.IsInitialized() {
-		for i := range importedConnections {
-			if importedConnections[i].HasPassword && importedConnections[i].Password != "" {
-				// Check if password is already encrypted
-				if len(importedConnections[i].Password) < 20 || !isBase64Like(importedConnections[i].Password) {
-					// Password is plaintext, encrypt it
-					encryptedPassword, err := cs.encryptionService.EncryptPassword(importedConnections[i].Password)
-					if err != nil {
-						return fmt.Errorf("failed to encrypt password for connection %s: %w", importedConnections[i].Name, err)
-					}
-					importedConnections[i].Password = encryptedPassword
-				}
-			}
-		}
-	}
-
-	// Check for duplicate connections and add only new ones
-	var addedCount int
-	var skippedCount int
-
-	for _, conn := range importedConnections {
-		// Check if connection already exists
-		isDuplicate := false
-
-		// If connection has ID, check by ID
-		if conn.ID != "" {
-			for _, existingConn := range cs.connections {
-				if existingConn.ID == conn.ID {
-					isDuplicate = true
-					skippedCount++
-					break
-				}
-			}
-		} else {
-			// If no ID, check by combination of Host+Port+User+Name
-			for _, existingConn := range cs.connections {
-				if existingConn.Host == conn.Host &&
-					existingConn.Port == conn.Port &&
-					existingConn.User == conn.User &&
-					existingConn.Name == conn.Name {
-					isDuplicate = true
-					skippedCount++
-					break
-				}
-			}
-		}
-
-		if !isDuplicate {
-			// Generate new ID only if it's empty
-			if conn.ID == "" {
-				conn.ID = generateID()
-			}
-			conn.CreatedAt = time.Now()
-			conn.UpdatedAt = time.Now()
-			cs.connections = append(cs.connections, conn)
-			addedCount++
+// exportConnections возвращает подключения для экспорта: шаблоны пропускаются,
+// наследники записываются с действующими значениями без ссылки на шаблон
+func (cs *ConnectionService) exportConnections() []models.Connection {
+	connections := make([]models.Connection, 0, len(cs.connections))
+	for _, conn := range cs.connections {
+		if conn.Template {
+			continue
 		}
+		connections = append(connections, conn.Flatten())
 	}
-
-	// Save all connections only if we added new ones
-	if addedCount > 0 {
-		return cs.SaveConnectionsToFile()
-	}
-
-	// If no new connections were added, return a specific error
-	if skippedCount > 0 {
-		return fmt.Errorf("all %d connections already exist (duplicates skipped)", skippedCount)
-	}
-
-	return nil
+	return connections
 }
 
 // GetConfigPath returns the current config file path
@@ -410,9 +470,10 @@ func (cs *ConnectionService) getSampleConnections() []models.Connection {
 	}
 }
 
-// generateID генерирует простой ID (в реальном приложении используйте uuid)
+// generateID генерирует уникальный ID подключения. ID сохраняется в файле
+// конфигурации, по нему история сессий связывается с подключением
 func generateID() string {
-	return time.Now().Format("20060102150405")
+	return uuid.New().String()
 }
 
 // isBase64Like проверяет, похож ли пароль на base64 (зашифрованный)
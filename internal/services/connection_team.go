@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// teamVaultPath возвращает путь к командному хранилищу рядом с файлом конфигурации
+func (cs *ConnectionService) teamVaultPath() string {
+	return filepath.Join(filepath.Dir(cs.configPath), TeamVaultFileName)
+}
+
+// teamIdentityPath возвращает путь к личному ключу участника
+func (cs *ConnectionService) teamIdentityPath() string {
+	return filepath.Join(filepath.Dir(cs.configPath), TeamIdentityFileName)
+}
+
+// OpenTeamVault загружает личный ключ участника и открывает командное хранилище,
+// если оно есть. personal - шифрование мастер-паролем, которым защищен личный ключ.
+// После открытия подключения перечитываются с ключом данных хранилища
+func (cs *ConnectionService) OpenTeamVault(personal *EncryptionService) error {
+	if _, err := os.Stat(cs.teamIdentityPath()); err == nil {
+		identity, err := LoadTeamIdentity(cs.teamIdentityPath(), personal)
+		if err != nil {
+			return err
+		}
+		cs.teamIdentity = identity
+	}
+
+	vault, err := LoadTeamVault(cs.teamVaultPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if cs.teamIdentity == nil {
+		return fmt.Errorf("подключения хранятся в командном хранилище, а личного ключа на этой машине нет")
+	}
+	if err := vault.Unlock(cs.teamIdentity); err != nil {
+		return err
+	}
+
+	cs.teamVault = vault
+	if previous := vault.PreviousKey(); previous != nil {
+		cs.encryptionService.SetDataKey(previous)
+	}
+	cs.encryptionService.SetDataKey(vault.DataKey())
+	if err := cs.LoadConnectionsFromFile(); err != nil {
+		return err
+	}
+
+	// Прошлая смена ключа прервалась до перешифровки подключений: завершаем ее
+	if len(vault.Previous) == 0 {
+		return nil
+	}
+	if err := cs.saveLocal(); err != nil {
+		return err
+	}
+	return cs.completeTeamRekey()
+}
+
+// TeamVault возвращает открытое командное хранилище или nil
+func (cs *ConnectionService) TeamVault() *TeamVault {
+	return cs.teamVault
+}
+
+// TeamIdentity возвращает личный ключ участника или nil
+func (cs *ConnectionService) TeamIdentity() *TeamIdentity {
+	return cs.teamIdentity
+}
+
+// CreateTeamVault переводит подключения в командное хранилище с одним участником -
+// владельцем личного ключа. Пароли перешифровываются новым ключом данных
+func (cs *ConnectionService) CreateTeamVault(name string) error {
+	if cs.teamIdentity == nil {
+		return fmt.Errorf("сначала создайте личный ключ")
+	}
+	cs.syncBeforeEdit()
+	if cs.teamVault != nil {
+		return fmt.Errorf("командное хранилище уже создано")
+	}
+
+	vault, err := NewTeamVault()
+	if err != nil {
+		return err
+	}
+	if err := vault.AddMember(name, cs.teamIdentity.PublicKey()); err != nil {
+		return err
+	}
+	return cs.saveTeamVault(vault, true)
+}
+
+// AddTeamMember добавляет участника: ключ данных оборачивается для его открытого ключа
+func (cs *ConnectionService) AddTeamMember(name, publicKey string) error {
+	if cs.teamVault == nil {
+		return fmt.Errorf("командное хранилище не создано")
+	}
+	cs.syncBeforeEdit()
+
+	vault := cs.teamVault.clone()
+	if err := vault.AddMember(name, publicKey); err != nil {
+		return err
+	}
+	return cs.saveTeamVault(vault, false)
+}
+
+// RemoveTeamMember удаляет участника, меняет ключ данных и перешифровывает им пароли
+func (cs *ConnectionService) RemoveTeamMember(nameOrKey string) (TeamMember, error) {
+	if cs.teamVault == nil {
+		return TeamMember{}, fmt.Errorf("командное хранилище не создано")
+	}
+	cs.syncBeforeEdit()
+
+	vault := cs.teamVault.clone()
+	if index := vault.FindMember(nameOrKey); index >= 0 && vault.Members[index].PublicKey == cs.teamIdentity.PublicKey() {
+		return TeamMember{}, fmt.Errorf("нельзя удалить собственный ключ: его удаляет другой участник")
+	}
+	removed, err := vault.RemoveMember(nameOrKey)
+	if err != nil {
+		return TeamMember{}, err
+	}
+	return removed, cs.saveTeamVault(vault, true)
+}
+
+// saveTeamVault записывает командное хранилище; rekey - ключ данных изменился и пароли
+// нужно перешифровать. Хранилище записывается вместе с прежним ключом данных, поэтому
+// файл подключений читается и при сбое до перешифровки; прежний ключ убирается
+// из файла после того, как подключения записаны новым ключом
+func (cs *ConnectionService) saveTeamVault(vault *TeamVault, rekey bool) error {
+	if err := vault.Save(cs.teamVaultPath()); err != nil {
+		return err
+	}
+	cs.teamVault = vault
+
+	if rekey {
+		cs.encryptionService.SetDataKey(vault.DataKey())
+		if err := cs.saveLocal(); err != nil {
+			return err
+		}
+		if err := cs.completeTeamRekey(); err != nil {
+			return err
+		}
+	}
+
+	if cs.gitSync != nil {
+		if _, err := cs.Sync(); err != nil {
+			return fmt.Errorf("хранилище сохранено локально, но синхронизация не удалась: %w", err)
+		}
+	}
+	return nil
+}
+
+// completeTeamRekey вызывается после записи подключений новым ключом данных:
+// перешифровывает журнал отмены и убирает прежний ключ из файла хранилища
+func (cs *ConnectionService) completeTeamRekey() error {
+	_ = cs.saveJournal()
+	if len(cs.teamVault.Previous) == 0 {
+		return nil
+	}
+	cs.teamVault.completeRekey()
+	return cs.teamVault.Save(cs.teamVaultPath())
+}
+
+// syncTeamVault сравнивает командное хранилище с версией из репозитория синхронизации.
+// Более новая версия из репозитория открывается личным ключом и заменяет локальную;
+// rekeyed - ключ данных изменился, ahead - локальную версию нужно отправить
+func (cs *ConnectionService) syncTeamVault(hasRemote bool) (rekeyed, ahead bool, err error) {
+	var remote *TeamVault
+	if hasRemote {
+		data, ok, err := cs.gitSync.show(cs.gitSync.remoteRef(), TeamVaultFileName)
+		if err != nil {
+			return false, false, err
+		}
+		if ok {
+			if remote, err = parseTeamVault([]byte(data)); err != nil {
+				return false, false, err
+			}
+		}
+	}
+
+	local := cs.teamVault
+	switch {
+	case remote == nil:
+		return false, local != nil, nil
+	case local != nil && local.Generation > remote.Generation:
+		return false, true, nil
+	case local != nil && local.Generation == remote.Generation && local.sameMembers(remote):
+		return false, false, nil
+	}
+
+	// Версия из репозитория новее или состав изменили одновременно: остается версия репозитория
+	if cs.teamIdentity == nil {
+		return false, false, fmt.Errorf("подключения в репозитории хранятся в командном хранилище: создайте личный ключ (ssh-keeper team key) и передайте его участнику")
+	}
+	if err := remote.Unlock(cs.teamIdentity); err != nil {
+		return false, false, err
+	}
+	rekeyed = local == nil || !slices.Equal(local.DataKey(), remote.DataKey())
+	if rekeyed && local != nil {
+		// Подключения еще зашифрованы прежним ключом: он хранится, пока их не перешифруют
+		remote.Previous = local.Members
+		remote.previousKey = local.DataKey()
+	}
+	if err := remote.Save(cs.teamVaultPath()); err != nil {
+		return false, false, err
+	}
+
+	cs.teamVault = remote
+	cs.encryptionService.SetDataKey(remote.DataKey())
+	return rekeyed, false, nil
+}
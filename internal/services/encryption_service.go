@@ -1,6 +1,7 @@
 package services
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
@@ -13,6 +14,8 @@ import (
 type EncryptionService struct {
 	masterPasswordService *MasterPasswordService
 	derivedKey            []byte
+	dataKey               []byte   // Ключ командного хранилища вместо ключа мастер-пароля
+	previousKeys          [][]byte // Прежние ключи: данные, зашифрованные до смены ключа, остаются читаемыми
 }
 
 // NewEncryptionService creates a new encryption service
@@ -37,20 +40,14 @@ func (es *EncryptionService) Encrypt(plaintext string) (string, error) {
 	}
 
 	// Проверяем, что ключ инициализирован
-	if es.derivedKey == nil {
+	key := es.key()
+	if key == nil {
 		return "", fmt.Errorf("ключ шифрования не инициализирован")
 	}
 
-	// Create AES cipher
-	block, err := aes.NewCipher(es.derivedKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
+	gcm, err := newGCM(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return "", err
 	}
 
 	// Generate random nonce
@@ -73,7 +70,8 @@ func (es *EncryptionService) Decrypt(ciphertext string) (string, error) {
 	}
 
 	// Проверяем, что ключ инициализирован
-	if es.derivedKey == nil {
+	key := es.key()
+	if key == nil {
 		return "", fmt.Errorf("ключ шифрования не инициализирован")
 	}
 
@@ -83,33 +81,69 @@ func (es *EncryptionService) Decrypt(ciphertext string) (string, error) {
 		return "", fmt.Errorf("failed to decode base64: %w", err)
 	}
 
+	// После смены ключа командного хранилища пробуем и прежние ключи
+	var decryptErr error
+	for _, candidate := range append([][]byte{key}, es.previousKeys...) {
+		gcm, err := newGCM(candidate)
+		if err != nil {
+			return "", err
+		}
+
+		// Extract nonce
+		nonceSize := gcm.NonceSize()
+		if len(data) < nonceSize {
+			return "", fmt.Errorf("ciphertext too short")
+		}
+
+		nonce, ciphertextBytes := data[:nonceSize], data[nonceSize:]
+
+		// Decrypt the data
+		plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, nil)
+		if err == nil {
+			return string(plaintext), nil
+		}
+		decryptErr = err
+	}
+
+	return "", fmt.Errorf("failed to decrypt: %w", decryptErr)
+}
+
+// newGCM создает AES-GCM с ключом
+func newGCM(key []byte) (cipher.AEAD, error) {
 	// Create AES cipher
-	block, err := aes.NewCipher(es.derivedKey)
+	block, err := aes.NewCipher(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
 	}
 
 	// Create GCM mode
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
 	}
+	return gcm, nil
+}
 
-	// Extract nonce
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", fmt.Errorf("ciphertext too short")
+// key возвращает действующий ключ: ключ командного хранилища или ключ мастер-пароля
+func (es *EncryptionService) key() []byte {
+	if es.dataKey != nil {
+		return es.dataKey
 	}
+	return es.derivedKey
+}
 
-	nonce, ciphertextBytes := data[:nonceSize], data[nonceSize:]
-
-	// Decrypt the data
-	plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to decrypt: %w", err)
+// SetDataKey шифрует данные ключом командного хранилища вместо ключа мастер-пароля.
+// Прежний ключ остается доступным для расшифровки данных, записанных до смены
+func (es *EncryptionService) SetDataKey(key []byte) {
+	if previous := es.key(); previous != nil && !bytes.Equal(previous, key) {
+		es.previousKeys = append(es.previousKeys, previous)
 	}
+	es.dataKey = key
+}
 
-	return string(plaintext), nil
+// HasDataKey проверяет, используется ли ключ командного хранилища
+func (es *EncryptionService) HasDataKey() bool {
+	return es.dataKey != nil
 }
 
 // EncryptPassword encrypts a password for storage
@@ -140,5 +174,8 @@ func (es *EncryptionService) refreshKey() error {
 
 // IsInitialized проверяет, инициализирован ли сервис шифрования
 func (es *EncryptionService) IsInitialized() bool {
+	if es.dataKey != nil {
+		return true
+	}
 	return es.derivedKey != nil && es.masterPasswordService.IsInitialized()
 }
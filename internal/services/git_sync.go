@@ -0,0 +1,457 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+// SyncDirName каталог рабочей копии git-репозитория синхронизации в каталоге конфигурации
+const SyncDirName = "sync"
+
+// SyncFileName имя файла подключений в репозитории синхронизации
+const SyncFileName = "connections.conf"
+
+// SyncPushAttempts сколько раз повторяется синхронизация, если другая машина
+// успела отправить изменения между получением и отправкой
+const SyncPushAttempts = 3
+
+// GitSync синхронизирует файл подключений через git-репозиторий: локальный
+// каталог, bare-репозиторий или удаленный сервер. В репозиторий попадает файл
+// конфигурации как есть, поэтому пароли остаются зашифрованными мастер-паролем.
+// HEAD рабочей копии - последнее состояние, которое было синхронизировано с удаленной веткой
+type GitSync struct {
+	dir    string
+	remote string
+	branch string
+}
+
+// SyncResult итог синхронизации
+type SyncResult struct {
+	Pulled    bool                  // Локальные подключения обновлены из репозитория
+	Pushed    bool                  // Локальные изменения отправлены в репозиторий
+	Conflicts []models.SyncConflict // Подключения, измененные на обеих сторонах
+}
+
+// NewGitSync создает синхронизацию с репозиторием remote; dir - каталог рабочей копии
+func NewGitSync(dir, remote, branch string) *GitSync {
+	if branch == "" {
+		branch = "main"
+	}
+	// Относительный путь к локальному репозиторию git считал бы от рабочей копии
+	if !strings.Contains(remote, ":") && !filepath.IsAbs(remote) {
+		if abs, err := filepath.Abs(remote); err == nil {
+			remote = abs
+		}
+	}
+	return &GitSync{dir: dir, remote: remote, branch: branch}
+}
+
+// Remote возвращает адрес репозитория синхронизации
+func (gs *GitSync) Remote() string {
+	return gs.remote
+}
+
+// Branch возвращает ветку синхронизации
+func (gs *GitSync) Branch() string {
+	return gs.branch
+}
+
+// git выполняет команду git в рабочей копии и возвращает ее вывод
+func (gs *GitSync) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", gs.dir}, args...)...)
+	// Синхронизация работает в фоне интерфейса: git не должен запрашивать учетные данные
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// Первой строки достаточно для сообщения в интерфейсе
+		if message, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// hasRevision проверяет, существует ли ревизия
+func (gs *GitSync) hasRevision(rev string) bool {
+	_, err := gs.git("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	return err == nil
+}
+
+// remoteRef возвращает ссылку на удаленную ветку синхронизации
+func (gs *GitSync) remoteRef() string {
+	return "refs/remotes/origin/" + gs.branch
+}
+
+// prepare создает рабочую копию при первом запуске и получает изменения удаленной ветки
+func (gs *GitSync) prepare() error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("для синхронизации нужен git: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(gs.dir, ".git")); os.IsNotExist(err) {
+		if err := os.MkdirAll(gs.dir, 0700); err != nil {
+			return fmt.Errorf("failed to create sync directory: %w", err)
+		}
+		if _, err := gs.git("init", "--quiet"); err != nil {
+			return err
+		}
+		if _, err := gs.git("symbolic-ref", "HEAD", "refs/heads/"+gs.branch); err != nil {
+			return err
+		}
+		if _, err := gs.git("remote", "add", "origin", gs.remote); err != nil {
+			return err
+		}
+	} else if _, err := gs.git("remote", "set-url", "origin", gs.remote); err != nil {
+		return err
+	}
+
+	// Пустой репозиторий еще не содержит ветку: это не ошибка
+	heads, err := gs.git("ls-remote", "--heads", "origin", "refs/heads/"+gs.branch)
+	if err != nil {
+		return fmt.Errorf("репозиторий синхронизации недоступен: %w", err)
+	}
+	if heads == "" {
+		gs.git("update-ref", "-d", gs.remoteRef())
+		return nil
+	}
+	_, err = gs.git("fetch", "--quiet", "origin", "+refs/heads/"+gs.branch+":"+gs.remoteRef())
+	return err
+}
+
+// show возвращает содержимое файла в ревизии; false - в ревизии нет файла
+func (gs *GitSync) show(rev, name string) (string, bool, error) {
+	if !gs.hasRevision(rev) {
+		return "", false, nil
+	}
+	if _, err := gs.git("cat-file", "-e", rev+":"+name); err != nil {
+		return "", false, nil
+	}
+	data, err := gs.git("show", rev+":"+name)
+	if err != nil {
+		return "", false, err
+	}
+	return data + "\n", true, nil
+}
+
+// readRevision сохраняет файл подключений из ревизии во временный файл.
+// Пустой путь означает, что в ревизии нет файла
+func (gs *GitSync) readRevision(rev string) (string, error) {
+	data, ok, err := gs.show(rev, SyncFileName)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	file, err := os.CreateTemp("", "ssh-keeper-sync-*.conf")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(data); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// commit записывает файлы в рабочую копию и создает коммит.
+// files - пары имя в репозитории и локальный путь
+func (gs *GitSync) commit(files [][2]string, message string) error {
+	for _, file := range files {
+		data, err := os.ReadFile(file[1])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file[1], err)
+		}
+		if err := os.WriteFile(filepath.Join(gs.dir, file[0]), data, 0600); err != nil {
+			return fmt.Errorf("failed to write sync file: %w", err)
+		}
+		if _, err := gs.git("add", file[0]); err != nil {
+			return err
+		}
+	}
+
+	commit := []string{"commit", "--quiet", "-m", message}
+	// Коммит не должен зависеть от того, настроен ли git на этой машине
+	if email, _ := gs.git("config", "user.email"); email == "" {
+		hostname, _ := os.Hostname()
+		commit = append([]string{"-c", "user.name=SSH Keeper", "-c", "user.email=ssh-keeper@" + hostname}, commit...)
+	}
+	_, err := gs.git(commit...)
+	return err
+}
+
+// push отправляет коммит в удаленную ветку. При ошибке рабочая копия
+// возвращается к состоянию restore
+func (gs *GitSync) push(restore string) error {
+	if _, err := gs.git("push", "--quiet", "origin", "HEAD:refs/heads/"+gs.branch); err != nil {
+		gs.restore(restore)
+		return err
+	}
+	_, err := gs.git("update-ref", gs.remoteRef(), "HEAD")
+	return err
+}
+
+// Fetch получает изменения удаленной ветки. Обращается к сети, но затрагивает
+// только рабочую копию синхронизации, поэтому может выполняться в фоне
+func (gs *GitSync) Fetch() error {
+	return gs.prepare()
+}
+
+// SyncPush подготовленный коммит синхронизации, который осталось отправить
+type SyncPush struct {
+	gs      *GitSync
+	restore string
+}
+
+// Run отправляет коммит. Как и Fetch, может выполняться в фоне
+func (p *SyncPush) Run() error {
+	return p.gs.push(p.restore)
+}
+
+// restore возвращает рабочую копию к ревизии; пустая ревизия - к ветке без коммитов
+func (gs *GitSync) restore(rev string) {
+	if rev != "" {
+		gs.git("reset", "--quiet", "--hard", rev)
+		return
+	}
+	gs.git("update-ref", "-d", "HEAD")
+	gs.git("rm", "--quiet", "--cached", "--ignore-unmatch", SyncFileName, TeamVaultFileName)
+}
+
+// SetGitSync включает синхронизацию подключений через git-репозиторий
+func (cs *ConnectionService) SetGitSync(gitSync *GitSync) {
+	cs.gitSync = gitSync
+}
+
+// GitSync возвращает настроенную синхронизацию или nil
+func (cs *ConnectionService) GitSync() *GitSync {
+	return cs.gitSync
+}
+
+// SyncError возвращает ошибку последней автоматической синхронизации
+func (cs *ConnectionService) SyncError() error {
+	return cs.syncErr
+}
+
+// SetSyncError запоминает результат синхронизации, выполненной в фоне
+func (cs *ConnectionService) SetSyncError(err error) {
+	cs.syncErr = err
+}
+
+// TakeSyncPending проверяет, есть ли сохраненные изменения, которые еще не
+// синхронизированы, и снимает отметку: интерфейс запускает по ней одну синхронизацию
+func (cs *ConnectionService) TakeSyncPending() bool {
+	pending := cs.gitSync != nil && cs.syncPending
+	cs.syncPending = false
+	return pending
+}
+
+// syncBeforeEdit получает изменения из репозитория перед правкой командного хранилища.
+// Ошибка синхронизации не мешает правке: изменения будут отправлены при следующей синхронизации
+func (cs *ConnectionService) syncBeforeEdit() {
+	if cs.gitSync != nil {
+		_, cs.syncErr = cs.Sync()
+	}
+}
+
+// Sync выполняет трехстороннее слияние локальных подключений с репозиторием и
+// отправляет результат. Подключения сравниваются по ID, а не построчно, поэтому
+// правки разных подключений на разных машинах объединяются без конфликтов.
+// Sync блокирует выполнение до ответа репозитория; интерфейс выполняет те же шаги
+// по отдельности: Fetch и SyncPush.Run в фоне, SyncMerge - в основном потоке
+func (cs *ConnectionService) Sync() (*SyncResult, error) {
+	if cs.gitSync == nil {
+		return nil, fmt.Errorf("синхронизация не настроена")
+	}
+
+	var err error
+	for attempt := 0; attempt < SyncPushAttempts; attempt++ {
+		if err := cs.gitSync.Fetch(); err != nil {
+			cs.syncErr = err
+			return nil, err
+		}
+
+		result, push, mergeErr := cs.SyncMerge()
+		if mergeErr != nil || push == nil {
+			cs.syncErr = mergeErr
+			return result, mergeErr
+		}
+
+		// Другая машина успела отправить изменения: повторяем с ними
+		if err = push.Run(); err == nil {
+			result.Pushed = true
+			cs.syncErr = nil
+			return result, nil
+		}
+	}
+	cs.syncErr = fmt.Errorf("не удалось отправить изменения: %w", err)
+	return nil, cs.syncErr
+}
+
+// SyncMerge объединяет подключения с полученным Fetch состоянием репозитория,
+// сохраняет результат локально и готовит коммит. Сеть не используется, но меняются
+// подключения сервиса, поэтому вызывается там же, где их правят. push равен nil,
+// если отправлять нечего
+func (cs *ConnectionService) SyncMerge() (result *SyncResult, push *SyncPush, err error) {
+	if cs.gitSync == nil {
+		return nil, nil, fmt.Errorf("синхронизация не настроена")
+	}
+	gs := cs.gitSync
+	// Изменения, сохраненные после этого момента, потребуют новой синхронизации
+	cs.syncPending = false
+
+	// base - последнее синхронизированное состояние, remote - текущее состояние репозитория
+	hasRemote := gs.hasRevision(gs.remoteRef())
+
+	// Состав командного хранилища получаем до чтения подключений: после смены ключа
+	// новые данные читаются только новым ключом
+	rekeyed, vaultAhead, err := cs.syncTeamVault(hasRemote)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Прерванная смена ключа завершается до отправки: прежний ключ не попадает в репозиторий
+	if cs.teamVault != nil && len(cs.teamVault.Previous) > 0 {
+		rekeyed = true
+	}
+
+	base, err := cs.readSyncRevision("HEAD")
+	if err != nil {
+		// Основа зашифрована ключом, которого на этой машине не было: слияние без основы
+		// объединяет подключения обеих сторон, ничего не удаляя
+		base = nil
+	}
+	remote := base
+	if hasRemote {
+		if remote, err = cs.readSyncRevision(gs.remoteRef()); err != nil {
+			return nil, nil, err
+		}
+	}
+	local, err := cs.readStore(cs.configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged, conflicts := models.MergeConnections(base, local, remote)
+	result = &SyncResult{Conflicts: conflicts}
+
+	// После смены ключа локальный файл перешифровывается, даже если подключения не изменились
+	if rekeyed || !models.SameConnections(merged, local) {
+		if err := cs.checkSyncEncryption(merged); err != nil {
+			return nil, nil, err
+		}
+		previous := cs.connections
+		cs.connections = merged
+		models.ResolveInheritance(cs.connections)
+		if err := cs.saveLocal(); err != nil {
+			cs.connections = previous
+			return nil, nil, err
+		}
+		if rekeyed {
+			if err := cs.completeTeamRekey(); err != nil {
+				return nil, nil, err
+			}
+		}
+		result.Pulled = !models.SameConnections(merged, local)
+	}
+
+	restore := ""
+	if hasRemote {
+		restore = gs.remoteRef()
+		if _, err := gs.git("reset", "--quiet", "--hard", restore); err != nil {
+			return nil, nil, err
+		}
+		if models.SameConnections(merged, remote) && !vaultAhead {
+			return result, nil, nil
+		}
+	} else if gs.hasRevision("HEAD") {
+		restore = "HEAD"
+	}
+	if _, err := os.Stat(cs.configPath); os.IsNotExist(err) {
+		return result, nil, nil
+	}
+
+	if err := cs.checkSyncEncryption(merged); err != nil {
+		return nil, nil, err
+	}
+	if restore == "HEAD" {
+		// Ветка была удалена в репозитории: ревизия для отката - текущий коммит
+		if restore, err = gs.git("rev-parse", "HEAD"); err != nil {
+			return nil, nil, err
+		}
+	}
+	hostname, _ := os.Hostname()
+	message := fmt.Sprintf("Sync %d connections from %s at %s", len(merged), hostname, time.Now().Format(time.RFC3339))
+	files := [][2]string{{SyncFileName, cs.configPath}}
+	if cs.teamVault != nil {
+		files = append(files, [2]string{TeamVaultFileName, cs.teamVaultPath()})
+	}
+	if err := gs.commit(files, message); err != nil {
+		gs.restore(restore)
+		return nil, nil, err
+	}
+	return result, &SyncPush{gs: gs, restore: restore}, nil
+}
+
+// checkSyncEncryption не допускает отправку открытых паролей в репозиторий
+func (cs *ConnectionService) checkSyncEncryption(connections []models.Connection) error {
+	if cs.encryptionService.IsInitialized() {
+		return nil
+	}
+	for _, conn := range connections {
+		if conn.Password != "" {
+			return fmt.Errorf("для синхронизации паролей нужен мастер-пароль: без него пароли хранятся открытыми")
+		}
+	}
+	return nil
+}
+
+// readSyncRevision читает подключения из ревизии репозитория синхронизации
+func (cs *ConnectionService) readSyncRevision(rev string) ([]models.Connection, error) {
+	path, err := cs.gitSync.readRevision(rev)
+	if err != nil || path == "" {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	connections, err := cs.readStore(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", rev, err)
+	}
+	return connections, nil
+}
+
+// readStore читает подключения из файла конфигурации в том виде, в котором они
+// сохранены: пароли расшифрованы, наследуемые значения не подставлены
+func (cs *ConnectionService) readStore(path string) ([]models.Connection, error) {
+	storeService := NewSSHConfigService(path)
+	config, err := storeService.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	connections := storeService.ConvertSSHConfigToConnections(config)
+	if cs.encryptionService.IsInitialized() {
+		for i := range connections {
+			password := connections[i].Password
+			if password == "" || len(password) < 20 || !isBase64Like(password) {
+				continue
+			}
+			decrypted, err := cs.encryptionService.DecryptPassword(password)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt password for connection %s (другой мастер-пароль?): %w", connections[i].ID, err)
+			}
+			connections[i].Password = decrypted
+		}
+	}
+	return connections, nil
+}
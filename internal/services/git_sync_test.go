@@ -0,0 +1,198 @@
+package services
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+// newSyncClone создает сервис подключений со своей рабочей копией синхронизации
+func newSyncClone(t *testing.T, remote, name string) *ConnectionService {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	cs := NewConnectionService(filepath.Join(dir, "connections.conf"))
+	cs.SetGitSync(NewGitSync(filepath.Join(dir, "sync"), remote, "main"))
+	return cs
+}
+
+// setupSync создает пустой bare-репозиторий и две машины, у которых синхронизированы
+// подключения web и db
+func setupSync(t *testing.T) (a, b *ConnectionService) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v: %s", err, out)
+	}
+
+	a = newSyncClone(t, remote, "a")
+	b = newSyncClone(t, remote, "b")
+	for _, name := range []string{"web", "db"} {
+		if err := a.AddConnection(models.NewConnection(name, name+".example.com", "deploy")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustSync(t, a)
+	mustSync(t, b)
+	if got := len(b.GetAllConnections()); got != 2 {
+		t.Fatalf("second machine got %d connections, want 2", got)
+	}
+	return a, b
+}
+
+// mustSync синхронизирует подключения и возвращает конфликты
+func mustSync(t *testing.T, cs *ConnectionService) []models.SyncConflict {
+	t.Helper()
+	result, err := cs.Sync()
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	return result.Conflicts
+}
+
+// editConnection правит подключение так же, как форма редактирования.
+// updatedAt задает время правки, от которого зависит исход конфликта
+func editConnection(t *testing.T, cs *ConnectionService, name string, updatedAt time.Time, edit func(*models.Connection)) {
+	t.Helper()
+	opened := *findConnection(t, cs, name)
+	conn := opened
+	edit(&conn)
+	if err := cs.UpdateConnection(&opened, &conn); err != nil {
+		t.Fatal(err)
+	}
+	findConnection(t, cs, name).UpdatedAt = updatedAt
+	if err := cs.SaveConnectionsToFile(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// findConnection возвращает подключение сервиса по имени
+func findConnection(t *testing.T, cs *ConnectionService, name string) *models.Connection {
+	t.Helper()
+	for i := range cs.connections {
+		if cs.connections[i].Name == name {
+			return &cs.connections[i]
+		}
+	}
+	t.Fatalf("connection %s not found", name)
+	return nil
+}
+
+func TestSyncDifferentConnections(t *testing.T) {
+	a, b := setupSync(t)
+	now := time.Now()
+
+	editConnection(t, a, "web", now, func(c *models.Connection) { c.Host = "web-a.example.com" })
+	editConnection(t, b, "db", now, func(c *models.Connection) { c.User = "admin" })
+	mustSync(t, a)
+	if conflicts := mustSync(t, b); len(conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none", conflicts)
+	}
+	mustSync(t, a)
+
+	for _, cs := range []*ConnectionService{a, b} {
+		if got := findConnection(t, cs, "web").Host; got != "web-a.example.com" {
+			t.Errorf("web host = %q, want web-a.example.com", got)
+		}
+		if got := findConnection(t, cs, "db").User; got != "admin" {
+			t.Errorf("db user = %q, want admin", got)
+		}
+	}
+}
+
+func TestSyncSameConnection(t *testing.T) {
+	a, b := setupSync(t)
+	now := time.Now()
+
+	editConnection(t, a, "web", now, func(c *models.Connection) { c.Host = "web-a.example.com" })
+	editConnection(t, b, "web", now.Add(time.Minute), func(c *models.Connection) { c.Host = "web-b.example.com" })
+	mustSync(t, a)
+	conflicts := mustSync(t, b)
+	if len(conflicts) != 1 || conflicts[0].Name != "web" || !conflicts[0].LocalWins || conflicts[0].Deleted {
+		t.Errorf("conflicts = %+v, want web kept locally", conflicts)
+	}
+	mustSync(t, a)
+
+	// Остается более поздняя правка
+	for _, cs := range []*ConnectionService{a, b} {
+		if got := findConnection(t, cs, "web").Host; got != "web-b.example.com" {
+			t.Errorf("web host = %q, want web-b.example.com", got)
+		}
+	}
+}
+
+func TestSyncSameConnectionDifferentFields(t *testing.T) {
+	a, b := setupSync(t)
+	now := time.Now()
+
+	editConnection(t, a, "web", now.Add(time.Minute), func(c *models.Connection) { c.User = "admin" })
+	editConnection(t, b, "web", now, func(c *models.Connection) { c.Port = 2222 })
+	mustSync(t, a)
+	if conflicts := mustSync(t, b); len(conflicts) != 0 {
+		t.Errorf("conflicts = %+v, want none for different fields", conflicts)
+	}
+	mustSync(t, a)
+
+	// Правки разных полей одного подключения не теряются
+	for _, cs := range []*ConnectionService{a, b} {
+		if web := findConnection(t, cs, "web"); web.User != "admin" || web.Port != 2222 {
+			t.Errorf("web = %s:%d, want admin:2222", web.User, web.Port)
+		}
+	}
+}
+
+func TestSyncDeleteAndUpdate(t *testing.T) {
+	a, b := setupSync(t)
+
+	if err := a.DeleteConnection(findConnection(t, a, "db").ID); err != nil {
+		t.Fatal(err)
+	}
+	editConnection(t, b, "db", time.Now(), func(c *models.Connection) { c.Port = 2222 })
+	mustSync(t, a)
+	conflicts := mustSync(t, b)
+	if len(conflicts) != 1 || conflicts[0].Name != "db" || !conflicts[0].Deleted {
+		t.Errorf("conflicts = %+v, want db deleted and changed", conflicts)
+	}
+	mustSync(t, a)
+
+	// Изменение побеждает удаление
+	for _, cs := range []*ConnectionService{a, b} {
+		if got := findConnection(t, cs, "db").Port; got != 2222 {
+			t.Errorf("db port = %d, want 2222", got)
+		}
+	}
+}
+
+func TestUpdateConnectionKeepsChangesSyncedDuringEdit(t *testing.T) {
+	a, b := setupSync(t)
+
+	// Форма на второй машине открыта до того, как пришла правка с первой
+	opened := *findConnection(t, b, "web")
+	editConnection(t, a, "web", time.Now(), func(c *models.Connection) { c.User = "admin" })
+	mustSync(t, a)
+	mustSync(t, b)
+
+	edited := opened
+	edited.Port = 2222
+	if err := b.UpdateConnection(&opened, &edited); err != nil {
+		t.Fatal(err)
+	}
+	web := findConnection(t, b, "web")
+	if web.User != "admin" || web.Port != 2222 {
+		t.Errorf("web = %s@:%d, want admin@:2222", web.User, web.Port)
+	}
+
+	// Правка того же поля не затирает изменение другой машины
+	edited = opened
+	edited.User = "root"
+	if err := b.UpdateConnection(&opened, &edited); err == nil {
+		t.Error("UpdateConnection overwrote a field changed on another machine")
+	}
+}
@@ -12,6 +12,11 @@ var (
 	globalEncryptionService     *EncryptionService
 	globalSecurityConfigService *SecurityConfigService
 	globalAutoUpdateService     *AutoUpdateService
+	globalSSHKeyService         *SSHKeyService
+	globalHistoryService        *HistoryService
+	globalRecordingService      *RecordingService
+	globalSSHConfigMirror       *SSHConfigMirror
+	globalExportFormat          = ExportFormatOpenSSH
 )
 
 // SetGlobalConnectionService sets the global connection service
@@ -40,12 +45,13 @@ func AddConnection(conn *models.Connection) error {
 	return globalConnectionService.AddConnection(conn)
 }
 
-// UpdateConnection updates a connection using the global service
-func UpdateConnection(conn *models.Connection) error {
+// UpdateConnection updates a connection using the global service;
+// opened is the copy the edit started from
+func UpdateConnection(opened, conn *models.Connection) error {
 	if globalConnectionService == nil {
 		return fmt.Errorf("connection service not initialized")
 	}
-	return globalConnectionService.UpdateConnection(conn)
+	return globalConnectionService.UpdateConnection(opened, conn)
 }
 
 // DeleteConnection deletes a connection using the global service
@@ -72,6 +78,22 @@ func ReloadConnections() error {
 	return globalConnectionService.ReloadConnections()
 }
 
+// GetSyncError returns the last git sync error of the global service
+func GetSyncError() error {
+	if globalConnectionService == nil {
+		return nil
+	}
+	return globalConnectionService.SyncError()
+}
+
+// GetSSHConfigIncludeError returns the last error writing the SSH config include file
+func GetSSHConfigIncludeError() error {
+	if globalConnectionService == nil {
+		return nil
+	}
+	return globalConnectionService.SSHConfigIncludeError()
+}
+
 // SetGlobalMasterPasswordService sets the global master password service
 func SetGlobalMasterPasswordService(service *MasterPasswordService) {
 	globalMasterPasswordService = service
@@ -183,3 +205,56 @@ func SetGlobalAutoUpdateService(service *AutoUpdateService) {
 func GetGlobalAutoUpdateService() *AutoUpdateService {
 	return globalAutoUpdateService
 }
+
+// SetGlobalSSHKeyService sets the global SSH key service
+func SetGlobalSSHKeyService(service *SSHKeyService) {
+	globalSSHKeyService = service
+}
+
+// GetGlobalSSHKeyService returns the global SSH key service
+func GetGlobalSSHKeyService() *SSHKeyService {
+	if globalSSHKeyService == nil {
+		globalSSHKeyService = NewSSHKeyService()
+	}
+	return globalSSHKeyService
+}
+
+// SetGlobalHistoryService sets the global session history service
+func SetGlobalHistoryService(service *HistoryService) {
+	globalHistoryService = service
+}
+
+// GetGlobalHistoryService returns the global session history service
+func GetGlobalHistoryService() *HistoryService {
+	return globalHistoryService
+}
+
+// SetGlobalRecordingService sets the global session recording service
+func SetGlobalRecordingService(service *RecordingService) {
+	globalRecordingService = service
+}
+
+// GetGlobalRecordingService returns the global session recording service
+func GetGlobalRecordingService() *RecordingService {
+	return globalRecordingService
+}
+
+// SetGlobalSSHConfigMirror sets the global OpenSSH config mirror
+func SetGlobalSSHConfigMirror(mirror *SSHConfigMirror) {
+	globalSSHConfigMirror = mirror
+}
+
+// GetGlobalSSHConfigMirror returns the global OpenSSH config mirror or nil when it is disabled
+func GetGlobalSSHConfigMirror() *SSHConfigMirror {
+	return globalSSHConfigMirror
+}
+
+// SetGlobalExportFormat sets the default export format from the settings
+func SetGlobalExportFormat(format ExportFormat) {
+	globalExportFormat = format
+}
+
+// GetGlobalExportFormat returns the default export format
+func GetGlobalExportFormat() ExportFormat {
+	return globalExportFormat
+}
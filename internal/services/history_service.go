@@ -0,0 +1,129 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"ssh-keeper/internal/models"
+)
+
+// HistoryFileName имя файла журнала сессий в каталоге конфигурации
+const HistoryFileName = "history.jsonl"
+
+// HistoryService ведет журнал SSH сессий: одна JSON запись на строку
+type HistoryService struct {
+	path string
+}
+
+// NewHistoryService создает сервис истории с журналом по указанному пути
+func NewHistoryService(path string) *HistoryService {
+	return &HistoryService{path: path}
+}
+
+// Record добавляет запись о сессии в конец журнала
+func (hs *HistoryService) Record(record models.SessionRecord) error {
+	if err := os.MkdirAll(filepath.Dir(hs.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode session record: %w", err)
+	}
+
+	file, err := os.OpenFile(hs.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// Load читает все записи журнала в порядке записи.
+// Поврежденные строки (например, оборванные при сбое) пропускаются
+func (hs *HistoryService) Load() ([]models.SessionRecord, error) {
+	file, err := os.Open(hs.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	var records []models.SessionRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record models.SessionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history file: %w", err)
+	}
+
+	return records, nil
+}
+
+// Stats возвращает статистику сессий по ID подключения
+func (hs *HistoryService) Stats() (map[string]*models.ConnectionStats, error) {
+	records, err := hs.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*models.ConnectionStats)
+	for _, record := range records {
+		stat, ok := stats[record.ConnectionID]
+		if !ok {
+			stat = &models.ConnectionStats{ConnectionID: record.ConnectionID}
+			stats[record.ConnectionID] = stat
+		}
+
+		stat.Sessions++
+		stat.TotalDuration += record.Duration()
+		if record.ExitCode != 0 {
+			stat.Failures++
+		}
+		if !record.StartedAt.Before(stat.LastUsed) {
+			stat.LastUsed = record.StartedAt
+			stat.LastExitCode = record.ExitCode
+			stat.Name = record.Name
+		}
+	}
+
+	return stats, nil
+}
+
+// SortedStats возвращает статистику, отсортированную по частоте (frequent = true)
+// или по времени последнего подключения
+func (hs *HistoryService) SortedStats(frequent bool) ([]*models.ConnectionStats, error) {
+	stats, err := hs.Stats()
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]*models.ConnectionStats, 0, len(stats))
+	for _, stat := range stats {
+		sorted = append(sorted, stat)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if frequent && sorted[i].Sessions != sorted[j].Sessions {
+			return sorted[i].Sessions > sorted[j].Sessions
+		}
+		return sorted[i].LastUsed.After(sorted[j].LastUsed)
+	})
+
+	return sorted, nil
+}
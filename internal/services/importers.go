@@ -0,0 +1,591 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"ssh-keeper/internal/config"
+	"ssh-keeper/internal/models"
+)
+
+// ImportSource формат файла импорта
+type ImportSource string
+
+const (
+	ImportSourceSSHConfig ImportSource = "ssh-config"
+	ImportSourceBundle    ImportSource = "bundle"
+	ImportSourcePuTTY     ImportSource = "putty"
+	ImportSourceRemmina   ImportSource = "remmina"
+	ImportSourceMobaXterm ImportSource = "mobaxterm"
+	// ImportSourceCSV таблица с заголовком: экспорт Termius, Royal TSX или SSH Keeper
+	ImportSourceCSV ImportSource = "csv"
+	// ImportSourceJSON массив объектов: экспорт Royal TSX, Termius или SSH Keeper
+	ImportSourceJSON ImportSource = "json"
+	// ImportSourceAnsible inventory Ansible в формате INI или YAML; повторный импорт синхронизирует хосты
+	ImportSourceAnsible ImportSource = "ansible"
+)
+
+// importSourceLabels названия форматов импорта для интерфейса
+var importSourceLabels = map[ImportSource]string{
+	ImportSourceSSHConfig: "SSH config",
+	ImportSourceBundle:    "зашифрованный экспорт SSH Keeper",
+	ImportSourcePuTTY:     "PuTTY (.reg)",
+	ImportSourceRemmina:   "Remmina",
+	ImportSourceMobaXterm: "MobaXterm (.mxtsessions)",
+	ImportSourceCSV:       "CSV (Termius, Royal TSX, SSH Keeper)",
+	ImportSourceJSON:      "JSON (Royal TSX, Termius, SSH Keeper)",
+	ImportSourceAnsible:   "Ansible inventory",
+}
+
+// Label возвращает название формата импорта
+func (s ImportSource) Label() string {
+	if label, ok := importSourceLabels[s]; ok {
+		return label
+	}
+	return string(s)
+}
+
+// DetectImportSource определяет формат файла или каталога импорта по расширению и содержимому
+func DetectImportSource(path string) ImportSource {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		// Remmina хранит каждый профиль в отдельном файле
+		return ImportSourceRemmina
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".reg":
+		return ImportSourcePuTTY
+	case ".remmina":
+		return ImportSourceRemmina
+	case ".mxtsessions":
+		return ImportSourceMobaXterm
+	case ".csv":
+		return ImportSourceCSV
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImportSourceSSHConfig
+	}
+	text := strings.TrimSpace(decodeText(data))
+	switch {
+	case strings.HasPrefix(text, "Windows Registry Editor"), strings.HasPrefix(text, "REGEDIT4"):
+		return ImportSourcePuTTY
+	case strings.HasPrefix(text, "[remmina]"):
+		return ImportSourceRemmina
+	case strings.HasPrefix(text, "[Bookmarks"):
+		return ImportSourceMobaXterm
+	case strings.HasPrefix(text, "{"), strings.HasPrefix(text, "[") && !isAnsibleINI(text):
+		if IsBundleFile(path) {
+			return ImportSourceBundle
+		}
+		return ImportSourceJSON
+	case isAnsibleINI(text), isAnsibleYAMLText(text):
+		return ImportSourceAnsible
+	}
+	return ImportSourceSSHConfig
+}
+
+// ParseImportFile читает подключения из файла (или каталога профилей Remmina) другого клиента.
+// SSH config и зашифрованный экспорт импортируются отдельно
+func ParseImportFile(path string, source ImportSource) ([]models.Connection, error) {
+	if source == ImportSourceRemmina {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return parseRemminaDir(path)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var connections []models.Connection
+	switch source {
+	case ImportSourcePuTTY:
+		connections = parsePuTTYReg(decodeText(data))
+	case ImportSourceRemmina:
+		connections = parseRemmina(decodeText(data), path)
+	case ImportSourceMobaXterm:
+		connections = parseMobaXterm(decodeText(data))
+	case ImportSourceCSV:
+		connections, err = parseCSVImport(decodeText(data))
+	case ImportSourceJSON:
+		connections, err = parseJSONImport(data)
+	case ImportSourceAnsible:
+		connections, err = ParseAnsibleInventory(path, data)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", source)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(connections) == 0 {
+		return nil, fmt.Errorf("в файле не найдено SSH подключений")
+	}
+	return connections, nil
+}
+
+// decodeText приводит содержимое файла к строке UTF-8: экспорт реестра Windows
+// обычно записан в UTF-16LE с BOM
+func decodeText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		data = data[2:]
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			units[i] = binary.LittleEndian.Uint16(data[2*i:])
+		}
+		return string(utf16.Decode(units))
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:])
+	}
+	return string(data)
+}
+
+// newImportedConnection создает подключение с адресом, который может содержать
+// пользователя, порт и схему (ssh://user@host:port)
+func newImportedConnection(name, address, user string, port int) models.Connection {
+	address = strings.TrimSpace(address)
+	address = strings.TrimPrefix(address, "ssh://")
+	address = strings.TrimSuffix(address, "/")
+
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		if user == "" {
+			user = address[:at]
+		}
+		address = address[at+1:]
+	}
+
+	// host:port, кроме адресов IPv6 без скобок
+	if host, portText, found := strings.Cut(address, ":"); found && !strings.Contains(portText, ":") {
+		address = host
+		if p, err := strconv.Atoi(portText); err == nil && port == 0 {
+			port = p
+		}
+	}
+	address = strings.Trim(address, "[]")
+
+	if port == 0 {
+		port = 22
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = address
+	}
+
+	return models.Connection{
+		Name:      name,
+		Host:      address,
+		Port:      port,
+		User:      strings.TrimSpace(user),
+		UseSSHKey: true,
+	}
+}
+
+// parsePuTTYReg разбирает сессии PuTTY из экспорта реестра
+// (HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions)
+func parsePuTTYReg(text string) []models.Connection {
+	const sessionsKey = `\Software\SimonTatham\PuTTY\Sessions\`
+
+	type session struct {
+		name   string
+		values map[string]string
+	}
+	var sessions []*session
+	var current *session
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			current = nil
+			key := line[1 : len(line)-1]
+			if i := strings.Index(key, sessionsKey); i >= 0 {
+				name, err := url.PathUnescape(key[i+len(sessionsKey):])
+				if err != nil {
+					name = key[i+len(sessionsKey):]
+				}
+				current = &session{name: name, values: make(map[string]string)}
+				sessions = append(sessions, current)
+			}
+		case current != nil && strings.HasPrefix(line, `"`):
+			name, value, found := strings.Cut(line, "=")
+			if !found {
+				continue
+			}
+			current.values[strings.Trim(name, `"`)] = parseRegValue(value)
+		}
+	}
+
+	var connections []models.Connection
+	for _, s := range sessions {
+		if s.name == "Default Settings" || s.values["HostName"] == "" {
+			continue
+		}
+		if protocol := s.values["Protocol"]; protocol != "" && protocol != "ssh" {
+			continue
+		}
+
+		port, _ := strconv.Atoi(s.values["PortNumber"])
+		conn := newImportedConnection(s.name, s.values["HostName"], s.values["UserName"], port)
+		conn.KeyPath = s.values["PublicKeyFile"]
+		connections = append(connections, conn)
+	}
+	return connections
+}
+
+// puttyKeyExt расширение ключей PuTTY: OpenSSH их не читает
+const puttyKeyExt = ".ppk"
+
+// replacePuTTYKey заменяет ключ PuTTY (.ppk) уже преобразованным ключом OpenSSH
+// рядом с ним (тот же путь без .ppk), а если его нет - убирает путь к ключу, и
+// тогда ssh пробует ключи по умолчанию и агент. Возвращает предупреждение для
+// предпросмотра импорта или пустую строку
+func replacePuTTYKey(conn *models.Connection) string {
+	ext := filepath.Ext(conn.KeyPath)
+	if !strings.EqualFold(ext, puttyKeyExt) {
+		return ""
+	}
+
+	ppk := conn.KeyPath
+	converted := strings.TrimSuffix(ppk, ext)
+	if info, err := os.Stat(config.ExpandPath(converted)); err == nil && info.Mode().IsRegular() {
+		conn.KeyPath = converted
+		return ""
+	}
+
+	conn.KeyPath = ""
+	return fmt.Sprintf("ключ PuTTY %s не подходит для OpenSSH и не перенесен; преобразуйте его: puttygen %s -O private-openssh -o %s",
+		ppk, ppk, converted)
+}
+
+// parseRegValue разбирает значение .reg: строку в кавычках или dword
+func parseRegValue(value string) string {
+	value = strings.TrimSpace(value)
+	if hex, ok := strings.CutPrefix(value, "dword:"); ok {
+		if n, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return strconv.FormatUint(n, 10)
+		}
+		return ""
+	}
+
+	value = strings.TrimPrefix(value, `"`)
+	value = strings.TrimSuffix(value, `"`)
+	return strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(value)
+}
+
+// iniSection секция INI файла с ключами в порядке следования
+type iniSection struct {
+	name   string
+	keys   []string
+	values map[string]string
+}
+
+// parseINI разбирает INI файл; ключи вне секций попадают в секцию с пустым именем
+func parseINI(text string) []*iniSection {
+	current := &iniSection{values: make(map[string]string)}
+	sections := []*iniSection{current}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "", strings.HasPrefix(line, ";"), strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			current = &iniSection{name: line[1 : len(line)-1], values: make(map[string]string)}
+			sections = append(sections, current)
+		default:
+			key, value, found := strings.Cut(line, "=")
+			if !found {
+				continue
+			}
+			key = strings.TrimSpace(key)
+			if _, exists := current.values[key]; !exists {
+				current.keys = append(current.keys, key)
+			}
+			current.values[key] = strings.TrimSpace(value)
+		}
+	}
+	return sections
+}
+
+// parseRemmina разбирает профиль Remmina; учитываются только протоколы SSH и SFTP
+func parseRemmina(text, path string) []models.Connection {
+	var connections []models.Connection
+	for _, section := range parseINI(text) {
+		if section.name != "remmina" {
+			continue
+		}
+		values := section.values
+		switch strings.ToUpper(values["protocol"]) {
+		case "SSH", "SFTP":
+		default:
+			continue
+		}
+
+		server := values["server"]
+		if server == "" {
+			server = values["ssh_server"]
+		}
+		if server == "" {
+			continue
+		}
+
+		user := values["ssh_username"]
+		if user == "" {
+			user = values["username"]
+		}
+		name := values["name"]
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+
+		conn := newImportedConnection(name, server, user, 0)
+		conn.Group = values["group"]
+		// ssh_auth: 0 - пароль (хранится зашифрованным секретом Remmina и не переносится)
+		if values["ssh_auth"] == "0" {
+			conn.UseSSHKey = false
+		} else {
+			conn.KeyPath = values["ssh_privatekey"]
+		}
+		connections = append(connections, conn)
+	}
+	return connections
+}
+
+// parseRemminaDir разбирает все профили *.remmina каталога
+func parseRemminaDir(dir string) ([]models.Connection, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.remmina"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var connections []models.Connection
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		connections = append(connections, parseRemmina(decodeText(data), path)...)
+	}
+	if len(connections) == 0 {
+		return nil, fmt.Errorf("в каталоге %s не найдено SSH профилей Remmina", dir)
+	}
+	return connections, nil
+}
+
+// parseMobaXterm разбирает закладки MobaXterm. Сессия SSH записывается как
+// "имя=#109#0%хост%порт%пользователь%...", путь к ключу - 15-е поле; SubRep задает папку
+func parseMobaXterm(text string) []models.Connection {
+	const sshSessionType = "109"
+
+	var connections []models.Connection
+	for _, section := range parseINI(text) {
+		if !strings.HasPrefix(section.name, "Bookmarks") {
+			continue
+		}
+		group := strings.ReplaceAll(section.values["SubRep"], `\`, "/")
+
+		for _, key := range section.keys {
+			if key == "SubRep" || key == "ImgNum" {
+				continue
+			}
+			parts := strings.Split(section.values[key], "#")
+			if len(parts) < 3 || parts[1] != sshSessionType {
+				continue
+			}
+
+			fields := strings.Split(parts[2], "%")
+			if len(fields) < 4 || fields[1] == "" {
+				continue
+			}
+			port, _ := strconv.Atoi(fields[2])
+			conn := newImportedConnection(key, fields[1], fields[3], port)
+			conn.Group = group
+			if len(fields) > 14 {
+				conn.KeyPath = fields[14]
+			}
+			connections = append(connections, conn)
+		}
+	}
+	return connections
+}
+
+// importFieldAliases названия столбцов и полей других клиентов. Названия
+// сравниваются в нижнем регистре без пробелов и знаков препинания
+var importFieldAliases = map[string][]string{
+	"name":     {"name", "label", "title", "displayname", "sessionname", "alias"},
+	"host":     {"host", "hostname", "hostnameip", "address", "ip", "computername", "server", "uri", "remotehost"},
+	"port":     {"port", "sshport"},
+	"user":     {"user", "username", "login", "credentialusername", "sshusername"},
+	"password": {"password", "credentialpassword", "sshpassword"},
+	"group":    {"group", "groups", "folder", "path", "parent"},
+	"tags":     {"tags", "tag", "labels"},
+	"key":      {"key", "keypath", "sshkey", "privatekey", "privatekeyfile", "identityfile", "keyfile"},
+	"jump":     {"jumphost", "proxyjump", "jump", "gateway"},
+	"protocol": {"protocol", "connectiontype", "type"},
+	"auth":     {"auth", "authentication"},
+	"command":  {"remotecommand", "startupcommand"},
+	"workdir":  {"workdir", "workingdirectory"},
+	"env":      {"env", "environment"},
+}
+
+// normalizeFieldName приводит название поля к виду для сравнения с importFieldAliases
+func normalizeFieldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// importFieldIndex сопоставляет нормализованным названиям полей их назначение
+func importFieldIndex() map[string]string {
+	index := make(map[string]string)
+	for field, aliases := range importFieldAliases {
+		for _, alias := range aliases {
+			index[alias] = field
+		}
+	}
+	return index
+}
+
+// connectionFromFields создает подключение из полей записи CSV или JSON.
+// Записи без хоста и с протоколом, отличным от SSH, пропускаются
+func connectionFromFields(values map[string]string) (models.Connection, bool) {
+	if values["host"] == "" {
+		return models.Connection{}, false
+	}
+	if protocol := strings.ToLower(values["protocol"]); protocol != "" &&
+		!strings.Contains(protocol, "ssh") && !strings.Contains(protocol, "terminal") && !strings.Contains(protocol, "sftp") {
+		return models.Connection{}, false
+	}
+
+	port, _ := strconv.Atoi(values["port"])
+	conn := newImportedConnection(values["name"], values["host"], values["user"], port)
+	conn.Group = strings.Trim(strings.ReplaceAll(values["group"], `\`, "/"), "/")
+	conn.Tags = models.ParseTags(values["tags"])
+	conn.JumpHost = values["jump"]
+	conn.RemoteCommand = values["command"]
+	conn.WorkDir = values["workdir"]
+	conn.Env = strings.Fields(values["env"])
+	conn.KeyPath = values["key"]
+
+	if values["auth"] == "password" || (values["password"] != "" && values["key"] == "") {
+		conn.UseSSHKey = false
+		conn.KeyPath = ""
+		conn.HasPassword = values["password"] != ""
+		conn.Password = values["password"]
+	}
+	return conn, true
+}
+
+// parseCSVImport разбирает таблицу с заголовком (Termius, Royal TSX, экспорт SSH Keeper)
+func parseCSVImport(text string) ([]models.Connection, error) {
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(rows) < 2 {
+		return nil, nil
+	}
+
+	index := importFieldIndex()
+	columns := make([]string, len(rows[0]))
+	for i, name := range rows[0] {
+		columns[i] = index[normalizeFieldName(name)]
+	}
+
+	var connections []models.Connection
+	for _, row := range rows[1:] {
+		values := make(map[string]string)
+		for i, value := range row {
+			if i < len(columns) && columns[i] != "" && values[columns[i]] == "" {
+				values[columns[i]] = strings.TrimSpace(value)
+			}
+		}
+		if conn, ok := connectionFromFields(values); ok {
+			connections = append(connections, conn)
+		}
+	}
+	return connections, nil
+}
+
+// parseJSONImport разбирает массив объектов JSON или объект со списком подключений
+// (Royal TSX: Objects, другие клиенты: hosts, connections, items)
+func parseJSONImport(data []byte) ([]models.Connection, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	items, ok := root.([]interface{})
+	if object, isObject := root.(map[string]interface{}); isObject {
+		for key, value := range object {
+			switch normalizeFieldName(key) {
+			case "objects", "hosts", "connections", "items", "sessions":
+				items, ok = value.([]interface{})
+			}
+			if ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("JSON не содержит списка подключений")
+	}
+
+	index := importFieldIndex()
+	var connections []models.Connection
+	for _, item := range items {
+		object, isObject := item.(map[string]interface{})
+		if !isObject {
+			continue
+		}
+
+		values := make(map[string]string)
+		for key, value := range object {
+			field := index[normalizeFieldName(key)]
+			if field == "" || values[field] != "" || value == nil {
+				continue
+			}
+			switch value := value.(type) {
+			case []interface{}:
+				parts := make([]string, 0, len(value))
+				for _, part := range value {
+					parts = append(parts, fmt.Sprint(part))
+				}
+				separator := ","
+				if field == "env" {
+					separator = " "
+				}
+				values[field] = strings.Join(parts, separator)
+			case float64:
+				values[field] = strconv.FormatFloat(value, 'f', -1, 64)
+			case string, bool:
+				values[field] = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+		if conn, ok := connectionFromFields(values); ok {
+			connections = append(connections, conn)
+		}
+	}
+	return connections, nil
+}
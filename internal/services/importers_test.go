@@ -0,0 +1,295 @@
+package services
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"ssh-keeper/internal/models"
+)
+
+func TestReplacePuTTYKey(t *testing.T) {
+	dir := t.TempDir()
+	converted := filepath.Join(dir, "work")
+	if err := os.WriteFile(converted, []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keyPath string
+		want    string
+		warning bool
+	}{
+		{"openssh key", filepath.Join(dir, "id_ed25519"), filepath.Join(dir, "id_ed25519"), false},
+		{"converted key next to ppk", filepath.Join(dir, "work.PPK"), converted, false},
+		{"ppk only", filepath.Join(dir, "home.ppk"), "", true},
+		{"windows path", `C:\Users\me\keys\prod.ppk`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := models.NewConnection("web", "10.0.0.5", "deploy")
+			conn.UseSSHKey = true
+			conn.KeyPath = tt.keyPath
+
+			warning := replacePuTTYKey(conn)
+			if conn.KeyPath != tt.want {
+				t.Errorf("KeyPath = %q, want %q", conn.KeyPath, tt.want)
+			}
+			if tt.warning != (warning != "") {
+				t.Errorf("warning = %q, want warning: %v", warning, tt.warning)
+			}
+			if tt.warning && !strings.Contains(warning, "puttygen "+tt.keyPath+" -O private-openssh") {
+				t.Errorf("warning %q does not suggest puttygen", warning)
+			}
+		})
+	}
+}
+
+// importedFields оставляет поля, которые заполняют парсеры импорта
+func importedFields(conn models.Connection) models.Connection {
+	return models.Connection{
+		Name: conn.Name, Host: conn.Host, Port: conn.Port, User: conn.User,
+		UseSSHKey: conn.UseSSHKey, KeyPath: conn.KeyPath, JumpHost: conn.JumpHost,
+		HasPassword: conn.HasPassword, Password: conn.Password, Group: conn.Group, Tags: conn.Tags,
+	}
+}
+
+// assertImported сравнивает разобранные подключения с ожидаемыми
+func assertImported(t *testing.T, got, want []models.Connection) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d connections, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if g, w := importedFields(got[i]), importedFields(want[i]); !reflect.DeepEqual(g, w) {
+			t.Errorf("connection %d:\n got %+v\nwant %+v", i, g, w)
+		}
+	}
+}
+
+func TestParsePuTTYReg(t *testing.T) {
+	const reg = `Windows Registry Editor Version 5.00
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\Default%20Settings]
+"HostName"="default.example.com"
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\prod%20web]
+"HostName"="10.0.0.5"
+"PortNumber"=dword:00000922
+"UserName"="deploy"
+"Protocol"="ssh"
+"PublicKeyFile"="C:\\Users\\me\\keys\\prod.ppk"
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\legacy]
+"HostName"="legacy.example.com"
+"Protocol"="telnet"
+
+[HKEY_CURRENT_USER\Software\SimonTatham\PuTTY\Sessions\quoted]
+"HostName"="admin@db.example.com"
+"PortNumber"=dword:00000016
+"UserName"=""
+`
+
+	want := []models.Connection{
+		{Name: "prod web", Host: "10.0.0.5", Port: 2338, User: "deploy", UseSSHKey: true, KeyPath: `C:\Users\me\keys\prod.ppk`},
+		{Name: "quoted", Host: "db.example.com", Port: 22, User: "admin", UseSSHKey: true},
+	}
+	assertImported(t, parsePuTTYReg(reg), want)
+
+	// regedit сохраняет экспорт в UTF-16LE с BOM
+	units := utf16.Encode([]rune(reg))
+	data := []byte{0xFF, 0xFE}
+	for _, unit := range units {
+		data = binary.LittleEndian.AppendUint16(data, unit)
+	}
+	assertImported(t, parsePuTTYReg(decodeText(data)), want)
+}
+
+func TestParseRegValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`"plain"`, "plain"},
+		{`"C:\\Users\\me"`, `C:\Users\me`},
+		{`"say \"hi\""`, `say "hi"`},
+		{"dword:00000016", "22"},
+		{"dword:zz", ""},
+	}
+	for _, tt := range tests {
+		if got := parseRegValue(tt.value); got != tt.want {
+			t.Errorf("parseRegValue(%s) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseRemmina(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		want    []models.Connection
+	}{
+		{
+			name: "key auth",
+			profile: `[remmina]
+name=Prod web
+protocol=SSH
+server=10.0.0.5:2222
+ssh_username=deploy
+ssh_auth=3
+ssh_privatekey=/home/me/.ssh/id_ed25519
+group=prod
+`,
+			want: []models.Connection{
+				{Name: "Prod web", Host: "10.0.0.5", Port: 2222, User: "deploy", UseSSHKey: true, KeyPath: "/home/me/.ssh/id_ed25519", Group: "prod"},
+			},
+		},
+		{
+			name: "password auth without name",
+			profile: `; Remmina profile
+[remmina]
+protocol=SFTP
+server=
+ssh_server=db.example.com
+username=postgres
+ssh_auth=0
+`,
+			want: []models.Connection{
+				{Name: "db-profile", Host: "db.example.com", Port: 22, User: "postgres"},
+			},
+		},
+		{
+			name: "rdp is skipped",
+			profile: `[remmina]
+name=Desktop
+protocol=RDP
+server=desktop.example.com
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertImported(t, parseRemmina(tt.profile, "/home/me/.local/share/remmina/db-profile.remmina"), tt.want)
+		})
+	}
+}
+
+func TestParseMobaXterm(t *testing.T) {
+	const sessions = `[Bookmarks]
+SubRep=
+ImgNum=42
+web=#109#0%10.0.0.5%2222%deploy%%-1%-1%%%%%0%0%0%C:\keys\web.pem%%-1%0%0%0%%1080%%0%0%1#MobaFont%10%0%0%-1%15%236,236,236%30,30,30%180,180,192%0%-1%0%%xterm%-1%0%_Std_Colors_0_%80%24%0%1%-1%<none>%%0%1%-1%-1#0# #-1
+rdp=#91#4%desktop.example.com%3389%admin%-1%0#MobaFont%10#0# #-1
+
+[Bookmarks_1]
+SubRep=Clients\Acme
+ImgNum=41
+db=#109#0%db.acme.local%22%postgres%%-1%-1#MobaFont%10#0# #-1
+empty=#109#0%%22%root#MobaFont%10#0# #-1
+`
+
+	want := []models.Connection{
+		{Name: "web", Host: "10.0.0.5", Port: 2222, User: "deploy", UseSSHKey: true, KeyPath: `C:\keys\web.pem`},
+		{Name: "db", Host: "db.acme.local", Port: 22, User: "postgres", UseSSHKey: true, Group: "Clients/Acme"},
+	}
+	assertImported(t, parseMobaXterm(sessions), want)
+}
+
+func TestParseCSVImportColumnAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []models.Connection
+	}{
+		{
+			name: "termius",
+			csv: `Groups,Label,Tags,Hostname/IP,Protocol,Port,Username,Password,SSH_KEY
+Prod,web,"web,eu",10.0.0.5,ssh,2222,deploy,,~/.ssh/id_ed25519
+Prod,db,,db.internal,ssh,22,postgres,secret,
+Office,desktop,,desktop.local,rdp,3389,admin,,
+`,
+			want: []models.Connection{
+				{Name: "web", Host: "10.0.0.5", Port: 2222, User: "deploy", UseSSHKey: true, KeyPath: "~/.ssh/id_ed25519", Group: "Prod", Tags: []string{"web", "eu"}},
+				{Name: "db", Host: "db.internal", Port: 22, User: "postgres", HasPassword: true, Password: "secret", Group: "Prod"},
+			},
+		},
+		{
+			name: "royal tsx",
+			csv: `Name,URI,CredentialUsername,Folder,Connection Type
+Jump,ssh://admin@bastion.example.com:2200,,Connections\Infra\,Terminal
+`,
+			want: []models.Connection{
+				{Name: "Jump", Host: "bastion.example.com", Port: 2200, User: "admin", UseSSHKey: true, Group: "Connections/Infra"},
+			},
+		},
+		{
+			name: "header only",
+			csv:  "name,host\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connections, err := parseCSVImport(tt.csv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertImported(t, connections, tt.want)
+		})
+	}
+}
+
+func TestParseJSONImport(t *testing.T) {
+	const royal = `{"Objects": [
+  {"Name": "web", "ComputerName": "10.0.0.5", "Port": 2222, "CredentialUsername": "deploy", "Tags": ["web", "eu"], "Type": "TerminalConnection"},
+  {"Name": "desktop", "ComputerName": "desktop.local", "Type": "RemoteDesktopConnection"},
+  "not an object"
+]}`
+
+	connections, err := parseJSONImport([]byte(royal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertImported(t, connections, []models.Connection{
+		{Name: "web", Host: "10.0.0.5", Port: 2222, User: "deploy", UseSSHKey: true, Tags: []string{"web", "eu"}},
+	})
+
+	if _, err := parseJSONImport([]byte(`{"version": 1}`)); err == nil {
+		t.Error("expected an error for JSON without a connection list")
+	}
+}
+
+func TestDetectImportSource(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    ImportSource
+	}{
+		{"sessions.reg", "", ImportSourcePuTTY},
+		{"web.remmina", "", ImportSourceRemmina},
+		{"export.mxtsessions", "", ImportSourceMobaXterm},
+		{"hosts.csv", "", ImportSourceCSV},
+		{"putty.txt", "Windows Registry Editor Version 5.00\n", ImportSourcePuTTY},
+		{"profile", "[remmina]\nprotocol=SSH\n", ImportSourceRemmina},
+		{"moba.ini", "[Bookmarks]\nSubRep=\n", ImportSourceMobaXterm},
+		{"export.json", `[{"host": "10.0.0.5"}]`, ImportSourceJSON},
+		{"config", "Host web\n    HostName 10.0.0.5\n", ImportSourceSSHConfig},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if got := DetectImportSource(path); got != tt.want {
+			t.Errorf("DetectImportSource(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := DetectImportSource(dir); got != ImportSourceRemmina {
+		t.Errorf("DetectImportSource(dir) = %q, want %q", got, ImportSourceRemmina)
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ssh-keeper/internal/models"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// knownHostEntry запись known_hosts: шаблоны хостов и ключ
+type knownHostEntry struct {
+	marker string
+	hosts  []string
+	key    ssh.PublicKey
+}
+
+// DefaultKnownHostsFiles возвращает существующие файлы known_hosts пользователя
+func DefaultKnownHostsFiles() []string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, name := range []string{"known_hosts", "known_hosts2"} {
+		path := filepath.Join(homeDir, ".ssh", name)
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// readKnownHosts читает записи из файлов known_hosts; некорректные строки пропускаются
+func readKnownHosts(files []string) ([]knownHostEntry, error) {
+	var entries []knownHostEntry
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			marker, hosts, key, _, _, err := ssh.ParseKnownHosts(line)
+			if err != nil || key == nil {
+				continue
+			}
+			entries = append(entries, knownHostEntry{marker: marker, hosts: hosts, key: key})
+		}
+	}
+	return entries, nil
+}
+
+// matchesHost проверяет, относится ли запись к адресу в формате known_hosts
+// (host или [host]:port). Хешированные имена сравниваются по HMAC-SHA1; шаблоны
+// с * и ? не учитываются, так как относятся к группам хостов, а не к проверенному ключу
+func (e knownHostEntry) matchesHost(address string) bool {
+	for _, pattern := range e.hosts {
+		if salt, hash, ok := parseHashedHost(pattern); ok {
+			mac := hmac.New(sha1.New, salt)
+			mac.Write([]byte(address))
+			if hmac.Equal(mac.Sum(nil), hash) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(pattern, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHashedHost разбирает хешированное имя |1|соль|хеш
+func parseHashedHost(pattern string) (salt, hash []byte, ok bool) {
+	parts := strings.Split(pattern, "|")
+	if len(parts) != 4 || parts[0] != "" || parts[1] != "1" {
+		return nil, nil, false
+	}
+	salt, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, false
+	}
+	hash, err = base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, nil, false
+	}
+	return salt, hash, true
+}
+
+// KnownHostsAddress возвращает адрес подключения в формате known_hosts
+func KnownHostsAddress(conn models.Connection) string {
+	port := conn.Port
+	if port == 0 {
+		port = 22
+	}
+	return knownhosts.Normalize(net.JoinHostPort(conn.Host, strconv.Itoa(port)))
+}
+
+// writeKnownHosts записывает проверенные ключи хостов подключений: ключи, которые
+// пользователь уже принял в файлах known_hosts. Отозванные ключи не записываются,
+// подключения без ключа отмечаются комментарием
+func writeKnownHosts(w io.Writer, connections []models.Connection, files []string) error {
+	entries, err := readKnownHosts(files)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "# known_hosts exported by SSH Keeper on %s\n", time.Now().Format(time.RFC3339))
+	if len(files) > 0 {
+		fmt.Fprintf(w, "# Host keys verified in %s\n", strings.Join(files, ", "))
+	}
+
+	written := make(map[string]bool)
+	for _, conn := range connections {
+		address := KnownHostsAddress(conn)
+
+		revoked := make(map[string]bool)
+		var keys []ssh.PublicKey
+		for _, entry := range entries {
+			switch {
+			case entry.marker == "revoked":
+				revoked[string(entry.key.Marshal())] = true
+			case entry.marker == "" && entry.matchesHost(address):
+				keys = append(keys, entry.key)
+			}
+		}
+
+		var lines []string
+		for _, key := range keys {
+			line := address + " " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+			if revoked[string(key.Marshal())] || written[line] {
+				continue
+			}
+			written[line] = true
+			lines = append(lines, line)
+		}
+
+		if len(lines) == 0 {
+			if !written["#"+address] {
+				written["#"+address] = true
+				fmt.Fprintf(w, "# %s (%s): no verified host key\n", conn.Name, address)
+			}
+			continue
+		}
+		fmt.Fprintf(w, "# %s\n", conn.Name)
+		for _, line := range lines {
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
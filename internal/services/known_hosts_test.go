@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ssh-keeper/internal/models"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// newTestHostKey создает открытый ключ хоста
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestWriteKnownHosts(t *testing.T) {
+	webKey, dbKey, stageKey, revokedKey, wildcardKey :=
+		newTestHostKey(t), newTestHostKey(t), newTestHostKey(t), newTestHostKey(t), newTestHostKey(t)
+
+	knownHostsPath := filepath.Join(t.TempDir(), "known_hosts")
+	data := strings.Join([]string{
+		knownhosts.Line([]string{"web.example.com", "10.0.0.5"}, webKey),
+		knownhosts.Line([]string{"web.example.com"}, revokedKey),
+		"@revoked * " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(revokedKey))),
+		// Хешированные имена (HashKnownHosts yes), в том числе с нестандартным портом
+		knownhosts.Line([]string{knownhosts.HashHostname(knownhosts.Normalize("db.internal:2222"))}, dbKey),
+		knownhosts.Line([]string{knownhosts.HashHostname("stage")}, stageKey),
+		// Шаблон относится к группе хостов, а не к проверенному ключу
+		knownhosts.Line([]string{"*.example.com"}, wildcardKey),
+		"not a known_hosts line",
+		"",
+	}, "\n")
+	if err := os.WriteFile(knownHostsPath, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	connections := []models.Connection{
+		{Name: "web", Host: "web.example.com", Port: 22},
+		{Name: "db", Host: "db.internal", Port: 2222},
+		{Name: "stage", Host: "stage"},
+		{Name: "new", Host: "new.example.com", Port: 22},
+	}
+	var buf bytes.Buffer
+	if err := WriteExport(&buf, connections, ExportOptions{Format: ExportFormatKnownHosts, KnownHostsFiles: []string{knownHostsPath}}); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	authorized := func(key ssh.PublicKey) string {
+		return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+	}
+	for _, want := range []string{
+		"\nweb.example.com " + authorized(webKey) + "\n",
+		"\n[db.internal]:2222 " + authorized(dbKey) + "\n",
+		"\nstage " + authorized(stageKey) + "\n",
+		"\n# new (new.example.com): no verified host key\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain %q:\n%s", strings.TrimSpace(want), output)
+		}
+	}
+	for _, key := range []ssh.PublicKey{revokedKey, wildcardKey} {
+		if strings.Contains(output, authorized(key)) {
+			t.Errorf("output contains a revoked or wildcard key:\n%s", output)
+		}
+	}
+
+	// Результат - действующий файл known_hosts для тех же адресов
+	exported := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(exported, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	callback, err := knownhosts.New(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 5), Port: 22}
+	checks := []struct {
+		address string
+		key     ssh.PublicKey
+	}{
+		{"web.example.com:22", webKey},
+		{"db.internal:2222", dbKey},
+		{"stage:22", stageKey},
+	}
+	for _, check := range checks {
+		if err := callback(check.address, remote, check.key); err != nil {
+			t.Errorf("exported known_hosts rejects %s: %v", check.address, err)
+		}
+	}
+}
+
+func TestKnownHostsAddress(t *testing.T) {
+	tests := []struct {
+		conn models.Connection
+		want string
+	}{
+		{models.Connection{Host: "web.example.com", Port: 22}, "web.example.com"},
+		{models.Connection{Host: "web.example.com"}, "web.example.com"},
+		{models.Connection{Host: "db.internal", Port: 2222}, "[db.internal]:2222"},
+		{models.Connection{Host: "2001:db8::1", Port: 2222}, "[2001:db8::1]:2222"},
+	}
+	for _, tt := range tests {
+		if got := KnownHostsAddress(tt.conn); got != tt.want {
+			t.Errorf("KnownHostsAddress(%s:%d) = %q, want %q", tt.conn.Host, tt.conn.Port, got, tt.want)
+		}
+	}
+}
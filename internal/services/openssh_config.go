@@ -0,0 +1,258 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"ssh-keeper/internal/models"
+)
+
+// OpenSSHSourcePrefix префикс источника подключений, прочитанных из конфигурации OpenSSH
+const OpenSSHSourcePrefix = "ssh-config:"
+
+// openSSHIncludeDepth предельная вложенность Include, как в OpenSSH
+const openSSHIncludeDepth = 16
+
+// openSSHBlock блок Host: шаблоны имен и параметры в порядке записи
+type openSSHBlock struct {
+	patterns []string
+	options  [][2]string // Ключевое слово в нижнем регистре и значение
+	match    bool        // Блок Match: условия не вычисляются, блок пропускается
+}
+
+// matches проверяет, относится ли блок к имени хоста. Шаблон с ! исключает хост
+func (b *openSSHBlock) matches(alias string) bool {
+	if b.match {
+		return false
+	}
+	matched := false
+	for _, pattern := range b.patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		ok, _ := path.Match(strings.ToLower(strings.TrimPrefix(pattern, "!")), strings.ToLower(alias))
+		if ok && negated {
+			return false
+		}
+		matched = matched || ok
+	}
+	return matched
+}
+
+// openSSHConfig разобранная конфигурация OpenSSH
+type openSSHConfig struct {
+	blocks  []*openSSHBlock
+	aliases []string // Конкретные имена хостов в порядке появления
+	files   []string // Прочитанные файлы, включая подключенные через Include
+	skip    map[string]bool
+}
+
+// ReadOpenSSHHosts читает конфигурацию OpenSSH (~/.ssh/config) и возвращает подключения
+// для каждого конкретного имени в Host. Параметры из блоков с шаблонами и общие
+// настройки применяются так же, как в ssh: действует первое найденное значение.
+// files - все прочитанные файлы: по ним отслеживаются изменения. Файлы из skip не читаются
+func ReadOpenSSHHosts(configPath string, skip ...string) (connections []models.Connection, files []string, err error) {
+	absolute, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	config := &openSSHConfig{skip: make(map[string]bool)}
+	for _, name := range skip {
+		if name, err := filepath.Abs(name); err == nil {
+			config.skip[name] = true
+		}
+	}
+	global := &openSSHBlock{patterns: []string{"*"}}
+	config.blocks = append(config.blocks, global)
+	if err := config.parseFile(absolute, global, 0); err != nil {
+		return nil, config.files, err
+	}
+
+	source := OpenSSHSourcePrefix + absolute
+	for _, alias := range config.aliases {
+		conn := config.connection(alias)
+		conn.ID = source + "#" + alias
+		conn.Source = source
+		conn.SourceHost = alias
+		connections = append(connections, conn)
+	}
+	return connections, config.files, nil
+}
+
+// parseFile разбирает файл; параметры до первого Host относятся к блоку current
+func (c *openSSHConfig) parseFile(name string, current *openSSHBlock, depth int) error {
+	if depth > openSSHIncludeDepth {
+		return fmt.Errorf("слишком глубокая вложенность Include: %s", name)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	c.files = append(c.files, name)
+
+	for number, line := range strings.Split(string(data), "\n") {
+		keyword, args := splitOpenSSHLine(line)
+		if keyword == "" {
+			continue
+		}
+
+		switch keyword {
+		case "host":
+			current = &openSSHBlock{patterns: args}
+			c.blocks = append(c.blocks, current)
+			for _, pattern := range args {
+				if !strings.ContainsAny(pattern, "*?!") && !c.hasAlias(pattern) {
+					c.aliases = append(c.aliases, pattern)
+				}
+			}
+		case "match":
+			current = &openSSHBlock{match: true}
+			c.blocks = append(c.blocks, current)
+		case "include":
+			for _, pattern := range args {
+				if err := c.include(pattern, current, depth); err != nil {
+					return fmt.Errorf("%s:%d: %w", name, number+1, err)
+				}
+			}
+		default:
+			if len(args) > 0 {
+				current.options = append(current.options, [2]string{keyword, strings.Join(args, " ")})
+			}
+		}
+	}
+	return nil
+}
+
+// include подключает файлы по шаблону; относительные пути считаются от ~/.ssh
+func (c *openSSHConfig) include(pattern string, current *openSSHBlock, depth int) error {
+	pattern = expandOpenSSHPath(pattern)
+	if !filepath.IsAbs(pattern) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		pattern = filepath.Join(homeDir, ".ssh", pattern)
+	}
+
+	// Отсутствующие файлы OpenSSH молча пропускает
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if c.skip[name] {
+			continue
+		}
+		if info, err := os.Stat(name); err != nil || info.IsDir() {
+			continue
+		}
+		if err := c.parseFile(name, current, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasAlias проверяет, встречалось ли имя хоста раньше
+func (c *openSSHConfig) hasAlias(alias string) bool {
+	for _, existing := range c.aliases {
+		if strings.EqualFold(existing, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// option возвращает первое значение параметра среди блоков, относящихся к хосту
+func (c *openSSHConfig) option(alias, keyword string) string {
+	for _, block := range c.blocks {
+		if !block.matches(alias) {
+			continue
+		}
+		for _, option := range block.options {
+			if option[0] == keyword {
+				return option[1]
+			}
+		}
+	}
+	return ""
+}
+
+// connection собирает подключение для имени хоста
+func (c *openSSHConfig) connection(alias string) models.Connection {
+	conn := models.NewConnection(alias, alias, "")
+	// Паролей в конфигурации OpenSSH нет: вход по ключам из IdentityFile, агента или ключам по умолчанию
+	conn.UseSSHKey = true
+
+	if hostname := c.option(alias, "hostname"); hostname != "" {
+		conn.Host = strings.ReplaceAll(hostname, "%h", alias)
+	}
+	if port, err := strconv.Atoi(c.option(alias, "port")); err == nil && port > 0 {
+		conn.Port = port
+	}
+	conn.User = c.option(alias, "user")
+	if conn.User == "" {
+		conn.User = currentUserName()
+	}
+	if identity := c.option(alias, "identityfile"); identity != "" && !strings.EqualFold(identity, "none") {
+		conn.KeyPath = expandOpenSSHPath(identity)
+	}
+
+	if jump := c.option(alias, "proxyjump"); jump != "" && !strings.EqualFold(jump, "none") {
+		conn.JumpHost = jump
+	} else if command := c.option(alias, "proxycommand"); command != "" {
+		conn.JumpHost = jumpHostFromProxyCommand(command)
+	}
+	if command := c.option(alias, "remotecommand"); command != "" && !strings.EqualFold(command, "none") {
+		conn.RemoteCommand = command
+	}
+	return *conn
+}
+
+// splitOpenSSHLine разбирает строку конфигурации OpenSSH: ключевое слово (в нижнем
+// регистре) отделяется пробелом или знаком =, аргументы могут быть в кавычках
+func splitOpenSSHLine(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), nil
+	}
+	keyword := strings.ToLower(line[:end])
+	rest := strings.TrimLeft(line[end:], " \t")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "="), " \t")
+	return keyword, splitInventoryLine(rest)
+}
+
+// expandOpenSSHPath разворачивает ~ и %d (домашний каталог) в пути
+func expandOpenSSHPath(name string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return name
+	}
+	name = strings.ReplaceAll(name, "%d", homeDir)
+	if name == "~" || strings.HasPrefix(name, "~/") {
+		return filepath.Join(homeDir, name[1:])
+	}
+	return name
+}
+
+// currentUserName возвращает имя текущего пользователя: его ssh использует, если User не задан
+func currentUserName() string {
+	current, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	// В Windows имя содержит домен: DOMAIN\user
+	if _, short, ok := strings.Cut(current.Username, `\`); ok {
+		return short
+	}
+	return current.Username
+}
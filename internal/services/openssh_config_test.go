@@ -0,0 +1,98 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSSHFiles создает файлы в ~/.ssh временного домашнего каталога
+func writeSSHFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.Mkdir(sshDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sshDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sshDir
+}
+
+func TestReadOpenSSHHostsFirstMatchWins(t *testing.T) {
+	sshDir := writeSSHFiles(t, map[string]string{
+		"config": strings.Join([]string{
+			"# Относительный Include ищется в ~/.ssh",
+			"Include extra.conf",
+			"",
+			"Host web",
+			"    HostName 10.0.0.5",
+			"    Port 2222",
+			"    User ignored",
+			"",
+			"Host web*",
+			"    Port 2200",
+			"    IdentityFile ~/.ssh/web",
+			"",
+			"Host *.internal !skip.internal",
+			"    User admin",
+			"",
+			"Host db.internal skip.internal",
+			"    HostName %h",
+			"",
+			"Match host web",
+			"    User match",
+			"",
+			"Host *",
+			"    User fallback",
+			"    Port = 22",
+			"    ProxyJump none",
+			"",
+		}, "\n"),
+		"extra.conf": strings.Join([]string{
+			"Host web",
+			"    User included",
+			"",
+			"Host bastion",
+			`    HostName "bastion.example.com"`,
+			"",
+		}, "\n"),
+	})
+
+	connections, files, err := ReadOpenSSHHosts(filepath.Join(sshDir, "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, conn := range connections {
+		got = append(got, fmt.Sprintf("%s %s@%s:%d key=%s jump=%s", conn.Name, conn.User, conn.Host, conn.Port, conn.KeyPath, conn.JumpHost))
+	}
+	want := []string{
+		// Include стоит первым: его User действует раньше User в Host web
+		"web included@10.0.0.5:2222 key=" + filepath.Join(sshDir, "web") + " jump=",
+		"bastion fallback@bastion.example.com:22 key= jump=",
+		"db.internal admin@db.internal:22 key= jump=",
+		// Отрицание исключает хост из блока *.internal
+		"skip.internal fallback@skip.internal:22 key= jump=",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("hosts:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	wantFiles := []string{filepath.Join(sshDir, "config"), filepath.Join(sshDir, "extra.conf")}
+	if strings.Join(files, ",") != strings.Join(wantFiles, ",") {
+		t.Errorf("files = %q, want %q", files, wantFiles)
+	}
+	for _, conn := range connections {
+		if !IsMirrored(conn) || conn.SourceHost != conn.Name {
+			t.Errorf("%s: Source = %q, SourceHost = %q", conn.Name, conn.Source, conn.SourceHost)
+		}
+	}
+}
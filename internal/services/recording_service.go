@@ -0,0 +1,295 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/recording"
+)
+
+const (
+	// RecordingsDirName каталог записей сессий в каталоге конфигурации
+	RecordingsDirName = "recordings"
+	// RecordingSettingsFileName файл настроек записи в каталоге конфигурации
+	RecordingSettingsFileName = "recording.json"
+
+	castExtension          = ".cast"
+	encryptedCastExtension = ".cast.enc"
+
+	// maxRecordingNameAttempts сколько имен пробуется для записей, начатых одновременно
+	maxRecordingNameAttempts = 100
+)
+
+// RecordingSettings настройки записи сессий
+type RecordingSettings struct {
+	Groups  []string `json:"groups,omitempty"` // Группы и теги, сессии которых записываются
+	Encrypt bool     `json:"encrypt"`          // Шифровать записи ключом хранилища
+}
+
+// RecordingInfo сведения о сохраненной записи
+type RecordingInfo struct {
+	Path         string
+	ConnectionID string
+	Title        string
+	StartedAt    time.Time
+	Size         int64
+	Encrypted    bool
+}
+
+// RecordingService управляет записью SSH сессий в формате asciicast v2.
+// Каждая сессия сохраняется в отдельный файл recordings/<ID подключения>/<время>.cast
+type RecordingService struct {
+	dir          string
+	settingsPath string
+	encryption   *EncryptionService
+}
+
+// NewRecordingService создает сервис записи сессий в каталоге конфигурации
+func NewRecordingService(configDir string, encryption *EncryptionService) *RecordingService {
+	return &RecordingService{
+		dir:          filepath.Join(configDir, RecordingsDirName),
+		settingsPath: filepath.Join(configDir, RecordingSettingsFileName),
+		encryption:   encryption,
+	}
+}
+
+// LoadSettings загружает настройки записи; отсутствующий файл - настройки по умолчанию
+func (rs *RecordingService) LoadSettings() (RecordingSettings, error) {
+	var settings RecordingSettings
+
+	data, err := os.ReadFile(rs.settingsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("failed to read recording settings: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("failed to parse recording settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SaveSettings сохраняет настройки записи
+func (rs *RecordingService) SaveSettings(settings RecordingSettings) error {
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording settings: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rs.settingsPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(rs.settingsPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write recording settings: %w", err)
+	}
+	return nil
+}
+
+// ToggleGroup включает или выключает запись для группы (тега). Возвращает новое состояние
+func (rs *RecordingService) ToggleGroup(group string) (bool, error) {
+	settings, err := rs.LoadSettings()
+	if err != nil {
+		return false, err
+	}
+
+	enabled := true
+	groups := make([]string, 0, len(settings.Groups)+1)
+	for _, existing := range settings.Groups {
+		if strings.EqualFold(existing, group) {
+			enabled = false
+			continue
+		}
+		groups = append(groups, existing)
+	}
+	if enabled {
+		groups = append(groups, group)
+	}
+
+	settings.Groups = groups
+	return enabled, rs.SaveSettings(settings)
+}
+
+// ShouldRecord проверяет, нужно ли записывать сессии подключения:
+// запись включена для самого подключения или для его группы/тега
+func (rs *RecordingService) ShouldRecord(conn *models.Connection) bool {
+	if conn.Record {
+		return true
+	}
+
+	settings, err := rs.LoadSettings()
+	if err != nil {
+		return false
+	}
+	for _, group := range settings.Groups {
+		if conn.HasTag(group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Start создает файл записи для новой сессии с размером терминала width x height
+func (rs *RecordingService) Start(conn *models.Connection, width, height int) (*recording.Writer, string, error) {
+	settings, err := rs.LoadSettings()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var codec recording.LineCodec
+	extension := castExtension
+	if settings.Encrypt {
+		if rs.encryption == nil || !rs.encryption.IsInitialized() {
+			return nil, "", fmt.Errorf("шифрование записей включено, но ключ хранилища не инициализирован")
+		}
+		codec = encryptionCodec{rs.encryption}
+		extension = encryptedCastExtension
+	}
+
+	dir := filepath.Join(rs.dir, sanitizeFileName(conn.ID))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, "", fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+
+	startedAt := time.Now()
+	header := recording.Header{
+		Width:     width,
+		Height:    height,
+		Timestamp: startedAt.Unix(),
+		Title:     fmt.Sprintf("%s (%s@%s)", conn.Name, conn.User, conn.Host),
+		Env: map[string]string{
+			"TERM":  os.Getenv("TERM"),
+			"SHELL": os.Getenv("SHELL"),
+		},
+	}
+
+	// Сессии одного подключения могут начаться одновременно (панели tmux, повторное
+	// подключение): к имени с миллисекундами при совпадении добавляется номер
+	name := startedAt.Format("20060102-150405.000")
+	for attempt := 1; ; attempt++ {
+		path := filepath.Join(dir, name+extension)
+		if attempt > 1 {
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", name, attempt, extension))
+		}
+		writer, err := recording.NewWriter(path, header, codec)
+		if errors.Is(err, os.ErrExist) && attempt < maxRecordingNameAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return writer, path, nil
+	}
+}
+
+// List возвращает сохраненные записи, новые первыми
+func (rs *RecordingService) List() ([]RecordingInfo, error) {
+	var recordings []RecordingInfo
+
+	err := filepath.WalkDir(rs.dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		encrypted := strings.HasSuffix(path, encryptedCastExtension)
+		if !encrypted && !strings.HasSuffix(path, castExtension) {
+			return nil
+		}
+
+		info := RecordingInfo{
+			Path:         path,
+			ConnectionID: filepath.Base(filepath.Dir(path)),
+			Encrypted:    encrypted,
+		}
+		if stat, err := entry.Info(); err == nil {
+			info.Size = stat.Size()
+			info.StartedAt = stat.ModTime()
+		}
+
+		// Заголовок зашифрованной записи доступен только при инициализированном ключе
+		if header, err := recording.ReadHeader(path, rs.codecFor(path)); err == nil {
+			info.Title = header.Title
+			info.StartedAt = time.Unix(header.Timestamp, 0)
+		}
+
+		recordings = append(recordings, info)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].StartedAt.After(recordings[j].StartedAt)
+	})
+	return recordings, nil
+}
+
+// Load загружает запись, расшифровывая ее при необходимости
+func (rs *RecordingService) Load(path string) (*recording.Cast, error) {
+	if strings.HasSuffix(path, encryptedCastExtension) && (rs.encryption == nil || !rs.encryption.IsInitialized()) {
+		return nil, fmt.Errorf("запись зашифрована, а ключ хранилища не инициализирован")
+	}
+	return recording.Load(path, rs.codecFor(path))
+}
+
+// Delete удаляет запись
+func (rs *RecordingService) Delete(path string) error {
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to delete recording: %w", err)
+	}
+	// Пустой каталог подключения больше не нужен
+	os.Remove(filepath.Dir(path))
+	return nil
+}
+
+// codecFor возвращает кодек для файла записи
+func (rs *RecordingService) codecFor(path string) recording.LineCodec {
+	if strings.HasSuffix(path, encryptedCastExtension) && rs.encryption != nil {
+		return encryptionCodec{rs.encryption}
+	}
+	return nil
+}
+
+// encryptionCodec шифрует каждую строку записи отдельно (AES-GCM ключом хранилища),
+// поэтому запись пишется потоково и оборванный при сбое файл остается читаемым
+type encryptionCodec struct {
+	encryption *EncryptionService
+}
+
+// EncodeLine шифрует строку записи
+func (ec encryptionCodec) EncodeLine(line string) (string, error) {
+	return ec.encryption.Encrypt(line)
+}
+
+// DecodeLine расшифровывает строку записи
+func (ec encryptionCodec) DecodeLine(line string) (string, error) {
+	return ec.encryption.Decrypt(line)
+}
+
+// sanitizeFileName заменяет символы, недопустимые в имени каталога
+func sanitizeFileName(name string) string {
+	if name == "" {
+		return "unknown"
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
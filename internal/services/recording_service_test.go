@@ -0,0 +1,89 @@
+package services
+
+import (
+	"crypto/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+// newTestEncryption возвращает сервис шифрования со случайным ключом
+func newTestEncryption(t *testing.T) *EncryptionService {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	es := &EncryptionService{}
+	es.SetDataKey(key)
+	return es
+}
+
+func TestRecordingServiceEncryptedRoundTrip(t *testing.T) {
+	rs := NewRecordingService(t.TempDir(), newTestEncryption(t))
+	if err := rs.SaveSettings(RecordingSettings{Encrypt: true}); err != nil {
+		t.Fatal(err)
+	}
+	conn := models.NewConnection("web", "10.0.0.5", "deploy")
+	conn.ID = "web"
+
+	writer, path, err := rs.Start(conn, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write([]byte("secret output")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, encryptedCastExtension) || strings.Contains(string(data), "secret") {
+		t.Fatalf("recording %s is not encrypted:\n%s", path, data)
+	}
+
+	cast, err := rs.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cast.Events) != 1 || cast.Events[0].Data != "secret output" {
+		t.Errorf("events = %+v", cast.Events)
+	}
+	if cast.Header.Title != "web (deploy@10.0.0.5)" {
+		t.Errorf("title = %q", cast.Header.Title)
+	}
+}
+
+func TestRecordingServiceConcurrentSessions(t *testing.T) {
+	rs := NewRecordingService(t.TempDir(), nil)
+	conn := models.NewConnection("web", "10.0.0.5", "deploy")
+	conn.ID = "web"
+
+	// Панели tmux открывают сессии одного подключения одновременно
+	paths := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		writer, path, err := rs.Start(conn, 80, 24)
+		if err != nil {
+			t.Fatalf("session %d: %v", i+1, err)
+		}
+		defer writer.Close()
+		if paths[path] {
+			t.Fatalf("session %d reused %s", i+1, path)
+		}
+		paths[path] = true
+	}
+
+	recordings, err := rs.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recordings) != 5 {
+		t.Errorf("List returned %d recordings, want 5", len(recordings))
+	}
+}
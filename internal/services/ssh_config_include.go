@@ -0,0 +1,147 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ssh-keeper/internal/models"
+)
+
+// OpenSSHIncludeFileName имя файла с подключениями SSH Keeper рядом с конфигурацией OpenSSH
+const OpenSSHIncludeFileName = "ssh-keeper.conf"
+
+// SSHConfigInclude файл с блоками Host для подключений по ключу, подключенный строкой
+// Include в начале конфигурации OpenSSH: ssh, scp, git и VS Code Remote находят
+// подключения SSH Keeper по имени
+type SSHConfigInclude struct {
+	configPath  string
+	includePath string
+}
+
+// NewSSHConfigInclude создает файл подключений рядом с конфигурацией OpenSSH configPath
+func NewSSHConfigInclude(configPath string) *SSHConfigInclude {
+	return &SSHConfigInclude{
+		configPath:  configPath,
+		includePath: filepath.Join(filepath.Dir(configPath), OpenSSHIncludeFileName),
+	}
+}
+
+// Path возвращает путь к файлу подключений
+func (i *SSHConfigInclude) Path() string {
+	return i.includePath
+}
+
+// Write перезаписывает файл подключений, если содержимое изменилось, и добавляет
+// строку Include в конфигурацию OpenSSH, если ее там еще нет
+func (i *SSHConfigInclude) Write(connections []models.Connection) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Generated by SSH Keeper: changes are overwritten on every save\n")
+	fmt.Fprintf(&buf, "# Key-based connections only; password connections stay in SSH Keeper\n\n")
+	// Команда при входе сломала бы scp, rsync, git и VS Code Remote, которые
+	// запускают на сервере свои команды: она остается только в экспорте openssh
+	if err := writeOpenSSHHosts(&buf, connections, false); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(i.includePath), 0700); err != nil {
+		return fmt.Errorf("failed to create SSH config directory: %w", err)
+	}
+	if current, err := os.ReadFile(i.includePath); err != nil || !bytes.Equal(current, buf.Bytes()) {
+		if err := os.WriteFile(i.includePath, buf.Bytes(), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", i.includePath, err)
+		}
+	}
+
+	return i.ensureInclude()
+}
+
+// ensureInclude добавляет Include в начало конфигурации OpenSSH. Строка должна стоять
+// до первого Host, иначе она действовала бы только внутри этого блока
+func (i *SSHConfigInclude) ensureInclude() error {
+	data, err := os.ReadFile(i.configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", i.configPath, err)
+	}
+	if i.isIncluded(data) {
+		return nil
+	}
+
+	// Права существующего файла сохраняются; запись на месте не заменяет символическую ссылку
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(i.configPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	header := fmt.Sprintf("# Added by SSH Keeper: connections from %s\nInclude %s\n\n", OpenSSHIncludeFileName, i.includeArgument())
+	if err := os.WriteFile(i.configPath, append([]byte(header), data...), mode); err != nil {
+		return fmt.Errorf("failed to update %s: %w", i.configPath, err)
+	}
+	return nil
+}
+
+// isIncluded проверяет, подключен ли файл строкой Include в конфигурации. Учитываются
+// только строки до первого Host или Match: Include внутри блока действует лишь для него
+func (i *SSHConfigInclude) isIncluded(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		keyword, args := splitOpenSSHLine(line)
+		if keyword == "host" || keyword == "match" {
+			return false
+		}
+		if keyword != "include" {
+			continue
+		}
+		for _, arg := range args {
+			name := expandOpenSSHPath(arg)
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(filepath.Dir(i.configPath), name)
+			}
+			if filepath.Clean(name) == filepath.Clean(i.includePath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// includeArgument возвращает путь для Include: внутри домашнего каталога - через ~,
+// чтобы конфигурацию можно было переносить между машинами
+func (i *SSHConfigInclude) includeArgument() string {
+	name := i.includePath
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		if relative, err := filepath.Rel(homeDir, name); err == nil && !strings.HasPrefix(relative, "..") {
+			name = "~/" + filepath.ToSlash(relative)
+		}
+	}
+	return quoteConfigValue(name)
+}
+
+// SetSSHConfigInclude включает запись подключений по ключу в файл для Include
+// в конфигурации OpenSSH и сразу записывает его
+func (cs *ConnectionService) SetSSHConfigInclude(include *SSHConfigInclude) error {
+	cs.sshInclude = include
+	return cs.writeSSHConfigInclude()
+}
+
+// SSHConfigIncludeError возвращает ошибку последней записи файла для Include
+func (cs *ConnectionService) SSHConfigIncludeError() error {
+	return cs.sshIncludeErr
+}
+
+// writeSSHConfigInclude записывает в файл для Include действующие подключения по ключу
+func (cs *ConnectionService) writeSSHConfigInclude() error {
+	if cs.sshInclude == nil {
+		return nil
+	}
+
+	var connections []models.Connection
+	for _, conn := range cs.exportConnections() {
+		if conn.UseSSHKey && conn.Host != "" {
+			connections = append(connections, conn)
+		}
+	}
+	cs.sshIncludeErr = cs.sshInclude.Write(connections)
+	return cs.sshIncludeErr
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+func TestSSHConfigIncludeOmitsRemoteCommand(t *testing.T) {
+	conn := models.NewConnection("web", "10.0.0.5", "deploy")
+	conn.UseSSHKey = true
+	conn.KeyPath = "~/.ssh/id_ed25519"
+	conn.RemoteCommand = "tmux attach"
+	connections := []models.Connection{*conn}
+
+	include := NewSSHConfigInclude(filepath.Join(t.TempDir(), "config"))
+	if err := include.Write(connections); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(include.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Host web\n") {
+		t.Fatalf("include file has no Host block:\n%s", data)
+	}
+	for _, keyword := range []string{"RemoteCommand", "RequestTTY"} {
+		if strings.Contains(string(data), keyword) {
+			t.Errorf("include file contains %s:\n%s", keyword, data)
+		}
+	}
+
+	// Явный экспорт сохраняет команду при входе
+	var export bytes.Buffer
+	if err := writeOpenSSHConfig(&export, connections); err != nil {
+		t.Fatal(err)
+	}
+	for _, keyword := range []string{"RemoteCommand tmux attach", "RequestTTY yes"} {
+		if !strings.Contains(export.String(), keyword) {
+			t.Errorf("export has no %q:\n%s", keyword, export.String())
+		}
+	}
+}
+
+func TestSSHConfigIncludeOnlyTopLevel(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config")
+	include := NewSSHConfigInclude(configPath)
+
+	// Include внутри блока Host действует только для этого блока
+	nested := "Host jump\n  HostName 10.0.0.1\n  Include " + include.Path() + "\n"
+	if err := os.WriteFile(configPath, []byte(nested), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := include.Write(nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# Added by SSH Keeper") || !strings.HasSuffix(string(data), nested) {
+		t.Fatalf("Include not added before the first Host:\n%s", data)
+	}
+
+	// Строка Include до первого Host уже подключает файл: конфигурация не меняется
+	if err := include.Write(nil); err != nil {
+		t.Fatal(err)
+	}
+	again, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("Include added twice:\n%s", again)
+	}
+}
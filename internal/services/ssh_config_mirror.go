@@ -0,0 +1,128 @@
+package services
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+// SSHConfigMirrorInterval интервал проверки изменений конфигурации OpenSSH
+const SSHConfigMirrorInterval = 2 * time.Second
+
+// fileStamp время изменения и размер файла
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// SSHConfigMirror зеркало хостов из конфигурации OpenSSH (~/.ssh/config): подключения
+// только для чтения, которые перечитываются при изменении файла или подключенных в нем
+type SSHConfigMirror struct {
+	mu          sync.Mutex
+	path        string
+	skip        []string
+	stamps      map[string]fileStamp
+	connections []models.Connection
+	err         error
+}
+
+// NewSSHConfigMirror создает зеркало конфигурации OpenSSH. Файлы из skip не читаются
+func NewSSHConfigMirror(path string, skip ...string) *SSHConfigMirror {
+	return &SSHConfigMirror{
+		path: path,
+		skip: skip,
+	}
+}
+
+// Path возвращает путь к конфигурации OpenSSH
+func (m *SSHConfigMirror) Path() string {
+	return m.path
+}
+
+// Refresh перечитывает конфигурацию, если файлы изменились с прошлой проверки.
+// changed - список хостов или ошибка чтения стали другими
+func (m *SSHConfigMirror) Refresh() (changed bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stamps != nil && !m.modified() {
+		return false, m.err
+	}
+
+	connections, files, err := ReadOpenSSHHosts(m.path, m.skip...)
+	if os.IsNotExist(err) && len(files) == 0 {
+		// Файла еще нет: зеркало пустое, его появление заметим по времени изменения
+		connections, err = nil, nil
+	}
+
+	stamps := map[string]fileStamp{m.path: statFile(m.path)}
+	for _, name := range files {
+		stamps[name] = statFile(name)
+	}
+
+	changed = !models.SameConnections(m.connections, connections) || errorText(m.err) != errorText(err)
+	m.stamps = stamps
+	m.connections = connections
+	m.err = err
+	return changed, err
+}
+
+// modified проверяет, изменился ли какой-нибудь из прочитанных файлов
+func (m *SSHConfigMirror) modified() bool {
+	for name, stamp := range m.stamps {
+		if statFile(name) != stamp {
+			return true
+		}
+	}
+	return false
+}
+
+// Connections возвращает хосты из конфигурации, кроме тех, чьи имена уже есть среди
+// подключений managed: перенесенный в хранилище хост не показывается дважды
+func (m *SSHConfigMirror) Connections(managed []models.Connection) []models.Connection {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	connections := make([]models.Connection, 0, len(m.connections))
+	for _, conn := range m.connections {
+		if !slices.ContainsFunc(managed, func(existing models.Connection) bool {
+			return strings.EqualFold(existing.Name, conn.Name)
+		}) {
+			connections = append(connections, conn)
+		}
+	}
+	return connections
+}
+
+// Err возвращает ошибку последнего чтения конфигурации
+func (m *SSHConfigMirror) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// IsMirrored проверяет, прочитано ли подключение из конфигурации OpenSSH
+func IsMirrored(conn models.Connection) bool {
+	return strings.HasPrefix(conn.Source, OpenSSHSourcePrefix)
+}
+
+// statFile возвращает отметку файла; у отсутствующего файла она пустая
+func statFile(name string) fileStamp {
+	info, err := os.Stat(name)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// errorText возвращает текст ошибки или пустую строку
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
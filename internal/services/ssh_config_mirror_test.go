@@ -0,0 +1,112 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+// mirrorNames возвращает имена хостов зеркала
+func mirrorNames(mirror *SSHConfigMirror, managed ...models.Connection) []string {
+	var names []string
+	for _, conn := range mirror.Connections(managed) {
+		names = append(names, conn.Name)
+	}
+	return names
+}
+
+func TestSSHConfigMirrorSkipsOwnInclude(t *testing.T) {
+	sshDir := writeSSHFiles(t, map[string]string{
+		"config": "Host web\n    HostName 10.0.0.5\n",
+	})
+	configPath := filepath.Join(sshDir, "config")
+
+	// Файл для Include с подключениями SSH Keeper и строка Include в начале config
+	include := NewSSHConfigInclude(configPath)
+	conn := models.NewConnection("managed", "10.0.0.9", "deploy")
+	conn.UseSSHKey = true
+	if err := include.Write([]models.Connection{*conn}); err != nil {
+		t.Fatal(err)
+	}
+
+	mirror := NewSSHConfigMirror(configPath, include.Path())
+	if _, err := mirror.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if names := mirrorNames(mirror); len(names) != 1 || names[0] != "web" {
+		t.Errorf("mirrored hosts = %q, want only web", names)
+	}
+
+	// Без skip тот же файл показал бы подключения SSH Keeper второй раз
+	unfiltered := NewSSHConfigMirror(configPath)
+	if _, err := unfiltered.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if names := mirrorNames(unfiltered); len(names) != 2 {
+		t.Errorf("unfiltered hosts = %q, want managed and web", names)
+	}
+}
+
+func TestSSHConfigMirrorRefresh(t *testing.T) {
+	sshDir := writeSSHFiles(t, map[string]string{
+		"config":     "Include hosts.conf\n\nHost web\n    HostName 10.0.0.5\n",
+		"hosts.conf": "Host db\n    HostName 10.0.0.6\n",
+	})
+	configPath := filepath.Join(sshDir, "config")
+
+	mirror := NewSSHConfigMirror(configPath)
+	if changed, err := mirror.Refresh(); err != nil || !changed {
+		t.Fatalf("first Refresh = %v, %v; want changed", changed, err)
+	}
+	if changed, err := mirror.Refresh(); err != nil || changed {
+		t.Errorf("Refresh without changes = %v, %v", changed, err)
+	}
+
+	// Изменение config
+	if err := os.WriteFile(configPath, []byte("Include hosts.conf\n\nHost web stage\n    HostName 10.0.0.5\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := mirror.Refresh(); err != nil || !changed {
+		t.Errorf("Refresh after editing config = %v, %v; want changed", changed, err)
+	}
+	if names := mirrorNames(mirror); len(names) != 3 || names[2] != "stage" {
+		t.Errorf("hosts = %q, want db, web, stage", names)
+	}
+
+	// Изменение подключенного через Include файла
+	if err := os.WriteFile(filepath.Join(sshDir, "hosts.conf"), []byte("Host db backup\n    HostName 10.0.0.6\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := mirror.Refresh(); err != nil || !changed {
+		t.Errorf("Refresh after editing the included file = %v, %v; want changed", changed, err)
+	}
+
+	// Перенесенный в хранилище хост не показывается дважды
+	managed := *models.NewConnection("WEB", "10.0.0.5", "deploy")
+	if names := mirrorNames(mirror, managed); len(names) != 3 || names[0] != "db" || names[1] != "backup" || names[2] != "stage" {
+		t.Errorf("hosts without managed = %q, want db, backup, stage", names)
+	}
+}
+
+func TestSSHConfigMirrorMissingFile(t *testing.T) {
+	sshDir := writeSSHFiles(t, nil)
+	configPath := filepath.Join(sshDir, "config")
+
+	mirror := NewSSHConfigMirror(configPath)
+	if _, err := mirror.Refresh(); err != nil {
+		t.Fatalf("Refresh without config: %v", err)
+	}
+	if names := mirrorNames(mirror); len(names) != 0 {
+		t.Errorf("hosts = %q, want none", names)
+	}
+
+	// Появление файла замечается при следующей проверке
+	if err := os.WriteFile(configPath, []byte("Host web\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := mirror.Refresh(); err != nil || !changed {
+		t.Errorf("Refresh after creating config = %v, %v; want changed", changed, err)
+	}
+}
@@ -58,9 +58,9 @@ func (scs *SSHConfigService) LoadConfig() (*models.SSHConfig, error) {
 
 		// Check for Host directive
 		if strings.HasPrefix(strings.ToLower(line), "host ") {
-			// Save previous host if exists
+			// Save previous host if exists (as is, keeping saved timestamps)
 			if currentHost != nil && inHostBlock {
-				config.AddHost(*currentHost)
+				config.Hosts = append(config.Hosts, *currentHost)
 			}
 
 			// Parse host patterns
@@ -102,12 +102,41 @@ func (scs *SSHConfigService) LoadConfig() (*models.SSHConfig, error) {
 				}
 			case "user":
 				currentHost.User = value
+			case "proxyjump":
+				currentHost.ProxyJump = value
+			case "group":
+				currentHost.Group = value
+			case "tags":
+				currentHost.Tags = models.ParseTags(value)
 			case "identityfile":
 				currentHost.IdentityFile = value
 			case "usesshkey":
 				currentHost.UseSSHKey = strings.ToLower(value) == "true" || value == "yes" || value == "1"
 			case "password":
 				currentHost.Password = value
+			case "remotecommand":
+				currentHost.RemoteCommand = value
+			case "workdir":
+				currentHost.WorkDir = value
+			case "env":
+				// Каждая переменная записывается отдельной строкой Env;
+				// старые файлы хранят список через запятую в одной строке
+				currentHost.Env = append(currentHost.Env, models.ParseEnv(value)...)
+			case "record":
+				currentHost.Record = strings.ToLower(value) == "true" || value == "yes" || value == "1"
+			case "controlmaster":
+				value = strings.ToLower(value)
+				currentHost.ControlMaster = value != "" && value != "no" && value != "false"
+			case "template":
+				currentHost.Template = strings.ToLower(value) == "true" || value == "yes" || value == "1"
+			case "parent":
+				currentHost.Parent = value
+			case "inherit":
+				currentHost.Inherit = strings.Split(value, ",")
+			case "source":
+				currentHost.Source = value
+			case "sourcehost":
+				currentHost.SourceHost = value
 			case "stricthostkeychecking":
 				currentHost.StrictHostKeyChecking = value
 			case "userknownhostsfile":
@@ -120,13 +149,13 @@ func (scs *SSHConfigService) LoadConfig() (*models.SSHConfig, error) {
 				if count, err := strconv.Atoi(value); err == nil {
 					currentHost.ServerAliveCountMax = count
 				}
-			case "ID":
+			case "id":
 				currentHost.ID = value
-			case "CreatedAt":
+			case "createdat":
 				if t, err := time.Parse(time.RFC3339, value); err == nil {
 					currentHost.CreatedAt = t
 				}
-			case "UpdatedAt":
+			case "updatedat":
 				if t, err := time.Parse(time.RFC3339, value); err == nil {
 					currentHost.UpdatedAt = t
 				}
@@ -136,13 +165,28 @@ func (scs *SSHConfigService) LoadConfig() (*models.SSHConfig, error) {
 
 	// Don't forget the last host
 	if currentHost != nil && inHostBlock {
-		config.AddHost(*currentHost)
+		config.Hosts = append(config.Hosts, *currentHost)
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("error reading config file: %w", err)
 	}
 
+	// Older versions could save the same ID for several hosts (ID was a timestamp).
+	// Duplicates fall back to the generated ID (Name + Host + User + Port)
+	seenIDs := make(map[string]bool)
+	for i := range config.Hosts {
+		id := config.Hosts[i].ID
+		if id == "" {
+			continue
+		}
+		if seenIDs[id] {
+			config.Hosts[i].ID = ""
+			continue
+		}
+		seenIDs[id] = true
+	}
+
 	return config, nil
 }
 
@@ -195,6 +239,15 @@ func (scs *SSHConfigService) SaveConfig(config *models.SSHConfig) error {
 		if host.User != "" {
 			fmt.Fprintf(writer, "    User %s\n", host.User)
 		}
+		if host.ProxyJump != "" {
+			fmt.Fprintf(writer, "    ProxyJump %s\n", host.ProxyJump)
+		}
+		if host.Group != "" {
+			fmt.Fprintf(writer, "    Group %s\n", host.Group)
+		}
+		if len(host.Tags) > 0 {
+			fmt.Fprintf(writer, "    Tags %s\n", strings.Join(host.Tags, ","))
+		}
 		if host.IdentityFile != "" {
 			fmt.Fprintf(writer, "    IdentityFile %s\n", host.IdentityFile)
 		}
@@ -204,6 +257,35 @@ func (scs *SSHConfigService) SaveConfig(config *models.SSHConfig) error {
 		if host.Password != "" {
 			fmt.Fprintf(writer, "    Password %s\n", host.Password)
 		}
+		if host.RemoteCommand != "" {
+			fmt.Fprintf(writer, "    RemoteCommand %s\n", host.RemoteCommand)
+		}
+		if host.WorkDir != "" {
+			fmt.Fprintf(writer, "    WorkDir %s\n", host.WorkDir)
+		}
+		// По строке на переменную: значение может содержать запятые
+		for _, entry := range host.Env {
+			fmt.Fprintf(writer, "    Env %s\n", entry)
+		}
+		if host.Record {
+			fmt.Fprintf(writer, "    Record true\n")
+		}
+		if host.ControlMaster {
+			fmt.Fprintf(writer, "    ControlMaster auto\n")
+		}
+		if host.Template {
+			fmt.Fprintf(writer, "    Template true\n")
+		}
+		if host.Parent != "" {
+			fmt.Fprintf(writer, "    Parent %s\n", host.Parent)
+		}
+		if host.Parent != "" && len(host.Inherit) > 0 {
+			fmt.Fprintf(writer, "    Inherit %s\n", strings.Join(host.Inherit, ","))
+		}
+		if host.Source != "" {
+			fmt.Fprintf(writer, "    Source %s\n", host.Source)
+			fmt.Fprintf(writer, "    SourceHost %s\n", host.SourceHost)
+		}
 		if host.StrictHostKeyChecking != "" {
 			fmt.Fprintf(writer, "    StrictHostKeyChecking %s\n", host.StrictHostKeyChecking)
 		}
@@ -241,7 +323,8 @@ func (scs *SSHConfigService) ConvertConnectionsToSSHConfig(connections []models.
 	for _, conn := range connections {
 		host := &models.SSHConfigHost{}
 		host.ConvertFromConnection(&conn)
-		config.AddHost(*host)
+		// AddHost would reset the timestamps that git sync compares
+		config.Hosts = append(config.Hosts, *host)
 	}
 
 	return config
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+func TestSSHConfigServiceEnvRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	service := NewSSHConfigService(path)
+
+	conn := models.NewConnection("web", "10.0.0.5", "deploy")
+	conn.UseSSHKey = true
+	conn.Env = []string{"PATH_LIST=a,b", "LANG", "DEBUG=1"}
+	if err := service.SaveConfig(service.ConvertConnectionsToSSHConfig([]models.Connection{*conn})); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := service.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	connections := service.ConvertSSHConfigToConnections(config)
+	if len(connections) != 1 || !slices.Equal(connections[0].Env, conn.Env) {
+		t.Fatalf("loaded %+v, want Env %q", connections, conn.Env)
+	}
+}
+
+func TestSSHConfigServiceLegacyEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	data := strings.Join([]string{
+		"Host web",
+		"    Name web",
+		"    HostName 10.0.0.5",
+		"    User deploy",
+		"    UseSSHKey true",
+		"    Env LANG,APP_ENV=prod,DEBUG=1",
+		"",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewSSHConfigService(path)
+	config, err := service.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	connections := service.ConvertSSHConfigToConnections(config)
+	want := []string{"LANG", "APP_ENV=prod", "DEBUG=1"}
+	if len(connections) != 1 || !slices.Equal(connections[0].Env, want) {
+		t.Fatalf("loaded %+v, want Env %q", connections, want)
+	}
+}
+
+func TestSSHConfigServiceKeepsLegacyIDs(t *testing.T) {
+	// Старый generateID: время с точностью до секунды, подключения, созданные
+	// в одну секунду, получали один ID
+	path := filepath.Join(t.TempDir(), "config")
+	data := strings.Join([]string{
+		"Host web",
+		"    Name web",
+		"    HostName 10.0.0.5",
+		"    User deploy",
+		"    ID 20240101120000",
+		"    CreatedAt 2024-01-01T12:00:00Z",
+		"    UpdatedAt 2024-02-01T08:30:00Z",
+		"",
+		"Host db",
+		"    Name db",
+		"    HostName 10.0.0.6",
+		"    User postgres",
+		"    ID 20240101120000",
+		"",
+		"Host stage",
+		"    Name stage",
+		"    HostName 10.0.0.7",
+		"    User root",
+		"    ID 20240101120001",
+		"",
+	}, "\n")
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewSSHConfigService(path)
+	load := func() []models.Connection {
+		t.Helper()
+		config, err := service.LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return service.ConvertSSHConfigToConnections(config)
+	}
+
+	first := load()
+	if len(first) != 3 {
+		t.Fatalf("loaded %d connections, want 3", len(first))
+	}
+	if first[0].ID != "20240101120000" || first[2].ID != "20240101120001" {
+		t.Errorf("saved IDs not kept: %q, %q", first[0].ID, first[2].ID)
+	}
+	// Повтор ID получает производный ID, а не делит его с первым подключением
+	if first[1].ID == "" || first[1].ID == first[0].ID {
+		t.Errorf("duplicate ID = %q", first[1].ID)
+	}
+	if !first[0].UpdatedAt.Equal(time.Date(2024, 2, 1, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("UpdatedAt = %v, want the saved timestamp", first[0].UpdatedAt)
+	}
+
+	// После сохранения в новом формате ID не меняются
+	if err := service.SaveConfig(service.ConvertConnectionsToSSHConfig(first)); err != nil {
+		t.Fatal(err)
+	}
+	second := load()
+	for i := range first {
+		if second[i].ID != first[i].ID {
+			t.Errorf("connection %s: ID %q after reload, want %q", first[i].Name, second[i].ID, first[i].ID)
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"ssh-keeper/internal/config"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// KeyTypeED25519 тип ключа ed25519
+	KeyTypeED25519 = "ed25519"
+	// KeyTypeRSA тип ключа RSA
+	KeyTypeRSA = "rsa"
+
+	// DefaultRSABits размер RSA ключа по умолчанию
+	DefaultRSABits = 4096
+	// MinRSABits минимально допустимый размер RSA ключа
+	MinRSABits = 2048
+)
+
+// SSHKeyInfo содержит сведения о SSH ключе
+type SSHKeyInfo struct {
+	Path          string
+	PublicKeyPath string
+	Type          string
+	Bits          int
+	Fingerprint   string
+	Comment       string
+	Encrypted     bool
+	Mode          os.FileMode
+	Warnings      []string
+	Error         string // Почему ключ не удалось прочитать; остальные поля, кроме Path и Mode, пусты
+}
+
+// KeyGenOptions параметры генерации нового ключа
+type KeyGenOptions struct {
+	Name       string
+	Type       string
+	Bits       int
+	Passphrase string
+	Comment    string
+}
+
+// SSHKeyService работает с SSH ключами пользователя
+type SSHKeyService struct {
+	sshDir string
+}
+
+// NewSSHKeyService создает сервис для работы с ключами в ~/.ssh
+func NewSSHKeyService() *SSHKeyService {
+	return &SSHKeyService{
+		sshDir: config.ExpandPath("~/.ssh"),
+	}
+}
+
+// GetSSHDir возвращает директорию с ключами
+func (ks *SSHKeyService) GetSSHDir() string {
+	return ks.sshDir
+}
+
+// ListKeys возвращает приватные ключи, найденные в ~/.ssh. Ключи, которые не удалось
+// разобрать (поврежденные или неподдерживаемые), возвращаются с заполненным Error
+func (ks *SSHKeyService) ListKeys() ([]SSHKeyInfo, error) {
+	entries, err := os.ReadDir(ks.sshDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []SSHKeyInfo{}, nil
+		}
+		return nil, fmt.Errorf("не удалось прочитать %s: %w", ks.sshDir, err)
+	}
+
+	keys := make([]SSHKeyInfo, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".pub") {
+			continue
+		}
+
+		path := filepath.Join(ks.sshDir, entry.Name())
+		if !isPrivateKeyFile(path) {
+			continue
+		}
+
+		info, err := ks.InspectKey(path)
+		if err != nil {
+			info = &SSHKeyInfo{Path: path, Type: "unknown", Error: err.Error()}
+			if stat, statErr := entry.Info(); statErr == nil {
+				info.Mode = stat.Mode().Perm()
+			}
+		}
+		keys = append(keys, *info)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Path < keys[j].Path
+	})
+
+	return keys, nil
+}
+
+// InspectKey читает приватный ключ и возвращает сведения о нем
+func (ks *SSHKeyService) InspectKey(path string) (*SSHKeyInfo, error) {
+	path = config.ExpandPath(path)
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("ключ %s не найден: %w", path, err)
+	}
+	if stat.IsDir() {
+		return nil, fmt.Errorf("%s является директорией", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать ключ: %w", err)
+	}
+
+	info := &SSHKeyInfo{
+		Path: path,
+		Mode: stat.Mode().Perm(),
+	}
+
+	var publicKey ssh.PublicKey
+
+	// Публичная часть из .pub файла не требует ввода парольной фразы
+	if pubData, err := os.ReadFile(path + ".pub"); err == nil {
+		if pub, comment, _, _, err := ssh.ParseAuthorizedKey(pubData); err == nil {
+			publicKey = pub
+			info.PublicKeyPath = path + ".pub"
+			info.Comment = comment
+		}
+	}
+
+	rawKey, err := ssh.ParseRawPrivateKey(data)
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if !errors.As(err, &missing) {
+			if isPublicKeyData(data) {
+				return nil, fmt.Errorf("%s является публичным ключом, укажите приватный", path)
+			}
+			return nil, fmt.Errorf("%s не является приватным SSH ключом: %w", path, err)
+		}
+		info.Encrypted = true
+		if publicKey == nil && missing.PublicKey != nil {
+			publicKey = missing.PublicKey
+		}
+	} else if publicKey == nil {
+		if signer, err := ssh.NewSignerFromKey(rawKey); err == nil {
+			publicKey = signer.PublicKey()
+		}
+	}
+
+	if publicKey != nil {
+		info.Type, info.Bits = describePublicKey(publicKey)
+		info.Fingerprint = ssh.FingerprintSHA256(publicKey)
+	} else {
+		info.Type = "unknown"
+	}
+
+	info.Warnings = permissionWarnings(path, info.Mode)
+
+	return info, nil
+}
+
+// ValidateKeyPath проверяет, что путь указывает на существующий приватный ключ.
+// Возвращает предупреждения (например, о слишком открытых правах доступа)
+func (ks *SSHKeyService) ValidateKeyPath(path string) ([]string, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+
+	info, err := ks.InspectKey(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Warnings, nil
+}
+
+// GenerateKey создает новую пару ключей в ~/.ssh
+func (ks *SSHKeyService) GenerateKey(opts KeyGenOptions) (*SSHKeyInfo, error) {
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		return nil, fmt.Errorf("имя ключа не может быть пустым")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("имя ключа не должно содержать разделители пути")
+	}
+
+	keyType := strings.ToLower(strings.TrimSpace(opts.Type))
+	if keyType == "" {
+		keyType = KeyTypeED25519
+	}
+
+	var privateKey interface{}
+	switch keyType {
+	case KeyTypeED25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось создать ключ ed25519: %w", err)
+		}
+		privateKey = key
+	case KeyTypeRSA:
+		bits := opts.Bits
+		if bits == 0 {
+			bits = DefaultRSABits
+		}
+		if bits < MinRSABits {
+			return nil, fmt.Errorf("размер RSA ключа должен быть не меньше %d бит", MinRSABits)
+		}
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось создать ключ RSA: %w", err)
+		}
+		privateKey = key
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип ключа: %s (доступны %s, %s)", opts.Type, KeyTypeED25519, KeyTypeRSA)
+	}
+
+	privatePath := filepath.Join(ks.sshDir, name)
+	publicPath := privatePath + ".pub"
+	for _, p := range []string{privatePath, publicPath} {
+		if _, err := os.Stat(p); err == nil {
+			return nil, fmt.Errorf("файл %s уже существует", p)
+		}
+	}
+
+	var block *pem.Block
+	var err error
+	if opts.Passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(privateKey, opts.Comment, []byte(opts.Passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(privateKey, opts.Comment)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось сохранить приватный ключ: %w", err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить публичный ключ: %w", err)
+	}
+
+	publicLine := bytes.TrimSpace(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+	if opts.Comment != "" {
+		publicLine = append(publicLine, ' ')
+		publicLine = append(publicLine, []byte(opts.Comment)...)
+	}
+	publicLine = append(publicLine, '\n')
+
+	if err := os.MkdirAll(ks.sshDir, 0700); err != nil {
+		return nil, fmt.Errorf("не удалось создать %s: %w", ks.sshDir, err)
+	}
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(block), 0600); err != nil {
+		return nil, fmt.Errorf("не удалось записать приватный ключ: %w", err)
+	}
+	if err := os.WriteFile(publicPath, publicLine, 0644); err != nil {
+		return nil, fmt.Errorf("не удалось записать публичный ключ: %w", err)
+	}
+
+	return ks.InspectKey(privatePath)
+}
+
+// ReadPublicKey возвращает публичный ключ в формате authorized_keys
+func (ks *SSHKeyService) ReadPublicKey(privatePath string) (string, error) {
+	privatePath = config.ExpandPath(privatePath)
+
+	data, err := os.ReadFile(privatePath + ".pub")
+	if err == nil {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey(data); err != nil {
+			return "", fmt.Errorf("некорректный публичный ключ %s.pub: %w", privatePath, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	// .pub файла нет - восстанавливаем публичную часть из приватного ключа
+	keyData, err := os.ReadFile(privatePath)
+	if err != nil {
+		return "", fmt.Errorf("не удалось прочитать ключ: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(keyData)
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) && missing.PublicKey != nil {
+			return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(missing.PublicKey))), nil
+		}
+		return "", fmt.Errorf("не удалось получить публичный ключ: %w", err)
+	}
+
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))), nil
+}
+
+// describePublicKey возвращает тип и длину ключа
+func describePublicKey(key ssh.PublicKey) (string, int) {
+	keyType := strings.TrimPrefix(key.Type(), "ssh-")
+
+	cryptoKey, ok := key.(ssh.CryptoPublicKey)
+	if !ok {
+		return keyType, 0
+	}
+
+	switch pub := cryptoKey.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		return keyType, pub.N.BitLen()
+	case *ecdsa.PublicKey:
+		return keyType, pub.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return keyType, 256
+	}
+
+	return keyType, 0
+}
+
+// permissionWarnings проверяет права доступа к приватному ключу
+func permissionWarnings(path string, mode os.FileMode) []string {
+	// На Windows права доступа POSIX не применяются
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	if mode&0077 != 0 {
+		return []string{fmt.Sprintf("права %04o на %s слишком открыты, выполните chmod 600", mode, path)}
+	}
+
+	return nil
+}
+
+// isPrivateKeyFile быстро проверяет, похож ли файл на приватный ключ
+func isPrivateKeyFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, 64)
+	n, _ := file.Read(header)
+
+	return bytes.Contains(header[:n], []byte("PRIVATE KEY-----"))
+}
+
+// isPublicKeyData проверяет, похожи ли данные на публичный ключ в формате authorized_keys
+func isPublicKeyData(data []byte) bool {
+	_, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	return err == nil
+}
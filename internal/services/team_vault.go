@@ -0,0 +1,424 @@
+package services
+
+import (
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// TeamVaultFileName имя файла командного хранилища в каталоге конфигурации
+const TeamVaultFileName = "team_vault.json"
+
+// TeamIdentityFileName имя файла личного ключа участника в каталоге конфигурации
+const TeamIdentityFileName = "team_identity"
+
+// TeamVaultFormat метка файла командного хранилища
+const TeamVaultFormat = "ssh-keeper-team-vault"
+
+// TeamVaultVersion текущая версия командного хранилища
+const TeamVaultVersion = 1
+
+// TeamPublicKeyPrefix префикс открытого ключа участника
+const TeamPublicKeyPrefix = "ssh-keeper-x25519:"
+
+// teamVaultCipher схема обертки ключа данных: эфемерный X25519, HKDF-SHA256 и AES-256-GCM
+const teamVaultCipher = "x25519-hkdf-sha256-aes-256-gcm"
+
+// teamWrapInfo контекст HKDF для ключа обертки
+const teamWrapInfo = "ssh-keeper team vault v1"
+
+// teamDataKeyLength длина ключа данных командного хранилища
+const teamDataKeyLength = 32
+
+// ErrTeamVaultLocked возвращается, если ключ данных не удалось расшифровать своим ключом
+var ErrTeamVaultLocked = errors.New("ваш ключ не является участником командного хранилища")
+
+// TeamIdentity личный ключ X25519 участника командного хранилища
+type TeamIdentity struct {
+	private *ecdh.PrivateKey
+}
+
+// TeamMember участник командного хранилища: его открытый ключ и ключ данных,
+// зашифрованный для этого ключа
+type TeamMember struct {
+	Name       string `json:"name"`
+	PublicKey  string `json:"public_key"`
+	Ephemeral  []byte `json:"ephemeral"` // Эфемерный открытый ключ X25519 обертки
+	Nonce      []byte `json:"nonce"`
+	WrappedKey []byte `json:"wrapped_key"`
+}
+
+// TeamVault командное хранилище: ключ данных, которым шифруются пароли подключений,
+// обернут для открытого ключа каждого участника. Каждый участник открывает
+// хранилище своим ключом, а тот защищен его мастер-паролем
+type TeamVault struct {
+	Format     string       `json:"format"`
+	Version    int          `json:"version"`
+	Generation int          `json:"generation"` // Увеличивается при каждом изменении состава участников
+	Cipher     string       `json:"cipher"`
+	Members    []TeamMember `json:"members"`
+	// Previous прежний ключ данных, обернутый для участников до смены ключа. Хранится,
+	// пока подключения не перешифрованы новым ключом, чтобы сбой между записью
+	// хранилища и файла подключений не оставил данные без ключа
+	Previous []TeamMember `json:"previous,omitempty"`
+
+	dataKey     []byte // Ключ данных после открытия хранилища
+	previousKey []byte // Прежний ключ данных, если смена ключа не завершена
+}
+
+// GenerateTeamIdentity создает новый личный ключ участника
+func GenerateTeamIdentity() (*TeamIdentity, error) {
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return &TeamIdentity{private: private}, nil
+}
+
+// LoadTeamIdentity читает личный ключ, зашифрованный мастер-паролем участника
+func LoadTeamIdentity(path string, encryption *EncryptionService) (*TeamIdentity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !encryption.IsInitialized() {
+		return nil, fmt.Errorf("для открытия командного ключа нужен мастер-пароль")
+	}
+
+	encoded, err := encryption.Decrypt(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось расшифровать командный ключ мастер-паролем: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("командный ключ поврежден: %w", err)
+	}
+	private, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("командный ключ поврежден: %w", err)
+	}
+	return &TeamIdentity{private: private}, nil
+}
+
+// LoadOrCreateTeamIdentity читает личный ключ участника или создает его при первом вызове.
+// created - ключ создан сейчас
+func LoadOrCreateTeamIdentity(path string, encryption *EncryptionService) (identity *TeamIdentity, created bool, err error) {
+	identity, err = LoadTeamIdentity(path, encryption)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return identity, false, err
+	}
+
+	if identity, err = GenerateTeamIdentity(); err != nil {
+		return nil, false, err
+	}
+	if err := identity.Save(path, encryption); err != nil {
+		return nil, false, err
+	}
+	return identity, true, nil
+}
+
+// Save записывает личный ключ, зашифрованный мастер-паролем участника
+func (id *TeamIdentity) Save(path string, encryption *EncryptionService) error {
+	if !encryption.IsInitialized() {
+		return fmt.Errorf("для сохранения командного ключа нужен мастер-пароль")
+	}
+
+	encrypted, err := encryption.Encrypt(base64.StdEncoding.EncodeToString(id.private.Bytes()))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(encrypted+"\n"), 0600)
+}
+
+// PublicKey возвращает открытый ключ участника для передачи владельцу хранилища
+func (id *TeamIdentity) PublicKey() string {
+	return TeamPublicKeyPrefix + base64.RawURLEncoding.EncodeToString(id.private.PublicKey().Bytes())
+}
+
+// ParseTeamPublicKey разбирает открытый ключ участника
+func ParseTeamPublicKey(value string) (*ecdh.PublicKey, error) {
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(value), TeamPublicKeyPrefix)
+	if !ok {
+		return nil, fmt.Errorf("открытый ключ должен начинаться с %s", TeamPublicKeyPrefix)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный открытый ключ: %w", err)
+	}
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный открытый ключ: %w", err)
+	}
+	return key, nil
+}
+
+// NewTeamVault создает открытое хранилище с новым ключом данных и без участников
+func NewTeamVault() (*TeamVault, error) {
+	dataKey := make([]byte, teamDataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return &TeamVault{
+		Format:  TeamVaultFormat,
+		Version: TeamVaultVersion,
+		Cipher:  teamVaultCipher,
+		dataKey: dataKey,
+	}, nil
+}
+
+// LoadTeamVault читает закрытое командное хранилище
+func LoadTeamVault(path string) (*TeamVault, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseTeamVault(data)
+}
+
+// parseTeamVault разбирает файл командного хранилища и проверяет заголовок
+func parseTeamVault(data []byte) (*TeamVault, error) {
+	var vault TeamVault
+	if err := json.Unmarshal(data, &vault); err != nil || vault.Format != TeamVaultFormat {
+		return nil, fmt.Errorf("файл не является командным хранилищем SSH Keeper")
+	}
+	switch {
+	case vault.Version < 1 || vault.Version > TeamVaultVersion:
+		return nil, fmt.Errorf("версия командного хранилища %d не поддерживается, обновите SSH Keeper", vault.Version)
+	case vault.Cipher != teamVaultCipher:
+		return nil, fmt.Errorf("неподдерживаемая схема шифрования: %s", vault.Cipher)
+	}
+	return &vault, nil
+}
+
+// Save записывает командное хранилище. Файл не содержит секретов в открытом виде
+func (v *TeamVault) Save(path string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode team vault: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write team vault: %w", err)
+	}
+	return nil
+}
+
+// Unlock открывает хранилище личным ключом участника. Если смена ключа данных
+// не завершена, открывается и прежний ключ
+func (v *TeamVault) Unlock(id *TeamIdentity) error {
+	dataKey, err := unwrapTeamDataKey(v.Members, id)
+	if err != nil {
+		return err
+	}
+	var previousKey []byte
+	if len(v.Previous) > 0 {
+		if previousKey, err = unwrapTeamDataKey(v.Previous, id); err != nil && !errors.Is(err, ErrTeamVaultLocked) {
+			return err
+		}
+	}
+	v.dataKey = dataKey
+	v.previousKey = previousKey
+	return nil
+}
+
+// unwrapTeamDataKey расшифровывает ключ данных, обернутый для личного ключа участника
+func unwrapTeamDataKey(members []TeamMember, id *TeamIdentity) ([]byte, error) {
+	publicKey := id.PublicKey()
+	for _, member := range members {
+		if member.PublicKey != publicKey {
+			continue
+		}
+		ephemeral, err := ecdh.X25519().NewPublicKey(member.Ephemeral)
+		if err != nil {
+			return nil, fmt.Errorf("командное хранилище повреждено: %w", err)
+		}
+		shared, err := id.private.ECDH(ephemeral)
+		if err != nil {
+			return nil, fmt.Errorf("командное хранилище повреждено: %w", err)
+		}
+		gcm, err := teamWrapCipher(shared, member.Ephemeral, id.private.PublicKey().Bytes())
+		if err != nil {
+			return nil, err
+		}
+		if len(member.Nonce) != gcm.NonceSize() {
+			return nil, ErrTeamVaultLocked
+		}
+		dataKey, err := gcm.Open(nil, member.Nonce, member.WrappedKey, []byte(member.PublicKey))
+		if err != nil || len(dataKey) != teamDataKeyLength {
+			return nil, ErrTeamVaultLocked
+		}
+		return dataKey, nil
+	}
+	return nil, ErrTeamVaultLocked
+}
+
+// IsUnlocked проверяет, открыто ли хранилище
+func (v *TeamVault) IsUnlocked() bool {
+	return v.dataKey != nil
+}
+
+// DataKey возвращает ключ данных открытого хранилища
+func (v *TeamVault) DataKey() []byte {
+	return v.dataKey
+}
+
+// PreviousKey возвращает прежний ключ данных, если смена ключа не завершена
+func (v *TeamVault) PreviousKey() []byte {
+	return v.previousKey
+}
+
+// FindMember ищет участника по имени или открытому ключу
+func (v *TeamVault) FindMember(nameOrKey string) int {
+	for i, member := range v.Members {
+		if member.PublicKey == nameOrKey || strings.EqualFold(member.Name, nameOrKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddMember оборачивает ключ данных для открытого ключа нового участника
+func (v *TeamVault) AddMember(name, publicKey string) error {
+	if !v.IsUnlocked() {
+		return fmt.Errorf("командное хранилище не открыто")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("укажите имя участника")
+	}
+	publicKey = strings.TrimSpace(publicKey)
+	if i := v.FindMember(publicKey); i >= 0 {
+		return fmt.Errorf("ключ уже принадлежит участнику %s", v.Members[i].Name)
+	}
+	if i := v.FindMember(name); i >= 0 {
+		return fmt.Errorf("участник %s уже есть", v.Members[i].Name)
+	}
+
+	member, err := wrapTeamDataKey(v.dataKey, name, publicKey)
+	if err != nil {
+		return err
+	}
+	v.Members = append(v.Members, member)
+	v.Generation++
+	return nil
+}
+
+// RemoveMember удаляет участника и меняет ключ данных: новый ключ оборачивается
+// для оставшихся участников, поэтому удаленный не сможет прочитать новые данные.
+// Данные после этого нужно перешифровать новым ключом
+func (v *TeamVault) RemoveMember(nameOrKey string) (TeamMember, error) {
+	if !v.IsUnlocked() {
+		return TeamMember{}, fmt.Errorf("командное хранилище не открыто")
+	}
+	index := v.FindMember(nameOrKey)
+	if index < 0 {
+		return TeamMember{}, fmt.Errorf("участник %s не найден", nameOrKey)
+	}
+	if len(v.Members) == 1 {
+		return TeamMember{}, fmt.Errorf("нельзя удалить последнего участника")
+	}
+	removed := v.Members[index]
+
+	dataKey := make([]byte, teamDataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return TeamMember{}, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	remaining := slices.Delete(slices.Clone(v.Members), index, index+1)
+	members := make([]TeamMember, 0, len(remaining))
+	for _, member := range remaining {
+		rewrapped, err := wrapTeamDataKey(dataKey, member.Name, member.PublicKey)
+		if err != nil {
+			return TeamMember{}, err
+		}
+		members = append(members, rewrapped)
+	}
+
+	v.Previous = v.Members
+	v.previousKey = v.dataKey
+	v.Members = members
+	v.dataKey = dataKey
+	v.Generation++
+	return removed, nil
+}
+
+// clone возвращает копию хранилища для изменения состава участников
+func (v *TeamVault) clone() *TeamVault {
+	vault := *v
+	vault.Members = slices.Clone(v.Members)
+	vault.Previous = slices.Clone(v.Previous)
+	return &vault
+}
+
+// completeRekey забывает прежний ключ данных после перешифровки подключений
+func (v *TeamVault) completeRekey() {
+	v.Previous = nil
+	v.previousKey = nil
+}
+
+// sameMembers проверяет, что у хранилищ одинаковые участники
+func (v *TeamVault) sameMembers(other *TeamVault) bool {
+	return slices.EqualFunc(v.Members, other.Members, func(a, b TeamMember) bool {
+		return a.Name == b.Name && a.PublicKey == b.PublicKey && slices.Equal(a.WrappedKey, b.WrappedKey)
+	})
+}
+
+// wrapTeamDataKey шифрует ключ данных для открытого ключа участника:
+// общий секрет эфемерного ключа и ключа участника превращается в ключ AES через HKDF
+func wrapTeamDataKey(dataKey []byte, name, publicKey string) (TeamMember, error) {
+	recipient, err := ParseTeamPublicKey(publicKey)
+	if err != nil {
+		return TeamMember{}, err
+	}
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return TeamMember{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return TeamMember{}, fmt.Errorf("failed to compute shared secret: %w", err)
+	}
+	gcm, err := teamWrapCipher(shared, ephemeral.PublicKey().Bytes(), recipient.Bytes())
+	if err != nil {
+		return TeamMember{}, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return TeamMember{}, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	publicKey = strings.TrimSpace(publicKey)
+	return TeamMember{
+		Name:       name,
+		PublicKey:  publicKey,
+		Ephemeral:  ephemeral.PublicKey().Bytes(),
+		Nonce:      nonce,
+		WrappedKey: gcm.Seal(nil, nonce, dataKey, []byte(publicKey)),
+	}, nil
+}
+
+// teamWrapCipher выводит ключ обертки из общего секрета X25519. Соль HKDF - эфемерный
+// ключ и ключ участника, как в age, чтобы ключ обертки был привязан к обоим
+func teamWrapCipher(shared, ephemeral, recipient []byte) (cipher.AEAD, error) {
+	salt := append(slices.Clone(ephemeral), recipient...)
+	key, err := hkdf.Key(sha256.New, shared, salt, teamWrapInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return newGCM(key)
+}
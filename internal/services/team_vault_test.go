@@ -0,0 +1,199 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+// newTestIdentity создает личный ключ участника
+func newTestIdentity(t *testing.T) *TeamIdentity {
+	t.Helper()
+	identity, err := GenerateTeamIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identity
+}
+
+// reloadTeamVault записывает хранилище и читает его заново, как другая машина
+func reloadTeamVault(t *testing.T, vault *TeamVault) *TeamVault {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), TeamVaultFileName)
+	if err := vault.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadTeamVault(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loaded
+}
+
+func TestTeamVaultRoundTrip(t *testing.T) {
+	alice, bob := newTestIdentity(t), newTestIdentity(t)
+	vault, err := NewTeamVault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, identity := range map[string]*TeamIdentity{"alice": alice, "bob": bob} {
+		if err := vault.AddMember(name, identity.PublicKey()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, identity := range []*TeamIdentity{alice, bob} {
+		loaded := reloadTeamVault(t, vault)
+		if err := loaded.Unlock(identity); err != nil {
+			t.Fatalf("Unlock: %v", err)
+		}
+		if !slices.Equal(loaded.DataKey(), vault.DataKey()) {
+			t.Error("unwrapped data key differs from the original")
+		}
+	}
+}
+
+func TestTeamVaultWrongIdentity(t *testing.T) {
+	alice := newTestIdentity(t)
+	vault, err := NewTeamVault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vault.AddMember("alice", alice.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := reloadTeamVault(t, vault)
+	if err := loaded.Unlock(newTestIdentity(t)); !errors.Is(err, ErrTeamVaultLocked) {
+		t.Errorf("Unlock with a stranger's key: err = %v, want ErrTeamVaultLocked", err)
+	}
+
+	// Подмена открытого ключа участника не дает чужому ключу прочитать обертку
+	mallory := newTestIdentity(t)
+	loaded.Members[0].PublicKey = mallory.PublicKey()
+	if err := loaded.Unlock(mallory); !errors.Is(err, ErrTeamVaultLocked) {
+		t.Errorf("Unlock with a substituted member key: err = %v, want ErrTeamVaultLocked", err)
+	}
+	if loaded.IsUnlocked() {
+		t.Error("vault unlocked by a wrong identity")
+	}
+}
+
+func TestParseTeamVaultRejectsUnsupportedVersion(t *testing.T) {
+	vault, err := NewTeamVault()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []int{0, -1, TeamVaultVersion + 1} {
+		vault.Version = version
+		data, err := json.Marshal(vault)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parseTeamVault(data); err == nil {
+			t.Errorf("version %d: expected an error", version)
+		}
+	}
+}
+
+func TestTeamVaultRemovedMemberCannotUnlock(t *testing.T) {
+	alice, bob := newTestIdentity(t), newTestIdentity(t)
+	vault, err := NewTeamVault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vault.AddMember("alice", alice.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	if err := vault.AddMember("bob", bob.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	oldKey := vault.DataKey()
+
+	if _, err := vault.RemoveMember("bob"); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Equal(vault.DataKey(), oldKey) {
+		t.Fatal("RemoveMember kept the data key")
+	}
+	vault.completeRekey()
+
+	loaded := reloadTeamVault(t, vault)
+	if err := loaded.Unlock(bob); !errors.Is(err, ErrTeamVaultLocked) {
+		t.Errorf("removed member: err = %v, want ErrTeamVaultLocked", err)
+	}
+	if err := loaded.Unlock(alice); err != nil {
+		t.Fatalf("remaining member: %v", err)
+	}
+	if !slices.Equal(loaded.DataKey(), vault.DataKey()) {
+		t.Error("remaining member got a different data key")
+	}
+}
+
+func TestOpenTeamVaultCompletesInterruptedRekey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	configPath := filepath.Join(t.TempDir(), "connections.conf")
+	owner, bob := newTestIdentity(t), newTestIdentity(t)
+
+	cs := NewConnectionService(configPath)
+	cs.encryptionService = newTestEncryption(t)
+	cs.teamIdentity = owner
+	web := models.NewConnection("web", "10.0.0.5", "deploy")
+	web.HasPassword = true
+	web.Password = "secret"
+	if err := cs.AddConnection(web); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.CreateTeamVault("owner"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.AddTeamMember("bob", bob.PublicKey()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Сбой после записи хранилища с новым ключом, до перешифровки подключений
+	vault := cs.teamVault.clone()
+	if _, err := vault.RemoveMember("bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vault.Save(cs.teamVaultPath()); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := NewConnectionService(configPath)
+	reopened.encryptionService = newTestEncryption(t)
+	reopened.teamIdentity = owner
+	if err := reopened.OpenTeamVault(reopened.encryptionService); err != nil {
+		t.Fatal(err)
+	}
+	if got := findConnection(t, reopened, "web").Password; got != "secret" {
+		t.Fatalf("password after interrupted rekey = %q, want secret", got)
+	}
+
+	// Смена ключа завершена: прежний ключ убран из файла, а подключения читаются новым
+	stored, err := LoadTeamVault(reopened.teamVaultPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Previous) != 0 {
+		t.Errorf("previous data key kept after the rekey completed: %+v", stored.Previous)
+	}
+	if err := stored.Unlock(bob); !errors.Is(err, ErrTeamVaultLocked) {
+		t.Errorf("removed member: err = %v, want ErrTeamVaultLocked", err)
+	}
+
+	fresh := NewConnectionService(configPath)
+	fresh.encryptionService = newTestEncryption(t)
+	fresh.teamIdentity = owner
+	if err := fresh.OpenTeamVault(fresh.encryptionService); err != nil {
+		t.Fatal(err)
+	}
+	if got := findConnection(t, fresh, "web").Password; got != "secret" {
+		t.Errorf("password with the new data key = %q, want secret", got)
+	}
+}
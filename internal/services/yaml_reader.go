@@ -0,0 +1,232 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// yamlLine значимая строка YAML с отступом
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAML разбирает подмножество YAML, достаточное для inventory и подобных файлов:
+// вложенные отображения, списки скаляров, строки в кавычках и комментарии.
+// Скаляры возвращаются строками, отображения - map[string]interface{}, списки - []interface{}
+func parseYAML(text string) (interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := stripYAMLComment(raw)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" || trimmed == "..." {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(line, " "), "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		lines = append(lines, yamlLine{
+			number: i + 1,
+			indent: len(line) - len(strings.TrimLeft(line, " ")),
+			text:   trimmed,
+		})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	value, next, err := parseYAMLBlock(lines, 0, lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[next].number)
+	}
+	return value, nil
+}
+
+// parseYAMLBlock разбирает отображение или список, начинающийся со строки i с отступом indent
+func parseYAMLBlock(lines []yamlLine, i, indent int) (interface{}, int, error) {
+	if isYAMLListItem(lines[i].text) {
+		var list []interface{}
+		for i < len(lines) && lines[i].indent == indent && isYAMLListItem(lines[i].text) {
+			item := strings.TrimSpace(strings.TrimPrefix(lines[i].text, "-"))
+			if err := checkYAMLFlow(item, lines[i].number); err != nil {
+				return nil, i, err
+			}
+			list = append(list, parseYAMLScalar(item))
+			i++
+		}
+		return list, i, nil
+	}
+
+	mapping := make(map[string]interface{})
+	for i < len(lines) && lines[i].indent == indent {
+		line := lines[i]
+		if isYAMLListItem(line.text) {
+			return nil, i, fmt.Errorf("line %d: list item inside a mapping", line.number)
+		}
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, i, fmt.Errorf("line %d: expected \"key: value\"", line.number)
+		}
+		i++
+
+		switch {
+		case isYAMLBlockScalar(rest):
+			// Многострочное значение (| или >, в том числе !vault |): строки с большим отступом
+			var block []string
+			for i < len(lines) && lines[i].indent > indent {
+				block = append(block, lines[i].text)
+				i++
+			}
+			separator := "\n"
+			if strings.Contains(rest, ">") {
+				separator = " "
+			}
+			value := strings.Join(block, separator)
+			if strings.HasPrefix(rest, "!vault") {
+				value = "!vault " + value
+			}
+			mapping[key] = value
+		case rest != "":
+			if err := checkYAMLFlow(rest, line.number); err != nil {
+				return nil, i, err
+			}
+			mapping[key] = parseYAMLScalar(rest)
+		case i < len(lines) && (lines[i].indent > indent ||
+			(lines[i].indent == indent && isYAMLListItem(lines[i].text))):
+			value, next, err := parseYAMLBlock(lines, i, lines[i].indent)
+			if err != nil {
+				return nil, next, err
+			}
+			mapping[key] = value
+			i = next
+		default:
+			mapping[key] = nil
+		}
+	}
+
+	if i < len(lines) && lines[i].indent > indent {
+		return nil, i, fmt.Errorf("line %d: unexpected indentation", lines[i].number)
+	}
+	return mapping, i, nil
+}
+
+// isYAMLListItem проверяет, что строка - элемент списка
+func isYAMLListItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isYAMLBlockScalar проверяет, что значение начинает многострочный блок
+func isYAMLBlockScalar(rest string) bool {
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, "!vault"))
+	return rest != "" && (rest[0] == '|' || rest[0] == '>') && len(rest) <= 3
+}
+
+// splitYAMLKey разделяет строку "key: value"; ключ может быть в кавычках
+func splitYAMLKey(text string) (string, string, bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := strings.IndexByte(text[1:], text[0])
+		if end < 0 {
+			return "", "", false
+		}
+		key := text[1 : end+1]
+		rest := strings.TrimSpace(text[end+2:])
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(rest[1:]), true
+	}
+
+	// Двоеточие без пробела после него - часть ключа (host:2222, web[01:03])
+	if strings.HasSuffix(text, ":") {
+		return strings.TrimSpace(text[:len(text)-1]), "", true
+	}
+	key, rest, found := strings.Cut(text, ": ")
+	if !found {
+		return "", "", false
+	}
+	return strings.TrimSpace(key), strings.TrimSpace(rest), true
+}
+
+// checkYAMLFlow отклоняет встроенные отображения и списки ({a: 1}, [a, b]), кроме
+// пустых: без ошибки они молча превратились бы в строку
+func checkYAMLFlow(text string, number int) error {
+	if text == "{}" || text == "[]" || (text[0] != '{' && text[0] != '[') {
+		return nil
+	}
+	return fmt.Errorf("line %d: flow collections like %q are not supported, use block style", number, text)
+}
+
+// parseYAMLScalar разбирает значение: строку в кавычках, {} и [] или обычный текст
+func parseYAMLScalar(text string) interface{} {
+	switch {
+	case text == "" || text == "~" || text == "null":
+		return nil
+	case text == "{}":
+		return map[string]interface{}{}
+	case text == "[]":
+		return []interface{}{}
+	case len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"':
+		if value, err := strconv.Unquote(text); err == nil {
+			return value
+		}
+		return text[1 : len(text)-1]
+	case len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'':
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	return text
+}
+
+// stripYAMLComment отрезает комментарий: # в начале строки или после пробела вне кавычек
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			// Кавычка открывает строку только в начале значения
+			if i == 0 || line[i-1] == ' ' || line[i-1] == ':' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+	return line
+}
+
+// yamlString приводит значение YAML к строке; списки объединяются через пробел
+func yamlString(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []interface{}:
+		parts := make([]string, 0, len(value))
+		for _, part := range value {
+			parts = append(parts, yamlString(part))
+		}
+		return strings.Join(parts, " ")
+	}
+	return fmt.Sprint(value)
+}
+
+// sortedKeys возвращает ключи отображения YAML по алфавиту
+func sortedKeys(mapping map[string]interface{}) []string {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,413 @@
+package sftp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/ssh"
+)
+
+// protocolVersion версия протокола SFTP, которую поддерживает клиент
+const protocolVersion = 3
+
+// maxPacketSize ограничение на размер входящего пакета
+const maxPacketSize = 256 * 1024
+
+// posixRenameExtension расширение OpenSSH для атомарного переименования с заменой
+const posixRenameExtension = "posix-rename@openssh.com"
+
+// FileInfo описывает удаленный файл
+type FileInfo struct {
+	Name string
+	FileAttrs
+}
+
+// IsDir проверяет, является ли файл каталогом
+func (fi FileInfo) IsDir() bool {
+	return fi.Mode().IsDir()
+}
+
+// Client минимальный SFTP клиент поверх подсистемы sftp системного ssh.
+// Запросы выполняются последовательно
+type Client struct {
+	cmd        *exec.Cmd
+	writer     io.WriteCloser
+	reader     *bufio.Reader
+	mu         sync.Mutex
+	nextID     uint32
+	extensions map[string]string
+}
+
+// Dial запускает `ssh -s <host> sftp` с параметрами подключения
+// (порт, ключ, jump хост, сохраненный пароль) и выполняет рукопожатие SFTP
+func Dial(conn *models.Connection) (*Client, error) {
+	cmd, err := ssh.Command(conn, []string{"-s", "-o", "ConnectTimeout=10"}, "sftp")
+	if err != nil {
+		return nil, err
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("не удалось запустить ssh: %w", err)
+	}
+
+	client := newClient(stdin, stdout)
+	client.cmd = cmd
+
+	if err := client.init(); err != nil {
+		client.Close()
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("ошибка SFTP подключения: %s", message)
+		}
+		return nil, fmt.Errorf("ошибка SFTP подключения: %w", err)
+	}
+
+	return client, nil
+}
+
+// newClient создает клиент поверх потоков подсистемы sftp
+func newClient(writer io.WriteCloser, reader io.Reader) *Client {
+	return &Client{
+		writer:     writer,
+		reader:     bufio.NewReaderSize(reader, 64*1024),
+		extensions: make(map[string]string),
+	}
+}
+
+// Close завершает сессию SFTP
+func (c *Client) Close() error {
+	c.writer.Close()
+	if c.cmd == nil {
+		return nil
+	}
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+	c.cmd.Wait()
+	return nil
+}
+
+// init отправляет SSH_FXP_INIT и читает SSH_FXP_VERSION
+func (c *Client) init() error {
+	if _, err := c.writer.Write(newPacket(fxpInit).uint32(protocolVersion).frame()); err != nil {
+		return err
+	}
+
+	packetType, payload, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+	if packetType != fxpVersion {
+		return fmt.Errorf("неожиданный ответ сервера: %d", packetType)
+	}
+
+	reader := &packetReader{buf: payload}
+	if version := reader.uint32(); version < protocolVersion {
+		return fmt.Errorf("сервер поддерживает SFTP версии %d", version)
+	}
+	for len(reader.buf) > 0 && reader.err == nil {
+		name := reader.string()
+		data := reader.string()
+		c.extensions[name] = data
+	}
+
+	return nil
+}
+
+// readPacket читает один пакет
+func (c *Client) readPacket() (byte, []byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	length := binary.BigEndian.Uint32(header[:])
+	if length == 0 || length > maxPacketSize {
+		return 0, nil, fmt.Errorf("sftp: некорректная длина пакета %d", length)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return 0, nil, err
+	}
+
+	return body[0], body[1:], nil
+}
+
+// request отправляет запрос и возвращает ответ с тем же id.
+// build дописывает аргументы запроса после id
+func (c *Client) request(packetType byte, build func(p *packetBuilder)) (byte, *packetReader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	id := c.nextID
+
+	packet := newPacket(packetType).uint32(id)
+	if build != nil {
+		build(packet)
+	}
+	if _, err := c.writer.Write(packet.frame()); err != nil {
+		return 0, nil, fmt.Errorf("sftp: соединение закрыто: %w", err)
+	}
+
+	responseType, payload, err := c.readPacket()
+	if err != nil {
+		return 0, nil, fmt.Errorf("sftp: соединение закрыто: %w", err)
+	}
+
+	reader := &packetReader{buf: payload}
+	if responseID := reader.uint32(); responseID != id {
+		return 0, nil, fmt.Errorf("sftp: неожиданный id ответа %d", responseID)
+	}
+
+	return responseType, reader, nil
+}
+
+// statusError преобразует ответ SSH_FXP_STATUS в ошибку (nil для SSH_FX_OK)
+func statusError(reader *packetReader) error {
+	code := reader.uint32()
+	message := reader.string()
+	if reader.err != nil {
+		return reader.err
+	}
+	if code == fxOK {
+		return nil
+	}
+	return &StatusError{Code: code, Message: message}
+}
+
+// unexpected формирует ошибку для неожиданного типа ответа
+func unexpected(responseType byte, reader *packetReader) error {
+	if responseType == fxpStatus {
+		if err := statusError(reader); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("sftp: неожиданный ответ сервера: %d", responseType)
+}
+
+// expectStatus выполняет запрос, ответом на который должен быть SSH_FXP_STATUS
+func (c *Client) expectStatus(packetType byte, build func(p *packetBuilder)) error {
+	responseType, reader, err := c.request(packetType, build)
+	if err != nil {
+		return err
+	}
+	if responseType != fxpStatus {
+		return unexpected(responseType, reader)
+	}
+	return statusError(reader)
+}
+
+// expectHandle выполняет запрос, ответом на который должен быть SSH_FXP_HANDLE
+func (c *Client) expectHandle(packetType byte, build func(p *packetBuilder)) (string, error) {
+	responseType, reader, err := c.request(packetType, build)
+	if err != nil {
+		return "", err
+	}
+	if responseType != fxpHandle {
+		return "", unexpected(responseType, reader)
+	}
+	handle := reader.string()
+	return handle, reader.err
+}
+
+// expectAttrs выполняет запрос, ответом на который должен быть SSH_FXP_ATTRS
+func (c *Client) expectAttrs(packetType byte, build func(p *packetBuilder)) (FileAttrs, error) {
+	responseType, reader, err := c.request(packetType, build)
+	if err != nil {
+		return FileAttrs{}, err
+	}
+	if responseType != fxpAttrs {
+		return FileAttrs{}, unexpected(responseType, reader)
+	}
+	attrs := reader.attrs()
+	return attrs, reader.err
+}
+
+// RealPath возвращает канонический абсолютный путь
+func (c *Client) RealPath(remotePath string) (string, error) {
+	responseType, reader, err := c.request(fxpRealpath, func(p *packetBuilder) {
+		p.string(remotePath)
+	})
+	if err != nil {
+		return "", err
+	}
+	if responseType != fxpName {
+		return "", unexpected(responseType, reader)
+	}
+	if count := reader.uint32(); count != 1 {
+		return "", fmt.Errorf("sftp: некорректный ответ realpath")
+	}
+	name := reader.string()
+	return name, reader.err
+}
+
+// Stat возвращает атрибуты файла (с переходом по символическим ссылкам)
+func (c *Client) Stat(remotePath string) (FileAttrs, error) {
+	return c.expectAttrs(fxpStat, func(p *packetBuilder) {
+		p.string(remotePath)
+	})
+}
+
+// ReadDir возвращает содержимое каталога без "." и ".."
+func (c *Client) ReadDir(remotePath string) ([]FileInfo, error) {
+	handle, err := c.expectHandle(fxpOpendir, func(p *packetBuilder) {
+		p.string(remotePath)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer c.closeHandle(handle)
+
+	var entries []FileInfo
+	for {
+		responseType, reader, err := c.request(fxpReaddir, func(p *packetBuilder) {
+			p.string(handle)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if responseType == fxpStatus {
+			if err := statusError(reader); err != nil && !isEOF(err) {
+				return nil, err
+			}
+			break
+		}
+		if responseType != fxpName {
+			return nil, unexpected(responseType, reader)
+		}
+
+		count := reader.uint32()
+		for i := uint32(0); i < count && reader.err == nil; i++ {
+			name := reader.string()
+			reader.string() // longname
+			attrs := reader.attrs()
+			if name == "." || name == ".." {
+				continue
+			}
+
+			info := FileInfo{Name: name, FileAttrs: attrs}
+			// Для символических ссылок показываем тип цели
+			if attrs.Permissions&modeTypeMask == modeSymlink {
+				if target, err := c.Stat(path.Join(remotePath, name)); err == nil {
+					info.FileAttrs = target
+				}
+			}
+			entries = append(entries, info)
+		}
+		if reader.err != nil {
+			return nil, reader.err
+		}
+	}
+
+	return entries, nil
+}
+
+// Mkdir создает каталог
+func (c *Client) Mkdir(remotePath string) error {
+	return c.expectStatus(fxpMkdir, func(p *packetBuilder) {
+		p.string(remotePath).uint32(0)
+	})
+}
+
+// Remove удаляет файл
+func (c *Client) Remove(remotePath string) error {
+	return c.expectStatus(fxpRemove, func(p *packetBuilder) {
+		p.string(remotePath)
+	})
+}
+
+// RemoveDir удаляет пустой каталог
+func (c *Client) RemoveDir(remotePath string) error {
+	return c.expectStatus(fxpRmdir, func(p *packetBuilder) {
+		p.string(remotePath)
+	})
+}
+
+// Rename переименовывает файл. SFTP v3 не разрешает замену существующего файла
+func (c *Client) Rename(oldPath, newPath string) error {
+	return c.expectStatus(fxpRename, func(p *packetBuilder) {
+		p.string(oldPath).string(newPath)
+	})
+}
+
+// PosixRename переименовывает файл с заменой существующего.
+// Если сервер не поддерживает расширение OpenSSH, файл назначения сначала удаляется
+func (c *Client) PosixRename(oldPath, newPath string) error {
+	if _, ok := c.extensions[posixRenameExtension]; ok {
+		return c.expectStatus(fxpExtended, func(p *packetBuilder) {
+			p.string(posixRenameExtension).string(oldPath).string(newPath)
+		})
+	}
+
+	if err := c.Remove(newPath); err != nil && !IsNotExist(err) {
+		return err
+	}
+	return c.Rename(oldPath, newPath)
+}
+
+// openFile открывает удаленный файл и возвращает handle
+func (c *Client) openFile(remotePath string, flags uint32) (string, error) {
+	return c.expectHandle(fxpOpen, func(p *packetBuilder) {
+		p.string(remotePath).uint32(flags)
+		// Атрибуты: права 0644 для новых файлов
+		p.uint32(attrPermissions).uint32(0644)
+	})
+}
+
+// closeHandle закрывает handle файла или каталога
+func (c *Client) closeHandle(handle string) error {
+	return c.expectStatus(fxpClose, func(p *packetBuilder) {
+		p.string(handle)
+	})
+}
+
+// readAt читает до length байт со смещения offset. Возвращает io.EOF в конце файла
+func (c *Client) readAt(handle string, offset uint64, length uint32) ([]byte, error) {
+	responseType, reader, err := c.request(fxpRead, func(p *packetBuilder) {
+		p.string(handle).uint64(offset).uint32(length)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch responseType {
+	case fxpData:
+		data := reader.bytes()
+		return data, reader.err
+	case fxpStatus:
+		if err := statusError(reader); err != nil && !isEOF(err) {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	return nil, unexpected(responseType, reader)
+}
+
+// writeAt записывает данные со смещения offset
+func (c *Client) writeAt(handle string, offset uint64, data []byte) error {
+	return c.expectStatus(fxpWrite, func(p *packetBuilder) {
+		p.string(handle).uint64(offset).bytes(data)
+	})
+}
@@ -0,0 +1,449 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// fakeServer сервер SFTP v3 поверх каталога root: отвечает на запросы клиента
+// в той же горутине, что и читает их, как sftp-server OpenSSH
+type fakeServer struct {
+	root        string
+	posixRename bool
+	handles     map[string]*os.File
+	dirs        map[string][]os.DirEntry
+	next        int
+	requests    []byte // Типы полученных запросов
+}
+
+// newTestClient запускает фейковый сервер и подключает к нему клиент
+func newTestClient(t *testing.T, posixRename bool) (*Client, *fakeServer) {
+	t.Helper()
+
+	server := &fakeServer{
+		root:        t.TempDir(),
+		posixRename: posixRename,
+		handles:     make(map[string]*os.File),
+		dirs:        make(map[string][]os.DirEntry),
+	}
+	toServer, clientWriter := io.Pipe()
+	clientReader, fromServer := io.Pipe()
+	go server.serve(toServer, fromServer)
+
+	client := newClient(clientWriter, clientReader)
+	if err := client.init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+// path переводит путь клиента в путь внутри root
+func (s *fakeServer) path(name string) string {
+	return filepath.Join(s.root, filepath.FromSlash(name))
+}
+
+func (s *fakeServer) serve(in io.ReadCloser, out io.WriteCloser) {
+	defer out.Close()
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(in, header[:]); err != nil {
+			return
+		}
+		body := make([]byte, binary.BigEndian.Uint32(header[:]))
+		if _, err := io.ReadFull(in, body); err != nil {
+			return
+		}
+		if _, err := out.Write(s.handle(body[0], &packetReader{buf: body[1:]})); err != nil {
+			return
+		}
+	}
+}
+
+// status собирает ответ SSH_FXP_STATUS по ошибке файловой системы
+func status(id uint32, err error) []byte {
+	code, message := uint32(fxOK), ""
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF):
+		code = fxEOF
+	case errors.Is(err, fs.ErrNotExist):
+		code, message = fxNoSuchFile, "No such file"
+	case errors.Is(err, fs.ErrPermission):
+		code, message = fxPermissionDenied, "Permission denied"
+	default:
+		code, message = 4, err.Error()
+	}
+	return newPacket(fxpStatus).uint32(id).uint32(code).string(message).string("").frame()
+}
+
+// attrs дописывает ATTRS файла в пакет
+func attrs(p *packetBuilder, info os.FileInfo) *packetBuilder {
+	mode := uint32(info.Mode().Perm())
+	if info.IsDir() {
+		mode |= modeDir
+	} else {
+		mode |= 0100000
+	}
+	return p.uint32(attrSize | attrPermissions | attrACModTime).
+		uint64(uint64(info.Size())).
+		uint32(mode).
+		uint32(uint32(info.ModTime().Unix())).uint32(uint32(info.ModTime().Unix()))
+}
+
+func (s *fakeServer) handle(packetType byte, r *packetReader) []byte {
+	s.requests = append(s.requests, packetType)
+
+	if packetType == fxpInit {
+		version := newPacket(fxpVersion).uint32(protocolVersion)
+		if s.posixRename {
+			version.string(posixRenameExtension).string("1")
+		}
+		return version.frame()
+	}
+
+	id := r.uint32()
+	switch packetType {
+	case fxpRealpath:
+		name := filepath.ToSlash(filepath.Clean("/" + r.string()))
+		return newPacket(fxpName).uint32(id).uint32(1).string(name).string(name).uint32(0).frame()
+
+	case fxpStat:
+		info, err := os.Stat(s.path(r.string()))
+		if err != nil {
+			return status(id, err)
+		}
+		return attrs(newPacket(fxpAttrs).uint32(id), info).frame()
+
+	case fxpOpen:
+		name, pflags := r.string(), r.uint32()
+		flags := 0
+		switch {
+		case pflags&fxfRead != 0 && pflags&fxfWrite != 0:
+			flags = os.O_RDWR
+		case pflags&fxfWrite != 0:
+			flags = os.O_WRONLY
+		}
+		if pflags&fxfCreat != 0 {
+			flags |= os.O_CREATE
+		}
+		if pflags&fxfTrunc != 0 {
+			flags |= os.O_TRUNC
+		}
+		file, err := os.OpenFile(s.path(name), flags, 0644)
+		if err != nil {
+			return status(id, err)
+		}
+		return newPacket(fxpHandle).uint32(id).string(s.newHandle(file, nil)).frame()
+
+	case fxpOpendir:
+		entries, err := os.ReadDir(s.path(r.string()))
+		if err != nil {
+			return status(id, err)
+		}
+		return newPacket(fxpHandle).uint32(id).string(s.newHandle(nil, entries)).frame()
+
+	case fxpReaddir:
+		handle := r.string()
+		entries, ok := s.dirs[handle]
+		if !ok {
+			return status(id, errors.New("bad handle"))
+		}
+		if len(entries) == 0 {
+			return status(id, io.EOF)
+		}
+		// По одной записи за ответ: клиент должен читать до SSH_FX_EOF
+		s.dirs[handle] = entries[1:]
+		info, err := entries[0].Info()
+		if err != nil {
+			return status(id, err)
+		}
+		p := newPacket(fxpName).uint32(id).uint32(1).string(info.Name()).string(info.Name())
+		return attrs(p, info).frame()
+
+	case fxpRead:
+		file, offset, length := s.handles[r.string()], r.uint64(), r.uint32()
+		buf := make([]byte, length)
+		n, err := file.ReadAt(buf, int64(offset))
+		if n == 0 {
+			return status(id, err)
+		}
+		return newPacket(fxpData).uint32(id).bytes(buf[:n]).frame()
+
+	case fxpWrite:
+		file, offset, data := s.handles[r.string()], r.uint64(), r.bytes()
+		_, err := file.WriteAt(data, int64(offset))
+		return status(id, err)
+
+	case fxpClose:
+		handle := r.string()
+		if file, ok := s.handles[handle]; ok {
+			delete(s.handles, handle)
+			return status(id, file.Close())
+		}
+		delete(s.dirs, handle)
+		return status(id, nil)
+
+	case fxpMkdir:
+		return status(id, os.Mkdir(s.path(r.string()), 0755))
+	case fxpRmdir, fxpRemove:
+		return status(id, os.Remove(s.path(r.string())))
+
+	case fxpRename:
+		oldPath, newPath := s.path(r.string()), s.path(r.string())
+		// SFTP v3 не разрешает замену существующего файла
+		if _, err := os.Stat(newPath); err == nil {
+			return status(id, fs.ErrExist)
+		}
+		return status(id, os.Rename(oldPath, newPath))
+
+	case fxpExtended:
+		if name := r.string(); name != posixRenameExtension || !s.posixRename {
+			return status(id, errors.New("unsupported "+name))
+		}
+		return status(id, os.Rename(s.path(r.string()), s.path(r.string())))
+	}
+
+	return status(id, errors.New("unsupported request "+strconv.Itoa(int(packetType))))
+}
+
+func (s *fakeServer) newHandle(file *os.File, entries []os.DirEntry) string {
+	s.next++
+	handle := strconv.Itoa(s.next)
+	if file != nil {
+		s.handles[handle] = file
+	} else {
+		s.dirs[handle] = entries
+	}
+	return handle
+}
+
+// count возвращает число запросов типа packetType
+func (s *fakeServer) count(packetType byte) int {
+	return bytes.Count(s.requests, []byte{packetType})
+}
+
+func writeFile(t *testing.T, name string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// payload возвращает содержимое в несколько блоков передачи
+func payload() []byte {
+	data := make([]byte, 3*chunkSize+123)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestClientInitExtensions(t *testing.T) {
+	client, _ := newTestClient(t, true)
+	if _, ok := client.extensions[posixRenameExtension]; !ok {
+		t.Errorf("extensions = %v, want %s", client.extensions, posixRenameExtension)
+	}
+}
+
+func TestClientDirectories(t *testing.T) {
+	client, server := newTestClient(t, false)
+
+	if err := client.Mkdir("/docs"); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	writeFile(t, server.path("/docs/a.txt"), []byte("hello"))
+	writeFile(t, server.path("/docs/b.txt"), nil)
+	if err := os.Mkdir(server.path("/docs/sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.ReadDir("/docs")
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	if len(entries) != 3 {
+		t.Fatalf("ReadDir returned %d entries, want 3", len(entries))
+	}
+	if entries[0].Name != "a.txt" || entries[0].Size != 5 || entries[0].IsDir() {
+		t.Errorf("entries[0] = %+v, want file a.txt of 5 bytes", entries[0])
+	}
+	if !entries[2].IsDir() || entries[2].Mode().Perm() != 0700 {
+		t.Errorf("entries[2] = %+v, want directory 0700", entries[2])
+	}
+	// Каталог закрывается после чтения
+	if len(server.dirs) != 0 {
+		t.Errorf("%d directory handles left open", len(server.dirs))
+	}
+
+	if real, err := client.RealPath("/docs/sub/.."); err != nil || real != "/docs" {
+		t.Errorf("RealPath = %q, %v; want /docs", real, err)
+	}
+
+	if err := client.Remove("/docs/a.txt"); err != nil {
+		t.Errorf("Remove: %v", err)
+	}
+	if _, err := client.Stat("/docs/a.txt"); !IsNotExist(err) {
+		t.Errorf("Stat removed file: %v, want not exist", err)
+	}
+	if err := client.RemoveDir("/docs/sub"); err != nil {
+		t.Errorf("RemoveDir: %v", err)
+	}
+	if err := client.RemoveDir("/missing"); !IsNotExist(err) {
+		t.Errorf("RemoveDir missing: %v, want not exist", err)
+	}
+}
+
+func TestClientUploadResume(t *testing.T) {
+	client, server := newTestClient(t, true)
+	data := payload()
+	local := filepath.Join(t.TempDir(), "data.bin")
+	writeFile(t, local, data)
+
+	// Прерванная передача оставила начало файла в .part
+	writeFile(t, server.path("/data.bin"+PartSuffix), data[:chunkSize+10])
+
+	var progress []int64
+	err := client.Upload(context.Background(), local, "/data.bin", func(done, total int64) {
+		if total != int64(len(data)) {
+			t.Errorf("progress total = %d, want %d", total, len(data))
+		}
+		progress = append(progress, done)
+	})
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+
+	got, err := os.ReadFile(server.path("/data.bin"))
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("uploaded file differs (err %v, %d bytes)", err, len(got))
+	}
+	if _, err := os.Stat(server.path("/data.bin" + PartSuffix)); !os.IsNotExist(err) {
+		t.Errorf(".part file left after upload: %v", err)
+	}
+	if progress[0] != chunkSize+10 || progress[len(progress)-1] != int64(len(data)) {
+		t.Errorf("progress = %v, want from %d to %d", progress, chunkSize+10, len(data))
+	}
+	if got, want := server.count(fxpWrite), 3; got != want {
+		t.Errorf("%d write requests, want %d (only the missing part)", got, want)
+	}
+	if server.count(fxpExtended) != 1 {
+		t.Errorf("posix-rename was not used")
+	}
+}
+
+func TestClientUploadReplacesWithoutPosixRename(t *testing.T) {
+	client, server := newTestClient(t, false)
+	local := filepath.Join(t.TempDir(), "config")
+	writeFile(t, local, []byte("new"))
+	writeFile(t, server.path("/config"), []byte("old contents"))
+
+	if err := client.Upload(context.Background(), local, "/config", nil); err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if got, _ := os.ReadFile(server.path("/config")); string(got) != "new" {
+		t.Errorf("remote file = %q, want %q", got, "new")
+	}
+	if server.count(fxpExtended) != 0 || server.count(fxpRemove) != 1 {
+		t.Errorf("requests %v: want remove and plain rename", server.requests)
+	}
+}
+
+func TestClientUploadCanceledKeepsPart(t *testing.T) {
+	client, server := newTestClient(t, true)
+	data := payload()
+	local := filepath.Join(t.TempDir(), "data.bin")
+	writeFile(t, local, data)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := client.Upload(ctx, local, "/data.bin", func(done, total int64) {
+		if done >= chunkSize {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Upload error = %v, want context.Canceled", err)
+	}
+
+	part, err := os.ReadFile(server.path("/data.bin" + PartSuffix))
+	if err != nil || !bytes.Equal(part, data[:chunkSize]) {
+		t.Fatalf(".part = %d bytes (err %v), want the first block", len(part), err)
+	}
+	if _, err := os.Stat(server.path("/data.bin")); !os.IsNotExist(err) {
+		t.Errorf("destination exists after canceled upload: %v", err)
+	}
+	if len(server.handles) != 0 {
+		t.Errorf("%d file handles left open", len(server.handles))
+	}
+}
+
+func TestClientDownloadResume(t *testing.T) {
+	client, server := newTestClient(t, true)
+	data := payload()
+	writeFile(t, server.path("/data.bin"), data)
+
+	local := filepath.Join(t.TempDir(), "data.bin")
+	writeFile(t, local+PartSuffix, data[:2*chunkSize])
+
+	if err := client.Download(context.Background(), "/data.bin", local, nil); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	got, err := os.ReadFile(local)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("downloaded file differs (err %v, %d bytes)", err, len(got))
+	}
+	if _, err := os.Stat(local + PartSuffix); !os.IsNotExist(err) {
+		t.Errorf(".part file left after download: %v", err)
+	}
+	// Два оставшихся блока и ответ SSH_FX_EOF
+	if got, want := server.count(fxpRead), 3; got != want {
+		t.Errorf("%d read requests, want %d", got, want)
+	}
+}
+
+func TestClientDownloadErrors(t *testing.T) {
+	client, server := newTestClient(t, true)
+	local := filepath.Join(t.TempDir(), "out")
+
+	if err := client.Download(context.Background(), "/missing", local, nil); !IsNotExist(err) {
+		t.Errorf("Download missing file: %v, want not exist", err)
+	}
+	if err := os.Mkdir(server.path("/dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Download(context.Background(), "/dir", local, nil); err == nil {
+		t.Error("Download of a directory succeeded")
+	}
+}
+
+func TestClientClosedConnection(t *testing.T) {
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	go func() {
+		// Сервер отвечает на рукопожатие и обрывает соединение
+		var header [4]byte
+		io.ReadFull(serverReader, header[:])
+		io.ReadFull(serverReader, make([]byte, binary.BigEndian.Uint32(header[:])))
+		serverWriter.Write(newPacket(fxpVersion).uint32(protocolVersion).frame())
+		serverReader.Close()
+		serverWriter.Close()
+	}()
+
+	client := newClient(clientWriter, clientReader)
+	if err := client.init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := client.Stat("/"); err == nil {
+		t.Error("Stat on a closed connection succeeded")
+	}
+}
@@ -0,0 +1,220 @@
+package sftp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Типы пакетов SFTP v3 (draft-ietf-secsh-filexfer-02)
+const (
+	fxpInit     = 1
+	fxpVersion  = 2
+	fxpOpen     = 3
+	fxpClose    = 4
+	fxpRead     = 5
+	fxpWrite    = 6
+	fxpOpendir  = 11
+	fxpReaddir  = 12
+	fxpRemove   = 13
+	fxpMkdir    = 14
+	fxpRmdir    = 15
+	fxpRealpath = 16
+	fxpStat     = 17
+	fxpRename   = 18
+	fxpStatus   = 101
+	fxpHandle   = 102
+	fxpData     = 103
+	fxpName     = 104
+	fxpAttrs    = 105
+	fxpExtended = 200
+)
+
+// Флаги открытия файла
+const (
+	fxfRead  = 0x01
+	fxfWrite = 0x02
+	fxfCreat = 0x08
+	fxfTrunc = 0x10
+)
+
+// Флаги атрибутов
+const (
+	attrSize        = 0x01
+	attrUIDGID      = 0x02
+	attrPermissions = 0x04
+	attrACModTime   = 0x08
+	attrExtended    = 0x80000000
+)
+
+// Коды статуса
+const (
+	fxOK               = 0
+	fxEOF              = 1
+	fxNoSuchFile       = 2
+	fxPermissionDenied = 3
+)
+
+// Биты типа файла в st_mode
+const (
+	modeTypeMask = 0170000
+	modeDir      = 0040000
+	modeSymlink  = 0120000
+)
+
+// StatusError ошибка, возвращенная сервером в пакете SSH_FXP_STATUS
+type StatusError struct {
+	Code    uint32
+	Message string
+}
+
+// Error реализует интерфейс error
+func (e *StatusError) Error() string {
+	switch e.Code {
+	case fxNoSuchFile:
+		return "файл не найден"
+	case fxPermissionDenied:
+		return "доступ запрещен"
+	}
+	if e.Message != "" {
+		return fmt.Sprintf("sftp: %s (код %d)", e.Message, e.Code)
+	}
+	return fmt.Sprintf("sftp: ошибка с кодом %d", e.Code)
+}
+
+// IsNotExist проверяет, что ошибка означает отсутствие файла
+func IsNotExist(err error) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.Code == fxNoSuchFile
+}
+
+// isEOF проверяет, что сервер сообщил о конце файла или списка
+func isEOF(err error) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.Code == fxEOF
+}
+
+// FileAttrs атрибуты удаленного файла
+type FileAttrs struct {
+	Size        uint64
+	Permissions uint32
+	ModTime     time.Time
+}
+
+// Mode возвращает os.FileMode, соответствующий st_mode
+func (a FileAttrs) Mode() os.FileMode {
+	mode := os.FileMode(a.Permissions & 0777)
+	switch a.Permissions & modeTypeMask {
+	case modeDir:
+		mode |= os.ModeDir
+	case modeSymlink:
+		mode |= os.ModeSymlink
+	}
+	return mode
+}
+
+// packetBuilder собирает тело пакета
+type packetBuilder struct {
+	buf []byte
+}
+
+func newPacket(packetType byte) *packetBuilder {
+	return &packetBuilder{buf: []byte{packetType}}
+}
+
+func (p *packetBuilder) uint32(value uint32) *packetBuilder {
+	p.buf = binary.BigEndian.AppendUint32(p.buf, value)
+	return p
+}
+
+func (p *packetBuilder) uint64(value uint64) *packetBuilder {
+	p.buf = binary.BigEndian.AppendUint64(p.buf, value)
+	return p
+}
+
+func (p *packetBuilder) string(value string) *packetBuilder {
+	return p.bytes([]byte(value))
+}
+
+func (p *packetBuilder) bytes(value []byte) *packetBuilder {
+	p.uint32(uint32(len(value)))
+	p.buf = append(p.buf, value...)
+	return p
+}
+
+// frame возвращает пакет с префиксом длины
+func (p *packetBuilder) frame() []byte {
+	out := make([]byte, 4, 4+len(p.buf))
+	binary.BigEndian.PutUint32(out, uint32(len(p.buf)))
+	return append(out, p.buf...)
+}
+
+// packetReader разбирает тело пакета
+type packetReader struct {
+	buf []byte
+	err error
+}
+
+func (r *packetReader) uint32() uint32 {
+	if r.err != nil || len(r.buf) < 4 {
+		r.err = fmt.Errorf("sftp: пакет слишком короткий")
+		return 0
+	}
+	value := binary.BigEndian.Uint32(r.buf)
+	r.buf = r.buf[4:]
+	return value
+}
+
+func (r *packetReader) uint64() uint64 {
+	if r.err != nil || len(r.buf) < 8 {
+		r.err = fmt.Errorf("sftp: пакет слишком короткий")
+		return 0
+	}
+	value := binary.BigEndian.Uint64(r.buf)
+	r.buf = r.buf[8:]
+	return value
+}
+
+func (r *packetReader) bytes() []byte {
+	length := r.uint32()
+	if r.err != nil || uint32(len(r.buf)) < length {
+		r.err = fmt.Errorf("sftp: пакет слишком короткий")
+		return nil
+	}
+	value := r.buf[:length]
+	r.buf = r.buf[length:]
+	return value
+}
+
+func (r *packetReader) string() string {
+	return string(r.bytes())
+}
+
+// attrs читает структуру ATTRS
+func (r *packetReader) attrs() FileAttrs {
+	var attrs FileAttrs
+	flags := r.uint32()
+	if flags&attrSize != 0 {
+		attrs.Size = r.uint64()
+	}
+	if flags&attrUIDGID != 0 {
+		r.uint32()
+		r.uint32()
+	}
+	if flags&attrPermissions != 0 {
+		attrs.Permissions = r.uint32()
+	}
+	if flags&attrACModTime != 0 {
+		r.uint32()
+		attrs.ModTime = time.Unix(int64(r.uint32()), 0)
+	}
+	if flags&attrExtended != 0 {
+		count := r.uint32()
+		for i := uint32(0); i < count && r.err == nil; i++ {
+			r.string()
+			r.string()
+		}
+	}
+	return attrs
+}
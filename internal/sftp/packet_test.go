@@ -0,0 +1,134 @@
+package sftp
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPacketBuilderFrame(t *testing.T) {
+	got := newPacket(fxpOpen).uint32(7).string("/tmp/a").uint32(fxfRead).uint64(1 << 33).frame()
+	want := []byte{
+		0, 0, 0, 27, // длина тела
+		fxpOpen,
+		0, 0, 0, 7,
+		0, 0, 0, 6, '/', 't', 'm', 'p', '/', 'a',
+		0, 0, 0, fxfRead,
+		0, 0, 0, 2, 0, 0, 0, 0,
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("frame() = %v, want %v", got, want)
+	}
+}
+
+func TestPacketReaderRoundTrip(t *testing.T) {
+	body := newPacket(fxpData).uint32(42).uint64(1 << 40).string("имя").bytes([]byte{1, 2, 3}).buf[1:]
+	reader := &packetReader{buf: body}
+
+	if got := reader.uint32(); got != 42 {
+		t.Errorf("uint32() = %d, want 42", got)
+	}
+	if got := reader.uint64(); got != 1<<40 {
+		t.Errorf("uint64() = %d, want %d", got, uint64(1)<<40)
+	}
+	if got := reader.string(); got != "имя" {
+		t.Errorf("string() = %q, want %q", got, "имя")
+	}
+	if got := reader.bytes(); !bytes.Equal(got, []byte{1, 2, 3}) {
+		t.Errorf("bytes() = %v, want [1 2 3]", got)
+	}
+	if reader.err != nil || len(reader.buf) != 0 {
+		t.Errorf("err = %v, rest = %v; want full read without error", reader.err, reader.buf)
+	}
+}
+
+func TestPacketReaderShort(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+		read func(r *packetReader)
+	}{
+		{"uint32", []byte{0, 0, 1}, func(r *packetReader) { r.uint32() }},
+		{"uint64", []byte{0, 0, 0, 0, 1}, func(r *packetReader) { r.uint64() }},
+		{"string length", []byte{0, 0, 0, 5, 'a', 'b'}, func(r *packetReader) { r.string() }},
+		{"after error", []byte{0, 0, 0, 1}, func(r *packetReader) { r.uint64(); r.uint32() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &packetReader{buf: tt.buf}
+			tt.read(reader)
+			if reader.err == nil {
+				t.Fatal("expected error for short packet")
+			}
+		})
+	}
+}
+
+func TestPacketReaderAttrs(t *testing.T) {
+	modTime := time.Unix(1700000000, 0)
+	body := newPacket(fxpAttrs).
+		uint32(attrSize | attrUIDGID | attrPermissions | attrACModTime | attrExtended).
+		uint64(12345).
+		uint32(1000).uint32(1000).
+		uint32(modeDir | 0755).
+		uint32(1600000000).uint32(uint32(modTime.Unix())).
+		uint32(1).string("name").string("value").
+		buf[1:]
+
+	reader := &packetReader{buf: body}
+	attrs := reader.attrs()
+	if reader.err != nil {
+		t.Fatalf("attrs() error: %v", reader.err)
+	}
+	if len(reader.buf) != 0 {
+		t.Errorf("attrs() left %d bytes", len(reader.buf))
+	}
+	if attrs.Size != 12345 {
+		t.Errorf("Size = %d, want 12345", attrs.Size)
+	}
+	if !attrs.ModTime.Equal(modTime) {
+		t.Errorf("ModTime = %v, want %v", attrs.ModTime, modTime)
+	}
+	if mode := attrs.Mode(); !mode.IsDir() || mode.Perm() != 0755 {
+		t.Errorf("Mode() = %v, want directory 0755", mode)
+	}
+}
+
+func TestFileAttrsMode(t *testing.T) {
+	tests := []struct {
+		permissions uint32
+		want        os.FileMode
+	}{
+		{0100644, 0644},
+		{modeDir | 0700, os.ModeDir | 0700},
+		{modeSymlink | 0777, os.ModeSymlink | 0777},
+	}
+	for _, tt := range tests {
+		if got := (FileAttrs{Permissions: tt.permissions}).Mode(); got != tt.want {
+			t.Errorf("Mode(%o) = %v, want %v", tt.permissions, got, tt.want)
+		}
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	notFound := &StatusError{Code: fxNoSuchFile, Message: "No such file"}
+	if !IsNotExist(notFound) {
+		t.Error("IsNotExist(SSH_FX_NO_SUCH_FILE) = false")
+	}
+	if IsNotExist(&StatusError{Code: fxPermissionDenied}) {
+		t.Error("IsNotExist(SSH_FX_PERMISSION_DENIED) = true")
+	}
+	if !isEOF(&StatusError{Code: fxEOF}) {
+		t.Error("isEOF(SSH_FX_EOF) = false")
+	}
+
+	reader := &packetReader{buf: newPacket(fxpStatus).uint32(fxOK).string("").string("").buf[1:]}
+	if err := statusError(reader); err != nil {
+		t.Errorf("statusError(SSH_FX_OK) = %v, want nil", err)
+	}
+	reader = &packetReader{buf: newPacket(fxpStatus).uint32(4).string("failure").string("").buf[1:]}
+	if err := statusError(reader); err == nil || err.Error() != "sftp: failure (код 4)" {
+		t.Errorf("statusError(SSH_FX_FAILURE) = %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+package sftp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// chunkSize размер блока при передаче (безопасен для всех серверов SFTP v3)
+const chunkSize = 32 * 1024
+
+// PartSuffix суффикс незавершенного файла. Передача пишет во временный файл
+// <имя>.part и переименовывает его по окончании; повторная передача продолжает
+// с размера уже существующего .part файла
+const PartSuffix = ".part"
+
+// Progress вызывается по мере передачи: done - передано байт, total - размер файла
+type Progress func(done, total int64)
+
+// Upload копирует локальный файл на сервер с возможностью докачки.
+// При отмене ctx временный файл остается на сервере для продолжения
+func (c *Client) Upload(ctx context.Context, localPath, remotePath string, progress Progress) error {
+	local, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer local.Close()
+
+	info, err := local.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("передача каталогов не поддерживается")
+	}
+	total := info.Size()
+
+	partPath := remotePath + PartSuffix
+	var offset int64
+	if attrs, err := c.Stat(partPath); err == nil && int64(attrs.Size) <= total {
+		offset = int64(attrs.Size)
+	}
+
+	flags := uint32(fxfWrite | fxfCreat)
+	if offset == 0 {
+		flags |= fxfTrunc
+	}
+	handle, err := c.openFile(partPath, flags)
+	if err != nil {
+		return err
+	}
+
+	if _, err := local.Seek(offset, io.SeekStart); err != nil {
+		c.closeHandle(handle)
+		return err
+	}
+
+	buf := make([]byte, chunkSize)
+	done := offset
+	report(progress, done, total)
+
+	for done < total {
+		if err := ctx.Err(); err != nil {
+			c.closeHandle(handle)
+			return err
+		}
+
+		n, readErr := local.Read(buf)
+		if n > 0 {
+			if err := c.writeAt(handle, uint64(done), buf[:n]); err != nil {
+				c.closeHandle(handle)
+				return err
+			}
+			done += int64(n)
+			report(progress, done, total)
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			c.closeHandle(handle)
+			return readErr
+		}
+	}
+
+	if err := c.closeHandle(handle); err != nil {
+		return err
+	}
+
+	return c.PosixRename(partPath, remotePath)
+}
+
+// Download копирует файл с сервера с возможностью докачки.
+// При отмене ctx временный файл остается локально для продолжения
+func (c *Client) Download(ctx context.Context, remotePath, localPath string, progress Progress) error {
+	attrs, err := c.Stat(remotePath)
+	if err != nil {
+		return err
+	}
+	if attrs.Mode().IsDir() {
+		return fmt.Errorf("передача каталогов не поддерживается")
+	}
+	total := int64(attrs.Size)
+
+	partPath := localPath + PartSuffix
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil && info.Size() <= total {
+		offset = info.Size()
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE
+	if offset == 0 {
+		flags |= os.O_TRUNC
+	}
+	local, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer local.Close()
+
+	if _, err := local.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	handle, err := c.openFile(remotePath, fxfRead)
+	if err != nil {
+		return err
+	}
+
+	done := offset
+	report(progress, done, total)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			c.closeHandle(handle)
+			return err
+		}
+
+		data, err := c.readAt(handle, uint64(done), chunkSize)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.closeHandle(handle)
+			return err
+		}
+
+		if _, err := local.Write(data); err != nil {
+			c.closeHandle(handle)
+			return err
+		}
+		done += int64(len(data))
+		report(progress, done, total)
+	}
+
+	if err := c.closeHandle(handle); err != nil {
+		return err
+	}
+	if err := local.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(partPath, localPath)
+}
+
+// report вызывает progress, если он задан
+func report(progress Progress, done, total int64) {
+	if progress != nil {
+		progress(done, total)
+	}
+}
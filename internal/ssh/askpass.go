@@ -0,0 +1,134 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+const (
+	// askPassModeEnv помечает запуск ssh-keeper в роли SSH_ASKPASS программы
+	askPassModeEnv = "SSH_KEEPER_ASKPASS"
+	// askPassFileEnv путь к временному файлу с паролем для ssh
+	askPassFileEnv = "SSH_KEEPER_ASKPASS_FILE"
+	// askPassLifetime сколько живет файл с паролем
+	askPassLifetime = time.Minute
+)
+
+// askPassFiles файлы с паролями, которые еще не удалены
+var (
+	askPassMu    sync.Mutex
+	askPassFiles = make(map[string]bool)
+)
+
+// IsAskPassInvocation проверяет, запущен ли процесс как SSH_ASKPASS помощник
+func IsAskPassInvocation() bool {
+	return os.Getenv(askPassModeEnv) == "1"
+}
+
+// RunAskPass выводит пароль для ssh. Файл не удаляется: ssh может спросить пароль
+// еще раз (jump хост с паролем, повтор после отказа), а удаляет файл запустивший
+// ssh процесс. Вызывается из main до любой инициализации
+func RunAskPass() {
+	name := os.Getenv(askPassFileEnv)
+	if name == "" {
+		os.Exit(1)
+	}
+	password, err := os.ReadFile(name)
+	if err != nil {
+		os.Exit(1)
+	}
+	fmt.Println(string(password))
+}
+
+// ApplyAskPass настраивает команду так, чтобы ssh получил пароль без терминала.
+// ssh вызовет текущий исполняемый файл как SSH_ASKPASS программу (требуется
+// OpenSSH 8.4+ для SSH_ASKPASS_REQUIRE). Пароль не попадает в окружение: дочерние
+// процессы получают только путь к файлу 0600, который удаляется через askPassLifetime
+// или при завершении ssh-keeper
+func ApplyAskPass(cmd *exec.Cmd, password string) error {
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+
+	askPassEnv, err := AskPassEnv(password)
+	if err != nil {
+		return err
+	}
+
+	cmd.Env = append(env, askPassEnv...)
+	return nil
+}
+
+// AskPassEnv сохраняет пароль во временный файл и возвращает переменные окружения
+// KEY=VALUE, с которыми ssh запросит его у ssh-keeper
+func AskPassEnv(password string) ([]string, error) {
+	env, name, err := detachedAskPassEnv(password)
+	if err != nil {
+		return nil, err
+	}
+
+	askPassMu.Lock()
+	askPassFiles[name] = true
+	askPassMu.Unlock()
+	time.AfterFunc(askPassLifetime, func() { removeAskPassFile(name) })
+
+	return env, nil
+}
+
+// detachedAskPassEnv как AskPassEnv, но файл не удаляется при завершении ssh-keeper:
+// его удаляет тот, кто запускает ssh (например, скрипт панели tmux, которая
+// живет дольше ssh-keeper). Возвращает также путь к файлу
+func detachedAskPassEnv(password string) ([]string, string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, "", fmt.Errorf("не удалось определить путь к ssh-keeper: %w", err)
+	}
+
+	// CreateTemp создает файл с правами 0600
+	file, err := os.CreateTemp("", "ssh-keeper-askpass-")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create askpass file: %w", err)
+	}
+	_, err = file.WriteString(password)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, "", fmt.Errorf("failed to write askpass file: %w", err)
+	}
+
+	return []string{
+		"SSH_ASKPASS=" + executable,
+		"SSH_ASKPASS_REQUIRE=force",
+		askPassModeEnv + "=1",
+		askPassFileEnv + "=" + file.Name(),
+	}, file.Name(), nil
+}
+
+// RemoveAskPassFiles удаляет оставшиеся файлы с паролями.
+// Вызывается перед завершением ssh-keeper
+func RemoveAskPassFiles() {
+	askPassMu.Lock()
+	names := make([]string, 0, len(askPassFiles))
+	for name := range askPassFiles {
+		names = append(names, name)
+	}
+	askPassMu.Unlock()
+
+	for _, name := range names {
+		removeAskPassFile(name)
+	}
+}
+
+// removeAskPassFile удаляет файл с паролем
+func removeAskPassFile(name string) {
+	askPassMu.Lock()
+	delete(askPassFiles, name)
+	askPassMu.Unlock()
+	os.Remove(name)
+}
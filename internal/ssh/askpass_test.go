@@ -0,0 +1,53 @@
+package ssh
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunAskPassAnswersRepeatedPrompts(t *testing.T) {
+	env, err := AskPassEnv("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(RemoveAskPassFiles)
+	for _, entry := range env {
+		if name, value, _ := strings.Cut(entry, "="); name == askPassFileEnv {
+			t.Setenv(askPassFileEnv, value)
+		}
+	}
+
+	// ssh спрашивает пароль второй раз для jump хоста или после отказа
+	for attempt := 1; attempt <= 2; attempt++ {
+		if got := captureStdout(t, RunAskPass); got != "s3cret\n" {
+			t.Fatalf("prompt %d: RunAskPass printed %q, want the password", attempt, got)
+		}
+	}
+
+	RemoveAskPassFiles()
+	if _, err := os.Stat(os.Getenv(askPassFileEnv)); !os.IsNotExist(err) {
+		t.Errorf("askpass file left after RemoveAskPassFiles: %v", err)
+	}
+}
+
+// captureStdout возвращает то, что fn вывел в stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	fn()
+	os.Stdout = stdout
+	w.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
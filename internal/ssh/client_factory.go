@@ -1,11 +1,16 @@
 package ssh
 
-import "ssh-keeper/internal/models"
+import (
+	"io"
+
+	"ssh-keeper/internal/models"
+)
 
 // SSHClientInterface определяет интерфейс для SSH клиентов
 type SSHClientInterface interface {
 	Connect() error
 	GetConnectionString() string
+	SetRecorder(w io.Writer)
 }
 
 // ClientFactory создает соответствующий SSH клиент на основе типа аутентификации
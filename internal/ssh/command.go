@@ -0,0 +1,108 @@
+package ssh
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"ssh-keeper/internal/models"
+)
+
+// BuildSSHArgs строит аргументы ssh для интерактивной сессии с учетом типа аутентификации:
+// опции, адрес user@host и команда при входе, если она задана
+func BuildSSHArgs(conn *models.Connection) []string {
+	if conn.HasPassword {
+		return NewPasswordClient(conn).buildSSHArgs()
+	}
+	return NewKeyClient(conn).buildSSHArgs()
+}
+
+// BuildSSHOptions строит опции ssh для подключения без адреса user@host
+func BuildSSHOptions(conn *models.Connection) []string {
+	if conn.HasPassword {
+		return NewPasswordClient(conn).buildSSHOptions()
+	}
+	return NewKeyClient(conn).buildSSHOptions()
+}
+
+// Address возвращает адрес подключения в виде user@host
+func Address(conn *models.Connection) string {
+	return fmt.Sprintf("%s@%s", conn.User, conn.Host)
+}
+
+// SessionCommand возвращает команду, выполняемую при входе: переход в рабочий каталог
+// и команда подключения. Если задан только каталог, после перехода запускается
+// оболочка входа. Пустая строка - обычная сессия
+func SessionCommand(conn *models.Connection) string {
+	if conn.WorkDir == "" {
+		return conn.RemoteCommand
+	}
+
+	command := conn.RemoteCommand
+	if command == "" {
+		command = `exec "${SHELL:-/bin/sh}" -l`
+	}
+	return "cd " + remoteDirQuote(conn.WorkDir) + " && " + command
+}
+
+// remoteDirQuote экранирует каталог для удаленной оболочки, сохраняя раскрытие ~
+func remoteDirQuote(dir string) string {
+	switch {
+	case dir == "~":
+		return dir
+	case strings.HasPrefix(dir, "~/"):
+		return "~/" + shellQuote(dir[2:])
+	}
+	return shellQuote(dir)
+}
+
+// EnvOptions возвращает опции ssh для переменных окружения подключения:
+// KEY=VALUE передаются через SetEnv, имена и шаблоны - через SendEnv.
+// Сервер принимает только переменные, разрешенные в AcceptEnv
+func EnvOptions(conn *models.Connection) []string {
+	var setEnv, sendEnv []string
+	for _, entry := range conn.Env {
+		name, value, found := strings.Cut(entry, "=")
+		if !found {
+			sendEnv = append(sendEnv, name)
+			continue
+		}
+		if strings.ContainsAny(value, " \t") {
+			value = `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+		}
+		setEnv = append(setEnv, name+"="+value)
+	}
+
+	// ssh учитывает только первую строку SetEnv, поэтому все переменные в одной опции
+	var options []string
+	if len(setEnv) > 0 {
+		options = append(options, "-o", "SetEnv="+strings.Join(setEnv, " "))
+	}
+	if len(sendEnv) > 0 {
+		options = append(options, "-o", "SendEnv="+strings.Join(sendEnv, " "))
+	}
+	return options
+}
+
+// Command создает неинтерактивную команду ssh для подключения.
+// options добавляются перед адресом, remote - после него.
+// Сохраненный пароль передается через SSH_ASKPASS, для ключей включается BatchMode,
+// чтобы ssh не пытался читать терминал, занятый интерфейсом
+func Command(conn *models.Connection, options []string, remote ...string) (*exec.Cmd, error) {
+	args := append([]string{}, options...)
+	if !conn.HasPassword {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	args = append(args, BuildSSHOptions(conn)...)
+	args = append(args, Address(conn))
+	args = append(args, remote...)
+
+	cmd := exec.Command("ssh", args...)
+	if conn.HasPassword {
+		if err := ApplyAskPass(cmd, conn.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	return cmd, nil
+}
@@ -0,0 +1,100 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+// ControlDirName каталог сокетов ControlMaster в каталоге конфигурации
+const ControlDirName = "cm"
+
+// ControlPersist сколько мастер-соединение живет после закрытия последней сессии
+const ControlPersist = "10m"
+
+// controlDir каталог сокетов ControlMaster; пустой - общий канал не используется
+var controlDir string
+
+// SetControlDir задает каталог сокетов ControlMaster и создает его
+func SetControlDir(dir string) error {
+	// Сокеты дают доступ к уже авторизованному соединению - каталог только для владельца
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create control directory: %w", err)
+	}
+	controlDir = dir
+	return nil
+}
+
+// ControlOptions возвращает опции ssh для общего канала подключения.
+// Для подключений без ControlMaster возвращает nil
+func ControlOptions(conn *models.Connection) []string {
+	if !conn.ControlMaster || controlDir == "" {
+		return nil
+	}
+
+	// %C - хеш от локального хоста, хоста, порта, пользователя и jump хоста:
+	// короткий путь не упирается в ограничение длины пути unix сокета
+	return []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + filepath.Join(controlDir, "%C"),
+		"-o", "ControlPersist=" + ControlPersist,
+	}
+}
+
+// StartMaster запускает мастер-соединение в фоне. Последующие ssh, exec, scp и sftp
+// для этого подключения используют его без повторной авторизации
+func StartMaster(conn *models.Connection) error {
+	if ControlOptions(conn) == nil {
+		return fmt.Errorf("общий канал не включен для подключения %s", conn.Name)
+	}
+
+	// -o перед остальными опциями: ssh использует первое значение параметра
+	cmd, err := Command(conn, []string{"-f", "-N", "-o", "ControlMaster=yes", "-o", "ConnectTimeout=10"})
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// После -f ssh уходит в фон, унаследовав stderr: не ждем закрытия канала
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil && !errors.Is(err, exec.ErrWaitDelay) {
+		return fmt.Errorf("не удалось запустить мастер-соединение: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// CheckMaster проверяет, работает ли мастер-соединение подключения
+func CheckMaster(conn *models.Connection) bool {
+	if ControlOptions(conn) == nil {
+		return false
+	}
+	return controlCommand(conn, "check").Run() == nil
+}
+
+// StopMaster завершает мастер-соединение подключения
+func StopMaster(conn *models.Connection) error {
+	if ControlOptions(conn) == nil {
+		return nil
+	}
+
+	output, err := controlCommand(conn, "exit").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("не удалось остановить мастер-соединение: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// controlCommand создает команду управления мастер-соединением (ssh -O)
+func controlCommand(conn *models.Connection, operation string) *exec.Cmd {
+	args := append([]string{"-O", operation}, BuildSSHOptions(conn)...)
+	return exec.Command("ssh", append(args, Address(conn))...)
+}
@@ -0,0 +1,185 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+// useControlDir задает каталог сокетов на время теста
+func useControlDir(t *testing.T) string {
+	t.Helper()
+	previous := controlDir
+	t.Cleanup(func() { controlDir = previous })
+
+	dir := filepath.Join(t.TempDir(), ControlDirName)
+	if err := SetControlDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// recordSSHArgs подменяет ssh скриптом, который записывает аргументы по одному
+// в строке и завершается с кодом из $FAKE_SSH_EXIT
+func recordSSHArgs(t *testing.T) func() []string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("подменный ssh - скрипт sh")
+	}
+
+	dir := t.TempDir()
+	recorded := filepath.Join(dir, "args")
+	fake := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + shellQuote(recorded) + "\nexit ${FAKE_SSH_EXIT:-0}\n"
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_SSH_EXIT", "0")
+
+	return func() []string {
+		t.Helper()
+		data, err := os.ReadFile(recorded)
+		if err != nil {
+			t.Fatalf("ssh was not run: %v", err)
+		}
+		os.Remove(recorded)
+		return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+}
+
+func masterConnection() *models.Connection {
+	conn := models.NewConnection("web", "10.0.0.5", "deploy")
+	conn.UseSSHKey = true
+	conn.ControlMaster = true
+	return conn
+}
+
+func TestControlOptions(t *testing.T) {
+	conn := masterConnection()
+
+	previous := controlDir
+	controlDir = ""
+	if options := ControlOptions(conn); options != nil {
+		t.Errorf("ControlOptions without a control directory = %v, want nil", options)
+	}
+	controlDir = previous
+
+	dir := useControlDir(t)
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+		t.Fatalf("control directory: %v, %v; want mode 0700", info, err)
+	}
+
+	want := []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + filepath.Join(dir, "%C"),
+		"-o", "ControlPersist=10m",
+	}
+	if options := ControlOptions(conn); !slices.Equal(options, want) {
+		t.Errorf("ControlOptions = %q, want %q", options, want)
+	}
+	// Опции попадают в аргументы интерактивной сессии и команд
+	if args := BuildSSHOptions(conn); !containsSequence(args, want) {
+		t.Errorf("BuildSSHOptions = %q, want %q", args, want)
+	}
+
+	conn.ControlMaster = false
+	if options := ControlOptions(conn); options != nil {
+		t.Errorf("ControlOptions without ControlMaster = %v, want nil", options)
+	}
+}
+
+func TestStartMasterArgs(t *testing.T) {
+	dir := useControlDir(t)
+	recorded := recordSSHArgs(t)
+	conn := masterConnection()
+
+	if err := StartMaster(conn); err != nil {
+		t.Fatal(err)
+	}
+	args := recorded()
+
+	if !slices.Equal(args[:6], []string{"-f", "-N", "-o", "ControlMaster=yes", "-o", "ConnectTimeout=10"}) {
+		t.Errorf("args %q: want -f -N -o ControlMaster=yes first", args)
+	}
+	// ssh берет первое значение параметра: ControlMaster=yes перекрывает auto из ControlOptions
+	if yes, auto := slices.Index(args, "ControlMaster=yes"), slices.Index(args, "ControlMaster=auto"); auto >= 0 && auto < yes {
+		t.Errorf("args %q: ControlMaster=auto precedes ControlMaster=yes", args)
+	}
+	for _, want := range []string{"ControlPath=" + filepath.Join(dir, "%C"), "ControlPersist=10m", "BatchMode=yes"} {
+		if i := slices.Index(args, want); i < 1 || args[i-1] != "-o" {
+			t.Errorf("args %q: want -o %s", args, want)
+		}
+	}
+	if args[len(args)-1] != "deploy@10.0.0.5" {
+		t.Errorf("args %q: want the address last", args)
+	}
+
+	t.Setenv("FAKE_SSH_EXIT", "255")
+	if err := StartMaster(conn); err == nil {
+		t.Error("StartMaster: expected an error when ssh fails")
+	}
+
+	conn.ControlMaster = false
+	if err := StartMaster(conn); err == nil {
+		t.Error("StartMaster: expected an error for a connection without ControlMaster")
+	}
+}
+
+func TestCheckMasterArgs(t *testing.T) {
+	dir := useControlDir(t)
+	recorded := recordSSHArgs(t)
+	conn := masterConnection()
+	conn.Port = 2222
+
+	if !CheckMaster(conn) {
+		t.Error("CheckMaster = false, want true when ssh -O check succeeds")
+	}
+	args := recorded()
+	if !slices.Equal(args[:2], []string{"-O", "check"}) {
+		t.Errorf("args %q: want -O check first", args)
+	}
+	// ssh -O находит сокет по тем же ControlPath, порту и адресу, что и мастер
+	for _, want := range [][]string{
+		{"-o", "ControlPath=" + filepath.Join(dir, "%C")},
+		{"-p", "2222"},
+	} {
+		if !containsSequence(args, want) {
+			t.Errorf("args %q: want %q", args, want)
+		}
+	}
+	if args[len(args)-1] != "deploy@10.0.0.5" {
+		t.Errorf("args %q: want the address last", args)
+	}
+
+	if err := StopMaster(conn); err != nil {
+		t.Fatal(err)
+	}
+	if args := recorded(); !slices.Equal(args[:2], []string{"-O", "exit"}) {
+		t.Errorf("StopMaster args %q: want -O exit first", args)
+	}
+
+	t.Setenv("FAKE_SSH_EXIT", "255")
+	if CheckMaster(conn) {
+		t.Error("CheckMaster = true, want false when ssh -O check fails")
+	}
+
+	conn.ControlMaster = false
+	if CheckMaster(conn) {
+		t.Error("CheckMaster = true for a connection without ControlMaster")
+	}
+}
+
+// containsSequence проверяет, что want идет в args подряд
+func containsSequence(args, want []string) bool {
+	for i := 0; i+len(want) <= len(args); i++ {
+		if slices.Equal(args[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
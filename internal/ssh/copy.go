@@ -0,0 +1,120 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"ssh-keeper/internal/models"
+)
+
+// Бэкенды копирования файлов
+const (
+	CopyBackendAuto  = "auto"
+	CopyBackendSCP   = "scp"
+	CopyBackendRsync = "rsync"
+)
+
+// CopyOptions параметры копирования файлов
+type CopyOptions struct {
+	Recursive bool
+	Backend   string
+}
+
+// RemotePath формирует путь вида user@host:path для scp и rsync
+func RemotePath(conn *models.Connection, path string) string {
+	host := conn.Host
+	// IPv6 адрес нужно заключить в скобки, иначе двоеточия спутаются с разделителем пути
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return fmt.Sprintf("%s@%s:%s", conn.User, host, path)
+}
+
+// ResolveCopyBackend выбирает бэкенд: rsync, если он установлен, иначе scp
+func ResolveCopyBackend(backend string) (string, error) {
+	switch backend {
+	case "", CopyBackendAuto:
+		if _, err := exec.LookPath("rsync"); err == nil {
+			return CopyBackendRsync, nil
+		}
+		return CopyBackendSCP, nil
+	case CopyBackendRsync:
+		if _, err := exec.LookPath("rsync"); err != nil {
+			return "", fmt.Errorf("rsync не найден в PATH")
+		}
+		return CopyBackendRsync, nil
+	case CopyBackendSCP:
+		return CopyBackendSCP, nil
+	}
+	return "", fmt.Errorf("неизвестный бэкенд копирования: %s", backend)
+}
+
+// CopyCommand создает команду scp или rsync для копирования между локальной машиной
+// и сервером conn. Пути сервера должны быть сформированы через RemotePath.
+// Команда интерактивная: вывод прогресса и запросы парольной фразы ключа идут в терминал
+func CopyCommand(conn *models.Connection, sources []string, destination string, opts CopyOptions) (*exec.Cmd, error) {
+	backend, err := ResolveCopyBackend(opts.Backend)
+	if err != nil {
+		return nil, err
+	}
+
+	// Порт добавляется отдельно: у scp он задается через -P, у ssh - через -p
+	withoutPort := *conn
+	withoutPort.Port = 22
+	sshOptions := BuildSSHOptions(&withoutPort)
+
+	var args []string
+	switch backend {
+	case CopyBackendRsync:
+		// -ltp: ссылки, время и права; --partial позволяет докачать прерванную передачу
+		args = append(args, "-ltpz", "--partial", "--progress")
+		if opts.Recursive {
+			args = append(args, "-r")
+		}
+		if conn.Port != 22 {
+			sshOptions = append([]string{"-p", strconv.Itoa(conn.Port)}, sshOptions...)
+		}
+		args = append(args, "-e", "ssh "+joinShellArgs(sshOptions))
+	case CopyBackendSCP:
+		if opts.Recursive {
+			args = append(args, "-r")
+		}
+		if conn.Port != 22 {
+			args = append(args, "-P", strconv.Itoa(conn.Port))
+		}
+		args = append(args, sshOptions...)
+	}
+
+	// После -- локальный путь, начинающийся с "-", не будет принят за опцию
+	args = append(args, "--")
+	args = append(args, sources...)
+	args = append(args, destination)
+
+	cmd := exec.Command(backend, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if conn.HasPassword {
+		if err := ApplyAskPass(cmd, conn.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	return cmd, nil
+}
+
+// joinShellArgs собирает аргументы в строку для rsync -e, экранируя аргументы с пробелами
+func joinShellArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t'\"\\") {
+			arg = shellQuote(arg)
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,62 @@
+package ssh
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+func TestCopyCommandPort(t *testing.T) {
+	conn := models.NewConnection("web", "10.0.0.5", "deploy")
+	conn.Port = 2222
+	conn.UseSSHKey = true
+
+	cmd, err := CopyCommand(conn, []string{"-p"}, RemotePath(conn, "/tmp/"), CopyOptions{Backend: CopyBackendSCP})
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := cmd.Args[1:]
+	if i := slices.Index(args, "-P"); i < 0 || args[i+1] != "2222" {
+		t.Errorf("scp args %v: want -P 2222", args)
+	}
+	// Путь источника "-p" идет после -- и не принимается scp за опцию
+	operands := slices.Index(args, "--")
+	if operands < 0 || !slices.Equal(args[operands+1:], []string{"-p", RemotePath(conn, "/tmp/")}) {
+		t.Errorf("scp args %v: want -- before the operands", args)
+	}
+	if slices.Contains(args[:operands], "-p") {
+		t.Errorf("scp args %v: ssh -p leaked into the options", args)
+	}
+
+	conn.Port = 22
+	cmd, err = CopyCommand(conn, []string{"a"}, RemotePath(conn, "b"), CopyOptions{Backend: CopyBackendSCP})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(cmd.Args, "-P") || slices.Contains(cmd.Args, "-p") {
+		t.Errorf("scp args %v: no port option expected for port 22", cmd.Args)
+	}
+}
+
+func TestCopyCommandRsyncPort(t *testing.T) {
+	if _, err := ResolveCopyBackend(CopyBackendRsync); err != nil {
+		t.Skip(err)
+	}
+	conn := models.NewConnection("web", "10.0.0.5", "deploy")
+	conn.Port = 2222
+	conn.UseSSHKey = true
+
+	cmd, err := CopyCommand(conn, []string{"a"}, RemotePath(conn, "b"), CopyOptions{Backend: CopyBackendRsync})
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := slices.Index(cmd.Args, "-e")
+	if i < 0 || !strings.HasPrefix(cmd.Args[i+1], "ssh -p 2222 ") {
+		t.Errorf("rsync args %v: want -e \"ssh -p 2222 ...\"", cmd.Args)
+	}
+	if !slices.Equal(cmd.Args[len(cmd.Args)-3:], []string{"--", "a", RemotePath(conn, "b")}) {
+		t.Errorf("rsync args %v: want -- before the operands", cmd.Args)
+	}
+}
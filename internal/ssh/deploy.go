@@ -0,0 +1,123 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"ssh-keeper/internal/config"
+	"ssh-keeper/internal/models"
+
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+// keyPresentMarker выводится удаленным скриптом, если ключ уже был добавлен
+const keyPresentMarker = "SSH_KEEPER_KEY_PRESENT"
+
+// deployKeyScript добавляет ключ из stdin в authorized_keys, не создавая дубликатов.
+// Ключ сравнивается по типу и телу как по целым полям, комментарий не учитывается.
+// Закомментированные строки пропускаются, строка с опциями (restrict, command=)
+// считается уже установленным ключом
+const deployKeyScript = `umask 077
+mkdir -p "$HOME/.ssh" || exit 1
+touch "$HOME/.ssh/authorized_keys" || exit 1
+chmod 700 "$HOME/.ssh" && chmod 600 "$HOME/.ssh/authorized_keys" || exit 1
+read -r key
+set -- $key
+if awk -v t="$1" -v k="$2" '!/^[[:space:]]*#/ { for (i = 1; i < NF; i++) if ($i == t && $(i+1) == k) f = 1 } END { exit !f }' "$HOME/.ssh/authorized_keys"; then echo ` + keyPresentMarker + `; exit 0; fi
+if [ -s "$HOME/.ssh/authorized_keys" ] && [ -n "$(tail -c 1 "$HOME/.ssh/authorized_keys")" ]; then echo >> "$HOME/.ssh/authorized_keys"; fi
+printf "%s\n" "$key" >> "$HOME/.ssh/authorized_keys"`
+
+// DeployResult содержит результат копирования ключа на сервер
+type DeployResult struct {
+	AlreadyPresent bool
+}
+
+// DeployPublicKey входит на сервер по сохраненному паролю и добавляет публичный ключ
+// в ~/.ssh/authorized_keys (аналог ssh-copy-id)
+func DeployPublicKey(conn *models.Connection, publicKey string) (*DeployResult, error) {
+	publicKey = strings.TrimSpace(publicKey)
+	if publicKey == "" || strings.ContainsAny(publicKey, "\r\n") {
+		return nil, fmt.Errorf("некорректный публичный ключ")
+	}
+	if conn.Password == "" {
+		return nil, fmt.Errorf("для подключения %s не сохранен пароль", conn.Name)
+	}
+
+	args := []string{"-T", "-o", "ConnectTimeout=10", "-o", "NumberOfPasswordPrompts=1"}
+	args = append(args, NewPasswordClient(conn).buildSSHOptions()...)
+	args = append(args, Address(conn))
+	args = append(args, "sh -c "+shellQuote(deployKeyScript))
+
+	cmd := exec.Command("ssh", args...)
+	if err := ApplyAskPass(cmd, conn.Password); err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(publicKey + "\n")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("не удалось добавить ключ: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return &DeployResult{
+		AlreadyPresent: strings.Contains(stdout.String(), keyPresentMarker),
+	}, nil
+}
+
+// ErrKeyLoginUnverified вход по ключу нельзя проверить без ввода парольной фразы
+var ErrKeyLoginUnverified = errors.New("ключ защищен парольной фразой: вход по ключу нельзя проверить без ее ввода")
+
+// VerifyKeyLogin проверяет, что вход по ключу работает без пароля. Для ключа с
+// парольной фразой, которого нет в ssh-agent, возвращает ErrKeyLoginUnverified:
+// проверка идет с BatchMode=yes и не может запросить фразу
+func VerifyKeyLogin(conn *models.Connection, keyPath string) error {
+	keyConn := *conn
+	keyConn.KeyPath = keyPath
+	keyConn.UseSSHKey = true
+	keyConn.HasPassword = false
+	keyConn.Password = ""
+	// Проверка должна пройти авторизацию заново, а не через работающий общий канал
+	keyConn.ControlMaster = false
+
+	args := []string{"-T", "-o", "ConnectTimeout=10", "-o", "BatchMode=yes", "-o", "IdentitiesOnly=yes"}
+	args = append(args, NewKeyClient(&keyConn).buildSSHOptions()...)
+	args = append(args, Address(&keyConn))
+	args = append(args, "true")
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("ssh", args...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// Отказ для такого ключа не означает, что ключ не работает
+		if keyNeedsPassphrase(keyPath) {
+			return ErrKeyLoginUnverified
+		}
+		return fmt.Errorf("вход по ключу не удался: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// keyNeedsPassphrase проверяет, защищен ли приватный ключ парольной фразой
+func keyNeedsPassphrase(keyPath string) bool {
+	data, err := os.ReadFile(config.ExpandPath(keyPath))
+	if err != nil {
+		return false
+	}
+	_, err = cryptossh.ParseRawPrivateKey(data)
+	var missing *cryptossh.PassphraseMissingError
+	return errors.As(err, &missing)
+}
+
+// shellQuote экранирует строку для POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
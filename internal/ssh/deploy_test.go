@@ -0,0 +1,128 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+func TestKeyNeedsPassphrase(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := cryptossh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked, err := cryptossh.MarshalPrivateKeyWithPassphrase(key, "", []byte("phrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	tests := []struct {
+		name  string
+		block *pem.Block
+		want  bool
+	}{
+		{"plain", plain, false},
+		{"locked", locked, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(tt.block), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if got := keyNeedsPassphrase(path); got != tt.want {
+			t.Errorf("keyNeedsPassphrase(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if keyNeedsPassphrase(filepath.Join(dir, "missing")) {
+		t.Error("keyNeedsPassphrase(missing) = true")
+	}
+}
+
+// runDeployKeyScript выполняет deployKeyScript локально с HOME во временном каталоге
+func runDeployKeyScript(t *testing.T, authorized, key string) (present bool, result string) {
+	t.Helper()
+	home := t.TempDir()
+	if err := os.Mkdir(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(home, ".ssh", "authorized_keys")
+	if err := os.WriteFile(path, []byte(authorized), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("sh", "-c", deployKeyScript)
+	cmd.Env = append(os.Environ(), "HOME="+home)
+	cmd.Stdin = strings.NewReader(key + "\n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("deployKeyScript: %v: %s", err, output)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Contains(string(output), keyPresentMarker), string(data)
+}
+
+func TestDeployKeyScript(t *testing.T) {
+	const key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKeeper user@laptop"
+
+	tests := []struct {
+		name        string
+		authorized  string
+		wantPresent bool
+		want        string
+	}{
+		{
+			name:       "empty file",
+			authorized: "",
+			want:       key + "\n",
+		},
+		{
+			name:        "same key with another comment",
+			authorized:  "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKeeper other@host\n",
+			wantPresent: true,
+			want:        "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKeeper other@host\n",
+		},
+		{
+			name:       "commented-out key",
+			authorized: "# ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKeeper user@laptop\n",
+			want:       "# ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKeeper user@laptop\n" + key + "\n",
+		},
+		{
+			name:        "key with options",
+			authorized:  `restrict,command="uptime" ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKeeper user@laptop` + "\n",
+			wantPresent: true,
+			want:        `restrict,command="uptime" ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKeeper user@laptop` + "\n",
+		},
+		{
+			name:       "key body is a prefix of another key",
+			authorized: "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKeeperLonger other@host",
+			want:       "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKeeperLonger other@host\n" + key + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			present, got := runDeployKeyScript(t, tt.authorized, key)
+			if present != tt.wantPresent {
+				t.Errorf("present = %v, want %v", present, tt.wantPresent)
+			}
+			if got != tt.want {
+				t.Errorf("authorized_keys = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
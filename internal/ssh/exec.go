@@ -0,0 +1,138 @@
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+// DefaultExecConcurrency число серверов, на которых команда выполняется одновременно
+const DefaultExecConcurrency = 10
+
+// ExecResult итог выполнения команды на одном сервере
+type ExecResult struct {
+	ExitCode int
+	Err      error // Ошибка запуска ssh или отмена; код возврата команды хранится в ExitCode
+	Duration time.Duration
+}
+
+// Failed сообщает, завершилась ли команда неуспешно
+func (er ExecResult) Failed() bool {
+	return er.Err != nil || er.ExitCode != 0
+}
+
+// ExecEvent событие параллельного выполнения: строка вывода или завершение команды.
+// Index - индекс подключения в переданном списке
+type ExecEvent struct {
+	Index  int
+	Line   string
+	Stderr bool
+	Done   bool
+	Result ExecResult
+}
+
+// RunParallel выполняет команду на всех подключениях, не более limit одновременно.
+// Строки вывода и результаты отправляются в events; канал закрывается, когда
+// команда завершится на всех серверах. Отмена ctx прерывает запущенные ssh
+func RunParallel(ctx context.Context, conns []models.Connection, command string, limit int, events chan<- ExecEvent) {
+	defer close(events)
+
+	if limit <= 0 {
+		limit = DefaultExecConcurrency
+	}
+
+	semaphore := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i := range conns {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				events <- ExecEvent{Index: index, Done: true, Result: ExecResult{ExitCode: -1, Err: ctx.Err()}}
+				return
+			}
+			defer func() { <-semaphore }()
+
+			result := runRemote(ctx, &conns[index], command, func(line string, stderr bool) {
+				events <- ExecEvent{Index: index, Line: line, Stderr: stderr}
+			})
+			events <- ExecEvent{Index: index, Done: true, Result: result}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+// runRemote выполняет команду на одном сервере, построчно передавая вывод в onLine
+func runRemote(ctx context.Context, conn *models.Connection, command string, onLine func(line string, stderr bool)) ExecResult {
+	start := time.Now()
+
+	cmd, err := Command(conn, []string{"-T", "-o", "ConnectTimeout=10"}, command)
+	if err != nil {
+		return ExecResult{ExitCode: -1, Err: err}
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return ExecResult{ExitCode: -1, Err: err}
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return ExecResult{ExitCode: -1, Err: err}
+	}
+
+	if err := cmd.Start(); err != nil {
+		return ExecResult{ExitCode: -1, Err: err}
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		cmd.Process.Kill()
+	})
+	defer stop()
+
+	var streams sync.WaitGroup
+	streams.Add(2)
+	go scanLines(stdout, false, onLine, &streams)
+	go scanLines(stderr, true, onLine, &streams)
+	streams.Wait()
+
+	err = cmd.Wait()
+	result := ExecResult{Duration: time.Since(start)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		result.ExitCode = -1
+		result.Err = ctx.Err()
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		result.Err = err
+	}
+
+	return result
+}
+
+// scanLines читает поток построчно до конца
+func scanLines(reader io.Reader, stderr bool, onLine func(line string, stderr bool), wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		onLine(scanner.Text(), stderr)
+	}
+	// Дочитываем остаток, если строка оказалась длиннее буфера
+	io.Copy(io.Discard, reader)
+}
@@ -0,0 +1,146 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+// installFakeSSH кладет в PATH подменный ssh, который выполняет последний аргумент
+// (удаленную команду) локально. Возвращает каталог для отметок команд
+func installFakeSSH(t *testing.T) (state string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("подменный ssh - скрипт sh")
+	}
+
+	dir := t.TempDir()
+	state = filepath.Join(dir, "state")
+	if err := os.Mkdir(state, 0700); err != nil {
+		t.Fatal(err)
+	}
+	// exec: отмена завершает сам процесс команды, как завершила бы настоящий ssh
+	fake := "#!/bin/sh\nfor arg; do command=$arg; done\nexec sh -c \"$command\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return state
+}
+
+// execTestConnections создает подключения по ключу
+func execTestConnections(count int) []models.Connection {
+	conns := make([]models.Connection, count)
+	for i := range conns {
+		conn := models.NewConnection("host-"+strconv.Itoa(i), "10.0.0."+strconv.Itoa(i+1), "deploy")
+		conn.UseSSHKey = true
+		conns[i] = *conn
+	}
+	return conns
+}
+
+// collectExecEvents читает события до закрытия канала
+func collectExecEvents(events <-chan ExecEvent, count int) (lines [][]string, results []ExecResult) {
+	lines = make([][]string, count)
+	results = make([]ExecResult, count)
+	for event := range events {
+		switch {
+		case event.Done:
+			results[event.Index] = event.Result
+		case event.Stderr:
+			lines[event.Index] = append(lines[event.Index], "err:"+event.Line)
+		default:
+			lines[event.Index] = append(lines[event.Index], event.Line)
+		}
+	}
+	return lines, results
+}
+
+func TestRunParallelExitCodesAndOutput(t *testing.T) {
+	installFakeSSH(t)
+	conns := execTestConnections(2)
+
+	events := make(chan ExecEvent)
+	go RunParallel(context.Background(), conns[:1], "echo out; echo oops >&2; exit 3", 0, events)
+	lines, results := collectExecEvents(events, 1)
+
+	if results[0].ExitCode != 3 || results[0].Err != nil || !results[0].Failed() {
+		t.Errorf("result = %+v, want exit 3", results[0])
+	}
+	if strings.Join(lines[0], ",") != "out,err:oops" && strings.Join(lines[0], ",") != "err:oops,out" {
+		t.Errorf("lines = %q, want out and oops on stderr", lines[0])
+	}
+
+	events = make(chan ExecEvent)
+	go RunParallel(context.Background(), conns[1:], "true", 0, events)
+	if _, results := collectExecEvents(events, 1); results[0].Failed() {
+		t.Errorf("result = %+v, want success", results[0])
+	}
+}
+
+func TestRunParallelLimit(t *testing.T) {
+	state := installFakeSSH(t)
+	const limit = 2
+	conns := execTestConnections(6)
+
+	events := make(chan ExecEvent)
+	// Команда отмечает себя в каталоге и записывает, сколько команд выполняется сейчас
+	command := "cd " + shellQuote(state) + " && touch running.$$ && ls | grep -c '^running' >> counts; sleep 0.2; rm running.$$"
+	go RunParallel(context.Background(), conns, command, limit, events)
+	_, results := collectExecEvents(events, len(conns))
+	for i, result := range results {
+		if result.Failed() {
+			t.Errorf("connection %d: %+v", i, result)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(state, "counts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := strings.Fields(string(data))
+	if len(counts) != len(conns) {
+		t.Fatalf("ssh started %d times, want %d", len(counts), len(conns))
+	}
+	for _, count := range counts {
+		if n, _ := strconv.Atoi(count); n > limit {
+			t.Errorf("%d ssh processes ran at once, limit is %d", n, limit)
+		}
+	}
+}
+
+func TestRunParallelCancel(t *testing.T) {
+	state := installFakeSSH(t)
+	conns := execTestConnections(3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan ExecEvent)
+	// Лимит 1: первая команда запущена, остальные ждут очереди
+	go RunParallel(ctx, conns, "echo started >> "+shellQuote(filepath.Join(state, "counts"))+"; exec sleep 10", 1, events)
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, results := collectExecEvents(events, len(conns))
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RunParallel returned %v after cancel", elapsed)
+	}
+	for i, result := range results {
+		if !errors.Is(result.Err, context.Canceled) || result.ExitCode != -1 {
+			t.Errorf("connection %d: %+v, want canceled", i, result)
+		}
+	}
+
+	// Ожидавшие в очереди команды не запускались
+	data, _ := os.ReadFile(filepath.Join(state, "counts"))
+	if started := len(strings.Fields(string(data))); started != 1 {
+		t.Errorf("ssh started %d times, want 1", started)
+	}
+}
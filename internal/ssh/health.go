@@ -0,0 +1,153 @@
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+// Параметры проверки доступности по умолчанию
+const (
+	DefaultHealthWorkers = 20
+	DefaultHealthTimeout = 3 * time.Second
+)
+
+// HealthStatus состояние доступности сервера
+type HealthStatus int
+
+const (
+	HealthUnknown HealthStatus = iota
+	HealthChecking
+	HealthOnline
+	HealthOffline
+)
+
+// HealthResult результат проверки доступности одного подключения
+type HealthResult struct {
+	Status    HealthStatus
+	Latency   time.Duration // Время установки TCP соединения
+	Banner    string        // Строка идентификации SSH сервера, если удалось прочитать
+	ViaJump   bool          // Проверялся jump хост, а не сам сервер
+	Err       error
+	CheckedAt time.Time
+}
+
+// HealthEvent результат проверки подключения с указанным ID
+type HealthEvent struct {
+	ID     string
+	Result HealthResult
+}
+
+// ProbeAddress проверяет доступность сервера для подключения. Для подключений
+// через jump хост напрямую доступен только первый jump хост, поэтому проверяется он
+func ProbeAddress(conn *models.Connection) (string, bool) {
+	if conn.JumpHost == "" {
+		return net.JoinHostPort(conn.Host, strconv.Itoa(portOrDefault(conn.Port))), false
+	}
+
+	// ProxyJump: [user@]host[:port][,[user@]host[:port]...]
+	jump := strings.Split(conn.JumpHost, ",")[0]
+	if index := strings.LastIndex(jump, "@"); index >= 0 {
+		jump = jump[index+1:]
+	}
+	jump = strings.TrimPrefix(jump, "ssh://")
+	if host, port, err := net.SplitHostPort(jump); err == nil {
+		return net.JoinHostPort(host, port), true
+	}
+	return net.JoinHostPort(strings.Trim(jump, "[]"), "22"), true
+}
+
+// Probe устанавливает TCP соединение с address и, если readBanner, читает
+// строку идентификации SSH сервера
+func Probe(ctx context.Context, address string, timeout time.Duration, readBanner bool) HealthResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return HealthResult{Status: HealthOffline, Err: err, CheckedAt: time.Now()}
+	}
+	defer conn.Close()
+
+	result := HealthResult{
+		Status:    HealthOnline,
+		Latency:   time.Since(start),
+		CheckedAt: time.Now(),
+	}
+
+	if readBanner {
+		deadline, _ := ctx.Deadline()
+		conn.SetReadDeadline(deadline)
+		// Сервер может прислать строки до идентификации (RFC 4253, 4.2)
+		reader := bufio.NewReaderSize(conn, 256)
+		for i := 0; i < 5; i++ {
+			line, err := reader.ReadString('\n')
+			if strings.HasPrefix(line, "SSH-") {
+				result.Banner = strings.TrimRight(line, "\r\n")
+				break
+			}
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// ProbeAll проверяет подключения пулом из workers горутин. Результаты отправляются
+// в events по мере готовности; канал закрывается после проверки всех подключений
+func ProbeAll(ctx context.Context, conns []models.Connection, workers int, readBanner bool, events chan<- HealthEvent) {
+	defer close(events)
+
+	if workers <= 0 {
+		workers = DefaultHealthWorkers
+	}
+
+	jobs := make(chan *models.Connection)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for conn := range jobs {
+				address, viaJump := ProbeAddress(conn)
+				result := Probe(ctx, address, DefaultHealthTimeout, readBanner)
+				result.ViaJump = viaJump
+				select {
+				case events <- HealthEvent{ID: conn.ID, Result: result}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	for i := range conns {
+		select {
+		case jobs <- &conns[i]:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// portOrDefault возвращает порт или 22, если он не задан
+func portOrDefault(port int) int {
+	if port == 0 {
+		return 22
+	}
+	return port
+}
@@ -0,0 +1,145 @@
+package ssh
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"ssh-keeper/internal/models"
+)
+
+// listenSSH запускает локальный сервер, который присылает строку идентификации SSH
+func listenSSH(t *testing.T, banner string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte(banner))
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// closedPort возвращает адрес порта, на котором никто не слушает
+func closedPort(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	return address
+}
+
+func TestProbeAddress(t *testing.T) {
+	tests := []struct {
+		name        string
+		conn        models.Connection
+		wantAddress string
+		wantViaJump bool
+	}{
+		{"default port", models.Connection{Host: "10.0.0.5"}, "10.0.0.5:22", false},
+		{"custom port", models.Connection{Host: "10.0.0.5", Port: 2222}, "10.0.0.5:2222", false},
+		{"ipv6", models.Connection{Host: "2001:db8::1", Port: 22}, "[2001:db8::1]:22", false},
+		{"jump host", models.Connection{Host: "10.0.0.5", JumpHost: "bastion"}, "bastion:22", true},
+		{"jump host with user and port", models.Connection{Host: "10.0.0.5", JumpHost: "admin@bastion:2200"}, "bastion:2200", true},
+		{"jump chain", models.Connection{Host: "10.0.0.5", JumpHost: "ssh://admin@first:2200,second"}, "first:2200", true},
+		{"ipv6 jump host", models.Connection{Host: "10.0.0.5", JumpHost: "[2001:db8::2]"}, "[2001:db8::2]:22", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, viaJump := ProbeAddress(&tt.conn)
+			if address != tt.wantAddress || viaJump != tt.wantViaJump {
+				t.Errorf("ProbeAddress = %q, %v; want %q, %v", address, viaJump, tt.wantAddress, tt.wantViaJump)
+			}
+		})
+	}
+}
+
+func TestProbe(t *testing.T) {
+	// Строки до идентификации пропускаются (RFC 4253, 4.2)
+	online := listenSSH(t, "Welcome\r\nSSH-2.0-OpenSSH_9.6\r\n")
+
+	result := Probe(context.Background(), online, time.Second, true)
+	if result.Status != HealthOnline || result.Err != nil {
+		t.Fatalf("Probe(listener) = %+v, want online", result)
+	}
+	if result.Banner != "SSH-2.0-OpenSSH_9.6" {
+		t.Errorf("Banner = %q", result.Banner)
+	}
+
+	result = Probe(context.Background(), closedPort(t), time.Second, true)
+	if result.Status != HealthOffline || result.Err == nil {
+		t.Errorf("Probe(closed port) = %+v, want offline", result)
+	}
+}
+
+func TestProbeAll(t *testing.T) {
+	online := listenSSH(t, "SSH-2.0-test\r\n")
+	onlineHost, onlinePort, _ := net.SplitHostPort(online)
+	offlineHost, offlinePort, _ := net.SplitHostPort(closedPort(t))
+
+	port := func(value string) int {
+		p, _ := strconv.Atoi(value)
+		return p
+	}
+	conns := []models.Connection{
+		{ID: "online", Host: onlineHost, Port: port(onlinePort)},
+		{ID: "offline", Host: offlineHost, Port: port(offlinePort)},
+		{ID: "jump", Host: "unreachable.invalid", JumpHost: "deploy@" + online},
+	}
+
+	events := make(chan HealthEvent)
+	go ProbeAll(context.Background(), conns, 2, false, events)
+
+	results := make(map[string]HealthResult)
+	for event := range events {
+		results[event.ID] = event.Result
+	}
+	if len(results) != len(conns) {
+		t.Fatalf("got %d results, want %d", len(results), len(conns))
+	}
+	if results["online"].Status != HealthOnline {
+		t.Errorf("online: %+v", results["online"])
+	}
+	if results["offline"].Status != HealthOffline {
+		t.Errorf("offline: %+v", results["offline"])
+	}
+	// Через jump хост проверяется сам jump хост
+	if jump := results["jump"]; jump.Status != HealthOnline || !jump.ViaJump {
+		t.Errorf("jump: %+v", jump)
+	}
+}
+
+func TestProbeAllCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	events := make(chan HealthEvent)
+	go ProbeAll(ctx, []models.Connection{{ID: "a", Host: "127.0.0.1", Port: 1}}, 1, false, events)
+
+	done := make(chan struct{})
+	go func() {
+		for range events {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ProbeAll did not close events after cancel")
+	}
+}
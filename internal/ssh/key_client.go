@@ -2,16 +2,19 @@ package ssh
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 
+	"ssh-keeper/internal/config"
 	"ssh-keeper/internal/models"
 )
 
 // KeyClient представляет SSH клиент для аутентификации по ключу
 type KeyClient struct {
 	connection *models.Connection
+	recorder   io.Writer
 }
 
 // NewKeyClient создает новый SSH клиент для аутентификации по ключу
@@ -21,6 +24,11 @@ func NewKeyClient(conn *models.Connection) *KeyClient {
 	}
 }
 
+// SetRecorder устанавливает получателя копии вывода сессии
+func (kc *KeyClient) SetRecorder(w io.Writer) {
+	kc.recorder = w
+}
+
 // Connect устанавливает SSH подключение с использованием ключа
 func (kc *KeyClient) Connect() error {
 	// Восстанавливаем терминал перед запуском SSH
@@ -33,6 +41,8 @@ func (kc *KeyClient) Connect() error {
 	}
 	defer pty.Close()
 
+	pty.SetRecorder(kc.recorder)
+
 	// Строим команду SSH
 	args := kc.buildSSHArgs()
 	cmd := exec.Command("ssh", args...)
@@ -84,6 +94,27 @@ func (kc *KeyClient) restoreTerminal() {
 
 // buildSSHArgs строит аргументы для команды ssh с аутентификацией по ключу
 func (kc *KeyClient) buildSSHArgs() []string {
+	args := kc.buildSSHOptions()
+
+	// Команда при входе требует терминал на удаленной стороне
+	remote := SessionCommand(kc.connection)
+	if remote != "" {
+		args = append(args, "-t")
+	}
+
+	// Адрес подключения
+	address := fmt.Sprintf("%s@%s", kc.connection.User, kc.connection.Host)
+	args = append(args, address)
+
+	if remote != "" {
+		args = append(args, remote)
+	}
+
+	return args
+}
+
+// buildSSHOptions строит опции ssh без адреса подключения
+func (kc *KeyClient) buildSSHOptions() []string {
 	args := []string{}
 
 	// Отключаем проверку host key для тестирования
@@ -95,10 +126,21 @@ func (kc *KeyClient) buildSSHArgs() []string {
 		args = append(args, "-p", fmt.Sprintf("%d", kc.connection.Port))
 	}
 
+	// Jump host
+	if kc.connection.JumpHost != "" {
+		args = append(args, "-J", kc.connection.JumpHost)
+	}
+
+	// Общий канал: повторные подключения используют уже авторизованное соединение
+	args = append(args, ControlOptions(kc.connection)...)
+
+	// Переменные окружения
+	args = append(args, EnvOptions(kc.connection)...)
+
 	// SSH ключ
 	if kc.connection.KeyPath != "" {
 		// Получаем абсолютный путь к ключу
-		keyPath, err := filepath.Abs(kc.connection.KeyPath)
+		keyPath, err := filepath.Abs(config.ExpandPath(kc.connection.KeyPath))
 		if err == nil {
 			args = append(args, "-i", keyPath)
 		}
@@ -116,10 +158,6 @@ func (kc *KeyClient) buildSSHArgs() []string {
 	args = append(args, "-o", "PubkeyAuthentication=yes")
 	args = append(args, "-o", "PasswordAuthentication=no")
 
-	// Адрес подключения
-	address := fmt.Sprintf("%s@%s", kc.connection.User, kc.connection.Host)
-	args = append(args, address)
-
 	return args
 }
 
@@ -173,14 +211,19 @@ func (kc *KeyClient) GetConnectionString() string {
 		}
 	}
 
-	return fmt.Sprintf("ssh%s%s %s@%s", key, port, kc.connection.User, kc.connection.Host)
+	remote := ""
+	if command := SessionCommand(kc.connection); command != "" {
+		remote = " -t " + shellQuote(command)
+	}
+
+	return fmt.Sprintf("ssh%s%s %s@%s%s", key, port, kc.connection.User, kc.connection.Host, remote)
 }
 
 // GetAvailableKeys возвращает список доступных SSH ключей
 func (kc *KeyClient) GetAvailableKeys() []string {
 	if kc.connection.KeyPath != "" {
 		// Если указан конкретный ключ, возвращаем его
-		keyPath, err := filepath.Abs(kc.connection.KeyPath)
+		keyPath, err := filepath.Abs(config.ExpandPath(kc.connection.KeyPath))
 		if err == nil {
 			return []string{keyPath}
 		}
@@ -0,0 +1,265 @@
+package ssh
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"ssh-keeper/internal/models"
+)
+
+// Multiplexer терминальный мультиплексор для открытия нескольких сессий
+type Multiplexer string
+
+// Поддерживаемые мультиплексоры
+const (
+	MultiplexerTmux   Multiplexer = "tmux"
+	MultiplexerScreen Multiplexer = "screen"
+)
+
+// DefaultMultiplexSession имя сессии мультиплексора по умолчанию
+const DefaultMultiplexSession = "ssh-keeper"
+
+// MultiplexOptions параметры открытия подключений в мультиплексоре
+type MultiplexOptions struct {
+	Session     string // Имя сессии; в существующую сессию добавляются новые окна
+	Panes       bool   // Все подключения панелями одного окна вместо отдельных окон (только tmux)
+	Synchronize bool   // Ввод во все панели одновременно; включает режим панелей (только tmux)
+}
+
+// DetectMultiplexer выбирает tmux, а если его нет - GNU screen
+func DetectMultiplexer() (Multiplexer, error) {
+	for _, multiplexer := range []Multiplexer{MultiplexerTmux, MultiplexerScreen} {
+		if _, err := exec.LookPath(string(multiplexer)); err == nil {
+			return multiplexer, nil
+		}
+	}
+	return "", fmt.Errorf("не найден ни tmux, ни screen")
+}
+
+// InsideMultiplexer проверяет, запущен ли ssh-keeper внутри сессии мультиплексора.
+// В этом случае к сессии нужно переключаться, а не подключаться
+func InsideMultiplexer(multiplexer Multiplexer) bool {
+	if multiplexer == MultiplexerTmux {
+		return os.Getenv("TMUX") != ""
+	}
+	return os.Getenv("STY") != ""
+}
+
+// OpenMultiplexed открывает подключения окнами или панелями сессии мультиплексора.
+// Каждая панель запускает ту же команду ssh, что и обычное подключение.
+// Возвращает команду для перехода в сессию: ее нужно запустить с терминалом
+// (например, через tea.ExecProcess). Внутри tmux переход выполняется сразу и
+// возвращается nil
+func OpenMultiplexed(multiplexer Multiplexer, conns []models.Connection, opts MultiplexOptions) (*exec.Cmd, error) {
+	if len(conns) == 0 {
+		return nil, fmt.Errorf("не выбраны подключения")
+	}
+
+	session := sanitizeSessionName(opts.Session)
+
+	switch multiplexer {
+	case MultiplexerTmux:
+		return openTmux(session, conns, opts)
+	case MultiplexerScreen:
+		if opts.Synchronize {
+			return nil, fmt.Errorf("синхронный ввод в панели доступен только в tmux")
+		}
+		return openScreen(session, conns)
+	}
+	return nil, fmt.Errorf("неизвестный мультиплексор: %s", multiplexer)
+}
+
+// PaneCommand возвращает команду shell, запускающую ssh для подключения.
+// Для подключений по паролю создается файл для SSH_ASKPASS (его путь возвращается
+// вторым значением): панель удаляет его сама, так как живет дольше ssh-keeper
+func PaneCommand(conn *models.Connection) (string, string, error) {
+	script, askPassFile, err := paneScript(conn)
+	if err != nil {
+		return "", "", err
+	}
+	// Явный sh: оболочка по умолчанию у tmux может быть не POSIX (например, fish)
+	return "sh -c " + shellQuote(script), askPassFile, nil
+}
+
+// paneScript возвращает скрипт sh для панели. При ошибке подключения панель
+// не закрывается, чтобы было видно сообщение ssh. Сервер tmux или screen не
+// наследует окружение ssh-keeper, поэтому переменные SSH_ASKPASS задаются в самом
+// скрипте; в командной строке оказывается только путь к файлу, но не пароль
+func paneScript(conn *models.Connection) (string, string, error) {
+	script := "ssh " + joinShellArgs(BuildSSHArgs(conn)) +
+		` || { code=$?; printf '\n[ssh-keeper] ssh завершился с кодом %s, нажмите Enter' "$code"; read -r _; }`
+	if !conn.HasPassword {
+		return script, "", nil
+	}
+
+	env, askPassFile, err := detachedAskPassEnv(conn.Password)
+	if err != nil {
+		return "", "", err
+	}
+	// Обычно файл удаляет помощник при чтении; trap убирает его, если ssh не дошел
+	// до запроса пароля
+	return "trap " + shellQuote("rm -f "+shellQuote(askPassFile)) + " EXIT; " +
+		joinShellArgs(env) + " " + script, askPassFile, nil
+}
+
+// openTmux создает окна или панели в сессии tmux
+func openTmux(session string, conns []models.Connection, opts MultiplexOptions) (_ *exec.Cmd, err error) {
+	panes := opts.Panes || opts.Synchronize
+	exists := exec.Command("tmux", "has-session", "-t", "="+session).Run() == nil
+
+	// -P -F выводит идентификаторы созданного окна и панели
+	const format = "#{window_id} #{pane_id}"
+
+	// Если панель не создана, файл с паролем удалить больше некому
+	var pending string
+	defer func() {
+		if err != nil && pending != "" {
+			os.Remove(pending)
+		}
+	}()
+
+	var window string
+	for i := range conns {
+		conn := &conns[i]
+		command, askPassFile, err := PaneCommand(conn)
+		if err != nil {
+			return nil, err
+		}
+		pending = askPassFile
+
+		var args []string
+		switch {
+		case i == 0 && !exists:
+			args = []string{"new-session", "-d", "-P", "-F", format, "-s", session, "-n", windowName(conn, panes), command}
+		case i == 0 || !panes:
+			args = []string{"new-window", "-d", "-P", "-F", format, "-t", "=" + session + ":", "-n", windowName(conn, panes), command}
+		default:
+			args = []string{"split-window", "-d", "-P", "-F", format, "-t", window, command}
+		}
+
+		output, err := runTmux(args...)
+		if err != nil {
+			return nil, err
+		}
+		// Запущенная панель удалит файл сама
+		pending = ""
+
+		ids := strings.Fields(output)
+		if len(ids) != 2 {
+			return nil, fmt.Errorf("tmux вернул неожиданный ответ: %q", output)
+		}
+		if i == 0 {
+			window = ids[0]
+		}
+
+		// Заголовок панели - имя подключения
+		if _, err := runTmux("select-pane", "-t", ids[1], "-T", conn.Name); err != nil {
+			return nil, err
+		}
+
+		if panes && i > 0 {
+			// После каждого разделения выравниваем панели, иначе место быстро кончается
+			if _, err := runTmux("select-layout", "-t", window, "tiled"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if panes {
+		options := [][]string{
+			{"set-option", "-w", "-t", window, "pane-border-status", "top"},
+			{"set-option", "-w", "-t", window, "pane-border-format", " #{pane_title} "},
+		}
+		if opts.Synchronize {
+			options = append(options, []string{"set-option", "-w", "-t", window, "synchronize-panes", "on"})
+		}
+		for _, option := range options {
+			if _, err := runTmux(option...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err := runTmux("select-window", "-t", window); err != nil {
+		return nil, err
+	}
+
+	if InsideMultiplexer(MultiplexerTmux) {
+		_, err := runTmux("switch-client", "-t", "="+session)
+		return nil, err
+	}
+	return exec.Command("tmux", "attach-session", "-t", "="+session), nil
+}
+
+// openScreen создает окна в сессии GNU screen
+func openScreen(session string, conns []models.Connection) (*exec.Cmd, error) {
+	// -X к несуществующей сессии завершается с ошибкой
+	exists := exec.Command("screen", "-S", session, "-X", "select", ".").Run() == nil
+
+	for i := range conns {
+		conn := &conns[i]
+		script, askPassFile, err := paneScript(conn)
+		if err != nil {
+			return nil, err
+		}
+		command := []string{"sh", "-c", script}
+
+		var args []string
+		if i == 0 && !exists {
+			args = append([]string{"-dmS", session, "-t", conn.Name}, command...)
+		} else {
+			args = append([]string{"-S", session, "-X", "screen", "-t", conn.Name}, command...)
+		}
+
+		cmd := exec.Command("screen", args...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			// Окно не создано, файл с паролем удалить больше некому
+			if askPassFile != "" {
+				os.Remove(askPassFile)
+			}
+			return nil, fmt.Errorf("screen: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	// -x подключается к сессии, даже если она уже открыта в другом терминале
+	return exec.Command("screen", "-x", session), nil
+}
+
+// runTmux выполняет команду tmux и возвращает ее вывод
+func runTmux(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("tmux", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("tmux %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// windowName возвращает имя окна tmux: имя подключения или общее имя для окна с панелями
+func windowName(conn *models.Connection, panes bool) string {
+	if panes {
+		return "ssh-keeper"
+	}
+	return conn.Name
+}
+
+// sanitizeSessionName заменяет символы, которые tmux и screen используют как разделители
+func sanitizeSessionName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return DefaultMultiplexSession
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '.', ' ', '\t':
+			return '-'
+		}
+		return r
+	}, name)
+}
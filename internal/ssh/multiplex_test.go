@@ -0,0 +1,73 @@
+package ssh
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"ssh-keeper/internal/models"
+)
+
+func TestPaneScriptAskPass(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("панели tmux/screen запускаются через sh")
+	}
+
+	// Подменный ssh сохраняет пароль, который получил бы от SSH_ASKPASS
+	dir := t.TempDir()
+	received := filepath.Join(dir, "received")
+	fake := "#!/bin/sh\n" +
+		"[ \"$SSH_ASKPASS_REQUIRE\" = force ] || exit 3\n" +
+		"cat \"$SSH_KEEPER_ASKPASS_FILE\" > " + shellQuote(received) + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := models.NewConnection("web", "10.0.0.5", "deploy")
+	conn.HasPassword = true
+	conn.Password = "s3cr'et"
+
+	script, askPassFile, err := paneScript(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if askPassFile == "" {
+		t.Fatal("paneScript: no askpass file for a password connection")
+	}
+	if strings.Contains(script, conn.Password) {
+		t.Errorf("script %q contains the password", script)
+	}
+	if info, err := os.Stat(askPassFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("askpass file: %v, %v; want mode 0600", info, err)
+	}
+
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Env = append(os.Environ(), "PATH="+dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("pane script: %v: %s", err, output)
+	}
+
+	if data, err := os.ReadFile(received); err != nil || string(data) != conn.Password {
+		t.Errorf("ssh received %q, %v; want %q", data, err, conn.Password)
+	}
+	// Панель удаляет файл сама, даже если помощник его не прочитал
+	if _, err := os.Stat(askPassFile); !os.IsNotExist(err) {
+		t.Errorf("askpass file %s left after the pane exited", askPassFile)
+	}
+}
+
+func TestPaneScriptKey(t *testing.T) {
+	conn := models.NewConnection("web", "10.0.0.5", "deploy")
+	conn.UseSSHKey = true
+
+	script, askPassFile, err := paneScript(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if askPassFile != "" || strings.Contains(script, "SSH_ASKPASS") {
+		t.Errorf("key connection got askpass: %q, %q", script, askPassFile)
+	}
+}
@@ -2,9 +2,9 @@ package ssh
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"time"
 
 	"ssh-keeper/internal/models"
 )
@@ -12,6 +12,7 @@ import (
 // PasswordClient представляет SSH клиент для аутентификации по паролю
 type PasswordClient struct {
 	connection *models.Connection
+	recorder   io.Writer
 	password   string
 }
 
@@ -27,13 +28,18 @@ func (pc *PasswordClient) SetPassword(password string) {
 	pc.password = password
 }
 
+// SetRecorder устанавливает получателя копии вывода сессии
+func (pc *PasswordClient) SetRecorder(w io.Writer) {
+	pc.recorder = w
+}
+
 // Connect устанавливает SSH подключение с использованием пароля
 func (pc *PasswordClient) Connect() error {
-	// Всегда используем PTY с передачей пароля
+	// Всегда используем PTY, пароль передается через SSH_ASKPASS
 	return pc.ConnectWithPTY()
 }
 
-// ConnectWithPTY использует PTY для подключения с передачей пароля
+// ConnectWithPTY использует PTY для подключения, пароль передается через SSH_ASKPASS
 func (pc *PasswordClient) ConnectWithPTY() error {
 	// Восстанавливаем терминал перед запуском SSH
 	pc.restoreTerminal()
@@ -45,24 +51,26 @@ func (pc *PasswordClient) ConnectWithPTY() error {
 	}
 	defer pty.Close()
 
+	pty.SetRecorder(pc.recorder)
+
 	// Строим команду SSH
 	args := pc.buildSSHArgs()
 	cmd := exec.Command("ssh", args...)
 
+	// Пароль получает сам ssh через SSH_ASKPASS и только когда действительно его
+	// запрашивает: при повторном использовании мастер-соединения запроса нет,
+	// и пароль не должен попасть в удаленную оболочку
+	if pc.password != "" {
+		if err := ApplyAskPass(cmd, pc.password); err != nil {
+			return err
+		}
+	}
+
 	// Запускаем SSH в PTY с прямым подключением к стандартным потокам
 	if err := pty.StartSSHWithDirectPTY(cmd); err != nil {
 		return fmt.Errorf("ошибка запуска SSH: %w", err)
 	}
 
-	// Если есть пароль, передаем его в отдельной горутине
-	if pc.password != "" {
-		go func() {
-			// Увеличиваем задержку, чтобы SSH успел запросить пароль
-			time.Sleep(2 * time.Second)
-			pty.Write([]byte(pc.password + "\n"))
-		}()
-	}
-
 	// Ждем завершения SSH команды
 	err = cmd.Wait()
 
@@ -105,6 +113,27 @@ func (pc *PasswordClient) restoreTerminal() {
 
 // buildSSHArgs строит аргументы для команды ssh с аутентификацией по паролю
 func (pc *PasswordClient) buildSSHArgs() []string {
+	args := pc.buildSSHOptions()
+
+	// Команда при входе требует терминал на удаленной стороне
+	remote := SessionCommand(pc.connection)
+	if remote != "" {
+		args = append(args, "-t")
+	}
+
+	// Адрес подключения
+	address := fmt.Sprintf("%s@%s", pc.connection.User, pc.connection.Host)
+	args = append(args, address)
+
+	if remote != "" {
+		args = append(args, remote)
+	}
+
+	return args
+}
+
+// buildSSHOptions строит опции ssh без адреса подключения
+func (pc *PasswordClient) buildSSHOptions() []string {
 	args := []string{}
 
 	// Отключаем проверку host key для тестирования
@@ -116,15 +145,22 @@ func (pc *PasswordClient) buildSSHArgs() []string {
 		args = append(args, "-p", fmt.Sprintf("%d", pc.connection.Port))
 	}
 
+	// Jump host
+	if pc.connection.JumpHost != "" {
+		args = append(args, "-J", pc.connection.JumpHost)
+	}
+
+	// Общий канал: повторные подключения используют уже авторизованное соединение
+	args = append(args, ControlOptions(pc.connection)...)
+
+	// Переменные окружения
+	args = append(args, EnvOptions(pc.connection)...)
+
 	// Настройки аутентификации - только пароль
 	args = append(args, "-o", "PreferredAuthentications=password")
 	args = append(args, "-o", "PubkeyAuthentication=no")
 	args = append(args, "-o", "PasswordAuthentication=yes")
 
-	// Адрес подключения
-	address := fmt.Sprintf("%s@%s", pc.connection.User, pc.connection.Host)
-	args = append(args, address)
-
 	return args
 }
 
@@ -135,5 +171,10 @@ func (pc *PasswordClient) GetConnectionString() string {
 		port = fmt.Sprintf(" -p %d", pc.connection.Port)
 	}
 
-	return fmt.Sprintf("ssh%s %s@%s (password auth)", port, pc.connection.User, pc.connection.Host)
+	remote := ""
+	if command := SessionCommand(pc.connection); command != "" {
+		remote = " -t " + shellQuote(command)
+	}
+
+	return fmt.Sprintf("ssh%s %s@%s%s (password auth)", port, pc.connection.User, pc.connection.Host, remote)
 }
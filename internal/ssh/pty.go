@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 
 	"github.com/creack/pty"
 	"golang.org/x/crypto/ssh/terminal"
@@ -12,8 +13,12 @@ import (
 
 // PTY представляет псевдо-терминал
 type PTY struct {
-	pty *os.File
-	tty *os.File
+	pty        *os.File
+	tty        *os.File
+	recorder   io.Writer
+	stdinState *terminal.State // Состояние stdin до перевода в raw режим
+	resize     chan os.Signal
+	resizeDone chan struct{} // Закрывается, когда горутина отслеживания размера завершилась
 }
 
 // NewPTY создает новый PTY
@@ -35,6 +40,12 @@ func NewPTY() (*PTY, error) {
 	}, nil
 }
 
+// SetRecorder устанавливает получателя копии вывода сессии (например, запись asciicast).
+// Ошибки записи не прерывают сессию
+func (p *PTY) SetRecorder(w io.Writer) {
+	p.recorder = w
+}
+
 // StartSSH запускает SSH команду в PTY
 func (p *PTY) StartSSH(sshCmd *exec.Cmd) error {
 	// Настраиваем стандартные потоки
@@ -72,10 +83,22 @@ func (p *PTY) StartSSHWithDirectPTY(sshCmd *exec.Cmd) error {
 		return err
 	}
 
+	// Нажатия (Ctrl+C, Ctrl+Z, стрелки) должны уходить на удаленную сторону как есть
+	p.makeStdinRaw()
+
+	// Размер PTY следует за размером терминала все время сессии
+	p.watchResize()
+
+	// Вывод сессии при необходимости дублируется в запись
+	var output io.Writer = os.Stdout
+	if p.recorder != nil {
+		output = io.MultiWriter(os.Stdout, ignoreErrorsWriter{p.recorder})
+	}
+
 	// Прямое подключение PTY к стандартным потокам без горутин
 	// Это блокирующий вызов, который будет работать до завершения SSH
 	go func() {
-		io.Copy(os.Stdout, p.pty)
+		io.Copy(output, p.pty)
 	}()
 	go func() {
 		io.Copy(p.pty, os.Stdin)
@@ -84,6 +107,52 @@ func (p *PTY) StartSSHWithDirectPTY(sshCmd *exec.Cmd) error {
 	return nil
 }
 
+// ignoreErrorsWriter не дает ошибке записи остановить копирование вывода сессии
+type ignoreErrorsWriter struct {
+	w io.Writer
+}
+
+// Write записывает данные, игнорируя ошибки
+func (iw ignoreErrorsWriter) Write(b []byte) (int, error) {
+	iw.w.Write(b)
+	return len(b), nil
+}
+
+// makeStdinRaw переводит stdin в raw режим, если это терминал.
+// Исходное состояние восстанавливается в Close
+func (p *PTY) makeStdinRaw() {
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return
+	}
+
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return
+	}
+	p.stdinState = state
+}
+
+// watchResize подстраивает размер PTY под терминал при каждом SIGWINCH
+func (p *PTY) watchResize() {
+	p.syncSize()
+
+	p.resize = make(chan os.Signal, 1)
+	p.resizeDone = make(chan struct{})
+	notifyResize(p.resize)
+	go func(resize <-chan os.Signal, done chan<- struct{}) {
+		defer close(done)
+		for range resize {
+			p.syncSize()
+		}
+	}(p.resize, p.resizeDone)
+}
+
+// syncSize копирует текущий размер терминала в PTY
+func (p *PTY) syncSize() {
+	pty.InheritSize(os.Stdout, p.pty)
+}
+
 // Read читает данные из PTY
 func (p *PTY) Read(b []byte) (int, error) {
 	return p.pty.Read(b)
@@ -94,8 +163,21 @@ func (p *PTY) Write(b []byte) (int, error) {
 	return p.pty.Write(b)
 }
 
-// Close закрывает PTY
+// Close закрывает PTY, прекращает отслеживание размера и восстанавливает stdin
 func (p *PTY) Close() error {
+	if p.resize != nil {
+		// После signal.Stop сигналы в канал не приходят; ждем горутину, чтобы она
+		// не изменила размер уже закрытого PTY
+		signal.Stop(p.resize)
+		close(p.resize)
+		<-p.resizeDone
+		p.resize = nil
+		p.resizeDone = nil
+	}
+	if p.stdinState != nil {
+		terminal.Restore(int(os.Stdin.Fd()), p.stdinState)
+		p.stdinState = nil
+	}
 	if p.tty != nil {
 		p.tty.Close()
 	}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package ssh
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize подписывает канал на сигнал изменения размера терминала (SIGWINCH)
+func notifyResize(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGWINCH)
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package ssh
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestPTYCloseStopsResizeWatcher(t *testing.T) {
+	p, err := NewPTY()
+	if err != nil {
+		t.Skip(err)
+	}
+	p.watchResize()
+	done := p.resizeDone
+
+	// Сигнал, пришедший перед закрытием, обрабатывается до закрытия PTY
+	p.resize <- syscall.SIGWINCH
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	default:
+		t.Fatal("resize goroutine still running after Close")
+	}
+	if p.resize != nil {
+		t.Error("resize channel left after Close")
+	}
+}
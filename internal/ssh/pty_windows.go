@@ -0,0 +1,8 @@
+//go:build windows
+
+package ssh
+
+import "os"
+
+// notifyResize ничего не делает: в Windows нет сигнала SIGWINCH
+func notifyResize(ch chan<- os.Signal) {}
@@ -3,14 +3,23 @@ package components
 import (
 	"fmt"
 	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/ssh"
 	"ssh-keeper/internal/ui/styles"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
 // ConnectionItem представляет элемент подключения для списка
 type ConnectionItem struct {
-	Connection models.Connection
+	Connection  models.Connection
+	Selected    bool             // Отмечен для групповой операции
+	Health      ssh.HealthResult // Результат последней проверки доступности
+	Sessions    int              // Число сессий по истории
+	LastUsed    time.Time        // Время последней сессии
+	MasterAlive bool             // Работает мастер-соединение ControlMaster
+	ReadOnly    bool             // Хост из ~/.ssh/config: изменяется только в самом файле
 }
 
 // NewConnectionItem создает новый элемент подключения
@@ -22,7 +31,15 @@ func NewConnectionItem(conn models.Connection) ConnectionItem {
 
 // Title возвращает заголовок элемента
 func (ci ConnectionItem) Title() string {
-	// Если название пустое, используем user@host
+	title := ci.name()
+	if ci.Selected {
+		return "✔ " + title
+	}
+	return title
+}
+
+// name возвращает название подключения, а если оно пустое - user@host
+func (ci ConnectionItem) name() string {
 	if ci.Connection.Name == "" {
 		return fmt.Sprintf("%s@%s", ci.Connection.User, ci.Connection.Host)
 	}
@@ -45,19 +62,102 @@ func (ci ConnectionItem) Description() string {
 		authIcon = "❓" // Неизвестно
 	}
 
-	return fmt.Sprintf("%s | %s | %s", hostInfo, userInfo, authIcon)
+	// Jump хост
+	if ci.Connection.JumpHost != "" {
+		hostInfo = fmt.Sprintf("%s ⇢ %s", ci.Connection.JumpHost, hostInfo)
+	}
+
+	description := fmt.Sprintf("%s | %s | %s", hostInfo, userInfo, authIcon)
+	if ci.Connection.Template {
+		// У шаблона обычно нет хоста: показываем только общие значения
+		description = fmt.Sprintf("📋 шаблон | %s | %s", userInfo, authIcon)
+	}
+	if ci.MasterAlive {
+		description += " ⇄"
+	}
+	if ci.ReadOnly {
+		description += " | ssh config, только чтение"
+	}
+	if health := ci.HealthLabel(); health != "" {
+		description = health + " | " + description
+	}
+
+	// Группа и теги
+	labels := make([]string, 0, len(ci.Connection.Tags)+1)
+	if ci.Connection.Group != "" {
+		labels = append(labels, "["+ci.Connection.Group+"]")
+	}
+	for _, tag := range ci.Connection.Tags {
+		labels = append(labels, "#"+tag)
+	}
+	if len(labels) > 0 {
+		description += " | " + strings.Join(labels, " ")
+	}
+
+	// Использование по истории сессий
+	if ci.Sessions > 0 {
+		description += fmt.Sprintf(" | %s ×%d", FormatAgo(ci.LastUsed), ci.Sessions)
+	}
+
+	return description
+}
+
+// HealthLabel возвращает краткое описание доступности сервера
+func (ci ConnectionItem) HealthLabel() string {
+	var label string
+	switch ci.Health.Status {
+	case ssh.HealthChecking:
+		return "… проверка"
+	case ssh.HealthOnline:
+		label = "● " + formatLatency(ci.Health.Latency)
+	case ssh.HealthOffline:
+		label = "✖ недоступен"
+	default:
+		return ""
+	}
+
+	if ci.Health.ViaJump {
+		label += " (jump)"
+	}
+	return label
+}
+
+// formatLatency форматирует задержку в миллисекундах
+func formatLatency(latency time.Duration) string {
+	if latency < time.Millisecond {
+		return "<1ms"
+	}
+	return fmt.Sprintf("%dms", latency.Milliseconds())
+}
+
+// FormatAgo форматирует время относительно текущего момента
+func FormatAgo(t time.Time) string {
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return "только что"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%d мин назад", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%d ч назад", int(elapsed.Hours()))
+	case elapsed < 30*24*time.Hour:
+		return fmt.Sprintf("%d дн назад", int(elapsed.Hours()/24))
+	}
+	return t.Format("02.01.2006")
 }
 
 // FilterValue возвращает значение для фильтрации
 func (ci ConnectionItem) FilterValue() string {
 	// Используем правильное название для поиска
-	title := ci.Title()
+	title := ci.name()
 
-	// Поиск по названию, хосту и пользователю
-	return fmt.Sprintf("%s %s %s",
+	// Поиск по названию, хосту, пользователю, группе и тегам
+	return fmt.Sprintf("%s %s %s %s %s",
 		title,
 		ci.Connection.Host,
-		ci.Connection.User)
+		ci.Connection.User,
+		ci.Connection.Group,
+		strings.Join(ci.Connection.Tags, " "))
 }
 
 // GetConnection возвращает подключение
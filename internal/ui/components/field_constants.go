@@ -3,9 +3,19 @@ package components
 // FieldNames константы для имен полей формы
 const (
 	FieldNameName     = "name"
+	FieldNameParent   = "parent"
+	FieldNameTemplate = "template"
 	FieldNameHost     = "host"
 	FieldNamePort     = "port"
 	FieldNameUser     = "user"
+	FieldNameJump     = "jump"
+	FieldNameGroup    = "group"
+	FieldNameTags     = "tags"
+	FieldNameCommand  = "remote_command"
+	FieldNameWorkDir  = "work_dir"
+	FieldNameEnv      = "env"
+	FieldNameRecord   = "record"
+	FieldNameControl  = "control_master"
 	FieldNameAuth     = "auth"
 	FieldNamePassword = "password"
 	FieldNameKey      = "key"
@@ -0,0 +1,191 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"ssh-keeper/internal/ui/styles"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// FileEntry элемент списка файлов
+type FileEntry struct {
+	Name    string
+	Size    int64
+	IsDir   bool
+	ModTime time.Time
+}
+
+// FilePane панель со списком файлов одного каталога
+type FilePane struct {
+	Title   string
+	Path    string
+	entries []FileEntry
+	cursor  int
+	offset  int
+}
+
+// NewFilePane создает новую панель файлов
+func NewFilePane(title string) *FilePane {
+	return &FilePane{Title: title}
+}
+
+// SetEntries устанавливает содержимое каталога (каталоги первыми, по алфавиту)
+func (fp *FilePane) SetEntries(path string, entries []FileEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+
+	// Сохраняем позицию курсора при обновлении того же каталога
+	if path != fp.Path {
+		fp.cursor = 0
+		fp.offset = 0
+	}
+	fp.Path = path
+	fp.entries = entries
+	if fp.cursor >= len(entries) {
+		fp.cursor = len(entries) - 1
+	}
+	if fp.cursor < 0 {
+		fp.cursor = 0
+	}
+}
+
+// Selected возвращает выбранный элемент
+func (fp *FilePane) Selected() (FileEntry, bool) {
+	if fp.cursor < 0 || fp.cursor >= len(fp.entries) {
+		return FileEntry{}, false
+	}
+	return fp.entries[fp.cursor], true
+}
+
+// MoveCursor перемещает курсор на delta позиций
+func (fp *FilePane) MoveCursor(delta int) {
+	fp.cursor += delta
+	if fp.cursor >= len(fp.entries) {
+		fp.cursor = len(fp.entries) - 1
+	}
+	if fp.cursor < 0 {
+		fp.cursor = 0
+	}
+}
+
+// Render отрисовывает панель заданного размера
+func (fp *FilePane) Render(width, height int, active bool) string {
+	borderColor := styles.ColorGray
+	if active {
+		borderColor = styles.ColorPrimary
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(borderColor)).
+		Width(width).
+		Height(height)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorWarning)).
+		Bold(true)
+
+	dirStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorSecondary))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorText)).
+		Background(lipgloss.Color(borderColor))
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted))
+
+	lines := []string{
+		titleStyle.Render(truncateLeft(fp.Title+": "+fp.Path, width)),
+	}
+
+	rows := height - 1
+	if rows < 1 {
+		rows = 1
+	}
+
+	// Прокручиваем так, чтобы курсор был виден
+	if fp.cursor < fp.offset {
+		fp.offset = fp.cursor
+	}
+	if fp.cursor >= fp.offset+rows {
+		fp.offset = fp.cursor - rows + 1
+	}
+
+	if len(fp.entries) == 0 {
+		lines = append(lines, mutedStyle.Render("(пусто)"))
+	}
+
+	for i := fp.offset; i < len(fp.entries) && i < fp.offset+rows; i++ {
+		entry := fp.entries[i]
+
+		name := entry.Name
+		size := FormatSize(entry.Size)
+		if entry.IsDir {
+			name += "/"
+			size = "<DIR>"
+		}
+
+		nameWidth := width - len(size) - 1
+		if nameWidth < 1 {
+			nameWidth = 1
+		}
+		name = truncateRight(name, nameWidth)
+		line := name + strings.Repeat(" ", max(nameWidth-lipgloss.Width(name), 0)) + " " + size
+
+		switch {
+		case i == fp.cursor && active:
+			line = selectedStyle.Render(line)
+		case entry.IsDir:
+			line = dirStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return boxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// FormatSize форматирует размер файла в читаемом виде
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// truncateRight обрезает строку справа до ширины width
+func truncateRight(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// truncateLeft обрезает строку слева до ширины width (для длинных путей)
+func truncateLeft(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	if width <= 1 {
+		return string(runes[len(runes)-width:])
+	}
+	return "…" + string(runes[len(runes)-width+1:])
+}
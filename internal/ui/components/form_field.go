@@ -17,6 +17,7 @@ const (
 	FieldTypePassword
 	FieldTypeBool
 	FieldTypeButton
+	FieldTypeSelect
 )
 
 // FieldConfig содержит конфигурацию поля
@@ -28,7 +29,8 @@ type FieldConfig struct {
 	MaxLength   int
 	Placeholder string
 	FieldType   FieldType
-	Style       string // Стиль для кнопок: "default", "warning", "error", "success"
+	Style       string         // Стиль для кнопок: "default", "warning", "error", "success"
+	Options     []SelectOption // Варианты поля выбора
 }
 
 // FormField представляет универсальное поле формы
@@ -37,7 +39,9 @@ type FormField struct {
 	input       textinput.Model
 	boolField   *BoolField
 	buttonField *ButtonField
+	selectField *SelectField
 	value       string
+	hint        string // Подсказка справа от поля
 	hasError    bool
 	focused     bool
 	visible     bool
@@ -62,6 +66,9 @@ func NewFormField(config FieldConfig) *FormField {
 		if config.Style != "" {
 			field.buttonField.SetStyle(config.Style)
 		}
+	case FieldTypeSelect:
+		field.selectField = NewSelectField(config.Options)
+		field.selectField.SetWidth(config.Width)
 	default:
 		field.input = textinput.New()
 		field.input.Placeholder = config.Placeholder
@@ -87,6 +94,8 @@ func (ff *FormField) Update(msg interface{}) (interface{}, interface{}) {
 	switch ff.config.FieldType {
 	case FieldTypeBool:
 		return ff.boolField.Update(msg)
+	case FieldTypeSelect:
+		return ff.selectField.Update(msg)
 	default:
 		var cmd tea.Cmd
 		ff.input, cmd = ff.input.Update(msg)
@@ -102,6 +111,8 @@ func (ff *FormField) Focus() {
 		ff.boolField.Focus()
 	case FieldTypeButton:
 		ff.buttonField.Focus()
+	case FieldTypeSelect:
+		ff.selectField.Focus()
 	default:
 		ff.input.Focus()
 	}
@@ -115,6 +126,8 @@ func (ff *FormField) Blur() {
 		ff.boolField.Blur()
 	case FieldTypeButton:
 		ff.buttonField.Blur()
+	case FieldTypeSelect:
+		ff.selectField.Blur()
 	default:
 		ff.input.Blur()
 	}
@@ -130,6 +143,8 @@ func (ff *FormField) Value() string {
 		return "false"
 	case FieldTypeButton:
 		return ff.buttonField.Value()
+	case FieldTypeSelect:
+		return ff.selectField.Value()
 	default:
 		return ff.input.Value()
 	}
@@ -140,6 +155,8 @@ func (ff *FormField) SetValue(value string) {
 	switch ff.config.FieldType {
 	case FieldTypeBool:
 		ff.boolField.SetValue(value == "true")
+	case FieldTypeSelect:
+		ff.selectField.SetValue(value)
 	default:
 		ff.input.SetValue(value)
 	}
@@ -165,6 +182,16 @@ func (ff *FormField) SetVisible(visible bool) {
 	ff.visible = visible
 }
 
+// SetRequired задает, обязательно ли поле для заполнения
+func (ff *FormField) SetRequired(required bool) {
+	ff.config.Required = required
+}
+
+// SetHint устанавливает подсказку, которая выводится справа от поля
+func (ff *FormField) SetHint(hint string) {
+	ff.hint = hint
+}
+
 // IsVisible возвращает true если поле видимо
 func (ff *FormField) IsVisible() bool {
 	return ff.visible
@@ -245,6 +272,8 @@ func (ff *FormField) Render() string {
 		fieldContent = ff.boolField.View()
 	case FieldTypeButton:
 		fieldContent = ff.buttonField.View()
+	case FieldTypeSelect:
+		fieldContent = ff.selectField.View()
 	default:
 		fieldContent = fieldStyle.Render(ff.input.View())
 	}
@@ -258,6 +287,14 @@ func (ff *FormField) Render() string {
 		labelContent = labelStyle.Render(ff.config.Label + ":")
 	}
 
+	if ff.hint != "" {
+		hintStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(styles.ColorMuted)).
+			Italic(styles.TextItalic).
+			PaddingLeft(1)
+		fieldContent = lipgloss.JoinHorizontal(lipgloss.Center, fieldContent, hintStyle.Render(ff.hint))
+	}
+
 	if labelContent == "" {
 		return fieldContent
 	}
@@ -266,7 +303,7 @@ func (ff *FormField) Render() string {
 
 // GetTextInput возвращает textinput.Model для текстовых полей
 func (ff *FormField) GetTextInput() (textinput.Model, bool) {
-	if ff.config.FieldType == FieldTypeBool {
+	if ff.config.FieldType == FieldTypeBool || ff.config.FieldType == FieldTypeSelect {
 		return textinput.Model{}, false
 	}
 	return ff.input, true
@@ -274,7 +311,7 @@ func (ff *FormField) GetTextInput() (textinput.Model, bool) {
 
 // SetTextInput устанавливает textinput.Model для текстовых полей
 func (ff *FormField) SetTextInput(input textinput.Model) {
-	if ff.config.FieldType != FieldTypeBool {
+	if ff.config.FieldType != FieldTypeBool && ff.config.FieldType != FieldTypeSelect {
 		ff.input = input
 	}
 }
@@ -0,0 +1,60 @@
+package components
+
+import (
+	"fmt"
+	"path/filepath"
+	"ssh-keeper/internal/services"
+	"strings"
+)
+
+// KeyItem представляет SSH ключ в списке
+type KeyItem struct {
+	Key services.SSHKeyInfo
+}
+
+// NewKeyItem создает новый элемент ключа
+func NewKeyItem(key services.SSHKeyInfo) KeyItem {
+	return KeyItem{
+		Key: key,
+	}
+}
+
+// Title возвращает заголовок элемента
+func (ki KeyItem) Title() string {
+	title := filepath.Base(ki.Key.Path)
+	if ki.Key.Encrypted {
+		title += " 🔐"
+	}
+	if len(ki.Key.Warnings) > 0 || ki.Key.Error != "" {
+		title += " ⚠"
+	}
+	return title
+}
+
+// Description возвращает описание элемента
+func (ki KeyItem) Description() string {
+	if ki.Key.Error != "" {
+		return "не удалось прочитать ключ"
+	}
+	parts := []string{ki.Key.Type}
+	if ki.Key.Bits > 0 {
+		parts[0] = fmt.Sprintf("%s %d", ki.Key.Type, ki.Key.Bits)
+	}
+	if ki.Key.Fingerprint != "" {
+		parts = append(parts, ki.Key.Fingerprint)
+	}
+	if ki.Key.Comment != "" {
+		parts = append(parts, ki.Key.Comment)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// FilterValue возвращает значение для фильтрации
+func (ki KeyItem) FilterValue() string {
+	return fmt.Sprintf("%s %s %s", filepath.Base(ki.Key.Path), ki.Key.Type, ki.Key.Comment)
+}
+
+// GetKey возвращает сведения о ключе
+func (ki KeyItem) GetKey() services.SSHKeyInfo {
+	return ki.Key
+}
@@ -0,0 +1,96 @@
+package components
+
+import (
+	"ssh-keeper/internal/ui/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SelectOption вариант поля выбора
+type SelectOption struct {
+	Value string
+	Label string
+}
+
+// SelectField представляет компонент выбора одного варианта из списка (←/→)
+type SelectField struct {
+	options []SelectOption
+	index   int
+	focused bool
+	width   int
+}
+
+// NewSelectField создает новое поле выбора
+func NewSelectField(options []SelectOption) *SelectField {
+	return &SelectField{
+		options: options,
+		width:   30,
+	}
+}
+
+// SetValue выбирает вариант по значению
+func (sf *SelectField) SetValue(value string) {
+	for i, option := range sf.options {
+		if option.Value == value {
+			sf.index = i
+			return
+		}
+	}
+}
+
+// Value возвращает значение выбранного варианта
+func (sf *SelectField) Value() string {
+	if len(sf.options) == 0 {
+		return ""
+	}
+	return sf.options[sf.index].Value
+}
+
+// Focus устанавливает фокус
+func (sf *SelectField) Focus() {
+	sf.focused = true
+}
+
+// Blur убирает фокус
+func (sf *SelectField) Blur() {
+	sf.focused = false
+}
+
+// Update обрабатывает обновления
+func (sf *SelectField) Update(msg tea.Msg) (*SelectField, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && sf.focused && len(sf.options) > 0 {
+		switch msg.String() {
+		case "left", "h":
+			sf.index = (sf.index - 1 + len(sf.options)) % len(sf.options)
+		case "right", "l", " ":
+			sf.index = (sf.index + 1) % len(sf.options)
+		}
+	}
+	return sf, nil
+}
+
+// View возвращает строку для отрисовки
+func (sf *SelectField) View() string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(styles.ColorGray)).
+		Padding(0, 1).
+		Width(sf.width)
+	if sf.focused {
+		style = style.BorderForeground(lipgloss.Color(styles.ColorWarning))
+	}
+
+	arrowStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorMuted))
+	label := ""
+	if len(sf.options) > 0 {
+		label = sf.options[sf.index].Label
+	}
+
+	return style.Render(arrowStyle.Render("◀ ") + label + arrowStyle.Render(" ▶"))
+}
+
+// SetWidth устанавливает ширину компонента
+func (sf *SelectField) SetWidth(width int) {
+	sf.width = width
+}
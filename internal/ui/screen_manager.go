@@ -177,8 +177,10 @@ func (sm *ScreenManager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 		// Передаем NavigateToMsg новому экрану для обработки
+		// (экран может вернуть команду, например для асинхронной загрузки данных)
 		if newScreen := sm.GetCurrentScreen(); newScreen != nil {
-			newScreen.Update(msg)
+			_, cmd := newScreen.Update(msg)
+			return sm, cmd
 		}
 		return sm, nil
 	case GoBackMsg:
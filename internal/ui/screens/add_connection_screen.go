@@ -2,13 +2,11 @@ package screens
 
 import (
 	"fmt"
-	"ssh-keeper/internal/models"
 	"ssh-keeper/internal/services"
 	"ssh-keeper/internal/ssh"
 	"ssh-keeper/internal/ui"
 	"ssh-keeper/internal/ui/components"
 	"ssh-keeper/internal/ui/styles"
-	"strconv"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -59,6 +57,8 @@ func NewAddConnectionScreen() *AddConnectionScreen {
 		FieldType:   components.FieldTypeText,
 	})
 
+	addTemplateFields(formManager)
+
 	formManager.AddField(components.FieldConfig{
 		Name:        components.FieldNameHost,
 		Label:       "Хост",
@@ -89,6 +89,84 @@ func NewAddConnectionScreen() *AddConnectionScreen {
 		FieldType:   components.FieldTypeText,
 	})
 
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameJump,
+		Label:       "Jump хост",
+		Required:    false,
+		Width:       50,
+		MaxLength:   200,
+		Placeholder: "user@bastion:22 (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameGroup,
+		Label:       "Группа",
+		Required:    false,
+		Width:       50,
+		MaxLength:   50,
+		Placeholder: "prod, staging... (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameTags,
+		Label:       "Теги",
+		Required:    false,
+		Width:       50,
+		MaxLength:   200,
+		Placeholder: "web, db через запятую (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameCommand,
+		Label:       "Команда при входе",
+		Required:    false,
+		Width:       50,
+		MaxLength:   500,
+		Placeholder: "htop, tmux attach... (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameWorkDir,
+		Label:       "Рабочий каталог",
+		Required:    false,
+		Width:       50,
+		MaxLength:   200,
+		Placeholder: "~/app, /var/www (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameEnv,
+		Label:       "Переменные окружения",
+		Required:    false,
+		Width:       50,
+		MaxLength:   500,
+		Placeholder: "APP_ENV=prod, LC_* через запятую (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameRecord,
+		Label:       "Записывать сессии (←/→)",
+		Required:    false,
+		Width:       20,
+		Placeholder: "",
+		FieldType:   components.FieldTypeBool,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameControl,
+		Label:       "Общий канал ControlMaster (←/→)",
+		Required:    false,
+		Width:       20,
+		Placeholder: "",
+		FieldType:   components.FieldTypeBool,
+	})
+
 	formManager.AddField(components.FieldConfig{
 		Name:        components.FieldNameAuth,
 		Label:       "Использовать пароль (←/→)",
@@ -277,6 +355,9 @@ func (acs *AddConnectionScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Обновляем видимость полей на основе выбора аутентификации
 	acs.updateFieldVisibility()
 
+	// Отмечаем поля, унаследованные от шаблона
+	updateTemplateFields(acs.formManager, nil)
+
 	// Обновляем содержимое viewport
 	acs.updateViewportContent()
 
@@ -388,29 +469,20 @@ func (acs *AddConnectionScreen) saveConnection() tea.Cmd {
 	values := acs.formManager.GetValues()
 
 	// Создаем подключение
-	port := 22 // По умолчанию
-	if values[components.FieldNamePort] != "" {
-		if p, err := strconv.Atoi(values[components.FieldNamePort]); err == nil {
-			port = p
-		}
-	}
-
-	connection := &models.Connection{
-		ID:          uuid.New().String(),
-		Name:        values[components.FieldNameName],
-		Host:        values[components.FieldNameHost],
-		Port:        port,
-		User:        values[components.FieldNameUser],
-		KeyPath:     values[components.FieldNameKey],
-		UseSSHKey:   !(values[components.FieldNameAuth] == "true"), // Если не пароль, то SSH ключ
-		HasPassword: values[components.FieldNameAuth] == "true" && values[components.FieldNamePassword] != "",
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	connection := connectionFromForm(values)
+	connection.ID = uuid.New().String()
+	connection.CreatedAt = time.Now()
+	connection.UpdatedAt = time.Now()
+
+	// Связываем с шаблоном: пустые поля берутся из него
+	if _, err := applyFormTemplate(connection, values, nil); err != nil {
+		acs.messageManager.AddError(fmt.Sprintf("❌ Шаблон: %v", err))
+		return nil
 	}
 
-	// Добавляем пароль если используется
-	if connection.HasPassword {
-		connection.Password = values[components.FieldNamePassword]
+	// Проверяем указанный SSH ключ
+	if !validateConnectionKey(connection, acs.messageManager) {
+		return nil
 	}
 
 	// Сохраняем подключение
@@ -446,27 +518,15 @@ func (acs *AddConnectionScreen) testConnection() tea.Cmd {
 	// Получаем значения полей
 	values := acs.formManager.GetValues()
 
-	// Создаем подключение для тестирования
-	port := 22 // По умолчанию
-	if values[components.FieldNamePort] != "" {
-		if p, err := strconv.Atoi(values[components.FieldNamePort]); err == nil {
-			port = p
-		}
-	}
-
-	connection := &models.Connection{
-		Name:        values[components.FieldNameName],
-		Host:        values[components.FieldNameHost],
-		Port:        port,
-		User:        values[components.FieldNameUser],
-		KeyPath:     values[components.FieldNameKey],
-		UseSSHKey:   !(values[components.FieldNameAuth] == "true"), // Если не пароль, то SSH ключ
-		HasPassword: values[components.FieldNameAuth] == "true" && values[components.FieldNamePassword] != "",
+	// Создаем подключение для тестирования с учетом значений шаблона
+	connection := connectionFromForm(values)
+	if _, err := applyFormTemplate(connection, values, nil); err != nil {
+		acs.messageManager.AddError(fmt.Sprintf("❌ Шаблон: %v", err))
+		return nil
 	}
-
-	// Добавляем пароль если используется
-	if connection.HasPassword {
-		connection.Password = values[components.FieldNamePassword]
+	if connection.Host == "" {
+		acs.messageManager.AddError("❌ Для тестирования укажите хост")
+		return nil
 	}
 
 	// Тестируем подключение
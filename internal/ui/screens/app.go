@@ -1,6 +1,8 @@
 package screens
 
 import (
+	"fmt"
+
 	"ssh-keeper/internal/services"
 	"ssh-keeper/internal/ui"
 
@@ -10,6 +12,26 @@ import (
 // App представляет основное приложение SSH Keeper
 type App struct {
 	*ui.ScreenManager
+
+	syncing     bool // Выполняется фоновая синхронизация
+	syncAttempt int  // Номер попытки отправки текущей синхронизации
+}
+
+// syncFetchedMsg изменения репозитория получены в фоне
+type syncFetchedMsg struct {
+	err error
+}
+
+// syncPushedMsg результат отправки коммита синхронизации
+type syncPushedMsg struct {
+	result *services.SyncResult
+	err    error
+}
+
+// syncDoneMsg фоновая синхронизация завершена; передается текущему экрану
+type syncDoneMsg struct {
+	result *services.SyncResult
+	err    error
 }
 
 // NewApp создает новое приложение SSH Keeper с менеджером экранов
@@ -26,6 +48,10 @@ func NewApp() *App {
 	updatesScreen := NewUpdatesScreen()
 	exportScreen := NewExportScreen()
 	importScreen := NewImportScreen()
+	keysScreen := NewKeysScreen()
+	generateKeyScreen := NewGenerateKeyScreen()
+	historyScreen := NewHistoryScreen()
+	recordingsScreen := NewRecordingsScreen()
 
 	// Регистрируем экраны
 	manager.RegisterScreen("welcome", welcome)
@@ -36,11 +62,33 @@ func NewApp() *App {
 	manager.RegisterScreen("updates", updatesScreen)
 	manager.RegisterScreen("export", exportScreen)
 	manager.RegisterScreen("import", importScreen)
+	manager.RegisterScreen("keys", keysScreen)
+	manager.RegisterScreen("generate_key", generateKeyScreen)
+	manager.RegisterScreen("history", historyScreen)
+	manager.RegisterScreen("recordings", recordingsScreen)
 
 	// Регистрируем фабрики экранов (для динамического создания)
 	manager.RegisterScreenFactory("edit_connection", func() ui.Screen {
 		return NewEditConnectionScreenEmpty()
 	})
+	manager.RegisterScreenFactory("deploy_key", func() ui.Screen {
+		return NewDeployKeyScreen()
+	})
+	manager.RegisterScreenFactory("sftp", func() ui.Screen {
+		return NewSFTPScreen()
+	})
+	manager.RegisterScreenFactory("exec", func() ui.Screen {
+		return NewExecScreen()
+	})
+	manager.RegisterScreenFactory("multiplex", func() ui.Screen {
+		return NewMultiplexScreen()
+	})
+	manager.RegisterScreenFactory("bulk_edit", func() ui.Screen {
+		return NewBulkEditScreen()
+	})
+	manager.RegisterScreenFactory("import_preview", func() ui.Screen {
+		return NewImportPreviewScreen()
+	})
 
 	// Определяем начальный экран на основе состояния мастер-пароля и настроек
 	var initialScreen string
@@ -102,6 +150,30 @@ func CreateMenuWithActions() *MainMenuScreen {
 					return ui.NavigateToCmd("settings")
 				},
 			},
+			{
+				Title:       "SSH ключи",
+				Description: "Просмотр, генерация и проверка SSH ключей",
+				Shortcut:    "4",
+				Action: func() tea.Cmd {
+					return ui.NavigateToCmd("keys")
+				},
+			},
+			{
+				Title:       "История",
+				Description: "Недавние сессии и статистика по серверам",
+				Shortcut:    "5",
+				Action: func() tea.Cmd {
+					return ui.NavigateToCmd("history")
+				},
+			},
+			{
+				Title:       "Записи сессий",
+				Description: "Воспроизведение записанных сессий и настройка записи",
+				Shortcut:    "6",
+				Action: func() tea.Cmd {
+					return ui.NavigateToCmd("recordings")
+				},
+			},
 			// {
 			// 	Title:       "Справка",
 			// 	Description: "Помощь по использованию приложения",
@@ -129,7 +201,76 @@ func CreateMenuWithActions() *MainMenuScreen {
 
 // Update обрабатывает обновления состояния приложения
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	return a.ScreenManager.Update(msg)
+	switch msg := msg.(type) {
+	case syncFetchedMsg:
+		return a, a.syncFetched(msg)
+	case syncPushedMsg:
+		return a, a.syncPushed(msg)
+	}
+
+	_, cmd := a.ScreenManager.Update(msg)
+	return a, tea.Batch(cmd, a.startSync())
+}
+
+// startSync запускает синхронизацию изменений, сохраненных экраном. Сеть
+// используется только в фоне; одновременно выполняется одна синхронизация,
+// изменения, сохраненные во время нее, синхронизируются следующей
+func (a *App) startSync() tea.Cmd {
+	svc := services.GetGlobalConnectionService()
+	if a.syncing || svc == nil || !svc.TakeSyncPending() {
+		return nil
+	}
+	a.syncing = true
+	a.syncAttempt = 0
+	return fetchSync(svc.GitSync())
+}
+
+// fetchSync получает изменения репозитория в фоне
+func fetchSync(gitSync *services.GitSync) tea.Cmd {
+	return func() tea.Msg {
+		return syncFetchedMsg{err: gitSync.Fetch()}
+	}
+}
+
+// syncFetched объединяет полученные изменения с локальными и отправляет результат
+func (a *App) syncFetched(msg syncFetchedMsg) tea.Cmd {
+	if msg.err != nil {
+		return a.finishSync(nil, msg.err)
+	}
+
+	// Слияние меняет подключения сервиса, поэтому выполняется здесь, а не в фоне
+	result, push, err := services.GetGlobalConnectionService().SyncMerge()
+	if err != nil || push == nil {
+		return a.finishSync(result, err)
+	}
+	return func() tea.Msg {
+		return syncPushedMsg{result: result, err: push.Run()}
+	}
+}
+
+// syncPushed завершает синхронизацию или повторяет ее, если другая машина
+// успела отправить изменения раньше
+func (a *App) syncPushed(msg syncPushedMsg) tea.Cmd {
+	if msg.err == nil {
+		msg.result.Pushed = true
+		return a.finishSync(msg.result, nil)
+	}
+
+	a.syncAttempt++
+	if a.syncAttempt < services.SyncPushAttempts {
+		return fetchSync(services.GetGlobalConnectionService().GitSync())
+	}
+	return a.finishSync(nil, fmt.Errorf("не удалось отправить изменения: %w", msg.err))
+}
+
+// finishSync сообщает результат синхронизации текущему экрану и запускает
+// следующую, если за это время были сохранены новые изменения
+func (a *App) finishSync(result *services.SyncResult, err error) tea.Cmd {
+	a.syncing = false
+	services.GetGlobalConnectionService().SetSyncError(err)
+
+	_, cmd := a.ScreenManager.Update(syncDoneMsg{result: result, err: err})
+	return tea.Batch(cmd, a.startSync())
 }
 
 // View возвращает строку для отрисовки
@@ -0,0 +1,327 @@
+package screens
+
+import (
+	"fmt"
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/services"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"ssh-keeper/internal/ui/styles"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// bulkField поле формы групповой правки
+type bulkField struct {
+	label string
+	input textinput.Model
+}
+
+// Индексы полей групповой правки
+const (
+	bulkFieldUser = iota
+	bulkFieldPort
+	bulkFieldKey
+	bulkFieldGroup
+	bulkFieldTags
+	bulkFieldJump
+)
+
+// bulkPreview изменения одного подключения
+type bulkPreview struct {
+	connection models.Connection
+	changes    []models.FieldChange
+}
+
+// BulkEditScreen представляет экран групповой правки подключений
+type BulkEditScreen struct {
+	*BaseScreen
+	connections    []models.Connection
+	fields         []bulkField
+	focused        int
+	preview        []bulkPreview // Не nil - показывается предпросмотр изменений
+	offset         int           // Прокрутка предпросмотра
+	messageManager *components.MessageManager
+}
+
+// NewBulkEditScreen создает экран групповой правки (для фабрики)
+func NewBulkEditScreen() *BulkEditScreen {
+	newInput := func(placeholder string, limit int) textinput.Model {
+		input := textinput.New()
+		input.Placeholder = placeholder
+		input.CharLimit = limit
+		input.Width = 50
+		return input
+	}
+
+	screen := &BulkEditScreen{
+		BaseScreen: NewBaseScreen("SSH Keeper - Групповая правка"),
+		fields: []bulkField{
+			{label: "Пользователь", input: newInput("без изменений", 50)},
+			{label: "Порт", input: newInput("без изменений", 5)},
+			{label: "SSH ключ", input: newInput("путь к ключу; - ключи по умолчанию", 200)},
+			{label: "Группа", input: newInput("без изменений; - очистить", 50)},
+			{label: "Теги", input: newInput("a, b заменить; +a -b добавить/удалить; - очистить", 200)},
+			{label: "Jump хост", input: newInput("без изменений; - очистить", 200)},
+		},
+		messageManager: components.NewMessageManager(),
+	}
+	screen.fields[0].input.Focus()
+
+	return screen
+}
+
+// SetData устанавливает список подключений для правки
+func (bes *BulkEditScreen) SetData(data interface{}) {
+	connections, ok := data.([]models.Connection)
+	if !ok || len(connections) == 0 {
+		bes.messageManager.AddError("Ошибка: не выбраны подключения")
+		return
+	}
+
+	bes.connections = connections
+	bes.BaseScreen.SetTitle(fmt.Sprintf("SSH Keeper - Групповая правка (%d подкл.)", len(connections)))
+}
+
+// edit собирает правку из полей формы
+func (bes *BulkEditScreen) edit() (models.BulkEdit, error) {
+	value := func(field int) string {
+		return strings.TrimSpace(bes.fields[field].input.Value())
+	}
+
+	edit := models.BulkEdit{
+		User:     value(bulkFieldUser),
+		KeyPath:  value(bulkFieldKey),
+		Group:    value(bulkFieldGroup),
+		Tags:     value(bulkFieldTags),
+		JumpHost: value(bulkFieldJump),
+	}
+
+	if port := value(bulkFieldPort); port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil || p < 1 || p > 65535 {
+			return edit, fmt.Errorf("порт должен быть числом от 1 до 65535")
+		}
+		edit.Port = p
+	}
+
+	return edit, nil
+}
+
+// showPreview вычисляет изменения для всех подключений и показывает их
+func (bes *BulkEditScreen) showPreview() {
+	edit, err := bes.edit()
+	if err != nil {
+		bes.messageManager.AddError(fmt.Sprintf("❌ %v", err))
+		return
+	}
+	if edit.IsEmpty() {
+		bes.messageManager.AddWarning("Заполните хотя бы одно поле")
+		return
+	}
+
+	// Проверяем новый ключ так же, как при редактировании подключения
+	if edit.KeyPath != "" && edit.KeyPath != models.BulkClear {
+		if !validateConnectionKey(&models.Connection{UseSSHKey: true, KeyPath: edit.KeyPath}, bes.messageManager) {
+			return
+		}
+	}
+
+	bes.preview = make([]bulkPreview, 0, len(bes.connections))
+	for _, conn := range bes.connections {
+		changes := edit.Apply(&conn)
+		bes.preview = append(bes.preview, bulkPreview{connection: conn, changes: changes})
+	}
+	bes.offset = 0
+}
+
+// apply сохраняет изменения одной записью файла конфигурации
+func (bes *BulkEditScreen) apply() tea.Cmd {
+	var opened, changed []models.Connection
+	for i, preview := range bes.preview {
+		if len(preview.changes) > 0 {
+			opened = append(opened, bes.connections[i])
+			changed = append(changed, preview.connection)
+		}
+	}
+	bes.preview = nil
+
+	if len(changed) == 0 {
+		bes.messageManager.AddInfo("Нет изменений")
+		return nil
+	}
+
+	connectionSvc := services.GetGlobalConnectionService()
+	if connectionSvc == nil {
+		bes.messageManager.AddError("Ошибка: сервис подключений не инициализирован")
+		return nil
+	}
+	if err := connectionSvc.UpdateConnections(opened, changed); err != nil {
+		bes.messageManager.AddError(fmt.Sprintf("Ошибка сохранения: %v", err))
+		return nil
+	}
+
+	bes.messageManager.AddSuccess(fmt.Sprintf("Изменено подключений: %d", len(changed)))
+	return ui.NavigateToCmd("connections")
+}
+
+// focus переводит фокус на поле с индексом index
+func (bes *BulkEditScreen) focus(index int) {
+	bes.fields[bes.focused].input.Blur()
+	bes.focused = (index + len(bes.fields)) % len(bes.fields)
+	bes.fields[bes.focused].input.Focus()
+}
+
+// Update обрабатывает обновления состояния
+func (bes *BulkEditScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		bes.SetSize(msg.Width, msg.Height)
+		return bes, nil
+
+	case tea.KeyMsg:
+		if bes.preview != nil {
+			switch msg.String() {
+			case "ctrl+c":
+				return bes, tea.Quit
+			case "esc":
+				bes.preview = nil
+			case "enter", "ctrl+s":
+				return bes, bes.apply()
+			case "up", "k":
+				bes.offset = max(bes.offset-1, 0)
+			case "down", "j":
+				bes.offset++
+			}
+			return bes, nil
+		}
+
+		switch msg.String() {
+		case "ctrl+c":
+			return bes, tea.Quit
+		case "esc":
+			return bes, ui.GoBackCmd()
+		case "tab", "down":
+			bes.focus(bes.focused + 1)
+			return bes, nil
+		case "shift+tab", "up":
+			bes.focus(bes.focused - 1)
+			return bes, nil
+		case "enter", "ctrl+s":
+			bes.showPreview()
+			return bes, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	bes.fields[bes.focused].input, cmd = bes.fields[bes.focused].input.Update(msg)
+	return bes, cmd
+}
+
+// View возвращает строку для отрисовки
+func (bes *BulkEditScreen) View() string {
+	bes.updateContent()
+	return bes.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (bes *BulkEditScreen) updateContent() {
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted)).
+		Italic(styles.TextItalic).
+		Width(80)
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorSecondary)).
+		Bold(styles.TextBold).
+		Width(15)
+
+	var contentParts []string
+	if messages := bes.messageManager.RenderMessages(80); messages != "" {
+		contentParts = append(contentParts, messages)
+	}
+
+	if bes.preview != nil {
+		contentParts = append(contentParts, bes.renderPreview()...)
+		contentParts = append(contentParts, "",
+			instructionsStyle.Render("Enter применить • ↑/↓ прокрутка • Esc вернуться к правке"))
+		bes.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+		return
+	}
+
+	names := make([]string, 0, len(bes.connections))
+	for _, conn := range bes.connections {
+		names = append(names, conn.Name)
+	}
+	contentParts = append(contentParts,
+		instructionsStyle.UnsetItalic().Render("Подключения: "+strings.Join(names, ", ")), "")
+
+	for _, field := range bes.fields {
+		contentParts = append(contentParts, lipgloss.JoinHorizontal(lipgloss.Center,
+			labelStyle.Render(field.label+":"), field.input.View()))
+	}
+
+	contentParts = append(contentParts, "",
+		instructionsStyle.Render("Пустое поле - без изменений • Tab/↑/↓ поле • Enter предпросмотр • Esc назад"))
+
+	bes.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+}
+
+// renderPreview отрисовывает изменения по подключениям с учетом прокрутки
+func (bes *BulkEditScreen) renderPreview() []string {
+	nameStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorSecondary)).
+		Bold(styles.TextBold)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorMuted))
+	oldStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorError))
+	newStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorSuccess))
+
+	changed := 0
+	var lines []string
+	for _, preview := range bes.preview {
+		if len(preview.changes) == 0 {
+			lines = append(lines, nameStyle.Render(preview.connection.Name)+mutedStyle.Render(" - без изменений"))
+			continue
+		}
+
+		changed++
+		lines = append(lines, nameStyle.Render(preview.connection.Name))
+		for _, change := range preview.changes {
+			lines = append(lines, fmt.Sprintf("  %s: %s → %s",
+				change.Field, oldStyle.Render(orDash(change.Old)), newStyle.Render(orDash(change.New))))
+		}
+	}
+
+	// Оставляем место под заголовок, сообщения и инструкции
+	visible := max(bes.height-14, 5)
+	bes.offset = min(bes.offset, max(len(lines)-visible, 0))
+	end := min(bes.offset+visible, len(lines))
+
+	header := nameStyle.Render(fmt.Sprintf("Предпросмотр: изменится %d из %d подключений", changed, len(bes.preview)))
+	result := append([]string{header, ""}, lines[bes.offset:end]...)
+	if end < len(lines) {
+		result = append(result, mutedStyle.Render(fmt.Sprintf("… еще %d строк", len(lines)-end)))
+	}
+	return result
+}
+
+// orDash возвращает значение или прочерк для пустого значения
+func orDash(value string) string {
+	if value == "" {
+		return "—"
+	}
+	return value
+}
+
+// Init инициализирует экран
+func (bes *BulkEditScreen) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// GetName возвращает имя экрана
+func (bes *BulkEditScreen) GetName() string {
+	return "bulk_edit"
+}
@@ -0,0 +1,191 @@
+package screens
+
+import (
+	"fmt"
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/services"
+	"ssh-keeper/internal/ui/components"
+	"strconv"
+	"strings"
+)
+
+// templateFormFields соответствие полей формы наследуемым полям подключения
+var templateFormFields = map[string]string{
+	components.FieldNamePort:     models.InheritPort,
+	components.FieldNameUser:     models.InheritUser,
+	components.FieldNameJump:     models.InheritJumpHost,
+	components.FieldNameGroup:    models.InheritGroup,
+	components.FieldNameTags:     models.InheritTags,
+	components.FieldNameCommand:  models.InheritRemoteCommand,
+	components.FieldNameWorkDir:  models.InheritWorkDir,
+	components.FieldNameEnv:      models.InheritEnv,
+	components.FieldNameRecord:   models.InheritRecord,
+	components.FieldNameControl:  models.InheritControlMaster,
+	components.FieldNameAuth:     models.InheritAuth,
+	components.FieldNameKey:      models.InheritAuth,
+	components.FieldNamePassword: models.InheritAuth,
+}
+
+// templateTextFields текстовые поля, которые при пустом значении берутся из шаблона
+var templateTextFields = []string{
+	components.FieldNamePort,
+	components.FieldNameUser,
+	components.FieldNameJump,
+	components.FieldNameGroup,
+	components.FieldNameTags,
+	components.FieldNameCommand,
+	components.FieldNameWorkDir,
+	components.FieldNameEnv,
+}
+
+// connectionFromForm создает подключение из значений формы (без ID и шаблона)
+func connectionFromForm(values map[string]string) *models.Connection {
+	port := 22 // По умолчанию
+	if values[components.FieldNamePort] != "" {
+		if p, err := strconv.Atoi(values[components.FieldNamePort]); err == nil {
+			port = p
+		}
+	}
+
+	connection := &models.Connection{
+		Name:          values[components.FieldNameName],
+		Host:          values[components.FieldNameHost],
+		Port:          port,
+		User:          values[components.FieldNameUser],
+		JumpHost:      strings.TrimSpace(values[components.FieldNameJump]),
+		Group:         strings.TrimSpace(values[components.FieldNameGroup]),
+		Tags:          models.ParseTags(values[components.FieldNameTags]),
+		RemoteCommand: strings.TrimSpace(values[components.FieldNameCommand]),
+		WorkDir:       strings.TrimSpace(values[components.FieldNameWorkDir]),
+		Env:           models.ParseEnv(values[components.FieldNameEnv]),
+		Record:        values[components.FieldNameRecord] == "true",
+		ControlMaster: values[components.FieldNameControl] == "true",
+		KeyPath:       values[components.FieldNameKey],
+		UseSSHKey:     !(values[components.FieldNameAuth] == "true"), // Если не пароль, то SSH ключ
+		HasPassword:   values[components.FieldNameAuth] == "true" && values[components.FieldNamePassword] != "",
+	}
+
+	// Добавляем пароль если используется
+	if connection.HasPassword {
+		connection.Password = values[components.FieldNamePassword]
+	}
+
+	return connection
+}
+
+// findTemplate ищет шаблон по названию из формы. Пустое название - подключение без шаблона
+func findTemplate(name string) (*models.Connection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, nil
+	}
+
+	connectionSvc := services.GetGlobalConnectionService()
+	if connectionSvc == nil {
+		return nil, fmt.Errorf("connection service not initialized")
+	}
+	return connectionSvc.FindConnectionByName(name)
+}
+
+// applyFormTemplate связывает подключение с шаблоном, указанным в форме.
+// Очищенные текстовые поля и ключ/пароль при том же типе аутентификации берутся
+// из шаблона; поля, унаследованные в opened (подключение, с которым открыта форма)
+// и не измененные, наследуются и дальше
+func applyFormTemplate(conn *models.Connection, values map[string]string, opened *models.Connection) (*models.Connection, error) {
+	conn.Template = values[components.FieldNameTemplate] == "true"
+
+	parent, err := findTemplate(values[components.FieldNameParent])
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		conn.InheritFrom(nil, nil, nil)
+		return nil, nil
+	}
+
+	var cleared []string
+	for _, field := range templateTextFields {
+		if strings.TrimSpace(values[field]) == "" {
+			cleared = append(cleared, templateFormFields[field])
+		}
+	}
+
+	secret := values[components.FieldNameKey]
+	if !conn.UseSSHKey {
+		secret = values[components.FieldNamePassword]
+	}
+	if conn.UseSSHKey == parent.UseSSHKey && secret == "" {
+		cleared = append(cleared, models.InheritAuth)
+	}
+
+	conn.InheritFrom(parent, opened, cleared)
+	return parent, nil
+}
+
+// updateTemplateFields отмечает поля формы, унаследованные от шаблона и переопределенные
+// (opened - подключение, с которым открыта форма), и снимает обязательность с полей, которые может заполнить шаблон
+func updateTemplateFields(formManager *components.FormManager, opened *models.Connection) {
+	values := formManager.GetValues()
+	conn := connectionFromForm(values)
+	parent, err := applyFormTemplate(conn, values, opened)
+
+	parentHint := ""
+	switch {
+	case err != nil:
+		parentHint = "✖ не найден"
+	case parent != nil && parent.Template:
+		parentHint = "✓ шаблон"
+	case parent != nil:
+		parentHint = "✓ подключение"
+	}
+	if field := formManager.GetField(components.FieldNameParent); field != nil {
+		field.SetHint(parentHint)
+	}
+
+	for name, inherit := range templateFormFields {
+		field := formManager.GetField(name)
+		if field == nil {
+			continue
+		}
+
+		hint := ""
+		if parent != nil {
+			if conn.Inherits(inherit) {
+				hint = "↳ из шаблона"
+			} else {
+				hint = "✎ переопределено"
+			}
+		}
+		field.SetHint(hint)
+	}
+
+	// Шаблону хост не нужен, наследнику пользователь может достаться от шаблона
+	if field := formManager.GetField(components.FieldNameHost); field != nil {
+		field.SetRequired(!conn.Template)
+	}
+	if field := formManager.GetField(components.FieldNameUser); field != nil {
+		field.SetRequired(parent == nil)
+	}
+}
+
+// addTemplateFields добавляет в форму поля шаблона
+func addTemplateFields(formManager *components.FormManager) {
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameParent,
+		Label:       "Шаблон",
+		Required:    false,
+		Width:       50,
+		MaxLength:   50,
+		Placeholder: "название шаблона или подключения (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameTemplate,
+		Label:       "Это шаблон (←/→)",
+		Required:    false,
+		Width:       20,
+		Placeholder: "",
+		FieldType:   components.FieldTypeBool,
+	})
+}
@@ -1,16 +1,21 @@
 package screens
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/recording"
 	"ssh-keeper/internal/services"
 	"ssh-keeper/internal/ssh"
 	"ssh-keeper/internal/ui"
 	"ssh-keeper/internal/ui/components"
 	"ssh-keeper/internal/ui/styles"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -25,7 +30,72 @@ type ConnectionsScreen struct {
 	searchInput    textinput.Model
 	connectionSvc  *services.ConnectionService
 	allItems       []list.Item
+	selected       map[string]bool // ID подключений, отмеченных для группового выполнения
 	messageManager *components.MessageManager
+
+	// Проверка доступности серверов
+	health       map[string]ssh.HealthResult
+	healthEvents chan ssh.HealthEvent
+	healthCancel context.CancelFunc
+	healthTick   int
+
+	// История сессий
+	stats    map[string]*models.ConnectionStats
+	sortMode connectionsSortMode
+
+	// Работающие мастер-соединения ControlMaster
+	masters map[string]bool
+
+	// Отмеченные подключения, ожидающие подтверждения удаления
+	confirmDelete []models.Connection
+
+	// Хосты из ~/.ssh/config (nil - зеркало выключено)
+	mirror     *services.SSHConfigMirror
+	mirrorTick int
+}
+
+// connectionsSortMode порядок подключений в списке
+type connectionsSortMode int
+
+const (
+	sortByConfig connectionsSortMode = iota
+	sortByRecent
+	sortByFrequent
+)
+
+// recentCount сколько подключений показывается в разделе "Недавние"
+const recentCount = 5
+
+// healthCheckInterval интервал автоматической проверки доступности серверов
+const healthCheckInterval = time.Minute
+
+// healthEventsMsg пачка результатов проверки доступности
+type healthEventsMsg struct {
+	events chan ssh.HealthEvent
+	batch  []ssh.HealthEvent
+	closed bool
+}
+
+// healthTickMsg сигнал периодической проверки доступности
+type healthTickMsg struct {
+	tick int
+}
+
+// mirrorTickMsg сигнал проверки изменений ~/.ssh/config
+type mirrorTickMsg struct {
+	tick int
+}
+
+// masterStatusMsg состояние мастер-соединений по ID подключений
+type masterStatusMsg struct {
+	alive map[string]bool
+}
+
+// masterToggledMsg результат запуска или остановки мастер-соединения
+type masterToggledMsg struct {
+	name    string
+	started bool
+	err     error
 }
 
 // NewConnectionsScreen создает новый экран подключений
@@ -70,7 +140,11 @@ func NewConnectionsScreen() *ConnectionsScreen {
 		searchInput:    searchInput,
 		connectionSvc:  services.GetGlobalConnectionService(),
 		allItems:       listItems,
+		selected:       make(map[string]bool),
 		messageManager: messageManager,
+		health:         make(map[string]ssh.HealthResult),
+		masters:        make(map[string]bool),
+		mirror:         services.GetGlobalSSHConfigMirror(),
 	}
 }
 
@@ -83,25 +157,459 @@ func (cs *ConnectionsScreen) refreshConnections() {
 		return
 	}
 
+	// Правки сохранены локально, даже если их не удалось отправить в репозиторий
+	if err := services.GetSyncError(); err != nil {
+		cs.messageManager.AddWarning(fmt.Sprintf("Синхронизация не выполнена: %v", err))
+	}
+	if err := services.GetSSHConfigIncludeError(); err != nil {
+		cs.messageManager.AddWarning(fmt.Sprintf("Файл для ~/.ssh/config не обновлен: %v", err))
+	}
+
 	// Получаем актуальные подключения
 	connections := services.GetConnections()
+	managed := len(connections)
+	if cs.mirror != nil {
+		if err := cs.mirror.Err(); err != nil {
+			cs.messageManager.AddWarning(fmt.Sprintf("Ошибка чтения %s: %v", cs.mirror.Path(), err))
+		}
+		connections = append(connections, cs.mirror.Connections(connections)...)
+	}
+
+	// Загружаем статистику сессий
+	cs.stats = nil
+	if history := services.GetGlobalHistoryService(); history != nil {
+		stats, err := history.Stats()
+		if err != nil {
+			cs.messageManager.AddError(fmt.Sprintf("Ошибка загрузки истории: %v", err))
+		}
+		cs.stats = stats
+	}
 
-	// Создаем новые элементы списка
+	// Создаем новые элементы списка, сохраняя отметки существующих подключений
 	var listItems []list.Item
-	for _, conn := range connections {
-		listItems = append(listItems, components.NewConnectionItem(conn))
+	selected := make(map[string]bool)
+	health := make(map[string]ssh.HealthResult)
+	for i, conn := range connections {
+		item := components.NewConnectionItem(conn)
+		item.ReadOnly = i >= managed
+		if result, ok := cs.health[conn.ID]; ok {
+			item.Health = result
+			health[conn.ID] = result
+		}
+		if cs.selected[conn.ID] {
+			item.Selected = true
+			selected[conn.ID] = true
+		}
+		if stat, ok := cs.stats[conn.ID]; ok {
+			item.Sessions = stat.Sessions
+			item.LastUsed = stat.LastUsed
+		}
+		listItems = append(listItems, item)
 	}
+	cs.sortItems(listItems)
 
 	// Обновляем список
+	cs.selected = selected
+	cs.health = health
 	cs.allItems = listItems
-	cs.list.SetItems(listItems)
+	cs.filterList()
 }
 
-// editSelectedConnection редактирует выбранное подключение
+// syncConflictMessage описывает, какая версия подключения осталась после синхронизации
+func syncConflictMessage(conflict models.SyncConflict) string {
+	if conflict.Deleted {
+		return fmt.Sprintf("Подключение %s удалено на одной машине и изменено на другой: изменение сохранено", conflict.Name)
+	}
+	kept := "с другой машины"
+	if conflict.LocalWins {
+		kept = "локальная"
+	}
+	return fmt.Sprintf("Подключение %s изменено на двух машинах: оставлена более новая версия (%s)", conflict.Name, kept)
+}
+
+// sortItems упорядочивает элементы в соответствии с режимом сортировки.
+// Подключения без истории остаются в конце в порядке конфигурации
+func (cs *ConnectionsScreen) sortItems(items []list.Item) {
+	if cs.sortMode == sortByConfig {
+		return
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a := items[i].(components.ConnectionItem)
+		b := items[j].(components.ConnectionItem)
+		if cs.sortMode == sortByFrequent && a.Sessions != b.Sessions {
+			return a.Sessions > b.Sessions
+		}
+		return a.LastUsed.After(b.LastUsed)
+	})
+}
+
+// cycleSortMode переключает порядок сортировки списка
+func (cs *ConnectionsScreen) cycleSortMode() {
+	cs.sortMode = (cs.sortMode + 1) % 3
+	cs.refreshConnections()
+
+	switch cs.sortMode {
+	case sortByRecent:
+		cs.messageManager.AddInfo("Сортировка: недавние")
+	case sortByFrequent:
+		cs.messageManager.AddInfo("Сортировка: частые")
+	default:
+		cs.messageManager.AddInfo("Сортировка: как в конфигурации")
+	}
+}
+
+// recentConnections возвращает последние использованные подключения
+func (cs *ConnectionsScreen) recentConnections() []models.Connection {
+	var recent []components.ConnectionItem
+	for _, item := range cs.allItems {
+		if connItem, ok := item.(components.ConnectionItem); ok && connItem.Sessions > 0 {
+			recent = append(recent, connItem)
+		}
+	}
+
+	sort.SliceStable(recent, func(i, j int) bool {
+		return recent[i].LastUsed.After(recent[j].LastUsed)
+	})
+	if len(recent) > recentCount {
+		recent = recent[:recentCount]
+	}
+
+	connections := make([]models.Connection, 0, len(recent))
+	for _, item := range recent {
+		connections = append(connections, item.GetConnection())
+	}
+	return connections
+}
+
+// renderRecent отрисовывает раздел "Недавние" с горячими клавишами Alt+1..Alt+5
+func (cs *ConnectionsScreen) renderRecent() string {
+	recent := cs.recentConnections()
+	if len(recent) == 0 {
+		return ""
+	}
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorPrimary)).
+		Bold(true)
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorMuted))
+
+	parts := make([]string, 0, len(recent))
+	for i, conn := range recent {
+		parts = append(parts, fmt.Sprintf("%s %s", keyStyle.Render(fmt.Sprintf("Alt+%d", i+1)), conn.Name))
+	}
+
+	return labelStyle.Render("Недавние: ") + strings.Join(parts, " • ")
+}
+
+// connectToRecent подключается к n-му недавнему подключению
+func (cs *ConnectionsScreen) connectToRecent(n int) {
+	recent := cs.recentConnections()
+	if n < 1 || n > len(recent) {
+		return
+	}
+	cs.launchSSHSession(&recent[n-1])
+}
+
+// toggleSelected отмечает выбранное подключение для группового выполнения команды
+func (cs *ConnectionsScreen) toggleSelected() {
+	item, ok := cs.list.SelectedItem().(components.ConnectionItem)
+	if !ok {
+		return
+	}
+
+	id := item.Connection.ID
+	if cs.selected[id] {
+		delete(cs.selected, id)
+	} else {
+		cs.selected[id] = true
+	}
+
+	cs.updateItems()
+	cs.list.CursorDown()
+}
+
+// updateItems переносит отметки и состояние доступности в элементы списка
+func (cs *ConnectionsScreen) updateItems() {
+	for i, listItem := range cs.allItems {
+		if connItem, ok := listItem.(components.ConnectionItem); ok {
+			connItem.Selected = cs.selected[connItem.Connection.ID]
+			connItem.Health = cs.health[connItem.Connection.ID]
+			connItem.MasterAlive = cs.masters[connItem.Connection.ID]
+			cs.allItems[i] = connItem
+		}
+	}
+
+	cs.filterList()
+}
+
+// startHealthCheck запускает проверку доступности всех подключений.
+// Предыдущая проверка, если она еще идет, прерывается
+func (cs *ConnectionsScreen) startHealthCheck() tea.Cmd {
+	if cs.healthCancel != nil {
+		cs.healthCancel()
+	}
+
+	var connections []models.Connection
+	for _, item := range cs.allItems {
+		if connItem, ok := item.(components.ConnectionItem); ok && !connItem.Connection.Template {
+			connections = append(connections, connItem.GetConnection())
+			if cs.health[connItem.Connection.ID].Status == ssh.HealthUnknown {
+				cs.health[connItem.Connection.ID] = ssh.HealthResult{Status: ssh.HealthChecking}
+			}
+		}
+	}
+	cs.updateItems()
+
+	if len(connections) == 0 {
+		cs.healthCancel = nil
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cs.healthCancel = cancel
+	// Буфер на все подключения: проверка не блокируется, даже если экран неактивен
+	cs.healthEvents = make(chan ssh.HealthEvent, len(connections))
+
+	go ssh.ProbeAll(ctx, connections, ssh.DefaultHealthWorkers, true, cs.healthEvents)
+
+	return waitHealthEvents(cs.healthEvents)
+}
+
+// waitHealthEvents ожидает следующий результат проверки и забирает уже готовые
+func waitHealthEvents(events chan ssh.HealthEvent) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return healthEventsMsg{events: events, closed: true}
+		}
+
+		batch := []ssh.HealthEvent{event}
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return healthEventsMsg{events: events, batch: batch, closed: true}
+				}
+				batch = append(batch, event)
+			default:
+				return healthEventsMsg{events: events, batch: batch}
+			}
+		}
+	}
+}
+
+// scheduleMirrorCheck планирует проверку изменений ~/.ssh/config
+func (cs *ConnectionsScreen) scheduleMirrorCheck() tea.Cmd {
+	if cs.mirror == nil {
+		return nil
+	}
+	cs.mirrorTick++
+	tick := cs.mirrorTick
+	return tea.Tick(services.SSHConfigMirrorInterval, func(time.Time) tea.Msg {
+		return mirrorTickMsg{tick: tick}
+	})
+}
+
+// scheduleHealthCheck планирует следующую периодическую проверку
+func (cs *ConnectionsScreen) scheduleHealthCheck() tea.Cmd {
+	cs.healthTick++
+	tick := cs.healthTick
+	return tea.Tick(healthCheckInterval, func(time.Time) tea.Msg {
+		return healthTickMsg{tick: tick}
+	})
+}
+
+// healthSummary возвращает строку со счетчиками доступности
+func (cs *ConnectionsScreen) healthSummary() string {
+	online, offline, checking := 0, 0, 0
+	for _, result := range cs.health {
+		switch result.Status {
+		case ssh.HealthOnline:
+			online++
+		case ssh.HealthOffline:
+			offline++
+		case ssh.HealthChecking:
+			checking++
+		}
+	}
+
+	if online+offline+checking == 0 {
+		return ""
+	}
+
+	summary := fmt.Sprintf("Доступны: %d", online)
+	if offline > 0 {
+		summary += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(styles.ColorError)).
+			Render(fmt.Sprintf(" • недоступны: %d", offline))
+	}
+	if checking > 0 {
+		summary += fmt.Sprintf(" • проверяется: %d", checking)
+	}
+	return summary
+}
+
+// execTargets возвращает подключения для групповых действий: отмеченные,
+// иначе все найденные поиском, иначе выбранное
+func (cs *ConnectionsScreen) execTargets() []models.Connection {
+	var targets []models.Connection
+
+	// Шаблоны не являются серверами и в групповых действиях не участвуют
+	if len(cs.selected) > 0 {
+		for _, item := range cs.allItems {
+			if connItem, ok := item.(components.ConnectionItem); ok && connItem.Selected && !connItem.Connection.Template {
+				targets = append(targets, connItem.GetConnection())
+			}
+		}
+		return targets
+	}
+
+	if cs.searchInput.Value() != "" {
+		for _, item := range cs.list.Items() {
+			if connItem, ok := item.(components.ConnectionItem); ok && !connItem.Connection.Template {
+				targets = append(targets, connItem.GetConnection())
+			}
+		}
+		return targets
+	}
+
+	if item, ok := cs.list.SelectedItem().(components.ConnectionItem); ok && !item.Connection.Template {
+		targets = append(targets, item.GetConnection())
+	}
+	return targets
+}
+
+// execOnTargets открывает экран выполнения команды на выбранных подключениях
+func (cs *ConnectionsScreen) execOnTargets() tea.Cmd {
+	targets := cs.execTargets()
+	if len(targets) == 0 {
+		cs.messageManager.AddError("Нет подключений для выполнения команды")
+		return nil
+	}
+	return ui.NavigateToWithDataCmd("exec", targets)
+}
+
+// checkMasters проверяет мастер-соединения подключений с общим каналом (ssh -O check)
+func (cs *ConnectionsScreen) checkMasters() tea.Cmd {
+	var connections []models.Connection
+	for _, item := range cs.allItems {
+		if connItem, ok := item.(components.ConnectionItem); ok && connItem.Connection.ControlMaster && !connItem.Connection.Template {
+			connections = append(connections, connItem.GetConnection())
+		}
+	}
+	if len(connections) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		alive := make(map[string]bool)
+		for i := range connections {
+			if ssh.CheckMaster(&connections[i]) {
+				alive[connections[i].ID] = true
+			}
+		}
+		return masterStatusMsg{alive: alive}
+	}
+}
+
+// toggleMaster запускает мастер-соединение выбранного подключения или останавливает работающее
+func (cs *ConnectionsScreen) toggleMaster() tea.Cmd {
+	item, ok := cs.list.SelectedItem().(components.ConnectionItem)
+	if !ok {
+		return nil
+	}
+
+	conn := item.GetConnection()
+	if !conn.ControlMaster {
+		cs.messageManager.AddWarning("Общий канал выключен: включите ControlMaster в настройках подключения")
+		return nil
+	}
+
+	if cs.masters[conn.ID] {
+		return func() tea.Msg {
+			return masterToggledMsg{name: conn.Name, err: ssh.StopMaster(&conn)}
+		}
+	}
+
+	cs.messageManager.AddInfo(fmt.Sprintf("Запуск общего канала %s...", conn.Name))
+	return func() tea.Msg {
+		return masterToggledMsg{name: conn.Name, started: true, err: ssh.StartMaster(&conn)}
+	}
+}
+
+// multiplexTargets открывает экран запуска выбранных подключений в tmux/screen
+func (cs *ConnectionsScreen) multiplexTargets() tea.Cmd {
+	targets := cs.execTargets()
+	if len(targets) == 0 {
+		cs.messageManager.AddError("Нет подключений для открытия")
+		return nil
+	}
+	return ui.NavigateToWithDataCmd("multiplex", targets)
+}
+
+// markedConnections возвращает отмеченные подключения в порядке списка
+func (cs *ConnectionsScreen) markedConnections() []models.Connection {
+	var marked []models.Connection
+	for _, item := range cs.allItems {
+		if connItem, ok := item.(components.ConnectionItem); ok && connItem.Selected {
+			marked = append(marked, connItem.GetConnection())
+		}
+	}
+	return marked
+}
+
+// rejectReadOnly сообщает об ошибке, если среди подключений есть хосты из ~/.ssh/config
+func (cs *ConnectionsScreen) rejectReadOnly(connections ...models.Connection) bool {
+	for _, conn := range connections {
+		if services.IsMirrored(conn) {
+			cs.messageManager.AddError(fmt.Sprintf("'%s' из %s: только чтение (Ctrl+S - перенести в SSH Keeper)", conn.Name, cs.mirror.Path()))
+			return true
+		}
+	}
+	return false
+}
+
+// convertSelectedToManaged копирует хост из ~/.ssh/config в подключения SSH Keeper
+func (cs *ConnectionsScreen) convertSelectedToManaged() {
+	item, ok := cs.list.SelectedItem().(components.ConnectionItem)
+	if !ok {
+		cs.messageManager.AddError("Не удалось получить данные подключения")
+		return
+	}
+	if !item.ReadOnly {
+		cs.messageManager.AddInfo(fmt.Sprintf("Подключение '%s' уже хранится в SSH Keeper", item.Connection.Name))
+		return
+	}
+
+	conn := item.GetConnection()
+	conn.Source = ""
+	conn.SourceHost = ""
+	if err := services.AddConnection(&conn); err != nil {
+		cs.messageManager.AddError(fmt.Sprintf("Ошибка сохранения: %v", err))
+		return
+	}
+
+	cs.refreshConnections()
+	cs.messageManager.AddSuccess(fmt.Sprintf("Хост '%s' перенесен в SSH Keeper (Ctrl+Z - отменить)", conn.Name))
+}
+
+// editSelectedConnection редактирует выбранное подключение, а если есть
+// отмеченные - открывает их групповую правку
 func (cs *ConnectionsScreen) editSelectedConnection() tea.Cmd {
+	if marked := cs.markedConnections(); len(marked) > 0 {
+		if cs.rejectReadOnly(marked...) {
+			return nil
+		}
+		return ui.NavigateToWithDataCmd("bulk_edit", marked)
+	}
+
 	selectedItem := cs.list.SelectedItem()
 	if item, ok := selectedItem.(components.ConnectionItem); ok {
 		conn := item.GetConnection()
+		if cs.rejectReadOnly(conn) {
+			return nil
+		}
 		// Добавляем отладочную информацию
 		cs.messageManager.AddInfo(fmt.Sprintf("Редактируем подключение: %s (ID: %s)", conn.Name, conn.ID))
 		// Переходим к экрану редактирования с данными подключения
@@ -111,11 +619,54 @@ func (cs *ConnectionsScreen) editSelectedConnection() tea.Cmd {
 	return nil
 }
 
-// deleteSelectedConnection удаляет выбранное подключение
+// openSFTPForSelected открывает файловый менеджер SFTP для выбранного подключения
+func (cs *ConnectionsScreen) openSFTPForSelected() tea.Cmd {
+	selectedItem := cs.list.SelectedItem()
+	if item, ok := selectedItem.(components.ConnectionItem); ok {
+		return ui.NavigateToWithDataCmd("sftp", item.GetConnection())
+	}
+	cs.messageManager.AddError("Не удалось получить данные подключения")
+	return nil
+}
+
+// deployKeyToSelected открывает экран копирования ключа для выбранного подключения
+func (cs *ConnectionsScreen) deployKeyToSelected() tea.Cmd {
+	selectedItem := cs.list.SelectedItem()
+	item, ok := selectedItem.(components.ConnectionItem)
+	if !ok {
+		cs.messageManager.AddError("Не удалось получить данные подключения")
+		return nil
+	}
+
+	conn := item.GetConnection()
+	if cs.rejectReadOnly(conn) {
+		return nil
+	}
+	if conn.UseSSHKey {
+		cs.messageManager.AddInfo(fmt.Sprintf("Подключение '%s' уже использует SSH ключ", conn.Name))
+		return nil
+	}
+
+	return ui.NavigateToWithDataCmd("deploy_key", conn)
+}
+
+// deleteSelectedConnection удаляет выбранное подключение. Отмеченные
+// подключения удаляются после подтверждения
 func (cs *ConnectionsScreen) deleteSelectedConnection() tea.Cmd {
+	if marked := cs.markedConnections(); len(marked) > 0 {
+		if cs.rejectReadOnly(marked...) {
+			return nil
+		}
+		cs.confirmDelete = marked
+		return nil
+	}
+
 	selectedItem := cs.list.SelectedItem()
 	if item, ok := selectedItem.(components.ConnectionItem); ok {
 		conn := item.GetConnection()
+		if cs.rejectReadOnly(conn) {
+			return nil
+		}
 
 		// Удаляем подключение через сервис
 		err := services.DeleteConnection(conn.ID)
@@ -128,11 +679,67 @@ func (cs *ConnectionsScreen) deleteSelectedConnection() tea.Cmd {
 		cs.refreshConnections()
 
 		// Показываем сообщение об успехе
-		cs.messageManager.AddSuccess(fmt.Sprintf("Подключение '%s' удалено", conn.Name))
+		cs.messageManager.AddSuccess(fmt.Sprintf("Подключение '%s' удалено (Ctrl+Z - отменить)", conn.Name))
 	}
 	return nil
 }
 
+// handleDeleteConfirm обрабатывает подтверждение удаления отмеченных подключений
+func (cs *ConnectionsScreen) handleDeleteConfirm(msg tea.KeyMsg) {
+	connections := cs.confirmDelete
+	cs.confirmDelete = nil
+
+	switch msg.String() {
+	case "y", "Y", "д", "Д":
+	default:
+		cs.messageManager.AddInfo("Удаление отменено")
+		return
+	}
+
+	ids := make([]string, 0, len(connections))
+	for _, conn := range connections {
+		ids = append(ids, conn.ID)
+	}
+	if err := cs.connectionSvc.DeleteConnections(ids); err != nil {
+		cs.messageManager.AddError(fmt.Sprintf("Ошибка удаления: %v", err))
+		return
+	}
+
+	cs.refreshConnections()
+	cs.messageManager.AddSuccess(fmt.Sprintf("Удалено подключений: %d (Ctrl+Z - отменить)", len(ids)))
+}
+
+// undoLastChange отменяет (redo = false) или повторяет последнюю операцию журнала
+func (cs *ConnectionsScreen) undoLastChange(redo bool) {
+	if cs.connectionSvc == nil {
+		cs.messageManager.AddError("Ошибка: сервис подключений не инициализирован")
+		return
+	}
+
+	action, apply, available := "Отменено", cs.connectionSvc.Undo, cs.connectionSvc.CanUndo
+	if redo {
+		action, apply, available = "Повторено", cs.connectionSvc.Redo, cs.connectionSvc.CanRedo
+	}
+
+	if _, ok := available(); !ok {
+		if redo {
+			cs.messageManager.AddInfo("Нечего повторять")
+		} else {
+			cs.messageManager.AddInfo("Нечего отменять")
+		}
+		return
+	}
+
+	description, err := apply()
+	if err != nil {
+		cs.messageManager.AddError(fmt.Sprintf("Ошибка: %v", err))
+		return
+	}
+
+	cs.refreshConnections()
+	cs.messageManager.AddSuccess(fmt.Sprintf("%s: %s", action, description))
+}
+
 // Update обрабатывает обновления состояния
 func (cs *ConnectionsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
@@ -140,17 +747,78 @@ func (cs *ConnectionsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		cs.SetSize(msg.Width, msg.Height)
-		cs.list.SetSize(msg.Width-4, msg.Height-15) // Учитываем место для поиска
+		cs.list.SetSize(msg.Width-4, msg.Height-17) // Учитываем место для поиска, недавних и строки доступности
 		return cs, nil
 
 	case ui.NavigateToMsg:
-		// Обновляем список при навигации к экрану
+		// Обновляем список и проверяем доступность при навигации к экрану
 		if msg.ScreenName == "connections" {
 			cs.refreshConnections()
+			return cs, tea.Batch(cs.startHealthCheck(), cs.scheduleHealthCheck(), cs.checkMasters(), cs.scheduleMirrorCheck())
+		}
+		return cs, nil
+
+	case syncDoneMsg:
+		// Ошибку синхронизации показывает refreshConnections
+		cs.refreshConnections()
+		if msg.result != nil {
+			for _, conflict := range msg.result.Conflicts {
+				cs.messageManager.AddWarning(syncConflictMessage(conflict))
+			}
 		}
 		return cs, nil
 
+	case masterStatusMsg:
+		cs.masters = msg.alive
+		cs.updateItems()
+		return cs, nil
+
+	case masterToggledMsg:
+		switch {
+		case msg.err != nil:
+			cs.messageManager.AddError(msg.err.Error())
+		case msg.started:
+			cs.messageManager.AddSuccess(fmt.Sprintf("Общий канал %s запущен", msg.name))
+		default:
+			cs.messageManager.AddInfo(fmt.Sprintf("Общий канал %s остановлен", msg.name))
+		}
+		return cs, cs.checkMasters()
+
+	case healthEventsMsg:
+		// Результаты прерванной проверки игнорируем
+		if msg.events != cs.healthEvents {
+			return cs, nil
+		}
+		for _, event := range msg.batch {
+			cs.health[event.ID] = event.Result
+		}
+		cs.updateItems()
+		if msg.closed {
+			return cs, nil
+		}
+		return cs, waitHealthEvents(cs.healthEvents)
+
+	case healthTickMsg:
+		if msg.tick != cs.healthTick {
+			return cs, nil
+		}
+		return cs, tea.Batch(cs.startHealthCheck(), cs.scheduleHealthCheck())
+
+	case mirrorTickMsg:
+		if msg.tick != cs.mirrorTick {
+			return cs, nil
+		}
+		// Перечитываем список, только если ~/.ssh/config изменился
+		if changed, _ := cs.mirror.Refresh(); changed {
+			cs.refreshConnections()
+		}
+		return cs, cs.scheduleMirrorCheck()
+
 	case tea.KeyMsg:
+		if cs.confirmDelete != nil {
+			cs.handleDeleteConfirm(msg)
+			return cs, nil
+		}
 
 		switch msg.String() {
 		case "ctrl+c":
@@ -169,6 +837,48 @@ func (cs *ConnectionsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+d":
 			// Удалить выбранное подключение
 			return cs, cs.deleteSelectedConnection()
+		case "ctrl+f":
+			// Открыть SFTP для выбранного подключения
+			return cs, cs.openSFTPForSelected()
+		case "ctrl+k":
+			// Скопировать публичный ключ на сервер
+			return cs, cs.deployKeyToSelected()
+		case "ctrl+x":
+			// Отметить подключение для групповых действий
+			cs.toggleSelected()
+			return cs, nil
+		case "ctrl+g":
+			// Выполнить команду на отмеченных или найденных подключениях
+			return cs, cs.execOnTargets()
+		case "ctrl+t":
+			// Открыть отмеченные подключения в tmux/screen
+			return cs, cs.multiplexTargets()
+		case "ctrl+r":
+			// Проверить доступность серверов и мастер-соединений
+			return cs, tea.Batch(cs.startHealthCheck(), cs.checkMasters())
+		case "ctrl+b":
+			// Запустить или остановить общий канал выбранного подключения
+			return cs, cs.toggleMaster()
+		case "ctrl+z":
+			// Отменить последнее изменение подключений
+			cs.undoLastChange(false)
+			return cs, nil
+		case "ctrl+y":
+			// Повторить отмененное изменение
+			cs.undoLastChange(true)
+			return cs, nil
+		case "ctrl+s":
+			// Перенести хост из ~/.ssh/config в SSH Keeper
+			cs.convertSelectedToManaged()
+			return cs, nil
+		case "ctrl+o":
+			// Переключить сортировку: конфигурация / недавние / частые
+			cs.cycleSortMode()
+			return cs, nil
+		case "alt+1", "alt+2", "alt+3", "alt+4", "alt+5":
+			// Подключиться к недавнему подключению
+			cs.connectToRecent(int(msg.String()[len("alt+")] - '0'))
+			return cs, nil
 		}
 	}
 
@@ -219,9 +929,12 @@ func (cs *ConnectionsScreen) updateContent() {
 
 	// Получаем содержимое списка
 	listContent := cs.list.View()
+	if cs.confirmDelete != nil {
+		listContent = cs.renderDeleteConfirm()
+	}
 
 	// Инструкции - принудительно применяем стиль к каждой строке
-	instructionsText := "↑/↓ нав. • Enter подкл. • Ctrl+E ред. • Ctrl+D удал. • Esc назад"
+	instructionsText := "↑/↓ нав. • Enter подкл. • Ctrl+E ред. (отмеченные - группой) • Ctrl+D удал. • Ctrl+K ключ • Ctrl+F файлы • Ctrl+X отметить • Ctrl+G команда • Ctrl+T tmux • Ctrl+B общий канал • Ctrl+R проверить • Ctrl+O сортировка • Ctrl+S в SSH Keeper (хост ssh config) • Ctrl+Z/Ctrl+Y отменить/повторить • Esc назад"
 	instructions := instructionsStyle.Render(instructionsText)
 
 	// Добавляем сообщения
@@ -232,13 +945,39 @@ func (cs *ConnectionsScreen) updateContent() {
 	if messages != "" {
 		contentParts = append(contentParts, messages)
 	}
-	contentParts = append(contentParts, searchView, "", listContent, "", instructions)
+	contentParts = append(contentParts, searchView)
+	if recent := cs.renderRecent(); recent != "" {
+		contentParts = append(contentParts, recent)
+	}
+	if summary := cs.healthSummary(); summary != "" {
+		contentParts = append(contentParts, instructionsStyle.UnsetItalic().Render(summary))
+	}
+	contentParts = append(contentParts, "", listContent, "", instructions)
 
 	content := lipgloss.JoinVertical(lipgloss.Left, contentParts...)
 
 	cs.SetContent(content)
 }
 
+// renderDeleteConfirm отрисовывает запрос подтверждения со списком удаляемых подключений
+func (cs *ConnectionsScreen) renderDeleteConfirm() string {
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorWarning)).
+		Bold(true)
+
+	lines := []string{warningStyle.Render(fmt.Sprintf("Удалить отмеченные подключения (%d)?", len(cs.confirmDelete)))}
+	for _, conn := range cs.confirmDelete {
+		line := "  • " + conn.Name
+		if conn.Template {
+			line += " (шаблон: наследники сохранят значения)"
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", warningStyle.Render("Подтвердите удаление: y - да, любая клавиша - отмена"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
 // Init инициализирует экран
 func (cs *ConnectionsScreen) Init() tea.Cmd {
 	// Перезагружаем подключения при инициализации
@@ -260,6 +999,18 @@ func (cs *ConnectionsScreen) filterList() {
 		return
 	}
 
+	// Запрос вида #тег ищет точное совпадение тега или группы
+	if tag, ok := strings.CutPrefix(query, "#"); ok && tag != "" {
+		var taggedItems []list.Item
+		for _, item := range cs.allItems {
+			if connItem, ok := item.(components.ConnectionItem); ok && connItem.Connection.HasTag(tag) {
+				taggedItems = append(taggedItems, item)
+			}
+		}
+		cs.list.SetItems(taggedItems)
+		return
+	}
+
 	// Фильтруем элементы
 	var filteredItems []list.Item
 	for _, item := range cs.allItems {
@@ -281,6 +1032,10 @@ func (cs *ConnectionsScreen) connectToSelected() {
 	selectedItem := cs.list.SelectedItem()
 	if item, ok := selectedItem.(components.ConnectionItem); ok {
 		conn := item.GetConnection()
+		if conn.Template {
+			cs.messageManager.AddWarning(fmt.Sprintf("'%s' - шаблон: к нему нельзя подключиться, откройте его для редактирования (e)", conn.Name))
+			return
+		}
 		// Создаем SSH экран и запускаем подключение
 		cs.launchSSHSession(&conn)
 	}
@@ -312,15 +1067,79 @@ func (cs *ConnectionsScreen) launchSSHSession(conn *models.Connection) {
 	fmt.Printf("Команда: %s\n", sshClient.GetConnectionString())
 	fmt.Println("Запускаем SSH процесс...")
 
+	// Включаем запись сессии, если она настроена для подключения или его группы
+	recorder, recordingPath := cs.startRecording(conn, sshClient)
+
 	// Запускаем SSH подключение (это закроет наше приложение)
-	sshClient.Connect()
+	startedAt := time.Now()
+	err := sshClient.Connect()
+	cs.recordSession(conn, startedAt, err)
 
 	// Если SSH завершился успешно, восстанавливаем терминал и закрываем приложение
 	fmt.Println("SSH сессия завершена. Приложение закрывается...")
 	cs.restoreTerminal(true)
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			fmt.Printf("Ошибка сохранения записи сессии: %v\n", err)
+		} else {
+			fmt.Printf("Запись сессии сохранена: %s\n", recordingPath)
+		}
+	}
 	os.Exit(0)
 }
 
+// startRecording начинает запись сессии в формате asciicast, если она включена.
+// Возвращает запись, которую нужно закрыть после сессии, и путь к ее файлу
+func (cs *ConnectionsScreen) startRecording(conn *models.Connection, sshClient ssh.SSHClientInterface) (*recording.Writer, string) {
+	recordings := services.GetGlobalRecordingService()
+	if recordings == nil || !recordings.ShouldRecord(conn) {
+		return nil, ""
+	}
+
+	width, height := 80, 24
+	if size, err := ssh.GetTerminalSize(); err == nil {
+		width, height = int(size.Cols), int(size.Rows)
+	}
+
+	writer, path, err := recordings.Start(conn, width, height)
+	if err != nil {
+		fmt.Printf("Запись сессии отключена: %v\n", err)
+		return nil, ""
+	}
+
+	sshClient.SetRecorder(writer)
+	fmt.Printf("Сессия записывается: %s\n", path)
+	return writer, path
+}
+
+// recordSession записывает завершенную сессию в историю
+func (cs *ConnectionsScreen) recordSession(conn *models.Connection, startedAt time.Time, err error) {
+	history := services.GetGlobalHistoryService()
+	if history == nil {
+		return
+	}
+
+	exitCode := 0
+	if err != nil {
+		exitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+
+	record := models.SessionRecord{
+		ConnectionID: conn.ID,
+		Name:         conn.Name,
+		StartedAt:    startedAt,
+		EndedAt:      time.Now(),
+		ExitCode:     exitCode,
+	}
+	if err := history.Record(record); err != nil {
+		fmt.Printf("Не удалось записать историю сессии: %v\n", err)
+	}
+}
+
 // restoreTerminal восстанавливает терминал
 func (cs *ConnectionsScreen) restoreTerminal(withReset bool) {
 	// Радикальное восстановление терминалае
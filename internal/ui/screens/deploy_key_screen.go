@@ -0,0 +1,277 @@
+package screens
+
+import (
+	"errors"
+	"fmt"
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/services"
+	"ssh-keeper/internal/ssh"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"ssh-keeper/internal/ui/styles"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// deployState состояние экрана копирования ключа
+type deployState int
+
+const (
+	deployStateSelect deployState = iota
+	deployStateRunning
+	deployStateConfirmSwitch
+	deployStateDone
+)
+
+// deployKeyResultMsg сообщение с результатом копирования и проверки ключа
+type deployKeyResultMsg struct {
+	keyPath        string
+	alreadyPresent bool
+	unverified     bool // Ключ с парольной фразой: вход по нему не проверен
+	err            error
+}
+
+// DeployKeyScreen представляет экран копирования публичного ключа на сервер
+type DeployKeyScreen struct {
+	*BaseScreen
+	connection     *models.Connection
+	list           list.Model
+	keyService     *services.SSHKeyService
+	messageManager *components.MessageManager
+	state          deployState
+	deployedKey    string
+}
+
+// NewDeployKeyScreen создает экран копирования ключа (для фабрики)
+func NewDeployKeyScreen() *DeployKeyScreen {
+	baseScreen := NewBaseScreen("SSH Keeper - Копирование ключа на сервер")
+
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+	l.KeyMap.Quit.SetKeys("ctrl+q")
+
+	screen := &DeployKeyScreen{
+		BaseScreen:     baseScreen,
+		list:           l,
+		keyService:     services.GetGlobalSSHKeyService(),
+		messageManager: components.NewMessageManager(),
+		state:          deployStateSelect,
+	}
+
+	keys, err := screen.keyService.ListKeys()
+	if err != nil {
+		screen.messageManager.AddError(fmt.Sprintf("Ошибка чтения ключей: %v", err))
+	}
+	items := make([]list.Item, 0, len(keys))
+	for _, key := range keys {
+		// Нечитаемый ключ нельзя скопировать на сервер
+		if key.Error == "" {
+			items = append(items, components.NewKeyItem(key))
+		}
+	}
+	screen.list.SetItems(items)
+
+	return screen
+}
+
+// SetData устанавливает подключение, на которое копируется ключ
+func (dks *DeployKeyScreen) SetData(data interface{}) {
+	connection, ok := data.(models.Connection)
+	if !ok {
+		dks.messageManager.AddError("Ошибка: не удалось загрузить данные подключения")
+		return
+	}
+
+	dks.connection = &connection
+	dks.BaseScreen.SetTitle(fmt.Sprintf("SSH Keeper - Ключ для '%s'", connection.Name))
+
+	if connection.Password == "" {
+		dks.messageManager.AddWarning("Пароль не сохранен - вход на сервер невозможен без него")
+	}
+}
+
+// deploySelected копирует выбранный ключ и проверяет вход по нему
+func (dks *DeployKeyScreen) deploySelected() tea.Cmd {
+	if dks.connection == nil {
+		dks.messageManager.AddError("Ошибка: подключение не инициализировано")
+		return nil
+	}
+
+	item, ok := dks.list.SelectedItem().(components.KeyItem)
+	if !ok {
+		dks.messageManager.AddError("Выберите ключ")
+		return nil
+	}
+	key := item.GetKey()
+
+	publicKey, err := dks.keyService.ReadPublicKey(key.Path)
+	if err != nil {
+		dks.messageManager.AddError(err.Error())
+		return nil
+	}
+
+	dks.state = deployStateRunning
+	dks.messageManager.AddInfo(fmt.Sprintf("Копируем %s на %s@%s...", key.Path, dks.connection.User, dks.connection.Host))
+
+	conn := *dks.connection
+	return func() tea.Msg {
+		result, err := ssh.DeployPublicKey(&conn, publicKey)
+		if err != nil {
+			return deployKeyResultMsg{keyPath: key.Path, err: err}
+		}
+		err = ssh.VerifyKeyLogin(&conn, key.Path)
+		if err != nil && !errors.Is(err, ssh.ErrKeyLoginUnverified) {
+			return deployKeyResultMsg{keyPath: key.Path, err: err}
+		}
+		return deployKeyResultMsg{keyPath: key.Path, alreadyPresent: result.AlreadyPresent, unverified: err != nil}
+	}
+}
+
+// switchToKeyAuth переключает подключение на ключ и удаляет сохраненный пароль
+func (dks *DeployKeyScreen) switchToKeyAuth() tea.Cmd {
+	opened := *dks.connection
+	dks.connection.UseSSHKey = true
+	dks.connection.KeyPath = dks.deployedKey
+	dks.connection.HasPassword = false
+	dks.connection.Password = ""
+
+	if err := services.UpdateConnection(&opened, dks.connection); err != nil {
+		dks.messageManager.AddError(fmt.Sprintf("Ошибка сохранения: %v", err))
+		dks.state = deployStateDone
+		return nil
+	}
+
+	return ui.NavigateToCmd("connections")
+}
+
+// Update обрабатывает обновления состояния
+func (dks *DeployKeyScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		dks.SetSize(msg.Width, msg.Height)
+		dks.list.SetSize(msg.Width-4, msg.Height-16)
+		return dks, nil
+
+	case deployKeyResultMsg:
+		if msg.err != nil {
+			dks.messageManager.AddError(msg.err.Error())
+			dks.state = deployStateSelect
+			return dks, nil
+		}
+		if msg.alreadyPresent {
+			dks.messageManager.AddInfo("Ключ уже был в authorized_keys")
+		} else {
+			dks.messageManager.AddSuccess("Ключ добавлен в ~/.ssh/authorized_keys")
+		}
+		if msg.unverified {
+			dks.messageManager.AddWarning(fmt.Sprintf("Вход по ключу не проверен: %v", ssh.ErrKeyLoginUnverified))
+		} else {
+			dks.messageManager.AddSuccess("Вход по ключу проверен")
+		}
+		dks.deployedKey = msg.keyPath
+		dks.state = deployStateConfirmSwitch
+		return dks, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return dks, tea.Quit
+		case "esc":
+			if dks.state == deployStateRunning {
+				return dks, nil
+			}
+			return dks, ui.GoBackCmd()
+		}
+
+		switch dks.state {
+		case deployStateRunning:
+			return dks, nil
+		case deployStateConfirmSwitch:
+			switch msg.String() {
+			case "y", "Y", "д", "Д":
+				return dks, dks.switchToKeyAuth()
+			case "n", "N", "н", "Н":
+				dks.state = deployStateDone
+				dks.messageManager.AddInfo("Подключение оставлено без изменений")
+			}
+			return dks, nil
+		case deployStateSelect:
+			if msg.String() == "enter" {
+				return dks, dks.deploySelected()
+			}
+		case deployStateDone:
+			return dks, nil
+		}
+	}
+
+	dks.list, cmd = dks.list.Update(msg)
+
+	return dks, cmd
+}
+
+// View возвращает строку для отрисовки
+func (dks *DeployKeyScreen) View() string {
+	dks.updateContent()
+	return dks.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (dks *DeployKeyScreen) updateContent() {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorPrimary)).
+		Bold(true).
+		Margin(0, 0, 1, 0)
+
+	promptStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorWarning)).
+		Bold(true)
+
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted)).
+		Italic(styles.TextItalic).
+		Width(80)
+
+	target := ""
+	if dks.connection != nil {
+		target = fmt.Sprintf("%s@%s:%d", dks.connection.User, dks.connection.Host, dks.connection.Port)
+	}
+	header := headerStyle.Render(fmt.Sprintf("Выберите публичный ключ для %s", target))
+
+	var contentParts []string
+	contentParts = append(contentParts, header)
+
+	switch dks.state {
+	case deployStateConfirmSwitch:
+		contentParts = append(contentParts,
+			promptStyle.Render("Переключить подключение на вход по ключу и удалить сохраненный пароль? (y/n)"))
+	case deployStateRunning:
+		contentParts = append(contentParts, "Выполняется...")
+	default:
+		contentParts = append(contentParts, dks.list.View())
+	}
+
+	if messages := dks.messageManager.RenderMessages(80); messages != "" {
+		contentParts = append(contentParts, "", messages)
+	}
+
+	contentParts = append(contentParts, "", instructionsStyle.Render("↑/↓ нав. • Enter скопировать ключ • Esc назад"))
+
+	dks.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+}
+
+// Init инициализирует экран
+func (dks *DeployKeyScreen) Init() tea.Cmd {
+	return nil
+}
+
+// GetName возвращает имя экрана
+func (dks *DeployKeyScreen) GetName() string {
+	return "deploy_key"
+}
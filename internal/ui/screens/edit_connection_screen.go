@@ -8,6 +8,7 @@ import (
 	"ssh-keeper/internal/ui/components"
 	"ssh-keeper/internal/ui/styles"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -23,6 +24,8 @@ type EditConnectionScreen struct {
 
 	// Подключение для редактирования
 	connection *models.Connection
+	// Копия подключения, с которой открыта форма: по ней видно, какие поля изменены
+	opened models.Connection
 
 	errors map[string]string
 
@@ -60,6 +63,8 @@ func NewEditConnectionScreenEmpty() *EditConnectionScreen {
 		FieldType:   components.FieldTypeText,
 	})
 
+	addTemplateFields(formManager)
+
 	formManager.AddField(components.FieldConfig{
 		Name:        components.FieldNameHost,
 		Label:       "Хост",
@@ -90,6 +95,84 @@ func NewEditConnectionScreenEmpty() *EditConnectionScreen {
 		FieldType:   components.FieldTypeText,
 	})
 
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameJump,
+		Label:       "Jump хост",
+		Required:    false,
+		Width:       50,
+		MaxLength:   200,
+		Placeholder: "user@bastion:22 (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameGroup,
+		Label:       "Группа",
+		Required:    false,
+		Width:       50,
+		MaxLength:   50,
+		Placeholder: "prod, staging... (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameTags,
+		Label:       "Теги",
+		Required:    false,
+		Width:       50,
+		MaxLength:   200,
+		Placeholder: "web, db через запятую (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameCommand,
+		Label:       "Команда при входе",
+		Required:    false,
+		Width:       50,
+		MaxLength:   500,
+		Placeholder: "htop, tmux attach... (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameWorkDir,
+		Label:       "Рабочий каталог",
+		Required:    false,
+		Width:       50,
+		MaxLength:   200,
+		Placeholder: "~/app, /var/www (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameEnv,
+		Label:       "Переменные окружения",
+		Required:    false,
+		Width:       50,
+		MaxLength:   500,
+		Placeholder: "LC_*, APP_ENV=prod через запятую (необязательно)",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameRecord,
+		Label:       "Записывать сессии (←/→)",
+		Required:    false,
+		Width:       20,
+		Placeholder: "",
+		FieldType:   components.FieldTypeBool,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        components.FieldNameControl,
+		Label:       "Общий канал ControlMaster (←/→)",
+		Required:    false,
+		Width:       20,
+		Placeholder: "",
+		FieldType:   components.FieldTypeBool,
+	})
+
 	formManager.AddField(components.FieldConfig{
 		Name:        components.FieldNameAuth,
 		Label:       "Использовать пароль (←/→)",
@@ -163,6 +246,7 @@ func NewEditConnectionScreenEmpty() *EditConnectionScreen {
 func (ecs *EditConnectionScreen) SetData(data interface{}) {
 	if connection, ok := data.(models.Connection); ok {
 		ecs.connection = &connection
+		ecs.opened = connection
 		// Добавляем отладочную информацию
 		ecs.messageManager.AddInfo(fmt.Sprintf("Получены данные для редактирования: %s (ID: %s)", connection.Name, connection.ID))
 		// Обновляем заголовок экрана
@@ -219,6 +303,76 @@ func (ecs *EditConnectionScreen) prefillForm() {
 		}
 	}
 
+	parentField := ecs.formManager.GetField(components.FieldNameParent)
+	if parentField != nil && ecs.connection.Parent != "" {
+		if parent := ecs.connectionSvc.GetConnectionByID(ecs.connection.Parent); parent != nil {
+			parentField.SetValue(parent.Name)
+		}
+	}
+
+	templateField := ecs.formManager.GetField(components.FieldNameTemplate)
+	if templateField != nil {
+		templateField.SetValue(fmt.Sprintf("%t", ecs.connection.Template))
+	}
+
+	jumpField := ecs.formManager.GetField(components.FieldNameJump)
+	if jumpField != nil {
+		if textInput, ok := jumpField.GetTextInput(); ok {
+			textInput.SetValue(ecs.connection.JumpHost)
+			jumpField.SetTextInput(textInput)
+		}
+	}
+
+	groupField := ecs.formManager.GetField(components.FieldNameGroup)
+	if groupField != nil {
+		if textInput, ok := groupField.GetTextInput(); ok {
+			textInput.SetValue(ecs.connection.Group)
+			groupField.SetTextInput(textInput)
+		}
+	}
+
+	tagsField := ecs.formManager.GetField(components.FieldNameTags)
+	if tagsField != nil {
+		if textInput, ok := tagsField.GetTextInput(); ok {
+			textInput.SetValue(strings.Join(ecs.connection.Tags, ", "))
+			tagsField.SetTextInput(textInput)
+		}
+	}
+
+	commandField := ecs.formManager.GetField(components.FieldNameCommand)
+	if commandField != nil {
+		if textInput, ok := commandField.GetTextInput(); ok {
+			textInput.SetValue(ecs.connection.RemoteCommand)
+			commandField.SetTextInput(textInput)
+		}
+	}
+
+	workDirField := ecs.formManager.GetField(components.FieldNameWorkDir)
+	if workDirField != nil {
+		if textInput, ok := workDirField.GetTextInput(); ok {
+			textInput.SetValue(ecs.connection.WorkDir)
+			workDirField.SetTextInput(textInput)
+		}
+	}
+
+	envField := ecs.formManager.GetField(components.FieldNameEnv)
+	if envField != nil {
+		if textInput, ok := envField.GetTextInput(); ok {
+			textInput.SetValue(models.FormatEnv(ecs.connection.Env))
+			envField.SetTextInput(textInput)
+		}
+	}
+
+	recordField := ecs.formManager.GetField(components.FieldNameRecord)
+	if recordField != nil {
+		recordField.SetValue(fmt.Sprintf("%t", ecs.connection.Record))
+	}
+
+	controlField := ecs.formManager.GetField(components.FieldNameControl)
+	if controlField != nil {
+		controlField.SetValue(fmt.Sprintf("%t", ecs.connection.ControlMaster))
+	}
+
 	// Определяем тип аутентификации
 	authField := ecs.formManager.GetField(components.FieldNameAuth)
 	if authField != nil {
@@ -245,8 +399,9 @@ func (ecs *EditConnectionScreen) prefillForm() {
 		}
 	}
 
-	// Обновляем видимость полей
+	// Обновляем видимость полей и отметки наследования
 	ecs.updateFieldVisibility()
+	updateTemplateFields(ecs.formManager, &ecs.opened)
 
 	// Принудительно обновляем фокус полей
 	ecs.formManager.UpdateFocus()
@@ -377,6 +532,9 @@ func (ecs *EditConnectionScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// Обновляем видимость полей на основе выбора аутентификации
 	ecs.updateFieldVisibility()
 
+	// Отмечаем поля, унаследованные от шаблона
+	updateTemplateFields(ecs.formManager, &ecs.opened)
+
 	// Обновляем содержимое viewport
 	ecs.updateViewportContent()
 
@@ -460,34 +618,29 @@ func (ecs *EditConnectionScreen) saveConnection() tea.Cmd {
 	// Получаем значения полей
 	values := ecs.formManager.GetValues()
 
-	// Обновляем подключение
-	port := 22 // По умолчанию
-	if values[components.FieldNamePort] != "" {
-		if p, err := strconv.Atoi(values[components.FieldNamePort]); err == nil {
-			port = p
-		}
+	// Обновляем поля подключения, сохраняя его ID и время создания
+	updated := connectionFromForm(values)
+	updated.ID = ecs.connection.ID
+	updated.CreatedAt = ecs.connection.CreatedAt
+	updated.UpdatedAt = time.Now()
+	updated.Source = ecs.connection.Source
+	updated.SourceHost = ecs.connection.SourceHost
+
+	// Связываем с шаблоном: пустые поля берутся из него
+	if _, err := applyFormTemplate(updated, values, &ecs.opened); err != nil {
+		ecs.messageManager.AddError(fmt.Sprintf("❌ Шаблон: %v", err))
+		return nil
 	}
+	ecs.connection = updated
 
-	// Обновляем поля подключения
-	ecs.connection.Name = values[components.FieldNameName]
-	ecs.connection.Host = values[components.FieldNameHost]
-	ecs.connection.Port = port
-	ecs.connection.User = values[components.FieldNameUser]
-	ecs.connection.KeyPath = values[components.FieldNameKey]
-	ecs.connection.UseSSHKey = !(values[components.FieldNameAuth] == "true") // Если не пароль, то SSH ключ
-	ecs.connection.HasPassword = values[components.FieldNameAuth] == "true" && values[components.FieldNamePassword] != ""
-	ecs.connection.UpdatedAt = time.Now()
-
-	// Обновляем пароль если используется
-	if ecs.connection.HasPassword {
-		ecs.connection.Password = values[components.FieldNamePassword]
-	} else {
-		ecs.connection.Password = ""
+	// Проверяем указанный SSH ключ
+	if !validateConnectionKey(ecs.connection, ecs.messageManager) {
+		return nil
 	}
 
 	// Сохраняем изменения
 	ecs.messageManager.AddInfo(fmt.Sprintf("Сохраняем подключение: %s (ID: %s)", ecs.connection.Name, ecs.connection.ID))
-	err := ecs.connectionSvc.UpdateConnection(ecs.connection)
+	err := ecs.connectionSvc.UpdateConnection(&ecs.opened, ecs.connection)
 	if err != nil {
 		// Показываем ошибку сохранения
 		ecs.messageManager.AddError(fmt.Sprintf("Ошибка сохранения: %v", err))
@@ -0,0 +1,491 @@
+package screens
+
+import (
+	"context"
+	"fmt"
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/ssh"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"ssh-keeper/internal/ui/styles"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// execMaxLines сколько последних строк вывода хранится для каждого сервера
+const execMaxLines = 500
+
+// execEventsMsg пачка событий выполнения команды
+type execEventsMsg struct {
+	events []ssh.ExecEvent
+	closed bool
+}
+
+// execHost состояние выполнения команды на одном сервере
+type execHost struct {
+	connection models.Connection
+	lines      []string
+	done       bool
+	result     ssh.ExecResult
+}
+
+// ExecScreen представляет экран выполнения команды на нескольких серверах
+type ExecScreen struct {
+	*BaseScreen
+	hosts          []*execHost
+	commandInput   textinput.Model
+	command        string
+	running        bool
+	started        bool
+	events         chan ssh.ExecEvent
+	cancel         context.CancelFunc
+	focused        int
+	zoomed         bool
+	messageManager *components.MessageManager
+}
+
+// NewExecScreen создает экран выполнения команды (для фабрики)
+func NewExecScreen() *ExecScreen {
+	baseScreen := NewBaseScreen("SSH Keeper - Выполнить команду")
+
+	commandInput := textinput.New()
+	commandInput.Placeholder = "uptime"
+	commandInput.CharLimit = 500
+	commandInput.Width = 60
+	commandInput.Focus()
+
+	return &ExecScreen{
+		BaseScreen:     baseScreen,
+		commandInput:   commandInput,
+		messageManager: components.NewMessageManager(),
+	}
+}
+
+// SetData устанавливает список подключений для выполнения команды
+func (es *ExecScreen) SetData(data interface{}) {
+	connections, ok := data.([]models.Connection)
+	if !ok || len(connections) == 0 {
+		es.messageManager.AddError("Ошибка: не выбраны подключения")
+		return
+	}
+
+	es.hosts = make([]*execHost, 0, len(connections))
+	for _, conn := range connections {
+		es.hosts = append(es.hosts, &execHost{connection: conn})
+	}
+	es.BaseScreen.SetTitle(fmt.Sprintf("SSH Keeper - Выполнить команду (%d серв.)", len(connections)))
+}
+
+// start запускает команду на всех серверах
+func (es *ExecScreen) start() tea.Cmd {
+	command := strings.TrimSpace(es.commandInput.Value())
+	if command == "" {
+		es.messageManager.AddError("Введите команду")
+		return nil
+	}
+	if len(es.hosts) == 0 {
+		return nil
+	}
+
+	connections := make([]models.Connection, 0, len(es.hosts))
+	for _, host := range es.hosts {
+		host.lines = nil
+		host.done = false
+		host.result = ssh.ExecResult{}
+		connections = append(connections, host.connection)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	es.command = command
+	es.cancel = cancel
+	es.events = make(chan ssh.ExecEvent, 256)
+	es.running = true
+	es.started = true
+	es.focused = 0
+	es.zoomed = false
+	es.commandInput.Blur()
+
+	go ssh.RunParallel(ctx, connections, command, ssh.DefaultExecConcurrency, es.events)
+
+	return waitExecEvents(es.events)
+}
+
+// waitExecEvents ожидает следующее событие и забирает уже накопившиеся
+func waitExecEvents(events <-chan ssh.ExecEvent) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := <-events
+		if !ok {
+			return execEventsMsg{closed: true}
+		}
+
+		batch := []ssh.ExecEvent{event}
+		for len(batch) < 200 {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return execEventsMsg{events: batch, closed: true}
+				}
+				batch = append(batch, event)
+			default:
+				return execEventsMsg{events: batch}
+			}
+		}
+		return execEventsMsg{events: batch}
+	}
+}
+
+// stop прерывает выполнение и дочитывает канал событий, чтобы не блокировать ssh
+func (es *ExecScreen) stop() {
+	if !es.running {
+		return
+	}
+	es.cancel()
+	go func(events <-chan ssh.ExecEvent) {
+		for range events {
+		}
+	}(es.events)
+	es.running = false
+}
+
+// applyEvents применяет события к состоянию серверов
+func (es *ExecScreen) applyEvents(events []ssh.ExecEvent) {
+	for _, event := range events {
+		host := es.hosts[event.Index]
+		if event.Done {
+			host.done = true
+			host.result = event.Result
+			continue
+		}
+
+		line := event.Line
+		if event.Stderr {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorWarning)).Render(line)
+		}
+		host.lines = append(host.lines, line)
+		if len(host.lines) > execMaxLines {
+			host.lines = host.lines[len(host.lines)-execMaxLines:]
+		}
+	}
+}
+
+// finish подводит итог после завершения команды на всех серверах
+func (es *ExecScreen) finish() {
+	es.running = false
+
+	var failed []string
+	for _, host := range es.hosts {
+		if host.result.Failed() {
+			failed = append(failed, fmt.Sprintf("%s (%s)", host.connection.Name, execResultText(host.result)))
+		}
+	}
+
+	if len(failed) == 0 {
+		es.messageManager.AddSuccess(fmt.Sprintf("Команда выполнена на всех серверах (%d)", len(es.hosts)))
+		return
+	}
+	es.messageManager.AddError(fmt.Sprintf("Ошибка на %d из %d: %s",
+		len(failed), len(es.hosts), strings.Join(failed, ", ")))
+}
+
+// execResultText возвращает краткое описание результата
+func execResultText(result ssh.ExecResult) string {
+	switch {
+	case result.Err == context.Canceled:
+		return "прервано"
+	case result.Err != nil:
+		return result.Err.Error()
+	default:
+		return fmt.Sprintf("код %d", result.ExitCode)
+	}
+}
+
+// Update обрабатывает обновления состояния
+func (es *ExecScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		es.SetSize(msg.Width, msg.Height)
+		return es, nil
+
+	case execEventsMsg:
+		if !es.running {
+			return es, nil
+		}
+		es.applyEvents(msg.events)
+		if msg.closed {
+			es.finish()
+			return es, nil
+		}
+		return es, waitExecEvents(es.events)
+
+	case tea.KeyMsg:
+		if !es.started {
+			return es, es.updateInput(msg)
+		}
+
+		switch msg.String() {
+		case "ctrl+c":
+			es.stop()
+			return es, tea.Quit
+		case "esc":
+			if es.running {
+				es.stop()
+				es.messageManager.AddWarning("Выполнение прервано")
+				return es, nil
+			}
+			return es, ui.GoBackCmd()
+		case "right", "down", "tab":
+			es.focused = (es.focused + 1) % len(es.hosts)
+		case "left", "up", "shift+tab":
+			es.focused = (es.focused - 1 + len(es.hosts)) % len(es.hosts)
+		case "enter":
+			es.zoomed = !es.zoomed
+		case "n":
+			// Новая команда для тех же серверов
+			if !es.running {
+				es.started = false
+				es.zoomed = false
+				es.commandInput.Focus()
+			}
+		}
+	}
+
+	return es, nil
+}
+
+// updateInput обрабатывает ввод команды
+func (es *ExecScreen) updateInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "ctrl+c":
+		return tea.Quit
+	case "esc":
+		return ui.GoBackCmd()
+	case "enter":
+		return es.start()
+	}
+
+	var cmd tea.Cmd
+	es.commandInput, cmd = es.commandInput.Update(msg)
+	return cmd
+}
+
+// View возвращает строку для отрисовки
+func (es *ExecScreen) View() string {
+	es.updateContent()
+	return es.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (es *ExecScreen) updateContent() {
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted)).
+		Italic(styles.TextItalic).
+		Width(100)
+
+	var contentParts []string
+	var instructionsText string
+
+	if !es.started {
+		contentParts = append(contentParts, es.renderInput())
+		instructionsText = "Enter выполнить • Esc назад"
+	} else {
+		contentParts = append(contentParts, es.renderSummary(), "", es.renderPanes())
+		if es.running {
+			instructionsText = "←/→ сервер • Enter развернуть • Esc прервать"
+		} else {
+			instructionsText = "←/→ сервер • Enter развернуть • N новая команда • Esc назад"
+		}
+	}
+
+	if messages := es.messageManager.RenderMessages(80); messages != "" {
+		contentParts = append(contentParts, messages)
+	}
+	contentParts = append(contentParts, instructionsStyle.Render(instructionsText))
+
+	es.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+}
+
+// renderInput отрисовывает ввод команды и список серверов
+func (es *ExecScreen) renderInput() string {
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorPrimary)).
+		Bold(true)
+
+	inputStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(styles.ColorWarning)).
+		Padding(0, 1)
+
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorMuted))
+
+	parts := []string{
+		labelStyle.Render("Команда:"),
+		inputStyle.Render(es.commandInput.View()),
+		"",
+		labelStyle.Render(fmt.Sprintf("Серверы (%d):", len(es.hosts))),
+	}
+
+	const maxListed = 10
+	for i, host := range es.hosts {
+		if i == maxListed {
+			parts = append(parts, mutedStyle.Render(fmt.Sprintf("  ... и еще %d", len(es.hosts)-maxListed)))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("  • %s %s", host.connection.Name,
+			mutedStyle.Render(ssh.Address(&host.connection))))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
+
+// renderSummary отрисовывает строку с командой и счетчиками
+func (es *ExecScreen) renderSummary() string {
+	done, failed := 0, 0
+	for _, host := range es.hosts {
+		if host.done {
+			done++
+			if host.result.Failed() {
+				failed++
+			}
+		}
+	}
+
+	commandStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorPrimary)).
+		Bold(true)
+
+	summary := fmt.Sprintf("$ %s   готово %d/%d", commandStyle.Render(es.command), done, len(es.hosts))
+	if failed > 0 {
+		summary += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(styles.ColorError)).
+			Render(fmt.Sprintf(", с ошибкой %d", failed))
+	}
+	return summary
+}
+
+// renderPanes отрисовывает панели вывода серверов сеткой
+func (es *ExecScreen) renderPanes() string {
+	// Ширина контента экрана - es.width-14, у каждой панели рамка в 2 символа
+	contentWidth := es.width - 14
+	availableHeight := es.height - 18
+	if availableHeight < 5 {
+		availableHeight = 5
+	}
+
+	if es.zoomed {
+		return es.renderPane(es.hosts[es.focused], true, contentWidth-2, availableHeight-2)
+	}
+
+	columns := 1
+	if contentWidth >= 100 && len(es.hosts) > 1 {
+		columns = 2
+	}
+	rows := (len(es.hosts) + columns - 1) / columns
+
+	// Высота панели: не меньше 3 строк вывода; если все не помещаются, показываем
+	// окно строк вокруг выбранного сервера
+	paneHeight := availableHeight/rows - 2
+	if paneHeight < 3 {
+		paneHeight = 3
+	}
+	visibleRows := availableHeight / (paneHeight + 2)
+	if visibleRows < 1 {
+		visibleRows = 1
+	}
+	firstRow := 0
+	if focusedRow := es.focused / columns; focusedRow >= visibleRows {
+		firstRow = focusedRow - visibleRows + 1
+	}
+
+	paneWidth := (contentWidth - (columns - 1)) / columns
+	var renderedRows []string
+	for row := firstRow; row < rows && row < firstRow+visibleRows; row++ {
+		var cells []string
+		for col := 0; col < columns; col++ {
+			index := row*columns + col
+			if index >= len(es.hosts) {
+				break
+			}
+			if col > 0 {
+				cells = append(cells, " ")
+			}
+			cells = append(cells, es.renderPane(es.hosts[index], index == es.focused, paneWidth-2, paneHeight))
+		}
+		renderedRows = append(renderedRows, lipgloss.JoinHorizontal(lipgloss.Top, cells...))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, renderedRows...)
+}
+
+// renderPane отрисовывает вывод одного сервера: заголовок со статусом и последние строки
+func (es *ExecScreen) renderPane(host *execHost, focused bool, width, height int) string {
+	if width < 10 {
+		width = 10
+	}
+
+	borderColor := styles.ColorWarning
+	status := "⏳ выполняется"
+	switch {
+	case !host.done && !es.running:
+		borderColor = styles.ColorGray
+		status = "✖ прервано"
+	case !host.done:
+	case host.result.Failed():
+		borderColor = styles.ColorError
+		status = "✖ " + execResultText(host.result)
+	default:
+		borderColor = styles.ColorSuccess
+		status = fmt.Sprintf("✔ %s", host.result.Duration.Round(100*time.Millisecond))
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	if focused {
+		titleStyle = titleStyle.Foreground(lipgloss.Color(styles.ColorPrimary))
+	}
+	title := titleStyle.Render(truncateRunes(host.connection.Name, width/2)) + "  " + status
+
+	lines := host.lines
+	if len(lines) > height-1 {
+		lines = lines[len(lines)-(height-1):]
+	}
+	body := make([]string, 0, height)
+	body = append(body, title)
+	for _, line := range lines {
+		body = append(body, lipgloss.NewStyle().MaxWidth(width).Render(line))
+	}
+	for len(body) < height {
+		body = append(body, "")
+	}
+
+	paneStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(borderColor)).
+		Width(width)
+	if focused {
+		paneStyle = paneStyle.BorderStyle(lipgloss.ThickBorder())
+	}
+
+	return paneStyle.Render(strings.Join(body, "\n"))
+}
+
+// truncateRunes обрезает строку до max символов
+func truncateRunes(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max || max < 2 {
+		return value
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// Init инициализирует экран
+func (es *ExecScreen) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// GetName возвращает имя экрана
+func (es *ExecScreen) GetName() string {
+	return "exec"
+}
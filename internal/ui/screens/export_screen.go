@@ -40,6 +40,69 @@ func NewExportScreen() *ExportScreen {
 		FieldType:   components.FieldTypeText,
 	})
 
+	formatOptions := make([]components.SelectOption, 0, len(services.ExportFormats))
+	for _, format := range services.ExportFormats {
+		formatOptions = append(formatOptions, components.SelectOption{
+			Value: string(format),
+			Label: exportFormatLabels[format],
+		})
+	}
+	formManager.AddField(components.FieldConfig{
+		Name:      "export_format",
+		Label:     "Формат (←/→)",
+		Width:     30,
+		FieldType: components.FieldTypeSelect,
+		Options:   formatOptions,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:      "export_secrets",
+		Label:     "Пароли (←/→)",
+		Width:     30,
+		FieldType: components.FieldTypeSelect,
+		Options: []components.SelectOption{
+			{Value: string(services.SecretsOmit), Label: "не экспортировать"},
+			{Value: string(services.SecretsEncrypt), Label: "зашифровать"},
+			{Value: string(services.SecretsPlain), Label: "открытым текстом"},
+		},
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "export_passphrase",
+		Label:       "Пароль файла",
+		Width:       30,
+		MaxLength:   128,
+		Placeholder: fmt.Sprintf("не короче %d символов", services.MinBundlePassphraseLength),
+		FieldType:   components.FieldTypePassword,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "export_passphrase_confirm",
+		Label:       "Повтор пароля",
+		Width:       30,
+		MaxLength:   128,
+		Placeholder: "",
+		FieldType:   components.FieldTypePassword,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "export_group",
+		Label:       "Группа",
+		Width:       30,
+		MaxLength:   50,
+		Placeholder: "все группы",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "export_tag",
+		Label:       "Тег",
+		Width:       30,
+		MaxLength:   50,
+		Placeholder: "все теги",
+		FieldType:   components.FieldTypeText,
+	})
+
 	formManager.AddField(components.FieldConfig{
 		Name:      "export_button",
 		Label:     "Экспорт",
@@ -47,6 +110,10 @@ func NewExportScreen() *ExportScreen {
 		Style:     "success",
 	})
 
+	// Формат по умолчанию берется из настроек приложения
+	formManager.GetField("export_format").SetValue(string(services.GetGlobalExportFormat()))
+	updateExportFields(formManager)
+
 	// Устанавливаем фокус на первое поле
 	formManager.SetCurrentField("export_path")
 	formManager.UpdateFocus()
@@ -67,6 +134,52 @@ func NewExportScreen() *ExportScreen {
 	}
 }
 
+// exportFormatLabels названия форматов экспорта
+var exportFormatLabels = map[services.ExportFormat]string{
+	services.ExportFormatOpenSSH:     "OpenSSH config",
+	services.ExportFormatKeeper:      "SSH Keeper config",
+	services.ExportFormatJSON:        "JSON",
+	services.ExportFormatYAML:        "YAML",
+	services.ExportFormatCSV:         "CSV",
+	services.ExportFormatBundle:      "Зашифрованный файл",
+	services.ExportFormatAnsibleINI:  "Ansible inventory (INI)",
+	services.ExportFormatAnsibleYAML: "Ansible inventory (YAML)",
+	services.ExportFormatKnownHosts:  "known_hosts",
+}
+
+// exportFormatDescriptions пояснения к форматам экспорта
+var exportFormatDescriptions = map[services.ExportFormat]string{
+	services.ExportFormatOpenSSH:     "Настоящий ~/.ssh/config: только стандартные ключевые слова, пароли не экспортируются.",
+	services.ExportFormatKeeper:      "Формат SSH Keeper с группами, тегами и паролями; подходит для импорта в SSH Keeper.",
+	services.ExportFormatJSON:        "Массив JSON: одно подключение - один объект.",
+	services.ExportFormatYAML:        "Список connections в YAML.",
+	services.ExportFormatCSV:         "Таблица CSV с заголовком; теги через запятую.",
+	services.ExportFormatBundle:      "Все подключения с паролями, зашифрованные паролем файла (Argon2id + AES-256-GCM).\nПароль не связан с мастер-паролем; импорт запросит его.",
+	services.ExportFormatAnsibleINI:  "Inventory Ansible: группы и теги - группы, адрес, порт, пользователь, ключ и jump хост - переменные хоста. Пароли не экспортируются.",
+	services.ExportFormatAnsibleYAML: "Inventory Ansible в YAML (all: children: ...): то же, что INI.",
+	services.ExportFormatKnownHosts:  "Ключи хостов, уже проверенные в ~/.ssh/known_hosts; подключения без ключа отмечаются комментарием.",
+}
+
+// updateExportFields показывает поля пароля файла только для зашифрованного экспорта
+func updateExportFields(formManager *components.FormManager) {
+	bundle := services.ExportFormat(formManager.GetField("export_format").Value()) == services.ExportFormatBundle
+	formManager.GetField("export_secrets").SetVisible(!bundle)
+	formManager.GetField("export_passphrase").SetVisible(bundle)
+	formManager.GetField("export_passphrase_confirm").SetVisible(bundle)
+}
+
+// exportOptions собирает параметры экспорта из формы
+func (es *ExportScreen) exportOptions() services.ExportOptions {
+	values := es.formManager.GetValues()
+	return services.ExportOptions{
+		Format:     services.ExportFormat(values["export_format"]),
+		Secrets:    services.SecretsMode(values["export_secrets"]),
+		Group:      strings.TrimSpace(values["export_group"]),
+		Tag:        strings.TrimSpace(values["export_tag"]),
+		Passphrase: values["export_passphrase"],
+	}
+}
+
 // Update обрабатывает обновления состояния
 func (es *ExportScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
@@ -109,6 +222,7 @@ func (es *ExportScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 							cmd = teaCmd
 						}
 					}
+					updateExportFields(es.formManager)
 				}
 			}
 		}
@@ -153,8 +267,17 @@ func (es *ExportScreen) updateContent() {
 	// Создаем заголовок
 	header := headerStyle.Render("Экспорт конфигурации SSH")
 
-	// Создаем описание
-	description := descriptionStyle.Render("Экспорт создаст файл с конфигурацией SSH в том же формате, но без шифрования паролей.")
+	// Создаем описание выбранного формата
+	opts := es.exportOptions()
+	descriptionText := exportFormatDescriptions[opts.Format]
+	switch {
+	case !opts.Format.SupportsSecrets(), opts.Format == services.ExportFormatBundle:
+	case opts.Secrets == services.SecretsPlain:
+		descriptionText += "\nПароли будут записаны в открытом виде - защитите файл!"
+	case opts.Secrets == services.SecretsEncrypt:
+		descriptionText += "\nПароли будут зашифрованы мастер-паролем."
+	}
+	description := descriptionStyle.Render(descriptionText)
 
 	// Рендерим форму
 	formContent := es.formManager.RenderForm()
@@ -198,8 +321,23 @@ func (es *ExportScreen) performExport() tea.Cmd {
 			}
 		}
 
-		// Выполняем экспорт без шифрования паролей
-		err := es.connectionService.ExportConfigPlain(exportPath)
+		opts := es.exportOptions()
+		if opts.Format == services.ExportFormatBundle {
+			if len(opts.Passphrase) < services.MinBundlePassphraseLength {
+				return ExportResultMsg{
+					Message: fmt.Sprintf("Пароль файла должен содержать минимум %d символов", services.MinBundlePassphraseLength),
+					Type:    "error",
+				}
+			}
+			if opts.Passphrase != es.formManager.GetField("export_passphrase_confirm").Value() {
+				return ExportResultMsg{
+					Message: "Пароли не совпадают",
+					Type:    "error",
+				}
+			}
+		}
+
+		count, err := es.connectionService.Export(exportPath, opts)
 		if err != nil {
 			return ExportResultMsg{
 				Success: false,
@@ -208,16 +346,15 @@ func (es *ExportScreen) performExport() tea.Cmd {
 			}
 		}
 
-		// Получаем количество подключений для отчета
-		connections := es.connectionService.GetAllConnections()
+		details := []string{fmt.Sprintf("Экспортировано %d подключений", count)}
+		if opts.Format.SupportsSecrets() && opts.Format != services.ExportFormatBundle && opts.Secrets == services.SecretsPlain {
+			details = append(details, "Пароли сохранены в открытом виде - защитите файл!")
+		}
 		return ExportResultMsg{
 			Success: true,
-			Message: fmt.Sprintf("Конфигурация успешно экспортирована в %s", exportPath),
+			Message: fmt.Sprintf("Конфигурация успешно экспортирована в %s (%s)", exportPath, exportFormatLabels[opts.Format]),
 			Type:    "success",
-			Details: []string{
-				fmt.Sprintf("Экспортировано %d подключений", len(connections)),
-				"Пароли сохранены в открытом виде - защитите файл!",
-			},
+			Details: details,
 		}
 	}
 }
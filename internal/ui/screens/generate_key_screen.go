@@ -0,0 +1,284 @@
+package screens
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"ssh-keeper/internal/services"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// GenerateKeyScreen представляет экран генерации нового SSH ключа
+type GenerateKeyScreen struct {
+	*BaseScreen
+	formManager    *components.FormManager
+	messageManager *components.MessageManager
+	keyService     *services.SSHKeyService
+}
+
+// NewGenerateKeyScreen создает новый экран генерации ключа
+func NewGenerateKeyScreen() *GenerateKeyScreen {
+	baseScreen := NewBaseScreen("SSH Keeper - Новый SSH ключ")
+
+	// Создаем менеджер формы
+	formManager := components.NewFormManager()
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "key_name",
+		Label:       "Имя файла",
+		Required:    true,
+		Width:       40,
+		MaxLength:   64,
+		Placeholder: "id_ed25519_work",
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "key_type",
+		Label:       "Тип",
+		Required:    false,
+		Width:       20,
+		MaxLength:   10,
+		Placeholder: services.KeyTypeED25519 + " / " + services.KeyTypeRSA,
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "key_bits",
+		Label:       "Бит (RSA)",
+		Required:    false,
+		Width:       15,
+		MaxLength:   5,
+		Placeholder: strconv.Itoa(services.DefaultRSABits),
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "key_passphrase",
+		Label:       "Парольная фраза",
+		Required:    false,
+		Width:       40,
+		MaxLength:   100,
+		Placeholder: "Оставьте пустым для ключа без пароля",
+		FieldType:   components.FieldTypePassword,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "key_passphrase_confirm",
+		Label:       "Повтор фразы",
+		Required:    false,
+		Width:       40,
+		MaxLength:   100,
+		Placeholder: "Повторите парольную фразу",
+		FieldType:   components.FieldTypePassword,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:        "key_comment",
+		Label:       "Комментарий",
+		Required:    false,
+		Width:       40,
+		MaxLength:   100,
+		Placeholder: defaultKeyComment(),
+		FieldType:   components.FieldTypeText,
+	})
+
+	formManager.AddField(components.FieldConfig{
+		Name:      "generate_button",
+		Label:     "Создать ключ",
+		FieldType: components.FieldTypeButton,
+		Style:     "success",
+	})
+
+	// Устанавливаем фокус на первое поле
+	formManager.SetCurrentField("key_name")
+	formManager.UpdateFocus()
+
+	return &GenerateKeyScreen{
+		BaseScreen:     baseScreen,
+		formManager:    formManager,
+		messageManager: components.NewMessageManager(),
+		keyService:     services.GetGlobalSSHKeyService(),
+	}
+}
+
+// Update обрабатывает обновления состояния
+func (gks *GenerateKeyScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		gks.SetSize(msg.Width, msg.Height)
+		return gks, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return gks, ui.GoBackCmd()
+
+		case "tab":
+			gks.formManager.NextField()
+			gks.formManager.UpdateFocus()
+
+		case "shift+tab":
+			gks.formManager.PrevField()
+			gks.formManager.UpdateFocus()
+
+		case "enter":
+			if gks.formManager.GetCurrentField() == "generate_button" {
+				return gks, gks.generateKey()
+			}
+			gks.formManager.NextField()
+			gks.formManager.UpdateFocus()
+
+		default:
+			// Обновляем текущее поле
+			if currentField := gks.formManager.GetCurrentFieldModel(); currentField != nil {
+				if !currentField.IsButton() {
+					_, fieldCmd := currentField.Update(msg)
+					if fieldCmd != nil {
+						if teaCmd, ok := fieldCmd.(tea.Cmd); ok {
+							cmd = teaCmd
+						}
+					}
+				}
+			}
+		}
+
+	case generateKeyResultMsg:
+		if msg.err != nil {
+			gks.messageManager.AddError(fmt.Sprintf("Ошибка генерации ключа: %v", msg.err))
+			return gks, nil
+		}
+		gks.messageManager.AddSuccess(fmt.Sprintf("Ключ создан: %s", msg.key.Path))
+		gks.messageManager.AddInfo(msg.key.Fingerprint)
+		gks.clearForm()
+	}
+
+	return gks, cmd
+}
+
+// generateKeyResultMsg сообщение с результатом генерации ключа
+type generateKeyResultMsg struct {
+	key *services.SSHKeyInfo
+	err error
+}
+
+// generateKey создает ключ по данным формы
+func (gks *GenerateKeyScreen) generateKey() tea.Cmd {
+	if !gks.formManager.ValidateAll() {
+		gks.messageManager.AddError("❌ Укажите имя файла ключа")
+		return nil
+	}
+
+	values := gks.formManager.GetValues()
+
+	if values["key_passphrase"] != values["key_passphrase_confirm"] {
+		gks.messageManager.AddError("❌ Парольные фразы не совпадают")
+		return nil
+	}
+
+	bits := 0
+	if value := strings.TrimSpace(values["key_bits"]); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			gks.messageManager.AddError("❌ Размер ключа должен быть числом")
+			return nil
+		}
+		bits = parsed
+	}
+
+	comment := strings.TrimSpace(values["key_comment"])
+	if comment == "" {
+		comment = defaultKeyComment()
+	}
+
+	opts := services.KeyGenOptions{
+		Name:       values["key_name"],
+		Type:       values["key_type"],
+		Bits:       bits,
+		Passphrase: values["key_passphrase"],
+		Comment:    comment,
+	}
+
+	gks.messageManager.AddInfo("Генерация ключа...")
+
+	// Генерация RSA 4096 может занять заметное время - выполняем асинхронно
+	return func() tea.Msg {
+		key, err := gks.keyService.GenerateKey(opts)
+		return generateKeyResultMsg{key: key, err: err}
+	}
+}
+
+// clearForm очищает поля формы
+func (gks *GenerateKeyScreen) clearForm() {
+	for _, fieldName := range gks.formManager.GetFieldOrder() {
+		if field := gks.formManager.GetField(fieldName); field != nil {
+			field.SetValue("")
+		}
+	}
+	gks.formManager.SetCurrentField("key_name")
+	gks.formManager.UpdateFocus()
+}
+
+// View возвращает строку для отрисовки
+func (gks *GenerateKeyScreen) View() string {
+	gks.updateContent()
+	return gks.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (gks *GenerateKeyScreen) updateContent() {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7D56F4")).
+		Bold(true).
+		Margin(0, 0, 1, 0)
+
+	descriptionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#808080")).
+		Margin(0, 0, 1, 0)
+
+	header := headerStyle.Render("Генерация SSH ключа")
+	description := descriptionStyle.Render(fmt.Sprintf("Ключ будет сохранен в %s с правами 600.", gks.keyService.GetSSHDir()))
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		description,
+		gks.formManager.RenderForm(),
+		"",
+		gks.messageManager.RenderMessages(gks.width-20),
+	)
+
+	gks.SetContent(content)
+}
+
+// Init инициализирует экран
+func (gks *GenerateKeyScreen) Init() tea.Cmd {
+	return nil
+}
+
+// GetName возвращает имя экрана
+func (gks *GenerateKeyScreen) GetName() string {
+	return "generate_key"
+}
+
+// defaultKeyComment возвращает комментарий по умолчанию в виде user@host
+func defaultKeyComment() string {
+	username := "user"
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		username = current.Username
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return username
+	}
+
+	return fmt.Sprintf("%s@%s", username, hostname)
+}
@@ -0,0 +1,224 @@
+package screens
+
+import (
+	"fmt"
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/services"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"ssh-keeper/internal/ui/styles"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// historyRecentSessions сколько последних сессий показывается под статистикой
+const historyRecentSessions = 20
+
+// HistoryScreen представляет экран истории сессий со статистикой по серверам
+type HistoryScreen struct {
+	*BaseScreen
+	stats          []*models.ConnectionStats
+	records        []models.SessionRecord
+	byFrequency    bool
+	offset         int
+	messageManager *components.MessageManager
+}
+
+// NewHistoryScreen создает экран истории сессий
+func NewHistoryScreen() *HistoryScreen {
+	return &HistoryScreen{
+		BaseScreen:     NewBaseScreen("SSH Keeper - История сессий"),
+		byFrequency:    true,
+		messageManager: components.NewMessageManager(),
+	}
+}
+
+// loadHistory перечитывает журнал сессий
+func (hs *HistoryScreen) loadHistory() {
+	hs.offset = 0
+	history := services.GetGlobalHistoryService()
+	if history == nil {
+		hs.messageManager.AddError("Сервис истории не инициализирован")
+		return
+	}
+
+	stats, err := history.SortedStats(hs.byFrequency)
+	if err != nil {
+		hs.messageManager.AddError(fmt.Sprintf("Ошибка загрузки истории: %v", err))
+		return
+	}
+	records, err := history.Load()
+	if err != nil {
+		hs.messageManager.AddError(fmt.Sprintf("Ошибка загрузки истории: %v", err))
+		return
+	}
+
+	hs.stats = stats
+	hs.records = records
+}
+
+// connectionName возвращает текущее имя подключения или имя из журнала для удаленных
+func connectionName(id, recorded string) string {
+	if conn := services.GetConnectionByID(id); conn != nil {
+		return conn.Name
+	}
+	return recorded + " (удалено)"
+}
+
+// Update обрабатывает обновления состояния
+func (hs *HistoryScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		hs.SetSize(msg.Width, msg.Height)
+		return hs, nil
+
+	case ui.NavigateToMsg:
+		if msg.ScreenName == "history" {
+			hs.loadHistory()
+		}
+		return hs, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return hs, tea.Quit
+		case "esc":
+			return hs, ui.GoBackCmd()
+		case "up", "k":
+			if hs.offset > 0 {
+				hs.offset--
+			}
+		case "down", "j":
+			hs.offset++
+		case "pgup":
+			hs.offset = max(hs.offset-10, 0)
+		case "pgdown":
+			hs.offset += 10
+		case "s":
+			hs.byFrequency = !hs.byFrequency
+			hs.loadHistory()
+		case "ctrl+r":
+			hs.loadHistory()
+		}
+	}
+
+	return hs, nil
+}
+
+// View возвращает строку для отрисовки
+func (hs *HistoryScreen) View() string {
+	hs.updateContent()
+	return hs.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (hs *HistoryScreen) updateContent() {
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted)).
+		Italic(styles.TextItalic).
+		Width(80)
+
+	var contentParts []string
+	if messages := hs.messageManager.RenderMessages(80); messages != "" {
+		contentParts = append(contentParts, messages)
+	}
+
+	if len(hs.stats) == 0 {
+		contentParts = append(contentParts, lipgloss.NewStyle().
+			Foreground(lipgloss.Color(styles.ColorMuted)).
+			Render("История пуста: сессии записываются при подключении из списка подключений"))
+	} else {
+		lines := hs.renderLines()
+
+		// Прокручиваем, оставляя место под инструкции
+		visible := hs.height - 14
+		if visible < 5 {
+			visible = 5
+		}
+		if hs.offset > len(lines)-visible {
+			hs.offset = max(len(lines)-visible, 0)
+		}
+		end := min(hs.offset+visible, len(lines))
+		contentParts = append(contentParts, strings.Join(lines[hs.offset:end], "\n"))
+	}
+
+	sortLabel := "по частоте"
+	if !hs.byFrequency {
+		sortLabel = "по последнему подключению"
+	}
+	contentParts = append(contentParts, "",
+		instructionsStyle.Render(fmt.Sprintf("↑/↓ прокрутка • S сортировка (%s) • Ctrl+R обновить • Esc назад", sortLabel)))
+
+	hs.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+}
+
+// renderLines формирует таблицу статистики и список последних сессий
+func (hs *HistoryScreen) renderLines() []string {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorPrimary)).
+		Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorMuted))
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorError))
+
+	const rowFormat = "%-28s %7s %7s %-14s %10s %10s"
+
+	lines := []string{
+		headerStyle.Render("Статистика по серверам"),
+		mutedStyle.Render(fmt.Sprintf(rowFormat, "Подключение", "Сессий", "Ошибок", "Последняя", "Средняя", "Всего")),
+	}
+	for _, stat := range hs.stats {
+		failures := fmt.Sprintf("%7d", stat.Failures)
+		if stat.Failures > 0 {
+			failures = errorStyle.Render(failures)
+		}
+		lines = append(lines, fmt.Sprintf("%-28s %7d %s %-14s %10s %10s",
+			truncateRunes(connectionName(stat.ConnectionID, stat.Name), 28),
+			stat.Sessions,
+			failures,
+			components.FormatAgo(stat.LastUsed),
+			formatDuration(stat.AverageDuration()),
+			formatDuration(stat.TotalDuration),
+		))
+	}
+
+	lines = append(lines, "", headerStyle.Render("Последние сессии"))
+	for i := len(hs.records) - 1; i >= 0 && i >= len(hs.records)-historyRecentSessions; i-- {
+		record := hs.records[i]
+		status := "✔"
+		if record.ExitCode != 0 {
+			status = errorStyle.Render(fmt.Sprintf("✖ %d", record.ExitCode))
+		}
+		lines = append(lines, fmt.Sprintf("%s  %-28s %10s  %s",
+			record.StartedAt.Format("02.01.2006 15:04"),
+			truncateRunes(connectionName(record.ConnectionID, record.Name), 28),
+			formatDuration(record.Duration()),
+			status,
+		))
+	}
+
+	return lines
+}
+
+// formatDuration форматирует длительность сессии
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%dс", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dм %dс", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dч %dм", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// Init инициализирует экран
+func (hs *HistoryScreen) Init() tea.Cmd {
+	return nil
+}
+
+// GetName возвращает имя экрана
+func (hs *HistoryScreen) GetName() string {
+	return "history"
+}
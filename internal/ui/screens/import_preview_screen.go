@@ -0,0 +1,290 @@
+package screens
+
+import (
+	"fmt"
+	"slices"
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/services"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"ssh-keeper/internal/ui/styles"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ImportPreviewData данные экрана предпросмотра импорта
+type ImportPreviewData struct {
+	Source      services.ImportSource
+	Path        string
+	Connections []models.Connection
+}
+
+// importActionLabels подписи действий импорта
+var importActionLabels = map[services.ImportAction]string{
+	services.ImportCreate:    "создать",
+	services.ImportSkip:      "пропустить",
+	services.ImportOverwrite: "заменить",
+	services.ImportKeepBoth:  "оставить оба",
+	services.ImportMerge:     "объединить",
+	services.ImportFlag:      "пометить удаленным",
+}
+
+// ImportPreviewScreen представляет экран предпросмотра импорта
+type ImportPreviewScreen struct {
+	*BaseScreen
+	data           *ImportPreviewData
+	items          []services.ImportItem
+	cursor         int // Выбранная строка
+	offset         int // Прокрутка списка
+	messageManager *components.MessageManager
+}
+
+// NewImportPreviewScreen создает экран предпросмотра импорта (для фабрики)
+func NewImportPreviewScreen() *ImportPreviewScreen {
+	return &ImportPreviewScreen{
+		BaseScreen:     NewBaseScreen("SSH Keeper - Предпросмотр импорта"),
+		messageManager: components.NewMessageManager(),
+	}
+}
+
+// SetData устанавливает разобранные подключения и сравнивает их с существующими
+func (ips *ImportPreviewScreen) SetData(data interface{}) {
+	preview, ok := data.(*ImportPreviewData)
+	if !ok || preview == nil {
+		ips.messageManager.AddError("Ошибка: нет данных для импорта")
+		return
+	}
+
+	connectionSvc := services.GetGlobalConnectionService()
+	if connectionSvc == nil {
+		ips.messageManager.AddError("Ошибка: сервис подключений не инициализирован")
+		return
+	}
+
+	ips.data = preview
+	ips.items = connectionSvc.PlanImport(preview.Connections)
+	ips.cursor = 0
+	ips.offset = 0
+}
+
+// counts возвращает количество подключений по статусам
+func (ips *ImportPreviewScreen) counts() (created, identical, conflicts, removed int) {
+	for _, item := range ips.items {
+		switch item.Status {
+		case services.ImportStatusNew:
+			created++
+		case services.ImportStatusIdentical:
+			identical++
+		case services.ImportStatusConflict:
+			conflicts++
+		case services.ImportStatusRemoved:
+			removed++
+		}
+	}
+	return created, identical, conflicts, removed
+}
+
+// cycleAction переключает действие выбранной строки
+func (ips *ImportPreviewScreen) cycleAction(step int) {
+	if ips.cursor >= len(ips.items) {
+		return
+	}
+	item := &ips.items[ips.cursor]
+	actions := item.Actions()
+	i := slices.Index(actions, item.Action)
+	item.Action = actions[(i+step+len(actions))%len(actions)]
+}
+
+// setConflictAction задает действие для всех конфликтующих подключений
+func (ips *ImportPreviewScreen) setConflictAction(action services.ImportAction) {
+	changed := 0
+	for i := range ips.items {
+		if ips.items[i].Status == services.ImportStatusConflict {
+			ips.items[i].Action = action
+			changed++
+		}
+	}
+	if changed > 0 {
+		ips.messageManager.AddInfo(fmt.Sprintf("Для %d конфликтов выбрано: %s", changed, importActionLabels[action]))
+	}
+}
+
+// apply применяет выбранные действия одной операцией
+func (ips *ImportPreviewScreen) apply() tea.Cmd {
+	if ips.data == nil {
+		return nil
+	}
+
+	connectionSvc := services.GetGlobalConnectionService()
+	summary, err := connectionSvc.ApplyImport(ips.items,
+		fmt.Sprintf("Импорт из %s (%s)", ips.data.Path, ips.data.Source.Label()))
+	if err != nil {
+		ips.messageManager.AddError(fmt.Sprintf("Ошибка импорта: %v", err))
+		return nil
+	}
+	if summary.Created == 0 && summary.Updated == 0 && summary.Flagged == 0 {
+		ips.messageManager.AddWarning("Все подключения пропущены - импортировать нечего")
+		return nil
+	}
+
+	message := fmt.Sprintf("Импорт: создано %d, обновлено %d, пропущено %d",
+		summary.Created, summary.Updated, summary.Skipped)
+	if summary.Flagged > 0 {
+		message += fmt.Sprintf(", помечено удаленными из источника %d (тег %s)", summary.Flagged, models.SourceRemovedTag)
+	}
+	ips.messageManager.AddSuccess(message)
+	return ui.NavigateToCmd("connections")
+}
+
+// Update обрабатывает обновления состояния
+func (ips *ImportPreviewScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		ips.SetSize(msg.Width, msg.Height)
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return ips, tea.Quit
+		case "esc":
+			return ips, ui.GoBackCmd()
+		case "enter", "ctrl+s":
+			return ips, ips.apply()
+		case "up", "k":
+			ips.cursor = max(ips.cursor-1, 0)
+		case "down", "j":
+			ips.cursor = min(ips.cursor+1, max(len(ips.items)-1, 0))
+		case "right", "l", " ":
+			ips.cycleAction(1)
+		case "left", "h":
+			ips.cycleAction(-1)
+		case "s":
+			ips.setConflictAction(services.ImportSkip)
+		case "o":
+			ips.setConflictAction(services.ImportOverwrite)
+		case "m":
+			ips.setConflictAction(services.ImportMerge)
+		case "b":
+			ips.setConflictAction(services.ImportKeepBoth)
+		}
+	}
+	return ips, nil
+}
+
+// View возвращает строку для отрисовки
+func (ips *ImportPreviewScreen) View() string {
+	ips.updateContent()
+	return ips.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (ips *ImportPreviewScreen) updateContent() {
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted)).
+		Italic(styles.TextItalic).
+		Width(80)
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorSecondary)).
+		Bold(styles.TextBold)
+	newStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorSuccess))
+	conflictStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorWarning))
+	removedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorError))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorMuted))
+	selectedStyle := lipgloss.NewStyle().Bold(styles.TextBold)
+
+	var contentParts []string
+	if messages := ips.messageManager.RenderMessages(80); messages != "" {
+		contentParts = append(contentParts, messages)
+	}
+
+	if ips.data != nil {
+		created, identical, conflicts, removed := ips.counts()
+		summary := fmt.Sprintf("Новых: %s • без изменений: %s • конфликтов: %s",
+			newStyle.Render(fmt.Sprint(created)), mutedStyle.Render(fmt.Sprint(identical)),
+			conflictStyle.Render(fmt.Sprint(conflicts)))
+		if removed > 0 {
+			summary += fmt.Sprintf(" • нет в источнике: %s", removedStyle.Render(fmt.Sprint(removed)))
+		}
+		contentParts = append(contentParts,
+			headerStyle.Render(fmt.Sprintf("%s: %s", ips.data.Source.Label(), ips.data.Path)),
+			summary,
+			"")
+
+		var lines []string
+		for i, item := range ips.items {
+			conn := item.Incoming
+			line := fmt.Sprintf("%s %s@%s:%d", conn.Name, orDash(conn.User), conn.Host, conn.Port)
+			if conn.Group != "" {
+				line += " [" + conn.Group + "]"
+			}
+			if item.Existing != nil && item.Existing.Name != conn.Name {
+				line += " ↔ " + item.Existing.Name
+			}
+			line += " → " + importActionLabels[item.Action]
+			if item.Warning != "" {
+				line += conflictStyle.Render(" ⚠")
+			}
+
+			switch item.Status {
+			case services.ImportStatusNew:
+				line = newStyle.Render("+ ") + line
+			case services.ImportStatusIdentical:
+				line = mutedStyle.Render("= " + line)
+			case services.ImportStatusRemoved:
+				line = removedStyle.Render("- ") + line
+			default:
+				line = conflictStyle.Render("≠ ") + line
+			}
+
+			if i == ips.cursor {
+				line = selectedStyle.Render("> ") + line
+			} else {
+				line = "  " + line
+			}
+			lines = append(lines, line)
+		}
+
+		// Оставляем место под заголовок, сообщения, различия и инструкции
+		visible := max(ips.height-20, 5)
+		if ips.cursor < ips.offset {
+			ips.offset = ips.cursor
+		} else if ips.cursor >= ips.offset+visible {
+			ips.offset = ips.cursor - visible + 1
+		}
+		end := min(ips.offset+visible, len(lines))
+		contentParts = append(contentParts, lines[ips.offset:end]...)
+		if end < len(lines) {
+			contentParts = append(contentParts, mutedStyle.Render(fmt.Sprintf("… еще %d", len(lines)-end)))
+		}
+
+		// Предупреждение выбранного подключения
+		if ips.cursor < len(ips.items) && ips.items[ips.cursor].Warning != "" {
+			contentParts = append(contentParts, "", conflictStyle.Render("⚠ "+ips.items[ips.cursor].Warning))
+		}
+
+		// Различия выбранного конфликта
+		if ips.cursor < len(ips.items) && ips.items[ips.cursor].Status == services.ImportStatusConflict {
+			contentParts = append(contentParts, "", headerStyle.Render("Отличия от существующего:"))
+			for _, change := range ips.items[ips.cursor].Changes {
+				contentParts = append(contentParts, fmt.Sprintf("  %s: %s → %s",
+					change.Field, mutedStyle.Render(orDash(change.Old)), orDash(change.New)))
+			}
+		}
+	}
+
+	contentParts = append(contentParts, "",
+		instructionsStyle.Render("↑/↓ выбор • ←/→ действие строки • s/o/m/b пропустить/заменить/объединить/оставить оба для всех конфликтов • Enter применить • Esc назад"))
+
+	ips.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+}
+
+// Init инициализирует экран
+func (ips *ImportPreviewScreen) Init() tea.Cmd {
+	return nil
+}
+
+// GetName возвращает имя экрана
+func (ips *ImportPreviewScreen) GetName() string {
+	return "import_preview"
+}
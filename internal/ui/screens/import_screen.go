@@ -1,8 +1,10 @@
 package screens
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"ssh-keeper/internal/models"
 	"ssh-keeper/internal/services"
 	"ssh-keeper/internal/ui"
 	"ssh-keeper/internal/ui/components"
@@ -39,6 +41,17 @@ func NewImportScreen() *ImportScreen {
 		FieldType:   components.FieldTypeText,
 	})
 
+	// Пароль зашифрованного файла; поле появляется, когда выбран такой файл
+	formManager.AddField(components.FieldConfig{
+		Name:        "import_passphrase",
+		Label:       "Пароль файла",
+		Width:       30,
+		MaxLength:   128,
+		Placeholder: "пароль, заданный при экспорте",
+		FieldType:   components.FieldTypePassword,
+	})
+	formManager.GetField("import_passphrase").SetVisible(false)
+
 	formManager.AddField(components.FieldConfig{
 		Name:      "import_button",
 		Label:     "Импорт",
@@ -123,6 +136,16 @@ func (is *ImportScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			for _, detail := range msg.Details {
 				is.messageManager.AddInfo(detail)
 			}
+		case "preview":
+			return is, ui.NavigateToWithDataCmd("import_preview", msg.Preview)
+		case "passphrase":
+			// Файл зашифрован: показываем поле пароля и переводим на него фокус
+			passphraseField := is.formManager.GetField("import_passphrase")
+			passphraseField.SetVisible(true)
+			passphraseField.SetValue("")
+			is.formManager.SetCurrentField("import_passphrase")
+			is.formManager.UpdateFocus()
+			is.messageManager.AddWarning(msg.Message)
 		case "warning":
 			is.messageManager.AddWarning(msg.Message)
 			// Добавляем дополнительные детали
@@ -159,7 +182,7 @@ func (is *ImportScreen) updateContent() {
 	header := headerStyle.Render("Импорт конфигурации SSH")
 
 	// Создаем описание
-	description := descriptionStyle.Render("Импорт загрузит подключения из файла конфигурации SSH. Пароли будут зашифрованы мастер-паролем.")
+	description := descriptionStyle.Render("Импорт загрузит подключения из файла конфигурации SSH или зашифрованного экспорта (будет запрошен пароль файла). Пароли будут зашифрованы мастер-паролем.")
 
 	// Рендерим форму
 	formContent := is.formManager.RenderForm()
@@ -202,51 +225,71 @@ func (is *ImportScreen) performImport() tea.Cmd {
 			}
 		}
 
-		// Получаем количество подключений до импорта
-		connectionsBefore := len(is.connectionService.GetAllConnections())
-
-		// Выполняем импорт с шифрованием паролей
-		err := is.connectionService.ImportConfigPlain(importPath)
+		// Все источники, кроме зашифрованного экспорта, сразу открывают предпросмотр
+		var connections []models.Connection
+		var err error
+		source := services.DetectImportSource(importPath)
+		switch source {
+		case services.ImportSourceBundle:
+			return is.importBundle(importPath)
+		case services.ImportSourceSSHConfig:
+			connections, err = is.connectionService.ParseSSHConfigFile(importPath)
+		default:
+			connections, err = services.ParseImportFile(importPath, source)
+		}
 		if err != nil {
-			// Проверяем, является ли это ошибкой о дубликатах
-			if strings.Contains(err.Error(), "all") && strings.Contains(err.Error(), "connections already exist") {
-				return ImportResultMsg{
-					Success: true,
-					Message: fmt.Sprintf("%v", err),
-					Type:    "warning",
-					Details: []string{"All connections from file already exist in database"},
-				}
-			} else {
-				return ImportResultMsg{
-					Success: false,
-					Message: fmt.Sprintf("Ошибка импорта: %v", err),
-					Type:    "error",
-				}
+			return ImportResultMsg{
+				Message: fmt.Sprintf("Ошибка импорта (%s): %v", source.Label(), err),
+				Type:    "error",
 			}
 		}
 
-		// Получаем количество подключений после импорта
-		connectionsAfter := len(is.connectionService.GetAllConnections())
-		importedCount := connectionsAfter - connectionsBefore
-
 		return ImportResultMsg{
 			Success: true,
-			Message: fmt.Sprintf("Конфигурация успешно импортирована из %s", importPath),
-			Type:    "success",
-			Details: []string{
-				fmt.Sprintf("Импортировано %d новых подключений", importedCount),
-				"All passwords encrypted with master password",
-			},
+			Type:    "preview",
+			Preview: &ImportPreviewData{Source: source, Path: importPath, Connections: connections},
 		}
 	}
 }
 
+// importBundle расшифровывает файл экспорта, запрашивая пароль, и открывает предпросмотр
+func (is *ImportScreen) importBundle(importPath string) tea.Msg {
+	passphrase := is.formManager.GetField("import_passphrase").Value()
+	if passphrase == "" {
+		return ImportResultMsg{
+			Message: "Файл зашифрован - введите пароль, заданный при экспорте",
+			Type:    "passphrase",
+		}
+	}
+
+	connections, err := services.ParseBundle(importPath, passphrase)
+	switch {
+	case errors.Is(err, services.ErrBundlePassphrase):
+		return ImportResultMsg{
+			Message: fmt.Sprintf("Не удалось расшифровать: %v", err),
+			Type:    "passphrase",
+		}
+	case err != nil:
+		return ImportResultMsg{
+			Message: fmt.Sprintf("Ошибка импорта: %v", err),
+			Type:    "error",
+		}
+	}
+
+	return ImportResultMsg{
+		Success: true,
+		Type:    "preview",
+		Preview: &ImportPreviewData{Source: services.ImportSourceBundle, Path: importPath, Connections: connections},
+	}
+}
+
 // ImportResultMsg сообщение с результатом импорта
 type ImportResultMsg struct {
 	Success bool
 	Message string
 	Type    string
 	Details []string
+	Preview *ImportPreviewData // Для Type "preview": разобранные подключения
 }
 
 // Init инициализирует экран
@@ -0,0 +1,179 @@
+package screens
+
+import (
+	"fmt"
+	"ssh-keeper/internal/services"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"ssh-keeper/internal/ui/styles"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// KeysScreen представляет экран управления SSH ключами
+type KeysScreen struct {
+	*BaseScreen
+	list           list.Model
+	keyService     *services.SSHKeyService
+	messageManager *components.MessageManager
+}
+
+// NewKeysScreen создает новый экран SSH ключей
+func NewKeysScreen() *KeysScreen {
+	baseScreen := NewBaseScreen("SSH Keeper - SSH ключи")
+
+	// Создаем список (компактный, без фильтрации)
+	l := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.SetShowHelp(false)
+
+	// Отключаем обработку клавиши 'q' в списке
+	l.KeyMap.Quit.SetKeys("ctrl+q")
+
+	l.Styles.PaginationStyle = lipgloss.NewStyle().
+		Margin(1, 0, 0, 0)
+
+	screen := &KeysScreen{
+		BaseScreen:     baseScreen,
+		list:           l,
+		keyService:     services.GetGlobalSSHKeyService(),
+		messageManager: components.NewMessageManager(),
+	}
+
+	screen.refreshKeys()
+
+	return screen
+}
+
+// refreshKeys перечитывает ключи из ~/.ssh
+func (ks *KeysScreen) refreshKeys() {
+	keys, err := ks.keyService.ListKeys()
+	if err != nil {
+		ks.messageManager.AddError(fmt.Sprintf("Ошибка чтения ключей: %v", err))
+		return
+	}
+
+	items := make([]list.Item, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, components.NewKeyItem(key))
+	}
+	ks.list.SetItems(items)
+}
+
+// showSelectedKey выводит подробности о выбранном ключе
+func (ks *KeysScreen) showSelectedKey() {
+	item, ok := ks.list.SelectedItem().(components.KeyItem)
+	if !ok {
+		return
+	}
+	key := item.GetKey()
+
+	ks.messageManager.AddInfo(fmt.Sprintf("Ключ: %s", key.Path))
+	if key.Error != "" {
+		ks.messageManager.AddError(key.Error)
+		return
+	}
+	if key.PublicKeyPath == "" {
+		ks.messageManager.AddWarning("Файл .pub не найден")
+	}
+	if key.Encrypted {
+		ks.messageManager.AddInfo("Ключ защищен парольной фразой")
+	}
+	for _, warning := range key.Warnings {
+		ks.messageManager.AddWarning(warning)
+	}
+}
+
+// Update обрабатывает обновления состояния
+func (ks *KeysScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		ks.SetSize(msg.Width, msg.Height)
+		ks.list.SetSize(msg.Width-4, msg.Height-14)
+		return ks, nil
+
+	case ui.NavigateToMsg, ui.GoBackMsg:
+		// Обновляем список при возврате на экран (например, после генерации ключа)
+		ks.refreshKeys()
+		return ks, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return ks, tea.Quit
+		case "esc":
+			return ks, ui.GoBackCmd()
+		case "enter":
+			ks.showSelectedKey()
+			return ks, nil
+		case "ctrl+n":
+			return ks, ui.NavigateToCmd("generate_key")
+		case "ctrl+r":
+			ks.refreshKeys()
+			ks.messageManager.AddInfo("Список ключей обновлен")
+			return ks, nil
+		}
+	}
+
+	// Обновляем список
+	ks.list, cmd = ks.list.Update(msg)
+
+	return ks, cmd
+}
+
+// View возвращает строку для отрисовки
+func (ks *KeysScreen) View() string {
+	ks.updateContent()
+	return ks.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (ks *KeysScreen) updateContent() {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorPrimary)).
+		Bold(true).
+		Margin(0, 0, 1, 0)
+
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted)).
+		Italic(styles.TextItalic).
+		Width(80)
+
+	header := headerStyle.Render(fmt.Sprintf("Ключи в %s", ks.keyService.GetSSHDir()))
+
+	var listContent string
+	if len(ks.list.Items()) == 0 {
+		listContent = lipgloss.NewStyle().
+			Foreground(lipgloss.Color(styles.ColorMuted)).
+			Render("Приватные ключи не найдены. Нажмите Ctrl+N, чтобы создать ключ.")
+	} else {
+		listContent = ks.list.View()
+	}
+
+	instructions := instructionsStyle.Render("↑/↓ нав. • Enter подробнее • Ctrl+N новый ключ • Ctrl+R обновить • Esc назад")
+
+	var contentParts []string
+	if messages := ks.messageManager.RenderMessages(80); messages != "" {
+		contentParts = append(contentParts, messages)
+	}
+	contentParts = append(contentParts, header, listContent, "", instructions)
+
+	ks.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+}
+
+// Init инициализирует экран
+func (ks *KeysScreen) Init() tea.Cmd {
+	ks.refreshKeys()
+	return nil
+}
+
+// GetName возвращает имя экрана
+func (ks *KeysScreen) GetName() string {
+	return "keys"
+}
@@ -0,0 +1,202 @@
+package screens
+
+import (
+	"fmt"
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/ssh"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"ssh-keeper/internal/ui/styles"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// multiplexDetachedMsg пользователь вышел из сессии мультиплексора
+type multiplexDetachedMsg struct {
+	err error
+}
+
+// MultiplexScreen представляет экран открытия нескольких подключений в tmux/screen
+type MultiplexScreen struct {
+	*BaseScreen
+	connections    []models.Connection
+	multiplexer    ssh.Multiplexer
+	sessionInput   textinput.Model
+	panes          bool
+	synchronize    bool
+	messageManager *components.MessageManager
+}
+
+// NewMultiplexScreen создает экран мультиплексора (для фабрики)
+func NewMultiplexScreen() *MultiplexScreen {
+	sessionInput := textinput.New()
+	sessionInput.Placeholder = ssh.DefaultMultiplexSession
+	sessionInput.SetValue(ssh.DefaultMultiplexSession)
+	sessionInput.CharLimit = 50
+	sessionInput.Width = 30
+	sessionInput.Focus()
+
+	screen := &MultiplexScreen{
+		BaseScreen:     NewBaseScreen("SSH Keeper - Открыть в tmux/screen"),
+		sessionInput:   sessionInput,
+		messageManager: components.NewMessageManager(),
+	}
+
+	multiplexer, err := ssh.DetectMultiplexer()
+	if err != nil {
+		screen.messageManager.AddError(fmt.Sprintf("Ошибка: %v", err))
+	}
+	screen.multiplexer = multiplexer
+
+	return screen
+}
+
+// SetData устанавливает список подключений для открытия
+func (ms *MultiplexScreen) SetData(data interface{}) {
+	connections, ok := data.([]models.Connection)
+	if !ok || len(connections) == 0 {
+		ms.messageManager.AddError("Ошибка: не выбраны подключения")
+		return
+	}
+
+	ms.connections = connections
+	ms.BaseScreen.SetTitle(fmt.Sprintf("SSH Keeper - Открыть в %s (%d серв.)", ms.multiplexerName(), len(connections)))
+}
+
+// multiplexerName возвращает имя найденного мультиплексора
+func (ms *MultiplexScreen) multiplexerName() string {
+	if ms.multiplexer == "" {
+		return "tmux/screen"
+	}
+	return string(ms.multiplexer)
+}
+
+// open создает окна или панели и переходит в сессию
+func (ms *MultiplexScreen) open() tea.Cmd {
+	if ms.multiplexer == "" || len(ms.connections) == 0 {
+		return nil
+	}
+
+	attach, err := ssh.OpenMultiplexed(ms.multiplexer, ms.connections, ssh.MultiplexOptions{
+		Session:     ms.sessionInput.Value(),
+		Panes:       ms.panes,
+		Synchronize: ms.synchronize,
+	})
+	if err != nil {
+		ms.messageManager.AddError(fmt.Sprintf("Ошибка: %v", err))
+		return nil
+	}
+
+	// Внутри tmux клиент уже переключен на сессию
+	if attach == nil {
+		ms.messageManager.AddSuccess(fmt.Sprintf("Подключения открыты в сессии %s", ms.sessionInput.Value()))
+		return nil
+	}
+
+	return tea.ExecProcess(attach, func(err error) tea.Msg {
+		return multiplexDetachedMsg{err: err}
+	})
+}
+
+// Update обрабатывает обновления состояния
+func (ms *MultiplexScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		ms.SetSize(msg.Width, msg.Height)
+		return ms, nil
+
+	case multiplexDetachedMsg:
+		if msg.err != nil {
+			ms.messageManager.AddError(fmt.Sprintf("Ошибка подключения к сессии: %v", msg.err))
+		} else {
+			ms.messageManager.AddInfo("Вы вышли из сессии; она продолжает работать в фоне")
+		}
+		return ms, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return ms, tea.Quit
+		case "esc":
+			return ms, ui.GoBackCmd()
+		case "enter":
+			return ms, ms.open()
+		case "ctrl+p":
+			ms.panes = !ms.panes
+			return ms, nil
+		case "ctrl+s":
+			ms.synchronize = !ms.synchronize
+			return ms, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	ms.sessionInput, cmd = ms.sessionInput.Update(msg)
+	return ms, cmd
+}
+
+// View возвращает строку для отрисовки
+func (ms *MultiplexScreen) View() string {
+	ms.updateContent()
+	return ms.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (ms *MultiplexScreen) updateContent() {
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted)).
+		Italic(styles.TextItalic).
+		Width(80)
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorPrimary)).
+		Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorMuted))
+
+	var contentParts []string
+	if messages := ms.messageManager.RenderMessages(80); messages != "" {
+		contentParts = append(contentParts, messages)
+	}
+
+	contentParts = append(contentParts, labelStyle.Render("Подключения:"))
+	for _, conn := range ms.connections {
+		contentParts = append(contentParts, fmt.Sprintf("  • %s %s",
+			conn.Name, mutedStyle.Render(fmt.Sprintf("(%s@%s)", conn.User, conn.Host))))
+	}
+
+	layout := "отдельные окна"
+	if ms.panes || ms.synchronize {
+		layout = "панели одного окна"
+	}
+	synchronize := "выкл"
+	if ms.synchronize {
+		synchronize = "вкл"
+	}
+
+	contentParts = append(contentParts,
+		"",
+		labelStyle.Render("Сессия: ")+ms.sessionInput.View(),
+		labelStyle.Render("Раскладка: ")+layout,
+		labelStyle.Render("Синхронный ввод: ")+synchronize,
+	)
+
+	if ms.multiplexer == ssh.MultiplexerScreen {
+		contentParts = append(contentParts, mutedStyle.Render("GNU screen: подключения открываются окнами, синхронный ввод недоступен"))
+	}
+
+	contentParts = append(contentParts, "",
+		instructionsStyle.Render("Enter открыть • Ctrl+P окна/панели • Ctrl+S синхронный ввод • Esc назад"))
+
+	ms.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+}
+
+// Init инициализирует экран
+func (ms *MultiplexScreen) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// GetName возвращает имя экрана
+func (ms *MultiplexScreen) GetName() string {
+	return "multiplex"
+}
@@ -0,0 +1,428 @@
+package screens
+
+import (
+	"fmt"
+	"sort"
+	"ssh-keeper/internal/recording"
+	"ssh-keeper/internal/services"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"ssh-keeper/internal/ui/styles"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// recordingsSection активный раздел экрана записей
+type recordingsSection int
+
+const (
+	sectionRecordings recordingsSection = iota
+	sectionGroups
+)
+
+// recordingPlayedMsg воспроизведение записи завершено
+type recordingPlayedMsg struct {
+	err error
+}
+
+// RecordingsScreen представляет экран записей SSH сессий: список записей с
+// воспроизведением и настройка записи по группам
+type RecordingsScreen struct {
+	*BaseScreen
+	recordings     []services.RecordingInfo
+	groups         []string
+	settings       services.RecordingSettings
+	section        recordingsSection
+	cursor         int
+	groupCursor    int
+	speed          float64
+	confirmDelete  bool
+	messageManager *components.MessageManager
+}
+
+// NewRecordingsScreen создает экран записей сессий
+func NewRecordingsScreen() *RecordingsScreen {
+	return &RecordingsScreen{
+		BaseScreen:     NewBaseScreen("SSH Keeper - Записи сессий"),
+		speed:          1,
+		messageManager: components.NewMessageManager(),
+	}
+}
+
+// loadRecordings перечитывает записи, настройки и группы подключений
+func (rs *RecordingsScreen) loadRecordings() {
+	recordings := services.GetGlobalRecordingService()
+	if recordings == nil {
+		rs.messageManager.AddError("Сервис записи сессий не инициализирован")
+		return
+	}
+
+	list, err := recordings.List()
+	if err != nil {
+		rs.messageManager.AddError(fmt.Sprintf("Ошибка загрузки записей: %v", err))
+	}
+	rs.recordings = list
+
+	settings, err := recordings.LoadSettings()
+	if err != nil {
+		rs.messageManager.AddError(fmt.Sprintf("Ошибка загрузки настроек записи: %v", err))
+	}
+	rs.settings = settings
+
+	rs.groups = rs.collectGroups()
+	rs.cursor = min(rs.cursor, max(len(rs.recordings)-1, 0))
+	rs.groupCursor = min(rs.groupCursor, max(len(rs.groups)-1, 0))
+}
+
+// collectGroups собирает группы и теги всех подключений, а также группы из
+// настроек, для которых подключений уже нет
+func (rs *RecordingsScreen) collectGroups() []string {
+	seen := make(map[string]bool)
+	var groups []string
+	add := func(group string) {
+		key := strings.ToLower(group)
+		if group == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		groups = append(groups, group)
+	}
+
+	for _, conn := range services.GetConnections() {
+		add(conn.Group)
+		for _, tag := range conn.Tags {
+			add(tag)
+		}
+	}
+	for _, group := range rs.settings.Groups {
+		add(group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i]) < strings.ToLower(groups[j])
+	})
+	return groups
+}
+
+// groupEnabled проверяет, включена ли запись для группы
+func (rs *RecordingsScreen) groupEnabled(group string) bool {
+	for _, enabled := range rs.settings.Groups {
+		if strings.EqualFold(enabled, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Update обрабатывает обновления состояния
+func (rs *RecordingsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		rs.SetSize(msg.Width, msg.Height)
+		return rs, nil
+
+	case ui.NavigateToMsg:
+		if msg.ScreenName == "recordings" {
+			rs.confirmDelete = false
+			rs.loadRecordings()
+		}
+		return rs, nil
+
+	case recordingPlayedMsg:
+		if msg.err != nil {
+			rs.messageManager.AddError(fmt.Sprintf("Ошибка воспроизведения: %v", msg.err))
+		}
+		return rs, nil
+
+	case tea.KeyMsg:
+		if rs.confirmDelete {
+			return rs, rs.handleDeleteConfirm(msg)
+		}
+
+		switch msg.String() {
+		case "ctrl+c":
+			return rs, tea.Quit
+		case "esc":
+			return rs, ui.GoBackCmd()
+		case "tab":
+			if rs.section == sectionRecordings {
+				rs.section = sectionGroups
+			} else {
+				rs.section = sectionRecordings
+			}
+		case "up", "k":
+			rs.moveCursor(-1)
+		case "down", "j":
+			rs.moveCursor(1)
+		case "+", "=":
+			rs.speed = min(rs.speed*2, recording.MaxSpeed)
+		case "-", "_":
+			rs.speed = max(rs.speed/2, recording.MinSpeed)
+		case "ctrl+e":
+			rs.toggleEncryption()
+		case "ctrl+r":
+			rs.loadRecordings()
+		case "d", "delete":
+			if rs.section == sectionRecordings && len(rs.recordings) > 0 {
+				rs.confirmDelete = true
+			}
+		case "enter", " ":
+			if rs.section == sectionGroups {
+				rs.toggleGroup()
+				return rs, nil
+			}
+			if msg.String() == "enter" {
+				return rs, rs.play()
+			}
+		}
+	}
+
+	return rs, nil
+}
+
+// moveCursor перемещает курсор в активном разделе
+func (rs *RecordingsScreen) moveCursor(delta int) {
+	if rs.section == sectionGroups {
+		rs.groupCursor = min(max(rs.groupCursor+delta, 0), max(len(rs.groups)-1, 0))
+		return
+	}
+	rs.cursor = min(max(rs.cursor+delta, 0), max(len(rs.recordings)-1, 0))
+}
+
+// handleDeleteConfirm обрабатывает подтверждение удаления записи
+func (rs *RecordingsScreen) handleDeleteConfirm(msg tea.KeyMsg) tea.Cmd {
+	rs.confirmDelete = false
+
+	switch msg.String() {
+	case "y", "Y", "д", "Д":
+		info := rs.recordings[rs.cursor]
+		if err := services.GetGlobalRecordingService().Delete(info.Path); err != nil {
+			rs.messageManager.AddError(fmt.Sprintf("Ошибка удаления записи: %v", err))
+			return nil
+		}
+		rs.messageManager.AddSuccess("Запись удалена")
+		rs.loadRecordings()
+	case "ctrl+c":
+		return tea.Quit
+	}
+	return nil
+}
+
+// play запускает воспроизведение выбранной записи. Интерфейс на это время
+// освобождает терминал
+func (rs *RecordingsScreen) play() tea.Cmd {
+	if len(rs.recordings) == 0 {
+		return nil
+	}
+
+	cast, err := services.GetGlobalRecordingService().Load(rs.recordings[rs.cursor].Path)
+	if err != nil {
+		rs.messageManager.AddError(fmt.Sprintf("Ошибка загрузки записи: %v", err))
+		return nil
+	}
+	if len(cast.Events) == 0 {
+		rs.messageManager.AddWarning("Запись пуста")
+		return nil
+	}
+
+	return tea.Exec(recording.NewPlayer(cast, rs.speed), func(err error) tea.Msg {
+		return recordingPlayedMsg{err: err}
+	})
+}
+
+// toggleGroup включает или выключает запись для выбранной группы
+func (rs *RecordingsScreen) toggleGroup() {
+	if len(rs.groups) == 0 {
+		return
+	}
+
+	group := rs.groups[rs.groupCursor]
+	enabled, err := services.GetGlobalRecordingService().ToggleGroup(group)
+	if err != nil {
+		rs.messageManager.AddError(fmt.Sprintf("Ошибка сохранения настроек: %v", err))
+		return
+	}
+
+	if enabled {
+		rs.messageManager.AddSuccess(fmt.Sprintf("Запись сессий группы %s включена", group))
+	} else {
+		rs.messageManager.AddInfo(fmt.Sprintf("Запись сессий группы %s выключена", group))
+	}
+	rs.loadRecordings()
+}
+
+// toggleEncryption включает или выключает шифрование новых записей
+func (rs *RecordingsScreen) toggleEncryption() {
+	recordings := services.GetGlobalRecordingService()
+	if recordings == nil {
+		return
+	}
+
+	settings := rs.settings
+	settings.Encrypt = !settings.Encrypt
+	if settings.Encrypt {
+		encryption := services.GetGlobalEncryptionService()
+		if encryption == nil || !encryption.IsInitialized() {
+			rs.messageManager.AddError("Для шифрования записей нужен мастер-пароль")
+			return
+		}
+	}
+
+	if err := recordings.SaveSettings(settings); err != nil {
+		rs.messageManager.AddError(fmt.Sprintf("Ошибка сохранения настроек: %v", err))
+		return
+	}
+	rs.settings = settings
+
+	if settings.Encrypt {
+		rs.messageManager.AddSuccess("Новые записи будут шифроваться ключом хранилища")
+	} else {
+		rs.messageManager.AddInfo("Новые записи будут сохраняться без шифрования")
+	}
+}
+
+// View возвращает строку для отрисовки
+func (rs *RecordingsScreen) View() string {
+	rs.updateContent()
+	return rs.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (rs *RecordingsScreen) updateContent() {
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted)).
+		Italic(styles.TextItalic).
+		Width(80)
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorPrimary)).
+		Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorMuted))
+
+	var contentParts []string
+	if messages := rs.messageManager.RenderMessages(80); messages != "" {
+		contentParts = append(contentParts, messages)
+	}
+
+	encryption := "выкл"
+	if rs.settings.Encrypt {
+		encryption = "вкл"
+	}
+	contentParts = append(contentParts, mutedStyle.Render(
+		fmt.Sprintf("Скорость воспроизведения: %gx • Шифрование новых записей: %s", rs.speed, encryption)), "")
+
+	contentParts = append(contentParts, rs.sectionTitle(headerStyle, "Записи", sectionRecordings))
+	contentParts = append(contentParts, rs.renderRecordings(mutedStyle)...)
+
+	contentParts = append(contentParts, "", rs.sectionTitle(headerStyle, "Запись по группам и тегам", sectionGroups))
+	contentParts = append(contentParts, rs.renderGroups(mutedStyle)...)
+
+	contentParts = append(contentParts, "")
+	if rs.confirmDelete {
+		contentParts = append(contentParts, lipgloss.NewStyle().
+			Foreground(lipgloss.Color(styles.ColorWarning)).
+			Bold(true).
+			Render("Удалить выбранную запись? (y/n)"))
+	} else if rs.section == sectionRecordings {
+		contentParts = append(contentParts, instructionsStyle.Render(
+			"Enter воспроизвести • +/- скорость • D удалить • Tab группы • Ctrl+E шифрование • Ctrl+R обновить • Esc назад"))
+		contentParts = append(contentParts, instructionsStyle.Render(
+			"При воспроизведении: пробел пауза • +/- скорость • Q выход"))
+	} else {
+		contentParts = append(contentParts, instructionsStyle.Render(
+			"Enter/пробел включить или выключить запись группы • Tab записи • Ctrl+E шифрование • Esc назад"))
+	}
+
+	rs.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+}
+
+// sectionTitle отрисовывает заголовок раздела, выделяя активный
+func (rs *RecordingsScreen) sectionTitle(style lipgloss.Style, title string, section recordingsSection) string {
+	if rs.section == section {
+		return style.Render("▶ " + title)
+	}
+	return style.Faint(true).Render("  " + title)
+}
+
+// renderRecordings формирует список записей вокруг курсора
+func (rs *RecordingsScreen) renderRecordings(mutedStyle lipgloss.Style) []string {
+	if len(rs.recordings) == 0 {
+		return []string{mutedStyle.Render("Записей нет: включите запись для подключения или группы")}
+	}
+
+	// Оставляем место под группы и инструкции
+	visible := max(rs.height-24, 5)
+	start := 0
+	if rs.cursor >= visible {
+		start = rs.cursor - visible + 1
+	}
+	end := min(start+visible, len(rs.recordings))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorWarning)).
+		Bold(true)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		info := rs.recordings[i]
+
+		title := info.Title
+		if title == "" {
+			title = connectionName(info.ConnectionID, info.ConnectionID)
+		}
+		lock := ""
+		if info.Encrypted {
+			lock = " 🔒"
+		}
+
+		line := fmt.Sprintf("%s  %-40s %8s%s",
+			info.StartedAt.Format("02.01.2006 15:04"),
+			truncateRunes(title, 40),
+			components.FormatSize(info.Size),
+			lock,
+		)
+		if rs.section == sectionRecordings && i == rs.cursor {
+			lines = append(lines, selectedStyle.Render("> "+line))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
+
+// renderGroups формирует список групп с отметками включенной записи
+func (rs *RecordingsScreen) renderGroups(mutedStyle lipgloss.Style) []string {
+	if len(rs.groups) == 0 {
+		return []string{mutedStyle.Render("У подключений нет групп и тегов")}
+	}
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorWarning)).
+		Bold(true)
+
+	var lines []string
+	for i, group := range rs.groups {
+		check := "[ ]"
+		if rs.groupEnabled(group) {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", check, group)
+		if rs.section == sectionGroups && i == rs.groupCursor {
+			lines = append(lines, selectedStyle.Render("> "+line))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return lines
+}
+
+// Init инициализирует экран
+func (rs *RecordingsScreen) Init() tea.Cmd {
+	return nil
+}
+
+// GetName возвращает имя экрана
+func (rs *RecordingsScreen) GetName() string {
+	return "recordings"
+}
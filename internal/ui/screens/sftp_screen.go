@@ -0,0 +1,641 @@
+package screens
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/sftp"
+	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
+	"ssh-keeper/internal/ui/styles"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// sftpPrompt тип текущего запроса ввода на экране SFTP
+type sftpPrompt int
+
+const (
+	sftpPromptNone sftpPrompt = iota
+	sftpPromptMkdir
+	sftpPromptRename
+	sftpPromptDelete
+)
+
+// sftpConnectedMsg сообщение о результате подключения SFTP
+type sftpConnectedMsg struct {
+	client *sftp.Client
+	home   string
+	err    error
+}
+
+// sftpListingMsg сообщение с содержимым удаленного каталога
+type sftpListingMsg struct {
+	path    string
+	entries []components.FileEntry
+	err     error
+}
+
+// sftpOpResultMsg сообщение с результатом операции над удаленным файлом
+type sftpOpResultMsg struct {
+	message string
+	err     error
+}
+
+// sftpProgressMsg сообщение о ходе передачи
+type sftpProgressMsg struct {
+	done  int64
+	total int64
+}
+
+// sftpTransferDoneMsg сообщение о завершении передачи
+type sftpTransferDoneMsg struct {
+	name   string
+	upload bool
+	err    error
+}
+
+// sftpTransfer состояние текущей передачи файла
+type sftpTransfer struct {
+	name     string
+	upload   bool
+	done     int64
+	total    int64
+	cancel   context.CancelFunc
+	messages chan tea.Msg
+}
+
+// SFTPScreen представляет двухпанельный файловый менеджер SFTP
+type SFTPScreen struct {
+	*BaseScreen
+	connection     *models.Connection
+	client         *sftp.Client
+	local          *components.FilePane
+	remote         *components.FilePane
+	remoteActive   bool
+	messageManager *components.MessageManager
+	input          textinput.Model
+	prompt         sftpPrompt
+	transfer       *sftpTransfer
+}
+
+// NewSFTPScreen создает экран SFTP (для фабрики)
+func NewSFTPScreen() *SFTPScreen {
+	baseScreen := NewBaseScreen("SSH Keeper - SFTP")
+
+	input := textinput.New()
+	input.CharLimit = 255
+	input.Width = 50
+
+	screen := &SFTPScreen{
+		BaseScreen:     baseScreen,
+		local:          components.NewFilePane("Локально"),
+		remote:         components.NewFilePane("Сервер"),
+		messageManager: components.NewMessageManager(),
+		input:          input,
+	}
+
+	startDir, err := os.Getwd()
+	if err != nil {
+		startDir, _ = os.UserHomeDir()
+	}
+	screen.loadLocal(startDir)
+
+	return screen
+}
+
+// SetData устанавливает подключение для SFTP сессии
+func (ss *SFTPScreen) SetData(data interface{}) {
+	connection, ok := data.(models.Connection)
+	if !ok {
+		ss.messageManager.AddError("Ошибка: не удалось загрузить данные подключения")
+		return
+	}
+
+	ss.connection = &connection
+	ss.BaseScreen.SetTitle(fmt.Sprintf("SSH Keeper - SFTP '%s'", connection.Name))
+}
+
+// connect асинхронно открывает SFTP сессию
+func (ss *SFTPScreen) connect() tea.Cmd {
+	if ss.connection == nil || ss.client != nil {
+		return nil
+	}
+
+	ss.messageManager.AddInfo(fmt.Sprintf("Подключение к %s@%s...", ss.connection.User, ss.connection.Host))
+
+	conn := *ss.connection
+	return func() tea.Msg {
+		client, err := sftp.Dial(&conn)
+		if err != nil {
+			return sftpConnectedMsg{err: err}
+		}
+		home, err := client.RealPath(".")
+		if err != nil {
+			client.Close()
+			return sftpConnectedMsg{err: err}
+		}
+		return sftpConnectedMsg{client: client, home: home}
+	}
+}
+
+// loadLocal загружает содержимое локального каталога
+func (ss *SFTPScreen) loadLocal(dir string) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		ss.messageManager.AddError(fmt.Sprintf("Ошибка чтения каталога: %v", err))
+		return
+	}
+
+	entries := make([]components.FileEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		// Символические ссылки на каталоги показываем как каталоги
+		isDir := info.IsDir()
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := os.Stat(filepath.Join(dir, dirEntry.Name())); err == nil {
+				isDir = target.IsDir()
+			}
+		}
+		entries = append(entries, components.FileEntry{
+			Name:    dirEntry.Name(),
+			Size:    info.Size(),
+			IsDir:   isDir,
+			ModTime: info.ModTime(),
+		})
+	}
+
+	ss.local.SetEntries(dir, entries)
+}
+
+// loadRemote асинхронно загружает содержимое удаленного каталога
+func (ss *SFTPScreen) loadRemote(dir string) tea.Cmd {
+	client := ss.client
+	if client == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		infos, err := client.ReadDir(dir)
+		if err != nil {
+			return sftpListingMsg{path: dir, err: err}
+		}
+
+		entries := make([]components.FileEntry, 0, len(infos))
+		for _, info := range infos {
+			entries = append(entries, components.FileEntry{
+				Name:    info.Name,
+				Size:    int64(info.Size),
+				IsDir:   info.IsDir(),
+				ModTime: info.ModTime,
+			})
+		}
+		return sftpListingMsg{path: dir, entries: entries}
+	}
+}
+
+// activePane возвращает активную панель
+func (ss *SFTPScreen) activePane() *components.FilePane {
+	if ss.remoteActive {
+		return ss.remote
+	}
+	return ss.local
+}
+
+// openSelected переходит в выбранный каталог
+func (ss *SFTPScreen) openSelected() tea.Cmd {
+	entry, ok := ss.activePane().Selected()
+	if !ok || !entry.IsDir {
+		return nil
+	}
+	return ss.changeDir(entry.Name)
+}
+
+// changeDir переходит в подкаталог name ("..", если нужно подняться)
+func (ss *SFTPScreen) changeDir(name string) tea.Cmd {
+	if ss.remoteActive {
+		if ss.client == nil {
+			return nil
+		}
+		return ss.loadRemote(path.Clean(path.Join(ss.remote.Path, name)))
+	}
+
+	ss.loadLocal(filepath.Clean(filepath.Join(ss.local.Path, name)))
+	return nil
+}
+
+// startTransfer копирует выбранный файл в каталог другой панели
+func (ss *SFTPScreen) startTransfer() tea.Cmd {
+	if ss.client == nil {
+		ss.messageManager.AddError("Нет SFTP подключения")
+		return nil
+	}
+	if ss.transfer != nil {
+		ss.messageManager.AddWarning("Дождитесь завершения текущей передачи")
+		return nil
+	}
+
+	entry, ok := ss.activePane().Selected()
+	if !ok {
+		return nil
+	}
+	if entry.IsDir {
+		ss.messageManager.AddError("Передача каталогов не поддерживается")
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transfer := &sftpTransfer{
+		name:     entry.Name,
+		upload:   !ss.remoteActive,
+		total:    entry.Size,
+		cancel:   cancel,
+		messages: make(chan tea.Msg, 1),
+	}
+	ss.transfer = transfer
+
+	client := ss.client
+	localPath := filepath.Join(ss.local.Path, entry.Name)
+	remotePath := path.Join(ss.remote.Path, entry.Name)
+
+	progress := func(done, total int64) {
+		// Не блокируем передачу, если интерфейс не успевает обрабатывать прогресс
+		select {
+		case transfer.messages <- sftpProgressMsg{done: done, total: total}:
+		default:
+		}
+	}
+
+	go func() {
+		var err error
+		if transfer.upload {
+			err = client.Upload(ctx, localPath, remotePath, progress)
+		} else {
+			err = client.Download(ctx, remotePath, localPath, progress)
+		}
+		transfer.messages <- sftpTransferDoneMsg{name: entry.Name, upload: transfer.upload, err: err}
+	}()
+
+	return waitTransfer(transfer.messages)
+}
+
+// waitTransfer ожидает следующее сообщение от передачи
+func waitTransfer(messages <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-messages
+	}
+}
+
+// startPrompt открывает запрос ввода для операции над файлом
+func (ss *SFTPScreen) startPrompt(prompt sftpPrompt) tea.Cmd {
+	if ss.remoteActive && ss.client == nil {
+		return nil
+	}
+
+	entry, ok := ss.activePane().Selected()
+	if prompt != sftpPromptMkdir && !ok {
+		return nil
+	}
+
+	ss.prompt = prompt
+	ss.input.SetValue("")
+	switch prompt {
+	case sftpPromptMkdir:
+		ss.input.Placeholder = "Имя нового каталога"
+	case sftpPromptRename:
+		ss.input.Placeholder = "Новое имя"
+		ss.input.SetValue(entry.Name)
+	case sftpPromptDelete:
+		return nil
+	}
+	ss.input.Focus()
+	return textinput.Blink
+}
+
+// applyPrompt выполняет операцию, подтвержденную в запросе ввода
+func (ss *SFTPScreen) applyPrompt() tea.Cmd {
+	prompt := ss.prompt
+	value := strings.TrimSpace(ss.input.Value())
+	ss.prompt = sftpPromptNone
+	ss.input.Blur()
+
+	entry, _ := ss.activePane().Selected()
+
+	if prompt != sftpPromptDelete && (value == "" || strings.ContainsAny(value, "/\\")) {
+		ss.messageManager.AddError("Некорректное имя")
+		return nil
+	}
+
+	if ss.remoteActive {
+		return ss.applyRemote(prompt, entry, value)
+	}
+
+	ss.applyLocal(prompt, entry, value)
+	return nil
+}
+
+// applyLocal выполняет операцию над локальным файлом
+func (ss *SFTPScreen) applyLocal(prompt sftpPrompt, entry components.FileEntry, value string) {
+	dir := ss.local.Path
+	var err error
+	var message string
+
+	switch prompt {
+	case sftpPromptMkdir:
+		err = os.Mkdir(filepath.Join(dir, value), 0755)
+		message = fmt.Sprintf("Каталог %s создан", value)
+	case sftpPromptRename:
+		err = os.Rename(filepath.Join(dir, entry.Name), filepath.Join(dir, value))
+		message = fmt.Sprintf("%s переименован в %s", entry.Name, value)
+	case sftpPromptDelete:
+		// os.Remove удаляет только пустые каталоги - рекурсивное удаление не делаем намеренно
+		err = os.Remove(filepath.Join(dir, entry.Name))
+		message = fmt.Sprintf("%s удален", entry.Name)
+	}
+
+	if err != nil {
+		ss.messageManager.AddError(err.Error())
+	} else {
+		ss.messageManager.AddSuccess(message)
+	}
+	ss.loadLocal(dir)
+}
+
+// applyRemote асинхронно выполняет операцию над удаленным файлом
+func (ss *SFTPScreen) applyRemote(prompt sftpPrompt, entry components.FileEntry, value string) tea.Cmd {
+	client := ss.client
+	dir := ss.remote.Path
+
+	return func() tea.Msg {
+		switch prompt {
+		case sftpPromptMkdir:
+			err := client.Mkdir(path.Join(dir, value))
+			return sftpOpResultMsg{message: fmt.Sprintf("Каталог %s создан", value), err: err}
+		case sftpPromptRename:
+			err := client.Rename(path.Join(dir, entry.Name), path.Join(dir, value))
+			return sftpOpResultMsg{message: fmt.Sprintf("%s переименован в %s", entry.Name, value), err: err}
+		case sftpPromptDelete:
+			var err error
+			if entry.IsDir {
+				err = client.RemoveDir(path.Join(dir, entry.Name))
+			} else {
+				err = client.Remove(path.Join(dir, entry.Name))
+			}
+			return sftpOpResultMsg{message: fmt.Sprintf("%s удален", entry.Name), err: err}
+		}
+		return nil
+	}
+}
+
+// close закрывает SFTP сессию
+func (ss *SFTPScreen) close() {
+	if ss.transfer != nil {
+		ss.transfer.cancel()
+	}
+	if ss.client != nil {
+		ss.client.Close()
+		ss.client = nil
+	}
+}
+
+// Update обрабатывает обновления состояния
+func (ss *SFTPScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		ss.SetSize(msg.Width, msg.Height)
+		return ss, nil
+
+	case ui.NavigateToMsg:
+		if msg.ScreenName == "sftp" {
+			return ss, ss.connect()
+		}
+		return ss, nil
+
+	case sftpConnectedMsg:
+		if msg.err != nil {
+			ss.messageManager.AddError(msg.err.Error())
+			return ss, nil
+		}
+		ss.client = msg.client
+		ss.messageManager.AddSuccess("SFTP подключение установлено")
+		return ss, ss.loadRemote(msg.home)
+
+	case sftpListingMsg:
+		if msg.err != nil {
+			ss.messageManager.AddError(fmt.Sprintf("%s: %v", msg.path, msg.err))
+			return ss, nil
+		}
+		ss.remote.SetEntries(msg.path, msg.entries)
+		return ss, nil
+
+	case sftpOpResultMsg:
+		if msg.err != nil {
+			ss.messageManager.AddError(msg.err.Error())
+		} else {
+			ss.messageManager.AddSuccess(msg.message)
+		}
+		return ss, ss.loadRemote(ss.remote.Path)
+
+	case sftpProgressMsg:
+		if ss.transfer == nil {
+			return ss, nil
+		}
+		ss.transfer.done = msg.done
+		ss.transfer.total = msg.total
+		return ss, waitTransfer(ss.transfer.messages)
+
+	case sftpTransferDoneMsg:
+		ss.transfer = nil
+		switch {
+		case msg.err == context.Canceled:
+			ss.messageManager.AddWarning(fmt.Sprintf("Передача %s прервана, повторите для докачки", msg.name))
+		case msg.err != nil:
+			ss.messageManager.AddError(fmt.Sprintf("Ошибка передачи %s: %v", msg.name, msg.err))
+		case msg.upload:
+			ss.messageManager.AddSuccess(fmt.Sprintf("%s загружен на сервер", msg.name))
+		default:
+			ss.messageManager.AddSuccess(fmt.Sprintf("%s скачан", msg.name))
+		}
+		ss.loadLocal(ss.local.Path)
+		return ss, ss.loadRemote(ss.remote.Path)
+
+	case tea.KeyMsg:
+		if ss.prompt != sftpPromptNone {
+			return ss, ss.updatePrompt(msg)
+		}
+
+		switch msg.String() {
+		case "ctrl+c":
+			ss.close()
+			return ss, tea.Quit
+		case "esc":
+			if ss.transfer != nil {
+				ss.transfer.cancel()
+				return ss, nil
+			}
+			ss.close()
+			return ss, ui.GoBackCmd()
+		case "tab":
+			ss.remoteActive = !ss.remoteActive
+		case "up", "k":
+			ss.activePane().MoveCursor(-1)
+		case "down", "j":
+			ss.activePane().MoveCursor(1)
+		case "pgup":
+			ss.activePane().MoveCursor(-10)
+		case "pgdown":
+			ss.activePane().MoveCursor(10)
+		case "enter":
+			return ss, ss.openSelected()
+		case "backspace", "left":
+			return ss, ss.changeDir("..")
+		case "c", "f5":
+			return ss, ss.startTransfer()
+		case "m", "f7":
+			return ss, ss.startPrompt(sftpPromptMkdir)
+		case "r", "f6":
+			return ss, ss.startPrompt(sftpPromptRename)
+		case "d", "f8", "delete":
+			ss.startPrompt(sftpPromptDelete)
+		case "ctrl+r":
+			ss.loadLocal(ss.local.Path)
+			return ss, ss.loadRemote(ss.remote.Path)
+		}
+	}
+
+	return ss, nil
+}
+
+// updatePrompt обрабатывает ввод в активном запросе
+func (ss *SFTPScreen) updatePrompt(msg tea.KeyMsg) tea.Cmd {
+	if ss.prompt == sftpPromptDelete {
+		switch msg.String() {
+		case "y", "Y", "д", "Д":
+			return ss.applyPrompt()
+		default:
+			ss.prompt = sftpPromptNone
+		}
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		ss.prompt = sftpPromptNone
+		ss.input.Blur()
+		return nil
+	case "enter":
+		return ss.applyPrompt()
+	}
+
+	var cmd tea.Cmd
+	ss.input, cmd = ss.input.Update(msg)
+	return cmd
+}
+
+// View возвращает строку для отрисовки
+func (ss *SFTPScreen) View() string {
+	ss.updateContent()
+	return ss.BaseScreen.View()
+}
+
+// updateContent обновляет содержимое экрана
+func (ss *SFTPScreen) updateContent() {
+	promptStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorWarning)).
+		Bold(true)
+
+	instructionsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.ColorMuted)).
+		Italic(styles.TextItalic).
+		Width(100)
+
+	// Ширина контента экрана - ss.width-14, у каждой панели рамка в 2 символа и 1 символ между панелями
+	paneWidth := (ss.width - 20) / 2
+	if paneWidth < 20 {
+		paneWidth = 20
+	}
+	paneHeight := ss.height - 16
+	if paneHeight < 5 {
+		paneHeight = 5
+	}
+
+	panes := lipgloss.JoinHorizontal(lipgloss.Top,
+		ss.local.Render(paneWidth, paneHeight, !ss.remoteActive),
+		" ",
+		ss.remote.Render(paneWidth, paneHeight, ss.remoteActive),
+	)
+
+	contentParts := []string{panes}
+
+	if ss.transfer != nil {
+		contentParts = append(contentParts, renderTransferProgress(ss.transfer, paneWidth))
+	}
+
+	switch ss.prompt {
+	case sftpPromptMkdir, sftpPromptRename:
+		contentParts = append(contentParts, ss.input.View())
+	case sftpPromptDelete:
+		if entry, ok := ss.activePane().Selected(); ok {
+			contentParts = append(contentParts, promptStyle.Render(fmt.Sprintf("Удалить %s? (y/n)", entry.Name)))
+		}
+	}
+
+	if messages := ss.messageManager.RenderMessages(80); messages != "" {
+		contentParts = append(contentParts, messages)
+	}
+
+	instructionsText := "Tab панель • Enter открыть • ← вверх • C/F5 копировать • M/F7 каталог • R/F6 переим. • D/F8 удал. • Ctrl+R обновить • Esc назад"
+	if ss.transfer != nil {
+		instructionsText = "Esc прервать передачу (повторное копирование продолжит с места остановки)"
+	}
+	contentParts = append(contentParts, instructionsStyle.Render(instructionsText))
+
+	ss.SetContent(lipgloss.JoinVertical(lipgloss.Left, contentParts...))
+}
+
+// renderTransferProgress отрисовывает строку прогресса передачи
+func renderTransferProgress(transfer *sftpTransfer, width int) string {
+	direction := "⬇"
+	if transfer.upload {
+		direction = "⬆"
+	}
+
+	percent := 100
+	if transfer.total > 0 {
+		percent = int(transfer.done * 100 / transfer.total)
+	}
+
+	barWidth := width / 2
+	filled := barWidth * percent / 100
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+
+	barStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(styles.ColorSecondary))
+
+	return fmt.Sprintf("%s %s %s %3d%% %s / %s",
+		direction,
+		transfer.name,
+		barStyle.Render(bar),
+		percent,
+		components.FormatSize(transfer.done),
+		components.FormatSize(transfer.total),
+	)
+}
+
+// Init инициализирует экран
+func (ss *SFTPScreen) Init() tea.Cmd {
+	return nil
+}
+
+// GetName возвращает имя экрана
+func (ss *SFTPScreen) GetName() string {
+	return "sftp"
+}
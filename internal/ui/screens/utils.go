@@ -1,7 +1,11 @@
 package screens
 
 import (
+	"fmt"
+	"ssh-keeper/internal/models"
+	"ssh-keeper/internal/services"
 	"ssh-keeper/internal/ui"
+	"ssh-keeper/internal/ui/components"
 
 	"github.com/charmbracelet/bubbles/list"
 )
@@ -14,3 +18,23 @@ func convertToListItem(menuItems []ui.MenuItem) []list.Item {
 	}
 	return items
 }
+
+// validateConnectionKey проверяет SSH ключ подключения и выводит ошибки и предупреждения.
+// Возвращает false, если ключ указан, но не может быть использован
+func validateConnectionKey(conn *models.Connection, messageManager *components.MessageManager) bool {
+	if !conn.UseSSHKey || conn.KeyPath == "" {
+		return true
+	}
+
+	warnings, err := services.GetGlobalSSHKeyService().ValidateKeyPath(conn.KeyPath)
+	if err != nil {
+		messageManager.AddError(fmt.Sprintf("❌ SSH ключ: %v", err))
+		return false
+	}
+
+	for _, warning := range warnings {
+		messageManager.AddWarning(warning)
+	}
+
+	return true
+}